@@ -41,6 +41,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"addnode","params":["127.0.0.1","remove"],"id":1}`,
 			unmarshalled: &acmjson.AddNodeCmd{Addr: "127.0.0.1", SubCmd: acmjson.ANRemove},
 		},
+		{
+			name: "clearbanned",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("clearbanned")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewClearBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"clearbanned","params":[],"id":1}`,
+			unmarshalled: &acmjson.ClearBannedCmd{},
+		},
 		{
 			name: "createrawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -103,6 +114,61 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","params":["00"],"id":1}`,
 			unmarshalled: &acmjson.DecodeScriptCmd{HexScript: "00"},
 		},
+		{
+			name: "disconnectnode address",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("disconnectnode", "127.0.0.1")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewDisconnectNodeCmd(acmjson.String("127.0.0.1"), nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"disconnectnode","params":["127.0.0.1"],"id":1}`,
+			unmarshalled: &acmjson.DisconnectNodeCmd{
+				Address: acmjson.String("127.0.0.1"),
+			},
+		},
+		{
+			name: "disconnectnode nodeid",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("disconnectnode", "", 5)
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewDisconnectNodeCmd(acmjson.String(""), acmjson.Int32(5))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"disconnectnode","params":["",5],"id":1}`,
+			unmarshalled: &acmjson.DisconnectNodeCmd{
+				Address: acmjson.String(""),
+				NodeID:  acmjson.Int32(5),
+			},
+		},
+		{
+			name: "estimatesmartfee",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("estimatesmartfee", 6)
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewEstimateSmartFeeCmd(6, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6],"id":1}`,
+			unmarshalled: &acmjson.EstimateSmartFeeCmd{
+				ConfTarget:   6,
+				EstimateMode: &acmjson.EstimateModeConservative,
+			},
+		},
+		{
+			name: "estimatesmartfee optional",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("estimatesmartfee", 6, "ECONOMICAL")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewEstimateSmartFeeCmd(6, &acmjson.EstimateModeEconomical)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6,"ECONOMICAL"],"id":1}`,
+			unmarshalled: &acmjson.EstimateSmartFeeCmd{
+				ConfTarget:   6,
+				EstimateMode: &acmjson.EstimateModeEconomical,
+			},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -128,6 +194,88 @@ func TestChainSvrCmds(t *testing.T) {
 				Node: acmjson.String("127.0.0.1"),
 			},
 		},
+		{
+			name: "getaddressbalance",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getaddressbalance", `{"addresses":["1Address"]}`)
+			},
+			staticCmd: func() interface{} {
+				query := acmjson.AddressIndexQuery{
+					Addresses: []string{"1Address"},
+				}
+				return acmjson.NewGetAddressBalanceCmd(query)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressbalance","params":[{"addresses":["1Address"]}],"id":1}`,
+			unmarshalled: &acmjson.GetAddressBalanceCmd{
+				Query: acmjson.AddressIndexQuery{
+					Addresses: []string{"1Address"},
+				},
+			},
+		},
+		{
+			name: "getaddressdeltas",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getaddressdeltas", `{"addresses":["1Address","3Address"],"start":100,"end":200,"includemempool":true}`)
+			},
+			staticCmd: func() interface{} {
+				query := acmjson.AddressIndexQuery{
+					Addresses:      []string{"1Address", "3Address"},
+					Start:          acmjson.Int32(100),
+					End:            acmjson.Int32(200),
+					IncludeMempool: acmjson.Bool(true),
+				}
+				return acmjson.NewGetAddressDeltasCmd(query)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressdeltas","params":[{"addresses":["1Address","3Address"],"start":100,"end":200,"includemempool":true}],"id":1}`,
+			unmarshalled: &acmjson.GetAddressDeltasCmd{
+				Query: acmjson.AddressIndexQuery{
+					Addresses:      []string{"1Address", "3Address"},
+					Start:          acmjson.Int32(100),
+					End:            acmjson.Int32(200),
+					IncludeMempool: acmjson.Bool(true),
+				},
+			},
+		},
+		{
+			name: "getaddresstxids",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getaddresstxids", `{"addresses":["1Address"],"start":100}`)
+			},
+			staticCmd: func() interface{} {
+				query := acmjson.AddressIndexQuery{
+					Addresses: []string{"1Address"},
+					Start:     acmjson.Int32(100),
+				}
+				return acmjson.NewGetAddressTxIDsCmd(query)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddresstxids","params":[{"addresses":["1Address"],"start":100}],"id":1}`,
+			unmarshalled: &acmjson.GetAddressTxIDsCmd{
+				Query: acmjson.AddressIndexQuery{
+					Addresses: []string{"1Address"},
+					Start:     acmjson.Int32(100),
+				},
+			},
+		},
+		{
+			name: "getaddressutxos",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getaddressutxos", `{"addresses":["1Address"],"includemempool":false}`)
+			},
+			staticCmd: func() interface{} {
+				query := acmjson.AddressIndexQuery{
+					Addresses:      []string{"1Address"},
+					IncludeMempool: acmjson.Bool(false),
+				}
+				return acmjson.NewGetAddressUtxosCmd(query)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressutxos","params":[{"addresses":["1Address"],"includemempool":false}],"id":1}`,
+			unmarshalled: &acmjson.GetAddressUtxosCmd{
+				Query: acmjson.AddressIndexQuery{
+					Addresses:      []string{"1Address"},
+					IncludeMempool: acmjson.Bool(false),
+				},
+			},
+		},
 		{
 			name: "getbestblockhash",
 			newCmd: func() (interface{}, error) {
@@ -210,6 +358,34 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getblockcount","params":[],"id":1}`,
 			unmarshalled: &acmjson.GetBlockCountCmd{},
 		},
+		{
+			name: "getblockfilter",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getblockfilter", "0000afaf")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetBlockFilterCmd("0000afaf", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockfilter","params":["0000afaf"],"id":1}`,
+			unmarshalled: &acmjson.GetBlockFilterCmd{
+				BlockHash:  "0000afaf",
+				FilterType: &acmjson.FilterTypeBasic,
+			},
+		},
+		{
+			name: "getblockfilter optional filtertype",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getblockfilter", "0000afaf", "basic")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetBlockFilterCmd("0000afaf", &acmjson.FilterTypeBasic)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockfilter","params":["0000afaf","basic"],"id":1}`,
+			unmarshalled: &acmjson.GetBlockFilterCmd{
+				BlockHash:  "0000afaf",
+				FilterType: &acmjson.FilterTypeBasic,
+			},
+		},
 		{
 			name: "getblockhash",
 			newCmd: func() (interface{}, error) {
@@ -235,6 +411,33 @@ func TestChainSvrCmds(t *testing.T) {
 				Verbose: acmjson.Bool(true),
 			},
 		},
+		{
+			name: "getblockstats height",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getblockstats", acmjson.HashOrHeight("123"))
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetBlockStatsCmd("123", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockstats","params":[123],"id":1}`,
+			unmarshalled: &acmjson.GetBlockStatsCmd{
+				HashOrHeight: "123",
+			},
+		},
+		{
+			name: "getblockstats hash",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getblockstats", "deadbeef", []string{"avgfee", "txs"})
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetBlockStatsCmd("deadbeef", &[]string{"avgfee", "txs"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockstats","params":["deadbeef",["avgfee","txs"]],"id":1}`,
+			unmarshalled: &acmjson.GetBlockStatsCmd{
+				HashOrHeight: "deadbeef",
+				Stats:        &[]string{"avgfee", "txs"},
+			},
+		},
 		{
 			name: "getblocktemplate",
 			newCmd: func() (interface{}, error) {
@@ -444,6 +647,62 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getinfo","params":[],"id":1}`,
 			unmarshalled: &acmjson.GetInfoCmd{},
 		},
+		{
+			name: "getmempoolancestors",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getmempoolancestors", "txhash")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetMempoolAncestorsCmd("txhash", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempoolancestors","params":["txhash"],"id":1}`,
+			unmarshalled: &acmjson.GetMempoolAncestorsCmd{
+				TxID:    "txhash",
+				Verbose: acmjson.Bool(false),
+			},
+		},
+		{
+			name: "getmempoolancestors optional",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getmempoolancestors", "txhash", true)
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetMempoolAncestorsCmd("txhash", acmjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempoolancestors","params":["txhash",true],"id":1}`,
+			unmarshalled: &acmjson.GetMempoolAncestorsCmd{
+				TxID:    "txhash",
+				Verbose: acmjson.Bool(true),
+			},
+		},
+		{
+			name: "getmempooldescendants",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getmempooldescendants", "txhash")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetMempoolDescendantsCmd("txhash", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldescendants","params":["txhash"],"id":1}`,
+			unmarshalled: &acmjson.GetMempoolDescendantsCmd{
+				TxID:    "txhash",
+				Verbose: acmjson.Bool(false),
+			},
+		},
+		{
+			name: "getmempooldescendants optional",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getmempooldescendants", "txhash", true)
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetMempoolDescendantsCmd("txhash", acmjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldescendants","params":["txhash",true],"id":1}`,
+			unmarshalled: &acmjson.GetMempoolDescendantsCmd{
+				TxID:    "txhash",
+				Verbose: acmjson.Bool(true),
+			},
+		},
 		{
 			name: "getmempoolentry",
 			newCmd: func() (interface{}, error) {
@@ -543,6 +802,32 @@ func TestChainSvrCmds(t *testing.T) {
 				Height: acmjson.Int(123),
 			},
 		},
+		{
+			name: "getnodeaddresses",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getnodeaddresses")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetNodeAddressesCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnodeaddresses","params":[],"id":1}`,
+			unmarshalled: &acmjson.GetNodeAddressesCmd{
+				Count: acmjson.Int32(1),
+			},
+		},
+		{
+			name: "getnodeaddresses optional",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("getnodeaddresses", 10)
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewGetNodeAddressesCmd(acmjson.Int32(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnodeaddresses","params":[10],"id":1}`,
+			unmarshalled: &acmjson.GetNodeAddressesCmd{
+				Count: acmjson.Int32(10),
+			},
+		},
 		{
 			name: "getpeerinfo",
 			newCmd: func() (interface{}, error) {
@@ -744,6 +1029,17 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "listbanned",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("listbanned")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewListBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listbanned","params":[],"id":1}`,
+			unmarshalled: &acmjson.ListBannedCmd{},
+		},
 		{
 			name: "ping",
 			newCmd: func() (interface{}, error) {
@@ -768,6 +1064,19 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "0123",
 			},
 		},
+		{
+			name: "pruneblockchain",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("pruneblockchain", 1000)
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewPruneBlockchainCmd(1000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"pruneblockchain","params":[1000],"id":1}`,
+			unmarshalled: &acmjson.PruneBlockchainCmd{
+				Height: 1000,
+			},
+		},
 		{
 			name: "reconsiderblock",
 			newCmd: func() (interface{}, error) {
@@ -781,6 +1090,46 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "savemempool",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("savemempool")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewSaveMempoolCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"savemempool","params":[],"id":1}`,
+			unmarshalled: &acmjson.SaveMempoolCmd{},
+		},
+		{
+			name: "scantxoutset",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("scantxoutset", "status")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewScanTxOutSetCmd(acmjson.STOStatus, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"scantxoutset","params":["status"],"id":1}`,
+			unmarshalled: &acmjson.ScanTxOutSetCmd{
+				Action: acmjson.STOStatus,
+			},
+		},
+		{
+			name: "scantxoutset optional",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("scantxoutset", "start",
+					[]string{"addr(1Address)", "raw(76a914)"})
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewScanTxOutSetCmd(acmjson.STOStart,
+					&[]string{"addr(1Address)", "raw(76a914)"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"scantxoutset","params":["start",["addr(1Address)","raw(76a914)"]],"id":1}`,
+			unmarshalled: &acmjson.ScanTxOutSetCmd{
+				Action:      acmjson.STOStart,
+				ScanObjects: &[]string{"addr(1Address)", "raw(76a914)"},
+			},
+		},
 		{
 			name: "searchrawtransactions",
 			newCmd: func() (interface{}, error) {
@@ -948,6 +1297,39 @@ func TestChainSvrCmds(t *testing.T) {
 				AllowHighFees: acmjson.Bool(false),
 			},
 		},
+		{
+			name: "setban",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("setban", "1.2.3.0/24", "add")
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewSetBanCmd("1.2.3.0/24", acmjson.SBAdd, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["1.2.3.0/24","add"],"id":1}`,
+			unmarshalled: &acmjson.SetBanCmd{
+				Subnet:   "1.2.3.0/24",
+				SubCmd:   acmjson.SBAdd,
+				BanTime:  acmjson.Int64(0),
+				Absolute: acmjson.Bool(false),
+			},
+		},
+		{
+			name: "setban optional",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("setban", "1.2.3.4", "add", 1577836800, true)
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewSetBanCmd("1.2.3.4", acmjson.SBAdd,
+					acmjson.Int64(1577836800), acmjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["1.2.3.4","add",1577836800,true],"id":1}`,
+			unmarshalled: &acmjson.SetBanCmd{
+				Subnet:   "1.2.3.4",
+				SubCmd:   acmjson.SBAdd,
+				BanTime:  acmjson.Int64(1577836800),
+				Absolute: acmjson.Bool(true),
+			},
+		},
 		{
 			name: "setgenerate",
 			newCmd: func() (interface{}, error) {
@@ -1020,6 +1402,19 @@ func TestChainSvrCmds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "testmempoolaccept",
+			newCmd: func() (interface{}, error) {
+				return acmjson.NewCmd("testmempoolaccept", []string{"rawtx1", "rawtx2"})
+			},
+			staticCmd: func() interface{} {
+				return acmjson.NewTestMempoolAcceptCmd([]string{"rawtx1", "rawtx2"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"testmempoolaccept","params":[["rawtx1","rawtx2"]],"id":1}`,
+			unmarshalled: &acmjson.TestMempoolAcceptCmd{
+				RawTxns: []string{"rawtx1", "rawtx2"},
+			},
+		},
 		{
 			name: "uptime",
 			newCmd: func() (interface{}, error) {
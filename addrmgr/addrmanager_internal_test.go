@@ -1,6 +1,7 @@
 package addrmgr
 
 import (
+	"bytes"
 	"io/ioutil"
 	"math/rand"
 	"net"
@@ -10,8 +11,8 @@ import (
 	"github.com/Actinium-project/acmd/wire"
 )
 
-// randAddr generates a *wire.NetAddress backed by a random IPv4/IPv6 address.
-func randAddr(t *testing.T) *wire.NetAddress {
+// randAddr generates a *wire.NetAddressV2 backed by a random IPv4/IPv6 address.
+func randAddr(t *testing.T) *wire.NetAddressV2 {
 	t.Helper()
 
 	ipv4 := rand.Intn(2) == 0
@@ -30,22 +31,23 @@ func randAddr(t *testing.T) *wire.NetAddress {
 		ip = b[:]
 	}
 
-	return &wire.NetAddress{
-		Services: wire.ServiceFlag(rand.Uint64()),
-		IP:       ip,
-		Port:     uint16(rand.Uint32()),
-	}
+	return wire.NewNetAddressV2IPPort(ip, uint16(rand.Uint32()),
+		wire.ServiceFlag(rand.Uint64()))
 }
 
 // assertAddr ensures that the two addresses match. The timestamp is not
 // checked as it does not affect uniquely identifying a specific address.
-func assertAddr(t *testing.T, got, expected *wire.NetAddress) {
+func assertAddr(t *testing.T, got, expected *wire.NetAddressV2) {
 	if got.Services != expected.Services {
 		t.Fatalf("expected address services %v, got %v",
 			expected.Services, got.Services)
 	}
-	if !got.IP.Equal(expected.IP) {
-		t.Fatalf("expected address IP %v, got %v", expected.IP, got.IP)
+	if got.Network != expected.Network {
+		t.Fatalf("expected address network %v, got %v",
+			expected.Network, got.Network)
+	}
+	if !bytes.Equal(got.Addr, expected.Addr) {
+		t.Fatalf("expected address %x, got %x", expected.Addr, got.Addr)
 	}
 	if got.Port != expected.Port {
 		t.Fatalf("expected address port %d, got %d", expected.Port,
@@ -56,7 +58,7 @@ func assertAddr(t *testing.T, got, expected *wire.NetAddress) {
 // assertAddrs ensures that the manager's address cache matches the given
 // expected addresses.
 func assertAddrs(t *testing.T, addrMgr *AddrManager,
-	expectedAddrs map[string]*wire.NetAddress) {
+	expectedAddrs map[string]*wire.NetAddressV2) {
 
 	t.Helper()
 
@@ -96,7 +98,7 @@ func TestAddrManagerSerialization(t *testing.T) {
 	// We'll be adding 5 random addresses to the manager.
 	const numAddrs = 5
 
-	expectedAddrs := make(map[string]*wire.NetAddress, numAddrs)
+	expectedAddrs := make(map[string]*wire.NetAddressV2, numAddrs)
 	for i := 0; i < numAddrs; i++ {
 		addr := randAddr(t)
 		expectedAddrs[NetAddressKey(addr)] = addr
@@ -141,7 +143,7 @@ func TestAddrManagerV1ToV2(t *testing.T) {
 	// each addresses' services will not be stored.
 	const numAddrs = 5
 
-	expectedAddrs := make(map[string]*wire.NetAddress, numAddrs)
+	expectedAddrs := make(map[string]*wire.NetAddressV2, numAddrs)
 	for i := 0; i < numAddrs; i++ {
 		addr := randAddr(t)
 		expectedAddrs[NetAddressKey(addr)] = addr
@@ -192,3 +194,63 @@ func TestAddrManagerV1ToV2(t *testing.T) {
 	addrMgr.loadPeers()
 	assertAddrs(t, addrMgr, expectedAddrs)
 }
+
+// TestAddrManagerV2ToV3 ensures that addresses of the networks introduced by
+// BIP0155 survive a round trip through the serialized address manager and
+// that addresses persisted by a v2 address manager are upgraded to v3.
+func TestAddrManagerV2ToV3(t *testing.T) {
+	t.Parallel()
+
+	tempDir, err := ioutil.TempDir("", "addrmgr")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cjdnsAddr := make([]byte, 16)
+	cjdnsAddr[0] = 0xfc
+	cjdnsAddr[15] = 0x01
+	legacyAddrs := []*wire.NetAddressV2{
+		wire.NewNetAddressV2IPPort(net.ParseIP("204.124.8.1"), 9333,
+			wire.SFNodeNetwork),
+		wire.NewNetAddressV2IPPort(
+			net.ParseIP("fd87:d87e:eb43:1234::5678"), 9333,
+			wire.SFNodeNetwork),
+		wire.NewNetAddressV2(wire.NetworkTorV3,
+			bytes.Repeat([]byte{0x11}, 32), 9333, wire.SFNodeNetwork),
+		wire.NewNetAddressV2(wire.NetworkI2P,
+			bytes.Repeat([]byte{0x22}, 32), 0, wire.SFNodeNetwork),
+	}
+	cjdns := wire.NewNetAddressV2(wire.NetworkCJDNS, cjdnsAddr, 9333,
+		wire.SFNodeNetwork)
+	src := wire.NewNetAddressV2IPPort(net.ParseIP("173.144.173.111"),
+		9333, wire.SFNodeNetwork)
+
+	// Persist the addresses which can be told apart by their string form
+	// using the v2 format which did not record the network.
+	addrMgr := New(tempDir, nil)
+	addrMgr.version = 2
+	expectedAddrs := make(map[string]*wire.NetAddressV2)
+	for _, addr := range legacyAddrs {
+		expectedAddrs[NetAddressKey(addr)] = addr
+		addrMgr.AddAddress(addr, src)
+	}
+	assertAddrs(t, addrMgr, expectedAddrs)
+	addrMgr.savePeers()
+
+	// Loading them with the current version must derive the networks from
+	// the address strings.
+	addrMgr = New(tempDir, nil)
+	addrMgr.loadPeers()
+	assertAddrs(t, addrMgr, expectedAddrs)
+
+	// CJDNS addresses are only distinguishable from IPv6 addresses by the
+	// network persisted by v3.
+	expectedAddrs[NetAddressKey(cjdns)] = cjdns
+	addrMgr.AddAddress(cjdns, src)
+	addrMgr.savePeers()
+
+	addrMgr = New(tempDir, nil)
+	addrMgr.loadPeers()
+	assertAddrs(t, addrMgr, expectedAddrs)
+}
@@ -5,6 +5,8 @@
 package addrmgr_test
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
@@ -19,7 +21,7 @@ import (
 // naTest is used to describe a test to be performed against the NetAddressKey
 // method.
 type naTest struct {
-	in   wire.NetAddress
+	in   wire.NetAddressV2
 	want string
 }
 
@@ -93,7 +95,7 @@ func addNaTests() {
 
 func addNaTest(ip string, port uint16, want string) {
 	nip := net.ParseIP(ip)
-	na := *wire.NewNetAddressIPPort(nip, port, wire.SFNodeNetwork)
+	na := *wire.NewNetAddressV2IPPort(nip, port, wire.SFNodeNetwork)
 	test := naTest{na, want}
 	naTests = append(naTests, test)
 }
@@ -157,37 +159,37 @@ func TestAddAddressByIP(t *testing.T) {
 
 func TestAddLocalAddress(t *testing.T) {
 	var tests = []struct {
-		address  wire.NetAddress
+		address  wire.NetAddressV2
 		priority addrmgr.AddressPriority
 		valid    bool
 	}{
 		{
-			wire.NetAddress{IP: net.ParseIP("192.168.0.100")},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("192.168.0.100"), 0, 0),
 			addrmgr.InterfacePrio,
 			false,
 		},
 		{
-			wire.NetAddress{IP: net.ParseIP("204.124.1.1")},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("204.124.1.1"), 0, 0),
 			addrmgr.InterfacePrio,
 			true,
 		},
 		{
-			wire.NetAddress{IP: net.ParseIP("204.124.1.1")},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("204.124.1.1"), 0, 0),
 			addrmgr.BoundPrio,
 			true,
 		},
 		{
-			wire.NetAddress{IP: net.ParseIP("::1")},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("::1"), 0, 0),
 			addrmgr.InterfacePrio,
 			false,
 		},
 		{
-			wire.NetAddress{IP: net.ParseIP("fe80::1")},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("fe80::1"), 0, 0),
 			addrmgr.InterfacePrio,
 			false,
 		},
 		{
-			wire.NetAddress{IP: net.ParseIP("2620:100::1")},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("2620:100::1"), 0, 0),
 			addrmgr.InterfacePrio,
 			true,
 		},
@@ -197,17 +199,99 @@ func TestAddLocalAddress(t *testing.T) {
 		result := amgr.AddLocalAddress(&test.address, test.priority)
 		if result == nil && !test.valid {
 			t.Errorf("TestAddLocalAddress test #%d failed: %s should have "+
-				"been accepted", x, test.address.IP)
+				"been accepted", x, test.address.IP())
 			continue
 		}
 		if result != nil && test.valid {
 			t.Errorf("TestAddLocalAddress test #%d failed: %s should not have "+
-				"been accepted", x, test.address.IP)
+				"been accepted", x, test.address.IP())
 			continue
 		}
 	}
 }
 
+func TestLocalAddresses(t *testing.T) {
+	amgr := addrmgr.New("testlocaladdresses", nil)
+	if addrs := amgr.LocalAddresses(); len(addrs) != 0 {
+		t.Fatalf("Expected no local addresses, got %d", len(addrs))
+	}
+
+	localAddrs := []wire.NetAddressV2{
+		*wire.NewNetAddressV2IPPort(net.ParseIP("2620:100::1"), 9333, 0),
+		*wire.NewNetAddressV2IPPort(net.ParseIP("204.124.1.1"), 9333, 0),
+		*wire.NewNetAddressV2IPPort(net.ParseIP("192.168.0.100"), 9333, 0),
+	}
+	for i := range localAddrs {
+		amgr.AddLocalAddress(&localAddrs[i], addrmgr.BoundPrio)
+	}
+
+	addrs := amgr.LocalAddresses()
+	if len(addrs) != 2 {
+		t.Fatalf("Expected 2 local addresses, got %d", len(addrs))
+	}
+	if !addrs[0].NetAddress.IP().Equal(localAddrs[1].IP()) ||
+		!addrs[1].NetAddress.IP().Equal(localAddrs[0].IP()) {
+
+		t.Errorf("Unexpected local addresses %v and %v",
+			addrs[0].NetAddress.IP(), addrs[1].NetAddress.IP())
+	}
+	for _, addr := range addrs {
+		if addr.Score != addrmgr.BoundPrio {
+			t.Errorf("Unexpected score for %v: got %v, want %v",
+				addr.NetAddress.IP(), addr.Score, addrmgr.BoundPrio)
+		}
+	}
+}
+
+func TestRandomAddresses(t *testing.T) {
+	n := addrmgr.New("testrandomaddresses", lookupFunc)
+	if addrs := n.RandomAddresses(0); len(addrs) != 0 {
+		t.Fatalf("Expected no addresses, got %d", len(addrs))
+	}
+
+	addrsToAdd := 100
+	addrs := make([]*wire.NetAddressV2, addrsToAdd)
+	var err error
+	for i := 0; i < addrsToAdd; i++ {
+		s := fmt.Sprintf("%d.173.147.%d:9333", i/64+60, i%64+60)
+		addrs[i], err = n.DeserializeNetAddress(s, wire.SFNodeNetwork)
+		if err != nil {
+			t.Fatalf("Failed to turn %s into an address: %v", s, err)
+		}
+	}
+	srcAddr := wire.NewNetAddressV2IPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
+	n.AddAddresses(addrs, srcAddr)
+	numAddrs := n.NumAddresses()
+
+	tests := []struct {
+		count int
+		want  int
+	}{
+		{count: 0, want: numAddrs},
+		{count: 1, want: 1},
+		{count: 10, want: 10},
+		{count: numAddrs + 1, want: numAddrs},
+	}
+	for _, test := range tests {
+		got := n.RandomAddresses(test.count)
+		if len(got) != test.want {
+			t.Errorf("RandomAddresses(%d): got %d addresses, want %d",
+				test.count, len(got), test.want)
+			continue
+		}
+
+		seen := make(map[string]struct{})
+		for _, na := range got {
+			key := addrmgr.NetAddressKey(na)
+			if _, ok := seen[key]; ok {
+				t.Errorf("RandomAddresses(%d): duplicate address %s",
+					test.count, key)
+			}
+			seen[key] = struct{}{}
+		}
+	}
+}
+
 func TestAttempt(t *testing.T) {
 	n := addrmgr.New("testattempt", lookupFunc)
 
@@ -257,7 +341,7 @@ func TestNeedMoreAddresses(t *testing.T) {
 	if !b {
 		t.Errorf("Expected that we need more addresses")
 	}
-	addrs := make([]*wire.NetAddress, addrsToAdd)
+	addrs := make([]*wire.NetAddressV2, addrsToAdd)
 
 	var err error
 	for i := 0; i < addrsToAdd; i++ {
@@ -268,7 +352,7 @@ func TestNeedMoreAddresses(t *testing.T) {
 		}
 	}
 
-	srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
+	srcAddr := wire.NewNetAddressV2IPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
 
 	n.AddAddresses(addrs, srcAddr)
 	numAddrs := n.NumAddresses()
@@ -285,7 +369,7 @@ func TestNeedMoreAddresses(t *testing.T) {
 func TestGood(t *testing.T) {
 	n := addrmgr.New("testgood", lookupFunc)
 	addrsToAdd := 64 * 64
-	addrs := make([]*wire.NetAddress, addrsToAdd)
+	addrs := make([]*wire.NetAddressV2, addrsToAdd)
 
 	var err error
 	for i := 0; i < addrsToAdd; i++ {
@@ -296,7 +380,7 @@ func TestGood(t *testing.T) {
 		}
 	}
 
-	srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
+	srcAddr := wire.NewNetAddressV2IPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
 
 	n.AddAddresses(addrs, srcAddr)
 	for _, addr := range addrs {
@@ -331,8 +415,8 @@ func TestGetAddress(t *testing.T) {
 	if ka == nil {
 		t.Fatalf("Did not get an address where there is one in the pool")
 	}
-	if ka.NetAddress().IP.String() != someIP {
-		t.Errorf("Wrong IP: got %v, want %v", ka.NetAddress().IP.String(), someIP)
+	if ka.NetAddress().IP().String() != someIP {
+		t.Errorf("Wrong IP: got %v, want %v", ka.NetAddress().IP().String(), someIP)
 	}
 
 	// Mark this as a good address and get it
@@ -341,8 +425,8 @@ func TestGetAddress(t *testing.T) {
 	if ka == nil {
 		t.Fatalf("Did not get an address where there is one in the pool")
 	}
-	if ka.NetAddress().IP.String() != someIP {
-		t.Errorf("Wrong IP: got %v, want %v", ka.NetAddress().IP.String(), someIP)
+	if ka.NetAddress().IP().String() != someIP {
+		t.Errorf("Wrong IP: got %v, want %v", ka.NetAddress().IP().String(), someIP)
 	}
 
 	numAddrs := n.NumAddresses()
@@ -352,51 +436,51 @@ func TestGetAddress(t *testing.T) {
 }
 
 func TestGetBestLocalAddress(t *testing.T) {
-	localAddrs := []wire.NetAddress{
-		{IP: net.ParseIP("192.168.0.100")},
-		{IP: net.ParseIP("::1")},
-		{IP: net.ParseIP("fe80::1")},
-		{IP: net.ParseIP("2001:470::1")},
+	localAddrs := []wire.NetAddressV2{
+		*wire.NewNetAddressV2IPPort(net.ParseIP("192.168.0.100"), 0, 0),
+		*wire.NewNetAddressV2IPPort(net.ParseIP("::1"), 0, 0),
+		*wire.NewNetAddressV2IPPort(net.ParseIP("fe80::1"), 0, 0),
+		*wire.NewNetAddressV2IPPort(net.ParseIP("2001:470::1"), 0, 0),
 	}
 
 	var tests = []struct {
-		remoteAddr wire.NetAddress
-		want0      wire.NetAddress
-		want1      wire.NetAddress
-		want2      wire.NetAddress
-		want3      wire.NetAddress
+		remoteAddr wire.NetAddressV2
+		want0      wire.NetAddressV2
+		want1      wire.NetAddressV2
+		want2      wire.NetAddressV2
+		want3      wire.NetAddressV2
 	}{
 		{
 			// Remote connection from public IPv4
-			wire.NetAddress{IP: net.ParseIP("204.124.8.1")},
-			wire.NetAddress{IP: net.IPv4zero},
-			wire.NetAddress{IP: net.IPv4zero},
-			wire.NetAddress{IP: net.ParseIP("204.124.8.100")},
-			wire.NetAddress{IP: net.ParseIP("fd87:d87e:eb43:25::1")},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("204.124.8.1"), 0, 0),
+			*wire.NewNetAddressV2IPPort(net.IPv4zero, 0, 0),
+			*wire.NewNetAddressV2IPPort(net.IPv4zero, 0, 0),
+			*wire.NewNetAddressV2IPPort(net.ParseIP("204.124.8.100"), 0, 0),
+			*wire.NewNetAddressV2IPPort(net.ParseIP("fd87:d87e:eb43:25::1"), 0, 0),
 		},
 		{
 			// Remote connection from private IPv4
-			wire.NetAddress{IP: net.ParseIP("172.16.0.254")},
-			wire.NetAddress{IP: net.IPv4zero},
-			wire.NetAddress{IP: net.IPv4zero},
-			wire.NetAddress{IP: net.IPv4zero},
-			wire.NetAddress{IP: net.IPv4zero},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("172.16.0.254"), 0, 0),
+			*wire.NewNetAddressV2IPPort(net.IPv4zero, 0, 0),
+			*wire.NewNetAddressV2IPPort(net.IPv4zero, 0, 0),
+			*wire.NewNetAddressV2IPPort(net.IPv4zero, 0, 0),
+			*wire.NewNetAddressV2IPPort(net.IPv4zero, 0, 0),
 		},
 		{
 			// Remote connection from public IPv6
-			wire.NetAddress{IP: net.ParseIP("2602:100:abcd::102")},
-			wire.NetAddress{IP: net.IPv6zero},
-			wire.NetAddress{IP: net.ParseIP("2001:470::1")},
-			wire.NetAddress{IP: net.ParseIP("2001:470::1")},
-			wire.NetAddress{IP: net.ParseIP("2001:470::1")},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("2602:100:abcd::102"), 0, 0),
+			*wire.NewNetAddressV2IPPort(net.IPv6zero, 0, 0),
+			*wire.NewNetAddressV2IPPort(net.ParseIP("2001:470::1"), 0, 0),
+			*wire.NewNetAddressV2IPPort(net.ParseIP("2001:470::1"), 0, 0),
+			*wire.NewNetAddressV2IPPort(net.ParseIP("2001:470::1"), 0, 0),
 		},
 		/* XXX
 		{
 			// Remote connection from Tor
-			wire.NetAddress{IP: net.ParseIP("fd87:d87e:eb43::100")},
-			wire.NetAddress{IP: net.IPv4zero},
-			wire.NetAddress{IP: net.ParseIP("204.124.8.100")},
-			wire.NetAddress{IP: net.ParseIP("fd87:d87e:eb43:25::1")},
+			*wire.NewNetAddressV2IPPort(net.ParseIP("fd87:d87e:eb43::100"), 0, 0),
+			*wire.NewNetAddressV2IPPort(net.IPv4zero, 0, 0),
+			*wire.NewNetAddressV2IPPort(net.ParseIP("204.124.8.100"), 0, 0),
+			*wire.NewNetAddressV2IPPort(net.ParseIP("fd87:d87e:eb43:25::1"), 0, 0),
 		},
 		*/
 	}
@@ -406,9 +490,9 @@ func TestGetBestLocalAddress(t *testing.T) {
 	// Test against default when there's no address
 	for x, test := range tests {
 		got := amgr.GetBestLocalAddress(&test.remoteAddr)
-		if !test.want0.IP.Equal(got.IP) {
+		if !test.want0.IP().Equal(got.IP()) {
 			t.Errorf("TestGetBestLocalAddress test1 #%d failed for remote address %s: want %s got %s",
-				x, test.remoteAddr.IP, test.want1.IP, got.IP)
+				x, test.remoteAddr.IP(), test.want1.IP(), got.IP())
 			continue
 		}
 	}
@@ -420,43 +504,120 @@ func TestGetBestLocalAddress(t *testing.T) {
 	// Test against want1
 	for x, test := range tests {
 		got := amgr.GetBestLocalAddress(&test.remoteAddr)
-		if !test.want1.IP.Equal(got.IP) {
+		if !test.want1.IP().Equal(got.IP()) {
 			t.Errorf("TestGetBestLocalAddress test1 #%d failed for remote address %s: want %s got %s",
-				x, test.remoteAddr.IP, test.want1.IP, got.IP)
+				x, test.remoteAddr.IP(), test.want1.IP(), got.IP())
 			continue
 		}
 	}
 
 	// Add a public IP to the list of local addresses.
-	localAddr := wire.NetAddress{IP: net.ParseIP("204.124.8.100")}
+	localAddr := *wire.NewNetAddressV2IPPort(net.ParseIP("204.124.8.100"), 0, 0)
 	amgr.AddLocalAddress(&localAddr, addrmgr.InterfacePrio)
 
 	// Test against want2
 	for x, test := range tests {
 		got := amgr.GetBestLocalAddress(&test.remoteAddr)
-		if !test.want2.IP.Equal(got.IP) {
+		if !test.want2.IP().Equal(got.IP()) {
 			t.Errorf("TestGetBestLocalAddress test2 #%d failed for remote address %s: want %s got %s",
-				x, test.remoteAddr.IP, test.want2.IP, got.IP)
+				x, test.remoteAddr.IP(), test.want2.IP(), got.IP())
 			continue
 		}
 	}
 	/*
 		// Add a Tor generated IP address
-		localAddr = wire.NetAddress{IP: net.ParseIP("fd87:d87e:eb43:25::1")}
+		localAddr = *wire.NewNetAddressV2IPPort(net.ParseIP("fd87:d87e:eb43:25::1"), 0, 0)
 		amgr.AddLocalAddress(&localAddr, addrmgr.ManualPrio)
 
 		// Test against want3
 		for x, test := range tests {
 			got := amgr.GetBestLocalAddress(&test.remoteAddr)
-			if !test.want3.IP.Equal(got.IP) {
+			if !test.want3.IP().Equal(got.IP()) {
 				t.Errorf("TestGetBestLocalAddress test3 #%d failed for remote address %s: want %s got %s",
-					x, test.remoteAddr.IP, test.want3.IP, got.IP)
+					x, test.remoteAddr.IP(), test.want3.IP(), got.IP())
 				continue
 			}
 		}
 	*/
 }
 
+func TestHostToNetAddress(t *testing.T) {
+	torV3PubKey, _ := hex.DecodeString("1d04a1d04a338c6e6ae970bfabee4904" +
+		"9d6702250984ca950c01673f4ec034ad")
+
+	tests := []struct {
+		name    string
+		host    string
+		network wire.NetworkID
+		addr    []byte
+		valid   bool
+	}{
+		{
+			name:    "ipv4",
+			host:    "173.194.115.66",
+			network: wire.NetworkIPv4,
+			addr:    []byte{173, 194, 115, 66},
+			valid:   true,
+		},
+		{
+			name:    "tor v2",
+			host:    "aaaqeayeaudaocaj.onion",
+			network: wire.NetworkTorV2,
+			addr:    []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			valid:   true,
+		},
+		{
+			name:    "tor v3",
+			host:    "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion",
+			network: wire.NetworkTorV3,
+			addr:    torV3PubKey,
+			valid:   true,
+		},
+		{
+			name:  "tor v3 bad checksum",
+			host:  "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczaa.onion",
+			valid: false,
+		},
+		{
+			name:    "i2p",
+			host:    "aeaqcaibaeaqcaibaeaqcaibaeaqcaibaeaqcaibaeaqcaibaeaq.b32.i2p",
+			network: wire.NetworkI2P,
+			addr:    bytes.Repeat([]byte{0x01}, 32),
+			valid:   true,
+		},
+	}
+
+	amgr := addrmgr.New("testhosttonetaddress", lookupFunc)
+	for _, test := range tests {
+		na, err := amgr.HostToNetAddress(test.host, 9333, 0)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: expected error for host %s", test.name,
+					test.host)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if na.Network != test.network || !bytes.Equal(na.Addr, test.addr) {
+			t.Errorf("%s: unexpected address - got %v %x, want %v %x",
+				test.name, na.Network, na.Addr, test.network,
+				test.addr)
+			continue
+		}
+
+		// The key must map back to the host the address was created
+		// from so it can be persisted and dialed.
+		want := net.JoinHostPort(test.host, "9333")
+		if key := addrmgr.NetAddressKey(na); key != want {
+			t.Errorf("%s: unexpected key - got %s, want %s",
+				test.name, key, want)
+		}
+	}
+}
+
 func TestNetAddressKey(t *testing.T) {
 	addNaTests()
 
@@ -232,6 +232,11 @@ type blockIndex struct {
 	sync.RWMutex
 	index map[chainhash.Hash]*blockNode
 	dirty map[*blockNode]struct{}
+
+	// children maps the nodes in the index to the nodes which have them as
+	// their parent, so the descendants of a node can be found by walking
+	// forward from it instead of scanning the entire index.
+	children map[*blockNode][]*blockNode
 }
 
 // newBlockIndex returns a new empty instance of a block index.  The index will
@@ -243,6 +248,7 @@ func newBlockIndex(db database.DB, chainParams *chaincfg.Params) *blockIndex {
 		chainParams: chainParams,
 		index:       make(map[chainhash.Hash]*blockNode),
 		dirty:       make(map[*blockNode]struct{}),
+		children:    make(map[*blockNode][]*blockNode),
 	}
 }
 
@@ -283,6 +289,9 @@ func (bi *blockIndex) AddNode(node *blockNode) {
 //
 // This function is NOT safe for concurrent access.
 func (bi *blockIndex) addNode(node *blockNode) {
+	if _, ok := bi.index[node.hash]; !ok && node.parent != nil {
+		bi.children[node.parent] = append(bi.children[node.parent], node)
+	}
 	bi.index[node.hash] = node
 }
 
@@ -319,6 +328,62 @@ func (bi *blockIndex) UnsetStatusFlags(node *blockNode, flags blockStatus) {
 	bi.Unlock()
 }
 
+// descendants returns all of the block nodes in the index which have the
+// provided node as an ancestor.  The provided node itself is not included.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) descendants(node *blockNode) []*blockNode {
+	bi.RLock()
+	descendants := append([]*blockNode(nil), bi.children[node]...)
+	for i := 0; i < len(descendants); i++ {
+		descendants = append(descendants, bi.children[descendants[i]]...)
+	}
+	bi.RUnlock()
+	return descendants
+}
+
+// leaves returns all of the block nodes in the index which are not the parent
+// of any other node in the index.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) leaves() []*blockNode {
+	bi.RLock()
+	parents := make(map[*blockNode]struct{}, len(bi.index))
+	for _, n := range bi.index {
+		if n.parent != nil {
+			parents[n.parent] = struct{}{}
+		}
+	}
+	var leaves []*blockNode
+	for _, n := range bi.index {
+		if _, ok := parents[n]; !ok {
+			leaves = append(leaves, n)
+		}
+	}
+	bi.RUnlock()
+	return leaves
+}
+
+// bestValidNode returns the block node with the most cumulative work that has
+// its block data stored and is not known to be invalid.  Note that the returned
+// node might still turn out to be invalid once it is fully validated.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) bestValidNode() *blockNode {
+	var best *blockNode
+	bi.RLock()
+	for _, n := range bi.index {
+		if n.status.KnownInvalid() || !n.status.HaveData() {
+			continue
+		}
+		if best == nil || n.workSum.Cmp(best.workSum) > 0 {
+			best = n
+		}
+	}
+	bi.RUnlock()
+	return best
+}
+
 // flushToDB writes all dirty block nodes to the database. If all writes
 // succeed, this clears the dirty set.
 func (bi *blockIndex) flushToDB() error {
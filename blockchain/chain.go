@@ -8,6 +8,7 @@ package blockchain
 import (
 	"container/list"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -101,6 +102,9 @@ type BlockChain struct {
 	sigCache            *txscript.SigCache
 	indexManager        IndexManager
 	hashCache           *txscript.HashCache
+	prune               bool
+	pruneTarget         uint64
+	minimumChainWork    *big.Int
 
 	// The following fields are calculated based upon the provided chain
 	// parameters.  They are also set when the instance is created and
@@ -138,6 +142,17 @@ type BlockChain struct {
 	nextCheckpoint *chaincfg.Checkpoint
 	checkpointNode *blockNode
 
+	// These fields are related to pruning block data.  They are protected
+	// by the chain lock.
+	//
+	// prunedHeight is the height of the most recent block whose data has
+	// been pruned.  It is zero when no blocks have been pruned.
+	//
+	// lastPruneCheck is the height of the best chain when pruning was last
+	// attempted automatically.
+	prunedHeight   int32
+	lastPruneCheck int32
+
 	// The state is used as a fairly efficient way to cache information
 	// about the current best chain state that is returned to callers when
 	// requested.  It operates on the principle of MVCC such that any time a
@@ -974,7 +989,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		// In the case the block is determined to be invalid due to a
 		// rule violation, mark it as invalid and mark all of its
 		// descendants as having an invalid ancestor.
-		err = b.checkConnectBlock(n, block, view, nil)
+		err = b.checkConnectBlock(n, block, view, nil, BFNone)
 		if err != nil {
 			if _, ok := err.(RuleError); ok {
 				b.index.SetStatusFlags(n, statusValidateFailed)
@@ -1080,6 +1095,8 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 // The flags modify the behavior of this function as follows:
 //  - BFFastAdd: Avoids several expensive transaction validation operations.
 //    This is useful when using checkpoints.
+//  - BFAssumeValid: Avoids validating the transaction scripts.  This is
+//    useful when using an assumed valid block.
 //
 // This function MUST be called with the chain state lock held (for writes).
 func (b *BlockChain) connectBestChain(node *blockNode, block *acmutil.Block, flags BehaviorFlags) (bool, error) {
@@ -1110,7 +1127,8 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *acmutil.Block, fla
 		view.SetBestHash(parentHash)
 		stxos := make([]SpentTxOut, 0, countSpentOutputs(block))
 		if !fastAdd {
-			err := b.checkConnectBlock(node, block, view, &stxos)
+			err := b.checkConnectBlock(node, block, view, &stxos,
+				flags)
 			if err == nil {
 				b.index.SetStatusFlags(node, statusValid)
 			} else if _, ok := err.(RuleError); ok {
@@ -1215,10 +1233,150 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *acmutil.Block, fla
 	return err == nil, err
 }
 
+// activateBestChain reorganizes the chain to the block with the most
+// cumulative work that is not known to be invalid when it has more work than the
+// current tip or when the current tip itself is known to be invalid.  Any
+// blocks which fail validation while attempting to do so are marked as such and
+// the next best block is tried instead.
+//
+// This function may modify node statuses in the block index without flushing.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) activateBestChain() error {
+	for {
+		tip := b.bestChain.Tip()
+		candidate := b.index.bestValidNode()
+		if candidate == nil || candidate == tip {
+			return nil
+		}
+		if !b.index.NodeStatus(tip).KnownInvalid() &&
+			candidate.workSum.Cmp(tip.workSum) <= 0 {
+
+			return nil
+		}
+
+		// Simply disconnect blocks when the candidate is an ancestor of
+		// the current tip.  Otherwise, find the nodes to reorganize to
+		// it, which might reveal that it descends from an invalid
+		// block, in which case the next best candidate is tried.
+		detachNodes, attachNodes := list.New(), list.New()
+		if b.bestChain.Contains(candidate) {
+			for n := tip; n != candidate; n = n.parent {
+				detachNodes.PushBack(n)
+			}
+		} else {
+			detachNodes, attachNodes = b.getReorganizeNodes(candidate)
+			if attachNodes.Len() == 0 {
+				continue
+			}
+		}
+
+		err := b.reorganizeChain(detachNodes, attachNodes)
+		if err != nil {
+			// A rule violation marks the offending block, and thus
+			// the candidate, as invalid, so try the next best
+			// candidate in that case.
+			if _, ok := err.(RuleError); ok &&
+				b.index.NodeStatus(candidate).KnownInvalid() {
+
+				continue
+			}
+			return err
+		}
+
+		return nil
+	}
+}
+
+// InvalidateBlock marks the block identified by the passed hash, along with all
+// of its descendants, as invalid.  When the block is part of the main chain,
+// it is disconnected along with all of its descendants and the chain is
+// reorganized to the remaining valid block with the most cumulative work.
+//
+// The invalid status is persisted, so the block will not be connected again
+// until it is reconsidered via ReconsiderBlock.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) InvalidateBlock(hash *chainhash.Hash) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %s is not known", hash)
+	}
+	if node.parent == nil {
+		return fmt.Errorf("block %s is the genesis block and can't be "+
+			"invalidated", hash)
+	}
+
+	log.Infof("Invalidating block %v (height %v)", hash, node.height)
+
+	b.index.SetStatusFlags(node, statusValidateFailed)
+	for _, n := range b.index.descendants(node) {
+		b.index.SetStatusFlags(n, statusInvalidAncestor)
+	}
+
+	// Move the chain to the best remaining valid tip.  The index is flushed
+	// regardless of whether or not that succeeds so the block remains
+	// invalid across restarts.
+	err := b.activateBestChain()
+	if writeErr := b.index.flushToDB(); writeErr != nil {
+		log.Warnf("Error flushing block index changes to disk: %v",
+			writeErr)
+		if err == nil {
+			err = writeErr
+		}
+	}
+	return err
+}
+
+// ReconsiderBlock removes the invalid status from the block identified by the
+// passed hash along with all of its ancestors and descendants, which undoes a
+// previous InvalidateBlock.  The chain is then reorganized to the block with
+// the most cumulative work should it no longer be the current tip.  Blocks
+// which had failed validation on their own are validated again in the process.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ReconsiderBlock(hash *chainhash.Hash) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %s is not known", hash)
+	}
+
+	log.Infof("Reconsidering block %v (height %v)", hash, node.height)
+
+	const invalidFlags = statusValidateFailed | statusInvalidAncestor
+	for n := node; n != nil; n = n.parent {
+		if b.index.NodeStatus(n).KnownInvalid() {
+			b.index.UnsetStatusFlags(n, invalidFlags)
+		}
+	}
+	for _, n := range b.index.descendants(node) {
+		if b.index.NodeStatus(n).KnownInvalid() {
+			b.index.UnsetStatusFlags(n, invalidFlags)
+		}
+	}
+
+	err := b.activateBestChain()
+	if writeErr := b.index.flushToDB(); writeErr != nil {
+		log.Warnf("Error flushing block index changes to disk: %v",
+			writeErr)
+		if err == nil {
+			err = writeErr
+		}
+	}
+	return err
+}
+
 // isCurrent returns whether or not the chain believes it is current.  Several
 // factors are used to guess, but the key factors that allow the chain to
 // believe it is current are:
 //  - Latest block height is after the latest checkpoint (if enabled)
+//  - Best chain has at least the minimum chain work (if required)
 //  - Latest block has a timestamp newer than 24 hours ago
 //
 // This function MUST be called with the chain state lock held (for reads).
@@ -1230,6 +1388,14 @@ func (b *BlockChain) isCurrent() bool {
 		return false
 	}
 
+	// Not current if the best chain has less than the minimum chain work
+	// (when required) since it can't be the chain of the network.
+	if b.minimumChainWork != nil &&
+		b.bestChain.Tip().workSum.Cmp(b.minimumChainWork) < 0 {
+
+		return false
+	}
+
 	// Not current if the latest best block has a timestamp before 24 hours
 	// ago.
 	//
@@ -1243,6 +1409,7 @@ func (b *BlockChain) isCurrent() bool {
 // factors are used to guess, but the key factors that allow the chain to
 // believe it is current are:
 //  - Latest block height is after the latest checkpoint (if enabled)
+//  - Best chain has at least the minimum chain work (if required)
 //  - Latest block has a timestamp newer than 24 hours ago
 //
 // This function is safe for concurrent access.
@@ -1253,6 +1420,21 @@ func (b *BlockChain) IsCurrent() bool {
 	return b.isCurrent()
 }
 
+// WithChainLock invokes the passed function while holding the chain lock for
+// reads, so the main chain can't be modified while it runs.  This allows
+// callers to keep state derived from the main chain consistent with it, for
+// example by checking MainChainHasBlock and BestSnapshot and updating the
+// database in response.
+//
+// The function must not call any methods which acquire the chain lock
+// themselves since that can deadlock.
+func (b *BlockChain) WithChainLock(fn func() error) error {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return fn()
+}
+
 // BestSnapshot returns information about the current best chain block and
 // related state as of the current point in time.  The returned instance must be
 // treated as immutable since it is shared by all callers.
@@ -1278,6 +1460,48 @@ func (b *BlockChain) HeaderByHash(hash *chainhash.Hash) (wire.BlockHeader, error
 	return node.Header(), nil
 }
 
+// ChainWork returns the total amount of work in the chain up to and including
+// the block with the given hash, regardless of whether or not the block is part
+// of the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ChainWork(hash *chainhash.Hash) (*big.Int, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		err := fmt.Errorf("block %s is not known", hash)
+		return nil, err
+	}
+
+	return new(big.Int).Set(node.workSum), nil
+}
+
+// HeaderHeightByHash returns the height of the block with the given hash,
+// regardless of whether or not the block is part of the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) HeaderHeightByHash(hash *chainhash.Hash) (int32, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		err := fmt.Errorf("block %s is not known", hash)
+		return 0, err
+	}
+
+	return node.height, nil
+}
+
+// IsKnownInvalid returns whether or not the block with the given hash is known
+// to be invalid, either because it failed validation itself or because one of
+// its ancestors did.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsKnownInvalid(hash *chainhash.Hash) bool {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return false
+	}
+	return b.index.NodeStatus(node).KnownInvalid()
+}
+
 // MainChainHasBlock returns whether or not the block with the given hash is in
 // the main chain.
 //
@@ -1328,6 +1552,20 @@ func (b *BlockChain) BlockHeightByHash(hash *chainhash.Hash) (int32, error) {
 	return node.height, nil
 }
 
+// MedianTimeByHash returns the median time of the blocks preceding and
+// including the block with the given hash in the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) MedianTimeByHash(hash *chainhash.Hash) (time.Time, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil || !b.bestChain.Contains(node) {
+		str := fmt.Sprintf("block %s is not in the main chain", hash)
+		return time.Time{}, errNotInMainChain(str)
+	}
+
+	return node.CalcPastMedianTime(), nil
+}
+
 // BlockHashByHeight returns the hash of the block at the given height in the
 // main chain.
 //
@@ -1711,6 +1949,27 @@ type Config struct {
 	// This field can be nil if the caller is not interested in using a
 	// signature cache.
 	HashCache *txscript.HashCache
+
+	// Prune enables pruning of old block data.  Once block data has been
+	// pruned, the chain must always be created with pruning enabled.
+	Prune bool
+
+	// PruneTarget is the target size in bytes of the stored block data
+	// when pruning is enabled.  Old blocks are automatically deleted once
+	// the stored block data exceeds it, while the most recent
+	// MinBlocksToKeep blocks are always retained.
+	//
+	// This field can be zero to only prune manually via PruneBlockchain.
+	PruneTarget uint64
+
+	// MinimumChainWork is the minimum amount of cumulative work the best
+	// chain must have for the chain to be considered current.  It is
+	// typically the MinimumChainWork of ChainParams unless overridden by
+	// the caller.
+	//
+	// This field can be nil if the caller does not wish to require a
+	// minimum amount of work.
+	MinimumChainWork *big.Int
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -1761,6 +2020,9 @@ func New(config *Config) (*BlockChain, error) {
 		blocksPerRetarget:   int32(targetTimespan / targetTimePerBlock),
 		index:               newBlockIndex(config.DB, params),
 		hashCache:           config.HashCache,
+		prune:               config.Prune,
+		pruneTarget:         config.PruneTarget,
+		minimumChainWork:    config.MinimumChainWork,
 		bestChain:           newChainView(nil),
 		orphans:             make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:         make(map[chainhash.Hash][]*orphanBlock),
@@ -1775,6 +2037,14 @@ func New(config *Config) (*BlockChain, error) {
 		return nil, err
 	}
 
+	// Refuse to run without pruning once block data has been pruned since
+	// the missing blocks can't be served or indexed.
+	if b.prunedHeight > 0 && !b.prune {
+		return nil, AssertError(fmt.Sprintf("blockchain.New block "+
+			"data has been pruned up to height %d, so pruning must "+
+			"remain enabled", b.prunedHeight))
+	}
+
 	// Perform any upgrades to the various chain-specific buckets as needed.
 	if err := b.maybeUpgradeDbBuckets(config.Interrupt); err != nil {
 		return nil, err
@@ -5,12 +5,15 @@
 package blockchain
 
 import (
+	"bytes"
+	"math/big"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/Actinium-project/acmd/chaincfg"
 	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/txscript"
 	"github.com/Actinium-project/acmd/wire"
 	"github.com/Actinium-project/acmutil"
 )
@@ -111,6 +114,45 @@ func TestHaveBlock(t *testing.T) {
 	}
 }
 
+// TestIsCurrent ensures the chain is only considered current when its best
+// block is recent and the best chain has the minimum chain work, if required.
+func TestIsCurrent(t *testing.T) {
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	genesis := chain.bestChain.Tip()
+	bits := genesis.bits
+	now := time.Unix(chain.timeSource.AdjustedTime().Unix(), 0)
+	staleTip := newFakeNode(genesis, 1, bits, now.Add(-25*time.Hour))
+	recentTip := newFakeNode(genesis, 1, bits, now)
+	workSum := recentTip.workSum
+
+	tests := []struct {
+		name     string
+		tip      *blockNode
+		minWork  *big.Int
+		expected bool
+	}{
+		{"recent tip without minimum work", recentTip, nil, true},
+		{"stale tip without minimum work", staleTip, nil, false},
+		{"recent tip with less than minimum work", recentTip,
+			new(big.Int).Add(workSum, big.NewInt(1)), false},
+		{"recent tip with exactly minimum work", recentTip,
+			new(big.Int).Set(workSum), true},
+		{"recent tip with more than minimum work", recentTip,
+			new(big.Int).Sub(workSum, big.NewInt(1)), true},
+		{"stale tip with minimum work", staleTip,
+			new(big.Int).Set(workSum), false},
+	}
+
+	for _, test := range tests {
+		chain.bestChain.SetTip(test.tip)
+		chain.minimumChainWork = test.minWork
+		if got := chain.IsCurrent(); got != test.expected {
+			t.Errorf("%s: unexpected result -- got %v, want %v",
+				test.name, got, test.expected)
+		}
+	}
+}
+
 // TestCalcSequenceLock tests the LockTimeToSequence function, and the
 // CalcSequenceLock method of a Chain instance. The tests exercise several
 // combinations of inputs to the CalcSequenceLock function in order to ensure
@@ -964,3 +1006,205 @@ func TestIntervalBlockHashes(t *testing.T) {
 		}
 	}
 }
+
+// TestFetchUtxoStats ensures the statistics about the utxo set and a scan of
+// the utxo set match the unspent outputs of the connected blocks and that the
+// calculation can be interrupted.
+func TestFetchUtxoStats(t *testing.T) {
+	// Create a new database and chain instance to run tests against.
+	params := &chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("fetchutxostats", params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block chain, set the coinbase
+	// maturity to 1.
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Generate a chain of four blocks where every block after the first
+	// spends outputs of its parent:
+	//
+	//   b1: coinbase
+	//   b2: coinbase, spend b1 coinbase into two outputs
+	//   b3: coinbase, spend the first output of the b2 spend
+	//   b4: coinbase, spend the b3 spend into an output and a provably
+	//       unspendable output
+	//
+	// This leaves the coinbase outputs of b2 through b4, the second output
+	// of the b2 spend and the first output of the b4 spend unspent, which
+	// are worth the subsidies of all four blocks together.
+	subsidy := CalcBlockSubsidy(1, params)
+	b1 := newTestBlock(params, params.GenesisBlock, 1)
+	spend1 := newSpendTx(wire.OutPoint{Hash: b1.MsgBlock().Transactions[0].TxHash()},
+		subsidy, 2)
+	b2 := newTestBlock(params, b1.MsgBlock(), 2, spend1)
+	spend2 := newSpendTx(wire.OutPoint{Hash: spend1.TxHash()},
+		spend1.TxOut[0].Value, 1)
+	b3 := newTestBlock(params, b2.MsgBlock(), 3, spend2)
+	spend3 := newSpendTx(wire.OutPoint{Hash: spend2.TxHash()},
+		spend2.TxOut[0].Value, 1)
+	spend3.AddTxOut(&wire.TxOut{PkScript: []byte{txscript.OP_RETURN}})
+	b4 := newTestBlock(params, b3.MsgBlock(), 4, spend3)
+	for i, block := range []*acmutil.Block{b1, b2, b3, b4} {
+		isMainChain, _, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("ProcessBlock fail on block %d: %v", i+1, err)
+		}
+		if !isMainChain {
+			t.Fatalf("ProcessBlock: block %d not on the main chain",
+				i+1)
+		}
+	}
+	const (
+		wantTxns         = 5
+		wantTxOuts       = 5
+		wantAmount int64 = 4 * 50 * acmutil.SatoshiPerBitcoin
+
+		wantSerializedSize = 187
+	)
+	wantUtxoHash, _ := chainhash.NewHashFromStr("cd0cc7ea672a517949ab4" +
+		"3ca46c9eda9a85e16d6e1b421e9dd7b914ef1388b9c")
+
+	stats, err := chain.FetchUtxoStats(nil)
+	if err != nil {
+		t.Fatalf("FetchUtxoStats: unexpected error: %v", err)
+	}
+	best := chain.BestSnapshot()
+	if stats.BestHash != best.Hash || stats.BestHeight != best.Height {
+		t.Fatalf("FetchUtxoStats: unexpected best block %v (%d), want "+
+			"%v (%d)", stats.BestHash, stats.BestHeight, best.Hash,
+			best.Height)
+	}
+	if stats.Transactions != wantTxns || stats.TxOuts != wantTxOuts ||
+		stats.TotalAmount != wantAmount {
+
+		t.Fatalf("FetchUtxoStats: got %d txns, %d outputs, amount %d, "+
+			"want %d txns, %d outputs, amount %d", stats.Transactions,
+			stats.TxOuts, stats.TotalAmount, wantTxns, wantTxOuts,
+			wantAmount)
+	}
+	if stats.SerializedSize != wantSerializedSize {
+		t.Fatalf("FetchUtxoStats: got serialized size %d, want %d",
+			stats.SerializedSize, wantSerializedSize)
+	}
+	if stats.Hash != *wantUtxoHash {
+		t.Fatalf("FetchUtxoStats: got hash %v, want %v", stats.Hash,
+			wantUtxoHash)
+	}
+
+	// The hash of the set must be deterministic.
+	stats2, err := chain.FetchUtxoStats(nil)
+	if err != nil {
+		t.Fatalf("FetchUtxoStats: unexpected error: %v", err)
+	}
+	if stats2.Hash != stats.Hash {
+		t.Fatalf("FetchUtxoStats: hash changed from %v to %v",
+			stats.Hash, stats2.Hash)
+	}
+
+	// Scanning the utxo set must visit every unspent output.  The hash of
+	// the set must be the double sha256 of the serialized outputs.
+	var scannedTxOuts, scannedAmount int64
+	var serialized bytes.Buffer
+	scanHash, scanHeight, err := chain.ScanUtxoSet(nil, func(outpoint wire.OutPoint,
+		entry *UtxoEntry) error {
+
+		scannedTxOuts++
+		scannedAmount += entry.Amount()
+
+		headerCode, err := utxoEntryHeaderCode(entry)
+		if err != nil {
+			return err
+		}
+		var buf [8]byte
+		serialized.Write(outpoint.Hash[:])
+		byteOrder.PutUint32(buf[:4], outpoint.Index)
+		serialized.Write(buf[:4])
+		byteOrder.PutUint32(buf[:4], uint32(headerCode))
+		serialized.Write(buf[:4])
+		byteOrder.PutUint64(buf[:], uint64(entry.Amount()))
+		serialized.Write(buf[:])
+		return wire.WriteVarBytes(&serialized, 0, entry.PkScript())
+	})
+	if err != nil {
+		t.Fatalf("ScanUtxoSet: unexpected error: %v", err)
+	}
+	if *scanHash != best.Hash || scanHeight != best.Height {
+		t.Fatalf("ScanUtxoSet: unexpected best block %v (%d), want "+
+			"%v (%d)", scanHash, scanHeight, best.Hash, best.Height)
+	}
+	if scannedTxOuts != wantTxOuts || scannedAmount != wantAmount {
+		t.Fatalf("ScanUtxoSet: got %d outputs, amount %d, want %d "+
+			"outputs, amount %d", scannedTxOuts, scannedAmount,
+			wantTxOuts, wantAmount)
+	}
+	wantHash := chainhash.DoubleHashH(serialized.Bytes())
+	if stats.Hash != wantHash {
+		t.Fatalf("FetchUtxoStats: got hash %v, want %v", stats.Hash,
+			wantHash)
+	}
+
+	// Closing the interrupt channel must cancel the calculation.
+	interrupt := make(chan struct{})
+	close(interrupt)
+	if _, err := chain.FetchUtxoStats(interrupt); err != errInterruptRequested {
+		t.Fatalf("FetchUtxoStats: unexpected error with interrupt: "+
+			"got %v, want %v", err, errInterruptRequested)
+	}
+}
+
+// TestIndexDescendants ensures the descendants of block nodes are found across
+// all branches of the block index.
+func TestIndexDescendants(t *testing.T) {
+	// Construct a synthetic block index consisting of the following
+	// structure:
+	//
+	//   genesis -> 1 -> 2 -> 3 -> 4
+	//                   \-> 3a -> 4a
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	genesis := chain.bestChain.Genesis()
+	branch0 := chainedNodes(genesis, 4)
+	branch1 := chainedNodes(branch0[1], 2)
+	for _, node := range append(branch0, branch1...) {
+		chain.index.AddNode(node)
+	}
+
+	tests := []struct {
+		name string
+		node *blockNode
+		want []*blockNode
+	}{{
+		name: "genesis",
+		node: genesis,
+		want: append(branch0, branch1...),
+	}, {
+		name: "fork point",
+		node: branch0[1],
+		want: append(branch0[2:], branch1...),
+	}, {
+		name: "side chain",
+		node: branch1[0],
+		want: branch1[1:],
+	}, {
+		name: "tip",
+		node: branch0[3],
+		want: nil,
+	}}
+
+	for _, test := range tests {
+		got := make(map[*blockNode]struct{})
+		for _, node := range chain.index.descendants(test.node) {
+			got[node] = struct{}{}
+		}
+		want := make(map[*blockNode]struct{})
+		for _, node := range test.want {
+			want[node] = struct{}{}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: unexpected descendants -- got %d nodes, "+
+				"want %d", test.name, len(got), len(want))
+		}
+	}
+}
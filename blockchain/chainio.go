@@ -6,6 +6,7 @@ package blockchain
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"math/big"
@@ -67,6 +68,10 @@ var (
 	// unspent transaction output set.
 	utxoSetBucketName = []byte("utxosetv2")
 
+	// prunedHeightKeyName is the name of the db key used to store the
+	// height of the most recent block whose data has been pruned.
+	prunedHeightKeyName = []byte("prunedheight")
+
 	// byteOrder is the preferred byte order used for serializing numeric
 	// fields for storage in the database.
 	byteOrder = binary.LittleEndian
@@ -766,6 +771,136 @@ func dbFetchUtxoEntry(dbTx database.Tx, outpoint wire.OutPoint) (*UtxoEntry, err
 	return entry, nil
 }
 
+// UtxoStats houses statistics about the unspent transaction output set as of
+// the best block it was calculated at.
+type UtxoStats struct {
+	// BestHash and BestHeight identify the best block at the time the
+	// statistics were calculated.
+	BestHash   chainhash.Hash
+	BestHeight int32
+
+	// Transactions is the number of transactions with unspent outputs and
+	// TxOuts is the number of unspent outputs.
+	Transactions int64
+	TxOuts       int64
+
+	// TotalAmount is the sum of the amounts of all unspent outputs.
+	TotalAmount int64
+
+	// SerializedSize is the total size of the database keys and values
+	// used to store the unspent outputs.
+	SerializedSize int64
+
+	// Hash commits to every unspent output in the set.  It is the double
+	// sha256 of the serialized outputs ordered by their outpoints, where
+	// each output is serialized as its outpoint hash and index, its header
+	// code, its amount, and its length-prefixed public key script.
+	Hash chainhash.Hash
+}
+
+// dbFetchBestChainState uses an existing database transaction to fetch the
+// best chain state.
+func dbFetchBestChainState(dbTx database.Tx) (bestChainState, error) {
+	return deserializeBestChainState(dbTx.Metadata().Get(chainStateKeyName))
+}
+
+// dbForEachUtxo uses an existing database transaction to call the passed
+// function for every unspent output in the utxo set ordered by their outpoints
+// along with the combined size of the database key and value used to store the
+// output.  The iteration stops when the function returns an error, which is
+// returned.
+//
+// The iteration can be cancelled by closing the interrupt channel in which case
+// errInterruptRequested is returned.
+func dbForEachUtxo(dbTx database.Tx, interrupt <-chan struct{},
+	fn func(outpoint wire.OutPoint, entry *UtxoEntry, size int) error) error {
+
+	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+	return utxoBucket.ForEach(func(k, v []byte) error {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		if len(k) <= chainhash.HashSize {
+			return database.Error{
+				ErrorCode: database.ErrCorruption,
+				Description: fmt.Sprintf("corrupt utxo key %x",
+					k),
+			}
+		}
+		entry, err := deserializeUtxoEntry(v)
+		if err != nil {
+			return database.Error{
+				ErrorCode: database.ErrCorruption,
+				Description: fmt.Sprintf("corrupt utxo entry "+
+					"for key %x: %v", k, err),
+			}
+		}
+
+		var outpoint wire.OutPoint
+		copy(outpoint.Hash[:], k[:chainhash.HashSize])
+		index, _ := deserializeVLQ(k[chainhash.HashSize:])
+		outpoint.Index = uint32(index)
+		return fn(outpoint, entry, len(k)+len(v))
+	})
+}
+
+// dbFetchUtxoStats uses an existing database transaction to calculate
+// statistics about the entire utxo set along with the best block the utxo set
+// represents.  Since the utxo set and the best chain state are always updated
+// together, the database transaction ensures they are consistent.
+//
+// The calculation can be cancelled by closing the interrupt channel in which
+// case errInterruptRequested is returned.
+func dbFetchUtxoStats(dbTx database.Tx, interrupt <-chan struct{}) (*UtxoStats, error) {
+	state, err := dbFetchBestChainState(dbTx)
+	if err != nil {
+		return nil, err
+	}
+	stats := &UtxoStats{
+		BestHash:   state.hash,
+		BestHeight: int32(state.height),
+	}
+
+	hasher := sha256.New()
+	var prevHash *chainhash.Hash
+	var buf [8]byte
+	err = dbForEachUtxo(dbTx, interrupt, func(outpoint wire.OutPoint,
+		entry *UtxoEntry, size int) error {
+
+		// The outputs are ordered by their outpoint, so all of the
+		// outputs of a transaction are adjacent.
+		if prevHash == nil || *prevHash != outpoint.Hash {
+			stats.Transactions++
+			prevHash = &outpoint.Hash
+		}
+		stats.TxOuts++
+		stats.TotalAmount += entry.Amount()
+		stats.SerializedSize += int64(size)
+
+		headerCode, err := utxoEntryHeaderCode(entry)
+		if err != nil {
+			return err
+		}
+		hasher.Write(outpoint.Hash[:])
+		byteOrder.PutUint32(buf[:4], outpoint.Index)
+		hasher.Write(buf[:4])
+		byteOrder.PutUint32(buf[:4], uint32(headerCode))
+		hasher.Write(buf[:4])
+		byteOrder.PutUint64(buf[:], uint64(entry.Amount()))
+		hasher.Write(buf[:])
+		return wire.WriteVarBytes(hasher, 0, entry.PkScript())
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The outputs are hashed with sha256 as they are serialized, so hash
+	// the resulting digest once more to produce their double sha256.
+	stats.Hash = chainhash.Hash(sha256.Sum256(hasher.Sum(nil)))
+
+	return stats, nil
+}
+
 // dbPutUtxoView uses an existing database transaction to update the utxo set
 // in the database based on the provided utxo view contents and state.  In
 // particular, only the entries that have been marked as modified are written
@@ -1202,6 +1337,9 @@ func (b *BlockChain) initChainState() error {
 			i++
 		}
 
+		// Load the height up to which block data has been pruned.
+		b.prunedHeight = dbFetchPrunedHeight(dbTx)
+
 		// Set the best chain view to the stored best state.
 		tip := b.index.LookupNode(&state.hash)
 		if tip == nil {
@@ -1278,6 +1416,25 @@ func deserializeBlockRow(blockRow []byte) (*wire.BlockHeader, blockStatus, error
 	return &header, blockStatus(statusByte), nil
 }
 
+// dbFetchPrunedHeight uses an existing database transaction to retrieve the
+// height of the most recent block whose data has been pruned.  Zero is
+// returned when no blocks have been pruned.
+func dbFetchPrunedHeight(dbTx database.Tx) int32 {
+	serialized := dbTx.Metadata().Get(prunedHeightKeyName)
+	if len(serialized) < 4 {
+		return 0
+	}
+	return int32(byteOrder.Uint32(serialized))
+}
+
+// dbPutPrunedHeight uses an existing database transaction to store the height
+// of the most recent block whose data has been pruned.
+func dbPutPrunedHeight(dbTx database.Tx, height int32) error {
+	var serialized [4]byte
+	byteOrder.PutUint32(serialized[:], uint32(height))
+	return dbTx.Metadata().Put(prunedHeightKeyName, serialized[:])
+}
+
 // dbFetchHeaderByHash uses an existing database transaction to retrieve the
 // block header for the provided hash.
 func dbFetchHeaderByHash(dbTx database.Tx, hash *chainhash.Hash) (*wire.BlockHeader, error) {
@@ -0,0 +1,143 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+)
+
+// ChainTipStatus describes the validation state of the branch that ends at a
+// chain tip.
+type ChainTipStatus byte
+
+// These constants are used to identify the possible states of a chain tip.
+const (
+	// ChainTipActive indicates the tip is the current best chain tip.
+	ChainTipActive ChainTipStatus = iota
+
+	// ChainTipValidFork indicates the branch is not part of the main chain,
+	// but all of its blocks have been fully validated.
+	ChainTipValidFork
+
+	// ChainTipValidHeaders indicates the data for all blocks in the branch
+	// is available, but the blocks have never been fully validated.
+	ChainTipValidHeaders
+
+	// ChainTipHeadersOnly indicates the branch contains at least one block
+	// for which only the header is known.
+	ChainTipHeadersOnly
+
+	// ChainTipInvalid indicates the branch contains at least one block that
+	// is known to be invalid.
+	ChainTipInvalid
+)
+
+// chainTipStatusStrings is a map of ChainTipStatus values back to the names
+// used by the getchaintips RPC.
+var chainTipStatusStrings = map[ChainTipStatus]string{
+	ChainTipActive:       "active",
+	ChainTipValidFork:    "valid-fork",
+	ChainTipValidHeaders: "valid-headers",
+	ChainTipHeadersOnly:  "headers-only",
+	ChainTipInvalid:      "invalid",
+}
+
+// String returns the ChainTipStatus as a human-readable name.
+func (s ChainTipStatus) String() string {
+	if str := chainTipStatusStrings[s]; str != "" {
+		return str
+	}
+	return fmt.Sprintf("Unknown ChainTipStatus (%d)", int(s))
+}
+
+// ChainTip houses information about the tip of a branch in the block chain.
+type ChainTip struct {
+	// Height is the height of the tip block.
+	Height int32
+
+	// Hash is the hash of the tip block.
+	Hash chainhash.Hash
+
+	// BranchLen is the number of blocks between the tip and the point the
+	// branch forks from the main chain.  It is zero for the active tip.
+	BranchLen int32
+
+	// Status is the validation state of the branch.
+	Status ChainTipStatus
+}
+
+// chainTipStatus returns the status of the branch which ends at the provided
+// node and forks from the main chain at the provided fork node.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) chainTipStatus(tip, fork *blockNode) ChainTipStatus {
+	if tip == b.bestChain.Tip() {
+		return ChainTipActive
+	}
+
+	status := ChainTipValidFork
+	for n := tip; n != nil && n != fork; n = n.parent {
+		nodeStatus := b.index.NodeStatus(n)
+		switch {
+		case nodeStatus.KnownInvalid():
+			return ChainTipInvalid
+		case !nodeStatus.HaveData():
+			status = ChainTipHeadersOnly
+		case !nodeStatus.KnownValid() && status == ChainTipValidFork:
+			status = ChainTipValidHeaders
+		}
+	}
+	return status
+}
+
+// ChainTips returns information about all known tips in the block index,
+// including the tip of the main chain and the tips of all side branches,
+// ordered by descending height.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ChainTips() []ChainTip {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	// The active tip might have children which are known to be invalid, so
+	// make sure it is always included.
+	bestTip := b.bestChain.Tip()
+	nodes := b.index.leaves()
+	haveBestTip := false
+	for _, node := range nodes {
+		if node == bestTip {
+			haveBestTip = true
+			break
+		}
+	}
+	if !haveBestTip {
+		nodes = append(nodes, bestTip)
+	}
+
+	tips := make([]ChainTip, 0, len(nodes))
+	for _, node := range nodes {
+		fork := b.bestChain.FindFork(node)
+		var branchLen int32
+		if fork != nil {
+			branchLen = node.height - fork.height
+		}
+		tips = append(tips, ChainTip{
+			Height:    node.height,
+			Hash:      node.hash,
+			BranchLen: branchLen,
+			Status:    b.chainTipStatus(node, fork),
+		})
+	}
+	sort.Slice(tips, func(i, j int) bool {
+		if tips[i].Height != tips[j].Height {
+			return tips[i].Height > tips[j].Height
+		}
+		return tips[i].Status < tips[j].Status
+	})
+	return tips
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Actinium-project/acmd/chaincfg"
+)
+
+// TestChainTips ensures the chain tips reported for a block index with several
+// side branches in different validation states are correct.
+func TestChainTips(t *testing.T) {
+	// Construct a synthetic block index consisting of the following
+	// structure:
+	//
+	//   genesis -> 1 -> 2 -> 3 -> 4 -> 5 -> 6x
+	//                   \-> 3a -> 4a
+	//                   \-> 3b -> 4b -> 5b
+	//              \-> 2c -> 3c
+	//              \-> 2d
+	//
+	// Where 6x failed validation, 3a-4a are stored but were never fully
+	// validated, 4b is an invalid block, 3c is only a header and 2d was
+	// fully validated on a previous main chain.
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	genesis := chain.bestChain.Tip()
+	branch0 := chainedNodes(genesis, 6)
+	branch1 := chainedNodes(branch0[1], 2)
+	branch2 := chainedNodes(branch0[1], 3)
+	branch3 := chainedNodes(branch0[0], 2)
+	branch4 := chainedNodes(branch0[0], 1)
+
+	setStatus := func(nodes []*blockNode, status blockStatus) {
+		for _, node := range nodes {
+			node.status = status
+			chain.index.AddNode(node)
+		}
+	}
+	valid := statusDataStored | statusValid
+	setStatus(branch0[:5], valid)
+	setStatus(branch0[5:], statusDataStored|statusValidateFailed)
+	setStatus(branch1, statusDataStored)
+	setStatus(branch2[:1], statusDataStored)
+	setStatus(branch2[1:2], statusDataStored|statusValidateFailed)
+	setStatus(branch2[2:], statusDataStored|statusInvalidAncestor)
+	setStatus(branch3[:1], statusDataStored)
+	setStatus(branch3[1:], statusNone)
+	setStatus(branch4, valid)
+	chain.bestChain.SetTip(branch0[4])
+
+	want := []ChainTip{
+		{Height: 6, Hash: branch0[5].hash, BranchLen: 1,
+			Status: ChainTipInvalid},
+		{Height: 5, Hash: branch0[4].hash, Status: ChainTipActive},
+		{Height: 5, Hash: branch2[2].hash, BranchLen: 3,
+			Status: ChainTipInvalid},
+		{Height: 4, Hash: branch1[1].hash, BranchLen: 2,
+			Status: ChainTipValidHeaders},
+		{Height: 3, Hash: branch3[1].hash, BranchLen: 2,
+			Status: ChainTipHeadersOnly},
+		{Height: 2, Hash: branch4[0].hash, BranchLen: 1,
+			Status: ChainTipValidFork},
+	}
+
+	got := chain.ChainTips()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ChainTips: mismatched tips -- got %+v, want %+v", got,
+			want)
+	}
+
+	// Ensure the status strings match the values used by the RPC server.
+	tests := []struct {
+		in   ChainTipStatus
+		want string
+	}{
+		{ChainTipActive, "active"},
+		{ChainTipValidFork, "valid-fork"},
+		{ChainTipValidHeaders, "valid-headers"},
+		{ChainTipHeadersOnly, "headers-only"},
+		{ChainTipInvalid, "invalid"},
+		{0xff, "Unknown ChainTipStatus (255)"},
+	}
+	for _, test := range tests {
+		if got := test.in.String(); got != test.want {
+			t.Errorf("String: unexpected result for %d -- got %q, "+
+				"want %q", test.in, got, test.want)
+		}
+	}
+}
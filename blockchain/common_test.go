@@ -382,3 +382,79 @@ func newFakeNode(parent *blockNode, blockVersion int32, bits uint32, timestamp t
 	}
 	return newBlockNode(header, parent)
 }
+
+// opTrueScript is a simple public key script that contains the OP_TRUE opcode.
+// It is used to create outputs that can be spent without signatures.
+var opTrueScript = []byte{txscript.OP_TRUE}
+
+// newTestBlock returns a block building on the passed parent at the passed
+// height, which has a coinbase paying the subsidy of the passed network to
+// opTrueScript followed by the passed transactions.  The block is timestamped
+// one second after its parent and solved with the proof-of-work algorithm the
+// network uses at its height, so it is valid as long as the transactions are.
+func newTestBlock(params *chaincfg.Params, parent *wire.MsgBlock, height int32,
+	txns ...*wire.MsgTx) *acmutil.Block {
+
+	coinbaseScript, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).AddInt64(0).Script()
+	if err != nil {
+		panic(err)
+	}
+	coinbaseTx := wire.NewMsgTx(1)
+	coinbaseTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		Sequence:        wire.MaxTxInSequenceNum,
+		SignatureScript: coinbaseScript,
+	})
+	coinbaseTx.AddTxOut(&wire.TxOut{
+		Value:    CalcBlockSubsidy(height, params),
+		PkScript: opTrueScript,
+	})
+
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   1,
+			PrevBlock: parent.BlockHash(),
+			Bits:      params.PowLimitBits,
+			Timestamp: parent.Header.Timestamp.Add(time.Second),
+		},
+		Transactions: append([]*wire.MsgTx{coinbaseTx}, txns...),
+	}
+	block := acmutil.NewBlock(msgBlock)
+	merkles := BuildMerkleTreeStore(block.Transactions(), false)
+	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+
+	// Solve the block by trying nonces until the proof-of-work hash meets
+	// the target.
+	target := CompactToBig(msgBlock.Header.Bits)
+	powAlgo := params.PowAlgorithm(height)
+	for {
+		hash, err := msgBlock.Header.PowHash(powAlgo)
+		if err != nil {
+			panic(err)
+		}
+		if HashToBig(hash).Cmp(target) <= 0 {
+			break
+		}
+		msgBlock.Header.Nonce++
+	}
+	return acmutil.NewBlock(msgBlock)
+}
+
+// newSpendTx returns a transaction which spends the passed outpoint that pays
+// to opTrueScript and splits its full value among the passed number of outputs
+// paying to opTrueScript.  Any remainder goes to the first output.
+func newSpendTx(outpoint wire.OutPoint, value int64, numOutputs int) *wire.MsgTx {
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: outpoint,
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	outputValue := value / int64(numOutputs)
+	for i := 0; i < numOutputs; i++ {
+		tx.AddTxOut(&wire.TxOut{Value: outputValue, PkScript: opTrueScript})
+	}
+	tx.TxOut[0].Value += value % int64(numOutputs)
+	return tx
+}
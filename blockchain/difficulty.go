@@ -224,6 +224,12 @@ func (b *BlockChain) calcNextRequiredDifficulty(lastNode *blockNode, newBlockTim
 		return b.chainParams.PowLimitBits, nil
 	}
 
+	// Actinium retargets every block using the Zawy LWMA algorithm once
+	// the chain reaches the fork height.
+	if lastNode.height+1 >= b.chainParams.ACMZawyLWMAHeight {
+		return b.calcNextRequiredDifficultyLWMA(lastNode)
+	}
+
 	// Return the previous block's difficulty requirements if this block
 	// is not at a difficulty retarget interval.
 	if (lastNode.height+1)%b.blocksPerRetarget != 0 {
@@ -307,6 +313,99 @@ func (b *BlockChain) calcNextRequiredDifficulty(lastNode *blockNode, newBlockTim
 	return newTargetBits, nil
 }
 
+// calcNextRequiredDifficultyLWMA calculates the required difficulty for the
+// block after the passed previous block node using Zawy's linearly weighted
+// moving average (LWMA) algorithm.  Unlike the interval based retarget, the
+// difficulty is adjusted every block from the targets and solve times of the
+// previous ACMZawyLWMAWindow blocks, with more recent solve times given
+// linearly higher weight.
+//
+// The calculation intentionally mirrors the integer arithmetic used by the
+// reference Actinium implementation so that both agree on the exact bits.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) calcNextRequiredDifficultyLWMA(lastNode *blockNode) (uint32, error) {
+	// Networks which do not retarget simply keep the previous block's
+	// difficulty requirements.
+	if b.chainParams.PoWNoRetargeting {
+		return lastNode.bits, nil
+	}
+
+	window := int64(b.chainParams.ACMZawyLWMAWindow)
+	if window <= 0 {
+		return 0, AssertError("LWMA averaging window must be positive")
+	}
+
+	// There are not enough blocks to average until the chain is longer
+	// than the window, so allow the minimum difficulty until then.
+	if int64(lastNode.height) < window {
+		return b.chainParams.PowLimitBits, nil
+	}
+
+	// Collect the nodes in the window ordered from oldest to newest.  The
+	// node just prior to the window provides the timestamp the first solve
+	// time is measured from.
+	nodes := make([]*blockNode, window)
+	iterNode := lastNode
+	for i := window - 1; i >= 0; i-- {
+		nodes[i] = iterNode
+		iterNode = iterNode.parent
+	}
+	if iterNode == nil {
+		return 0, AssertError("unable to obtain LWMA window start block")
+	}
+
+	// The weights 1..window sum to window*(window+1)/2, so k is the
+	// weighted sum of solve times when every block is found exactly on
+	// target.  Each target is divided by window*k up front rather than
+	// dividing the final result by k in order to match the reference
+	// implementation's rounding.
+	targetTimePerBlock := int64(b.chainParams.TargetTimePerBlock / time.Second)
+	k := window * (window + 1) * targetTimePerBlock / 2
+	maxSolveTime := b.chainParams.ACMZawyLWMAMaxSolveTimeFactor *
+		targetTimePerBlock
+	divisor := big.NewInt(window * k)
+
+	avgTarget := new(big.Int)
+	prevTimestamp := iterNode.timestamp
+	var weightedSolveTimes int64
+	for i, node := range nodes {
+		// Prevent solve times from being zero or negative by treating
+		// out of order timestamps as one second after the previous
+		// one.
+		timestamp := node.timestamp
+		if timestamp <= prevTimestamp {
+			timestamp = prevTimestamp + 1
+		}
+
+		// Clamp long solve times to prevent large drops in difficulty
+		// which would otherwise cause oscillations.
+		solveTime := timestamp - prevTimestamp
+		if solveTime > maxSolveTime {
+			solveTime = maxSolveTime
+		}
+		prevTimestamp = timestamp
+
+		weightedSolveTimes += solveTime * int64(i+1)
+		target := CompactToBig(node.bits)
+		avgTarget.Add(avgTarget, target.Div(target, divisor))
+	}
+	newTarget := avgTarget.Mul(avgTarget, big.NewInt(weightedSolveTimes))
+
+	// Limit new value to the proof of work limit.
+	if newTarget.Cmp(b.chainParams.PowLimit) > 0 {
+		newTarget.Set(b.chainParams.PowLimit)
+	}
+
+	newTargetBits := BigToCompact(newTarget)
+	log.Tracef("LWMA difficulty at block height %d: old target %08x, new "+
+		"target %08x, weighted solve times %d (target %d)",
+		lastNode.height+1, lastNode.bits, newTargetBits,
+		weightedSolveTimes, k)
+
+	return newTargetBits, nil
+}
+
 // CalcNextRequiredDifficulty calculates the required difficulty for the block
 // after the end of the current best chain based on the difficulty retarget
 // rules.
@@ -7,6 +7,9 @@ package blockchain
 import (
 	"math/big"
 	"testing"
+	"time"
+
+	"github.com/Actinium-project/acmd/chaincfg"
 )
 
 // TestBigToCompact ensures BigToCompact converts big integers to the expected
@@ -73,3 +76,149 @@ func TestCalcWork(t *testing.T) {
 		}
 	}
 }
+
+// TestCalcNextRequiredDifficultyLWMA ensures the Zawy LWMA difficulty
+// algorithm takes over from the interval based retarget at ACMZawyLWMAHeight
+// and calculates the expected difficulty for a variety of simnet chains.
+func TestCalcNextRequiredDifficultyLWMA(t *testing.T) {
+	t.Parallel()
+
+	// lwmaFromGenesis is a copy of the simnet parameters with the LWMA
+	// active from the genesis block so the early window can be tested.
+	lwmaFromGenesis := chaincfg.SimNetParams
+	lwmaFromGenesis.ACMZawyLWMAHeight = 0
+
+	constBits := func(bits uint32) func(int32) uint32 {
+		return func(int32) uint32 { return bits }
+	}
+	constSolveTime := func(solveTime int64) func(int32) int64 {
+		return func(int32) int64 { return solveTime }
+	}
+
+	tests := []struct {
+		name      string
+		params    *chaincfg.Params
+		height    int32 // height of the chain tip
+		bits      func(height int32) uint32
+		solveTime func(height int32) int64
+		want      uint32
+	}{
+		{
+			name:      "before fork uses interval retarget",
+			params:    &chaincfg.SimNetParams,
+			height:    198,
+			bits:      constBits(0x1d00ffff),
+			solveTime: constSolveTime(60),
+			want:      0x1d00ffff,
+		},
+		{
+			name:      "fork block with solve times on target",
+			params:    &chaincfg.SimNetParams,
+			height:    199,
+			bits:      constBits(0x1d00ffff),
+			solveTime: constSolveTime(600),
+			want:      0x1d00fffe,
+		},
+		{
+			name:      "fork block with fast solve times",
+			params:    &chaincfg.SimNetParams,
+			height:    199,
+			bits:      constBits(0x1d00ffff),
+			solveTime: constSolveTime(60),
+			want:      0x1c19997f,
+		},
+		{
+			name:      "fork block with clamped slow solve times",
+			params:    &chaincfg.SimNetParams,
+			height:    199,
+			bits:      constBits(0x1d00ffff),
+			solveTime: constSolveTime(7200),
+			want:      0x1d05fff9,
+		},
+		{
+			name:   "out of order timestamps",
+			params: &chaincfg.SimNetParams,
+			height: 250,
+			bits:   constBits(0x1d00ffff),
+			solveTime: func(height int32) int64 {
+				if height%3 == 0 {
+					return -300
+				}
+				return 900
+			},
+			want: 0x1d00d378,
+		},
+		{
+			name:   "varying difficulty and solve times",
+			params: &chaincfg.SimNetParams,
+			height: 300,
+			bits: func(height int32) uint32 {
+				if height%2 == 0 {
+					return 0x1d00ffff
+				}
+				return 0x1c7fffff
+			},
+			solveTime: func(height int32) int64 {
+				if height%2 == 0 {
+					return 120
+				}
+				return 1500
+			},
+			want: 0x1d01002c,
+		},
+		{
+			name:      "limited to proof of work limit",
+			params:    &chaincfg.SimNetParams,
+			height:    220,
+			bits:      constBits(0x207fffff),
+			solveTime: constSolveTime(3600),
+			want:      0x207fffff,
+		},
+		{
+			name:      "chain shorter than window",
+			params:    &lwmaFromGenesis,
+			height:    10,
+			bits:      constBits(0x1d00ffff),
+			solveTime: constSolveTime(600),
+			want:      0x207fffff,
+		},
+		{
+			name:      "chain as long as window",
+			params:    &lwmaFromGenesis,
+			height:    45,
+			bits:      constBits(0x1d00ffff),
+			solveTime: constSolveTime(600),
+			want:      0x1d00fffe,
+		},
+		{
+			name:      "no retargeting on regtest",
+			params:    &chaincfg.RegressionNetParams,
+			height:    100,
+			bits:      constBits(0x1d00ffff),
+			solveTime: constSolveTime(60),
+			want:      0x1d00ffff,
+		},
+	}
+
+	for _, test := range tests {
+		chain := newFakeChain(test.params)
+		node := chain.bestChain.Tip()
+		for height := int32(1); height <= test.height; height++ {
+			timestamp := time.Unix(node.timestamp+
+				test.solveTime(height), 0)
+			node = newFakeNode(node, 1, test.bits(height), timestamp)
+		}
+
+		nextTime := time.Unix(node.timestamp+
+			test.solveTime(test.height+1), 0)
+		got, err := chain.calcNextRequiredDifficulty(node, nextTime)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: mismatched difficulty - got %08x, want "+
+				"%08x", test.name, got, test.want)
+		}
+	}
+}
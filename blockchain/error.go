@@ -220,6 +220,11 @@ const (
 	// current chain tip. This is not a block validation rule, but is required
 	// for block proposals submitted via getblocktemplate RPC.
 	ErrPrevBlockNotBest
+
+	// ErrBadMerkleProof indicates a partial merkle tree proving the
+	// inclusion of transactions in a block is malformed or does not commit
+	// to the merkle root of the block.
+	ErrBadMerkleProof
 )
 
 // Map of ErrorCode values back to their constant names for pretty printing.
@@ -267,6 +272,7 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrPreviousBlockUnknown:      "ErrPreviousBlockUnknown",
 	ErrInvalidAncestorBlock:      "ErrInvalidAncestorBlock",
 	ErrPrevBlockNotBest:          "ErrPrevBlockNotBest",
+	ErrBadMerkleProof:            "ErrBadMerkleProof",
 }
 
 // String returns the ErrorCode as a human-readable name.
@@ -287,6 +287,36 @@ func TestFullBlocks(t *testing.T) {
 		}
 	}
 
+	// testInvalidatedBlock manually invalidates the block in the provided
+	// test instance and ensures that it did not fail.
+	testInvalidatedBlock := func(item fullblocktests.InvalidatedBlock) {
+		blockHash := item.Block.BlockHash()
+		t.Logf("Invalidating block %s (hash %s, height %d)",
+			item.Name, blockHash, item.Height)
+
+		err := chain.InvalidateBlock(&blockHash)
+		if err != nil {
+			t.Fatalf("block %q (hash %s, height %d) should have "+
+				"been invalidated: %v", item.Name, blockHash,
+				item.Height, err)
+		}
+	}
+
+	// testReconsideredBlock manually reconsiders the block in the provided
+	// test instance and ensures that it did not fail.
+	testReconsideredBlock := func(item fullblocktests.ReconsideredBlock) {
+		blockHash := item.Block.BlockHash()
+		t.Logf("Reconsidering block %s (hash %s, height %d)",
+			item.Name, blockHash, item.Height)
+
+		err := chain.ReconsiderBlock(&blockHash)
+		if err != nil {
+			t.Fatalf("block %q (hash %s, height %d) should have "+
+				"been reconsidered: %v", item.Name, blockHash,
+				item.Height, err)
+		}
+	}
+
 	for testNum, test := range tests {
 		for itemNum, item := range test {
 			switch item := item.(type) {
@@ -300,6 +330,10 @@ func TestFullBlocks(t *testing.T) {
 				testOrphanOrRejectedBlock(item)
 			case fullblocktests.ExpectedTip:
 				testExpectedTip(item)
+			case fullblocktests.InvalidatedBlock:
+				testInvalidatedBlock(item)
+			case fullblocktests.ReconsideredBlock:
+				testReconsideredBlock(item)
 			default:
 				t.Fatalf("test #%d, item #%d is not one of "+
 					"the supported test instance types -- "+
@@ -150,6 +150,41 @@ type RejectedNonCanonicalBlock struct {
 // This implements the TestInstance interface.
 func (b RejectedNonCanonicalBlock) FullBlockTestInstance() {}
 
+// InvalidatedBlock defines a test instance that manually invalidates a block
+// which is already known to the block chain along with all of its descendants.
+type InvalidatedBlock struct {
+	Name   string
+	Block  *wire.MsgBlock
+	Height int32
+}
+
+// Ensure InvalidatedBlock implements the TestInstance interface.
+var _ TestInstance = InvalidatedBlock{}
+
+// FullBlockTestInstance only exists to allow InvalidatedBlock to be treated as
+// a TestInstance.
+//
+// This implements the TestInstance interface.
+func (b InvalidatedBlock) FullBlockTestInstance() {}
+
+// ReconsideredBlock defines a test instance that removes the invalid status
+// from a block which is already known to the block chain along with all of its
+// ancestors and descendants.
+type ReconsideredBlock struct {
+	Name   string
+	Block  *wire.MsgBlock
+	Height int32
+}
+
+// Ensure ReconsideredBlock implements the TestInstance interface.
+var _ TestInstance = ReconsideredBlock{}
+
+// FullBlockTestInstance only exists to allow ReconsideredBlock to be treated as
+// a TestInstance.
+//
+// This implements the TestInstance interface.
+func (b ReconsideredBlock) FullBlockTestInstance() {}
+
 // spendableOut represents a transaction output that is spendable along with
 // additional metadata such as the block its in and how much it pays.
 type spendableOut struct {
@@ -313,15 +348,16 @@ func calcMerkleRoot(txns []*wire.MsgTx) chainhash.Hash {
 	return *merkles[len(merkles)-1]
 }
 
-// solveBlock attempts to find a nonce which makes the passed block header hash
-// to a value less than the target difficulty.  When a successful solution is
+// solveBlock attempts to find a nonce which makes the proof-of-work hash of the
+// passed block header, computed with the provided algorithm, a value less than
+// the target difficulty.  When a successful solution is
 // found true is returned and the nonce field of the passed header is updated
 // with the solution.  False is returned if no solution exists.
 //
 // NOTE: This function will never solve blocks with a nonce of 0.  This is done
 // so the 'nextBlock' function can properly detect when a nonce was modified by
 // a munge function.
-func solveBlock(header *wire.BlockHeader) bool {
+func solveBlock(header *wire.BlockHeader, powAlgo wire.PowAlgorithm) bool {
 	// sbResult is used by the solver goroutines to send results.
 	type sbResult struct {
 		found bool
@@ -342,8 +378,11 @@ func solveBlock(header *wire.BlockHeader) bool {
 				return
 			default:
 				hdr.Nonce = i
-				hash := hdr.BlockHash()
-				if blockchain.HashToBig(&hash).Cmp(
+				hash, err := hdr.PowHash(powAlgo)
+				if err != nil {
+					panic(err)
+				}
+				if blockchain.HashToBig(hash).Cmp(
 					targetDifficulty) <= 0 {
 
 					results <- sbResult{true, i}
@@ -533,7 +572,8 @@ func (g *testGenerator) nextBlock(blockName string, spend *spendableOut, mungers
 
 	// Only solve the block if the nonce wasn't manually changed by a munge
 	// function.
-	if block.Header.Nonce == curNonce && !solveBlock(&block.Header) {
+	powAlgo := g.params.PowAlgorithm(nextHeight)
+	if block.Header.Nonce == curNonce && !solveBlock(&block.Header, powAlgo) {
 		panic(fmt.Sprintf("Unable to solve block at height %d",
 			nextHeight))
 	}
@@ -835,6 +875,12 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	//
 	// expectTipBlock creates a test instance that expects the provided
 	// block to be the current tip of the block chain.
+	//
+	// invalidateBlock creates a test instance that manually invalidates
+	// the provided block.
+	//
+	// reconsiderBlock creates a test instance that manually reconsiders
+	// the provided block.
 	acceptBlock := func(blockName string, block *wire.MsgBlock, isMainChain, isOrphan bool) TestInstance {
 		blockHeight := g.blockHeights[blockName]
 		return AcceptedBlock{blockName, block, blockHeight, isMainChain,
@@ -857,6 +903,14 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 		blockHeight := g.blockHeights[blockName]
 		return ExpectedTip{blockName, block, blockHeight}
 	}
+	invalidateBlock := func(blockName string, block *wire.MsgBlock) TestInstance {
+		blockHeight := g.blockHeights[blockName]
+		return InvalidatedBlock{blockName, block, blockHeight}
+	}
+	reconsiderBlock := func(blockName string, block *wire.MsgBlock) TestInstance {
+		blockHeight := g.blockHeights[blockName]
+		return ReconsideredBlock{blockName, block, blockHeight}
+	}
 
 	// Define some convenience helper functions to populate the tests slice
 	// with test instances that have the described characteristics.
@@ -879,6 +933,16 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	//
 	// orphanedOrRejected creates and appends a single orphanOrRejectBlock
 	// test instance for the current tip.
+	//
+	// invalidatedWithExpectedTip creates and appends a two-instance test.
+	// The first instance is an invalidateBlock test instance for the
+	// provided block and the second instance is an expectBlockTip test
+	// instance for provided values.
+	//
+	// reconsideredWithExpectedTip creates and appends a two-instance test.
+	// The first instance is a reconsiderBlock test instance for the
+	// provided block and the second instance is an expectBlockTip test
+	// instance for provided values.
 	accepted := func() {
 		tests = append(tests, []TestInstance{
 			acceptBlock(g.tipName, g.tip, true, false),
@@ -905,6 +969,18 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 			orphanOrRejectBlock(g.tipName, g.tip),
 		})
 	}
+	invalidatedWithExpectedTip := func(blockName, tipName string) {
+		tests = append(tests, []TestInstance{
+			invalidateBlock(blockName, g.blocksByName[blockName]),
+			expectTipBlock(tipName, g.blocksByName[tipName]),
+		})
+	}
+	reconsideredWithExpectedTip := func(blockName, tipName string) {
+		tests = append(tests, []TestInstance{
+			reconsiderBlock(blockName, g.blocksByName[blockName]),
+			expectTipBlock(tipName, g.blocksByName[tipName]),
+		})
+	}
 
 	// ---------------------------------------------------------------------
 	// Generate enough blocks to have mature coinbase outputs to work with.
@@ -1440,12 +1516,16 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	// requires an unsolved block.
 	{
 		origHash := b46.BlockHash()
+		powAlgo := g.params.PowAlgorithm(g.blockHeights["b46"])
 		for {
-			// Keep incrementing the nonce until the hash treated as
-			// a uint256 is higher than the limit.
+			// Keep incrementing the nonce until the proof-of-work
+			// hash treated as a uint256 is higher than the limit.
 			b46.Header.Nonce++
-			blockHash := b46.BlockHash()
-			hashNum := blockchain.HashToBig(&blockHash)
+			powHash, err := b46.Header.PowHash(powAlgo)
+			if err != nil {
+				panic(err)
+			}
+			hashNum := blockchain.HashToBig(powHash)
 			if hashNum.Cmp(g.params.PowLimit) >= 0 {
 				break
 			}
@@ -2045,6 +2125,48 @@ func Generate(includeLargeReorg bool) (tests [][]TestInstance, err error) {
 	}
 	accepted()
 
+	// ---------------------------------------------------------------------
+	// Manual block invalidation and reconsideration tests.
+	// ---------------------------------------------------------------------
+
+	// Create a side chain block with the same amount of work as the tip.
+	//
+	//   ... -> b79(26) -> b81(27)
+	//                 \-> b82(27)
+	g.setTip("b79")
+	g.nextBlock("b82", outs[27])
+	acceptedToSideChainWithExpectedTip("b81")
+
+	// Invalidate the current tip which should cause a reorg to the side
+	// chain.
+	//
+	//   ... -> b79(26) -> b81(27) (invalid)
+	//                 \-> b82(27)
+	invalidatedWithExpectedTip("b81", "b82")
+
+	// Create a block that builds on the invalidated block.
+	//
+	//   ... -> b79(26) -> b81(27) (invalid) -> b83
+	//                 \-> b82(27)
+	g.setTip("b81")
+	g.nextBlock("b83", nil)
+	rejected(blockchain.ErrInvalidAncestorBlock)
+
+	// Reconsider the invalidated block.  Since it has the same amount of
+	// work as the current tip, no reorg is expected.
+	//
+	//   ... -> b79(26) -> b81(27)
+	//                 \-> b82(27)
+	reconsideredWithExpectedTip("b81", "b82")
+
+	// Invalidate the side chain block which should cause a reorg back to the
+	// original block and then reconsider it without causing another reorg.
+	//
+	//   ... -> b79(26) -> b81(27)
+	//                 \-> b82(27) (invalid)
+	invalidatedWithExpectedTip("b82", "b81")
+	reconsideredWithExpectedTip("b82", "b81")
+
 	// ---------------------------------------------------------------------
 	// Large block re-org test.
 	// ---------------------------------------------------------------------
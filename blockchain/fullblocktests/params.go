@@ -112,6 +112,7 @@ var regressionNetParams = &chaincfg.Params{
 	RetargetAdjustmentFactor: 4,                   // 25% less, 400% more
 	ReduceMinDifficulty:      true,
 	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
+	PoWNoRetargeting:         true,
 	GenerateSupported:        true,
 
 	// Checkpoints ordered from oldest to newest.
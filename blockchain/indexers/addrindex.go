@@ -22,6 +22,9 @@ const (
 	// addrIndexName is the human-readable name for the index.
 	addrIndexName = "address index"
 
+	// addrIndexVersion is the current version of the address index.
+	addrIndexVersion = 2
+
 	// level0MaxEntries is the maximum number of transactions that are
 	// stored in level 0 of an address index entry.  Subsequent levels store
 	// 2^n * level0MaxEntries entries, or in words, double the maximum of
@@ -575,6 +578,11 @@ func addrToKey(addr acmutil.Address) ([addrKeySize]byte, error) {
 // are ordered according to their order of appearance in the blockchain.  In
 // other words, first by block height and then by offset inside the block.
 //
+// The index also records the changes each transaction makes to the balances of
+// the addresses it involves, the unspent outputs of each address and the input
+// spending each output, so balances and unspent outputs can be queried without
+// loading the transactions.
+//
 // In addition, support is provided for a memory-only index of unconfirmed
 // transactions such as those which are kept in the memory pool before inclusion
 // in a block.
@@ -598,9 +606,13 @@ type AddrIndex struct {
 	// keep an index of all addresses which a given transaction involves.
 	// This allows fairly efficient updates when transactions are removed
 	// once they are included into a block.
+	//
+	// The deltasByTx field keeps the changes each transaction makes to the
+	// balances of the addresses it involves.
 	unconfirmedLock sync.RWMutex
 	txnsByAddr      map[[addrKeySize]byte]map[chainhash.Hash]*acmutil.Tx
 	addrsByTx       map[chainhash.Hash]map[[addrKeySize]byte]struct{}
+	deltasByTx      map[chainhash.Hash][]unconfirmedDelta
 }
 
 // Ensure the AddrIndex type implements the Indexer interface.
@@ -609,6 +621,9 @@ var _ Indexer = (*AddrIndex)(nil)
 // Ensure the AddrIndex type implements the NeedsInputser interface.
 var _ NeedsInputser = (*AddrIndex)(nil)
 
+// Ensure the AddrIndex type implements the VersionedIndexer interface.
+var _ VersionedIndexer = (*AddrIndex)(nil)
+
 // NeedsInputs signals that the index requires the referenced inputs in order
 // to properly create the index.
 //
@@ -626,6 +641,16 @@ func (idx *AddrIndex) Init() error {
 	return nil
 }
 
+// Version returns the current version of the address index.  Version 2 added
+// the address deltas, unspent outputs and spent-by links, so indexes created
+// before are rebuilt since the entries for the blocks which are already indexed
+// would be missing.
+//
+// This implements the VersionedIndexer interface.
+func (idx *AddrIndex) Version() uint32 {
+	return addrIndexVersion
+}
+
 // Key returns the database key to use for the index as a byte slice.
 //
 // This is part of the Indexer interface.
@@ -642,12 +667,22 @@ func (idx *AddrIndex) Name() string {
 
 // Create is invoked when the indexer manager determines the index needs
 // to be created for the first time.  It creates the bucket for the address
-// index.
+// index along with the buckets for the address deltas, unspent outputs and
+// spent-by links below it.
 //
 // This is part of the Indexer interface.
 func (idx *AddrIndex) Create(dbTx database.Tx) error {
-	_, err := dbTx.Metadata().CreateBucket(addrIndexKey)
-	return err
+	addrIdxBucket, err := dbTx.Metadata().CreateBucket(addrIndexKey)
+	if err != nil {
+		return err
+	}
+
+	for _, bucketName := range addrIndexSubBucketNames {
+		if _, err := addrIdxBucket.CreateBucket(bucketName); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // writeIndexData represents the address index data to be written for one block.
@@ -723,7 +758,8 @@ func (idx *AddrIndex) indexBlock(data writeIndexData, block *acmutil.Block,
 
 // ConnectBlock is invoked by the index manager when a new block has been
 // connected to the main chain.  This indexer adds a mapping for each address
-// the transactions in the block involve.
+// the transactions in the block involve along with the address deltas, unspent
+// outputs and spent-by links of the transactions.
 //
 // This is part of the Indexer interface.
 func (idx *AddrIndex) ConnectBlock(dbTx database.Tx, block *acmutil.Block,
@@ -758,12 +794,13 @@ func (idx *AddrIndex) ConnectBlock(dbTx database.Tx, block *acmutil.Block,
 		}
 	}
 
-	return nil
+	return idx.connectBlockDeltas(dbTx, block, stxos)
 }
 
 // DisconnectBlock is invoked by the index manager when a block has been
 // disconnected from the main chain.  This indexer removes the address mappings
-// each transaction in the block involve.
+// each transaction in the block involve along with their address deltas and
+// spent-by links, and restores the unspent outputs they spent.
 //
 // This is part of the Indexer interface.
 func (idx *AddrIndex) DisconnectBlock(dbTx database.Tx, block *acmutil.Block,
@@ -782,7 +819,7 @@ func (idx *AddrIndex) DisconnectBlock(dbTx database.Tx, block *acmutil.Block,
 		}
 	}
 
-	return nil
+	return idx.disconnectBlockDeltas(dbTx, block, stxos)
 }
 
 // TxRegionsForAddress returns a slice of block regions which identify each
@@ -891,6 +928,11 @@ func (idx *AddrIndex) AddUnconfirmedTx(tx *acmutil.Tx, utxoView *blockchain.Utxo
 	for _, txOut := range tx.MsgTx().TxOut {
 		idx.indexUnconfirmedAddresses(txOut.PkScript, tx)
 	}
+
+	// Record the changes to the balances of the addresses.
+	idx.unconfirmedLock.Lock()
+	idx.indexUnconfirmedDeltas(tx, utxoView)
+	idx.unconfirmedLock.Unlock()
 }
 
 // RemoveUnconfirmedTx removes the passed transaction from the unconfirmed
@@ -913,6 +955,7 @@ func (idx *AddrIndex) RemoveUnconfirmedTx(hash *chainhash.Hash) {
 
 	// Remove the entry from the transaction to address lookup map as well.
 	delete(idx.addrsByTx, *hash)
+	delete(idx.deltasByTx, *hash)
 }
 
 // UnconfirmedTxnsForAddress returns all transactions currently in the
@@ -957,6 +1000,7 @@ func NewAddrIndex(db database.DB, chainParams *chaincfg.Params) *AddrIndex {
 		chainParams: chainParams,
 		txnsByAddr:  make(map[[addrKeySize]byte]map[chainhash.Hash]*acmutil.Tx),
 		addrsByTx:   make(map[chainhash.Hash]map[[addrKeySize]byte]struct{}),
+		deltasByTx:  make(map[chainhash.Hash][]unconfirmedDelta),
 	}
 }
 
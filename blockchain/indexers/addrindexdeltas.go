@@ -0,0 +1,748 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/Actinium-project/acmd/blockchain"
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/database"
+	"github.com/Actinium-project/acmd/txscript"
+	"github.com/Actinium-project/acmd/wire"
+	"github.com/Actinium-project/acmutil"
+)
+
+const (
+	// addrDeltaKeySize is the number of bytes a key in the address delta
+	// bucket consumes.  It consists of the address key + 4 bytes block
+	// height + 4 bytes transaction index within the block + 1 byte
+	// direction + 4 bytes input or output index.
+	addrDeltaKeySize = addrKeySize + 4 + 4 + 1 + 4
+
+	// addrDeltaDirInput and addrDeltaDirOutput are the directions used in
+	// address delta keys.  Inputs sort before outputs so the deltas of a
+	// transaction are stored in the order they are applied.
+	addrDeltaDirInput  = 0
+	addrDeltaDirOutput = 1
+
+	// addrCreditValueSize is the size of a serialized credit delta.  It
+	// consists of the 32 byte transaction hash + 8 bytes amount.
+	addrCreditValueSize = chainhash.HashSize + 8
+
+	// addrDebitValueSize is the size of a serialized debit delta.  It
+	// consists of a serialized credit delta + 36 bytes spent outpoint.
+	addrDebitValueSize = addrCreditValueSize + chainhash.HashSize + 4
+
+	// addrSpendValueSize is the size of a serialized spent-by link.  It
+	// consists of the 32 byte spending transaction hash + 4 bytes input
+	// index + 4 bytes block height.
+	addrSpendValueSize = chainhash.HashSize + 4 + 4
+
+	// unconfirmedHeight is the height reported for the deltas and unspent
+	// outputs of transactions which are not in a block yet.
+	unconfirmedHeight = -1
+)
+
+var (
+	// addrDeltasBucketName is the name of the bucket below the address
+	// index bucket which houses the balance changes of each address.
+	addrDeltasBucketName = []byte("deltas")
+
+	// addrUtxosBucketName is the name of the bucket below the address
+	// index bucket which houses the unspent outputs of each address.
+	addrUtxosBucketName = []byte("utxos")
+
+	// addrSpentByBucketName is the name of the bucket below the address
+	// index bucket which links spent outputs to the inputs spending them.
+	addrSpentByBucketName = []byte("spentby")
+
+	// addrIndexSubBucketNames are the names of all of the buckets below
+	// the address index bucket.
+	addrIndexSubBucketNames = [][]byte{
+		addrDeltasBucketName,
+		addrUtxosBucketName,
+		addrSpentByBucketName,
+	}
+)
+
+// -----------------------------------------------------------------------------
+// In addition to the transaction regions, the address index records every
+// change to the balance of an address, the unspent outputs of every address,
+// and links every spent output to the input spending it.  This allows balances
+// and unspent outputs to be served without loading and decoding transactions.
+//
+// Each is housed in its own bucket below the address index bucket.
+//
+// The serialized delta key format is:
+//
+//   <addr key><block height><tx index><direction><index>
+//
+//   Field           Type      Size
+//   addr key        [21]byte  21 bytes
+//   block height    uint32    4 bytes (big endian)
+//   tx index        uint32    4 bytes (big endian)
+//   direction       uint8     1 byte (0 = input, 1 = output)
+//   index           uint32    4 bytes (big endian)
+//   -----
+//   Total: 34 bytes
+//
+// The key is big endian so the deltas of an address are iterated in the order
+// they appear in the blockchain.
+//
+// The serialized delta value format is:
+//
+//   <tx hash><amount>[<prev hash><prev index>]
+//
+//   Field           Type            Size
+//   tx hash         chainhash.Hash  32 bytes
+//   amount          int64           8 bytes
+//   prev hash       chainhash.Hash  32 bytes (inputs only)
+//   prev index      uint32          4 bytes (inputs only)
+//   -----
+//   Total: 40 bytes for outputs, 76 bytes for inputs
+//
+// The serialized unspent output key format is:
+//
+//   <addr key><tx hash><index>
+//
+//   Field           Type            Size
+//   addr key        [21]byte        21 bytes
+//   tx hash         chainhash.Hash  32 bytes
+//   index           uint32          4 bytes (big endian)
+//   -----
+//   Total: 57 bytes
+//
+// The serialized unspent output value format is:
+//
+//   <block height><amount><pkscript>
+//
+//   Field           Type      Size
+//   block height    uint32    4 bytes
+//   amount          int64     8 bytes
+//   pkscript        []byte    variable
+//
+// The serialized spent-by key format is:
+//
+//   <prev hash><prev index>
+//
+//   Field           Type            Size
+//   prev hash       chainhash.Hash  32 bytes
+//   prev index      uint32          4 bytes (big endian)
+//   -----
+//   Total: 36 bytes
+//
+// The serialized spent-by value format is:
+//
+//   <tx hash><input index><block height>
+//
+//   Field           Type            Size
+//   tx hash         chainhash.Hash  32 bytes
+//   input index     uint32          4 bytes
+//   block height    uint32          4 bytes
+//   -----
+//   Total: 40 bytes
+// -----------------------------------------------------------------------------
+
+// AddrSpend identifies the transaction input which spends an output.
+type AddrSpend struct {
+	TxHash chainhash.Hash
+	Index  uint32
+	Height int32
+}
+
+// AddrDelta describes a change to the balance of an address caused by either a
+// transaction output paying to it or a transaction input spending one of its
+// outputs.
+type AddrDelta struct {
+	// TxHash is the hash of the transaction which caused the change.
+	TxHash chainhash.Hash
+
+	// Index is the index of the output for credits and the index of the
+	// input for debits.
+	Index uint32
+
+	// Amount is positive for credits and negative for debits.
+	Amount int64
+
+	// Height is the height of the block containing the transaction and
+	// BlockIndex is the position of the transaction in that block.  The
+	// height is -1 for unconfirmed transactions.
+	Height     int32
+	BlockIndex uint32
+
+	// PrevOut is the output spent by debits.  It is nil for credits.
+	PrevOut *wire.OutPoint
+
+	// SpentBy identifies the input spending a credit in the main chain.  It
+	// is nil for debits and unspent or unconfirmed credits.
+	SpentBy *AddrSpend
+}
+
+// AddrUtxo describes an unspent output which pays to an address.
+type AddrUtxo struct {
+	OutPoint wire.OutPoint
+	Amount   int64
+	PkScript []byte
+
+	// Height is the height of the block containing the transaction which
+	// created the output or -1 when it is unconfirmed.
+	Height int32
+}
+
+// unconfirmedDelta pairs a delta of an unconfirmed transaction with the address
+// it applies to along with the script of credited outputs.
+type unconfirmedDelta struct {
+	addrKey  [addrKeySize]byte
+	delta    AddrDelta
+	pkScript []byte
+}
+
+// addrDeltaKey returns the key of the delta identified by the passed values.
+func addrDeltaKey(addrKey [addrKeySize]byte, height int32, blockIndex uint32, dir byte, index uint32) []byte {
+	key := make([]byte, addrDeltaKeySize)
+	copy(key, addrKey[:])
+	binary.BigEndian.PutUint32(key[addrKeySize:], uint32(height))
+	binary.BigEndian.PutUint32(key[addrKeySize+4:], blockIndex)
+	key[addrKeySize+8] = dir
+	binary.BigEndian.PutUint32(key[addrKeySize+9:], index)
+	return key
+}
+
+// serializeAddrDelta serializes the passed delta values according to the format
+// described in detail above.  The previous outpoint must be nil for credits.
+func serializeAddrDelta(txHash *chainhash.Hash, amount int64, prevOut *wire.OutPoint) []byte {
+	size := addrCreditValueSize
+	if prevOut != nil {
+		size = addrDebitValueSize
+	}
+	serialized := make([]byte, size)
+	copy(serialized, txHash[:])
+	byteOrder.PutUint64(serialized[chainhash.HashSize:], uint64(amount))
+	if prevOut != nil {
+		copy(serialized[addrCreditValueSize:], prevOut.Hash[:])
+		byteOrder.PutUint32(serialized[addrDebitValueSize-4:],
+			prevOut.Index)
+	}
+	return serialized
+}
+
+// deserializeAddrDelta decodes the passed serialized key and value of a delta
+// into the provided delta.
+func deserializeAddrDelta(key, serialized []byte, delta *AddrDelta) error {
+	if len(key) != addrDeltaKeySize {
+		return errDeserialize("unexpected delta key length")
+	}
+	dir := key[addrKeySize+8]
+	switch {
+	case dir == addrDeltaDirOutput && len(serialized) != addrCreditValueSize:
+		return errDeserialize("unexpected credit length")
+	case dir == addrDeltaDirInput && len(serialized) != addrDebitValueSize:
+		return errDeserialize("unexpected debit length")
+	}
+
+	delta.Height = int32(binary.BigEndian.Uint32(key[addrKeySize:]))
+	delta.BlockIndex = binary.BigEndian.Uint32(key[addrKeySize+4:])
+	delta.Index = binary.BigEndian.Uint32(key[addrKeySize+9:])
+	copy(delta.TxHash[:], serialized[:chainhash.HashSize])
+	delta.Amount = int64(byteOrder.Uint64(serialized[chainhash.HashSize:]))
+	delta.PrevOut = nil
+	if dir == addrDeltaDirInput {
+		var prevOut wire.OutPoint
+		copy(prevOut.Hash[:], serialized[addrCreditValueSize:])
+		prevOut.Index = byteOrder.Uint32(serialized[addrDebitValueSize-4:])
+		delta.PrevOut = &prevOut
+	}
+	return nil
+}
+
+// outPointKey returns the key of the passed outpoint in the spent-by bucket.
+func outPointKey(outPoint *wire.OutPoint) []byte {
+	key := make([]byte, chainhash.HashSize+4)
+	copy(key, outPoint.Hash[:])
+	binary.BigEndian.PutUint32(key[chainhash.HashSize:], outPoint.Index)
+	return key
+}
+
+// addrUtxoKey returns the key of the passed outpoint paying to the passed
+// address in the unspent output bucket.
+func addrUtxoKey(addrKey [addrKeySize]byte, outPoint *wire.OutPoint) []byte {
+	key := make([]byte, addrKeySize+chainhash.HashSize+4)
+	copy(key, addrKey[:])
+	copy(key[addrKeySize:], outPointKey(outPoint))
+	return key
+}
+
+// serializeAddrUtxo serializes the passed unspent output values according to
+// the format described in detail above.
+func serializeAddrUtxo(height int32, amount int64, pkScript []byte) []byte {
+	serialized := make([]byte, 12+len(pkScript))
+	byteOrder.PutUint32(serialized, uint32(height))
+	byteOrder.PutUint64(serialized[4:], uint64(amount))
+	copy(serialized[12:], pkScript)
+	return serialized
+}
+
+// deserializeAddrUtxo decodes the passed serialized key and value of an unspent
+// output into the provided unspent output.
+func deserializeAddrUtxo(key, serialized []byte, utxo *AddrUtxo) error {
+	if len(key) != addrKeySize+chainhash.HashSize+4 {
+		return errDeserialize("unexpected utxo key length")
+	}
+	if len(serialized) < 12 {
+		return errDeserialize("unexpected end of data")
+	}
+
+	copy(utxo.OutPoint.Hash[:], key[addrKeySize:])
+	utxo.OutPoint.Index = binary.BigEndian.Uint32(key[addrKeySize+
+		chainhash.HashSize:])
+	utxo.Height = int32(byteOrder.Uint32(serialized))
+	utxo.Amount = int64(byteOrder.Uint64(serialized[4:]))
+	utxo.PkScript = make([]byte, len(serialized)-12)
+	copy(utxo.PkScript, serialized[12:])
+	return nil
+}
+
+// serializeAddrSpend serializes the passed spent-by link according to the
+// format described in detail above.
+func serializeAddrSpend(spend *AddrSpend) []byte {
+	serialized := make([]byte, addrSpendValueSize)
+	copy(serialized, spend.TxHash[:])
+	byteOrder.PutUint32(serialized[chainhash.HashSize:], spend.Index)
+	byteOrder.PutUint32(serialized[chainhash.HashSize+4:],
+		uint32(spend.Height))
+	return serialized
+}
+
+// deserializeAddrSpend decodes the passed serialized spent-by link.
+func deserializeAddrSpend(serialized []byte) (*AddrSpend, error) {
+	if len(serialized) != addrSpendValueSize {
+		return nil, errDeserialize("unexpected spent-by length")
+	}
+
+	var spend AddrSpend
+	copy(spend.TxHash[:], serialized)
+	spend.Index = byteOrder.Uint32(serialized[chainhash.HashSize:])
+	spend.Height = int32(byteOrder.Uint32(serialized[chainhash.HashSize+4:]))
+	return &spend, nil
+}
+
+// corruptionError converts deserialization errors encountered while loading
+// the address deltas, unspent outputs and spent-by links of the passed address
+// key into database corruption errors.
+func corruptionError(addrKey []byte, err error) error {
+	if isDeserializeErr(err) {
+		return database.Error{
+			ErrorCode: database.ErrCorruption,
+			Description: fmt.Sprintf("failed to deserialize "+
+				"address index entry for key %x: %v", addrKey,
+				err),
+		}
+	}
+	return err
+}
+
+// pkScriptAddrKeys returns the unique address keys of all supported addresses
+// the passed public key script pays to.
+func (idx *AddrIndex) pkScriptAddrKeys(pkScript []byte) [][addrKeySize]byte {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript,
+		idx.chainParams)
+	if err != nil || len(addrs) == 0 {
+		return nil
+	}
+
+	addrKeys := make([][addrKeySize]byte, 0, len(addrs))
+	for _, addr := range addrs {
+		addrKey, err := addrToKey(addr)
+		if err != nil {
+			// Ignore unsupported address types.
+			continue
+		}
+
+		// Multisig scripts may contain the same key more than once.
+		var dup bool
+		for i := range addrKeys {
+			if addrKeys[i] == addrKey {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			addrKeys = append(addrKeys, addrKey)
+		}
+	}
+	return addrKeys
+}
+
+// connectBlockDeltas adds the deltas, unspent outputs and spent-by links of all
+// transactions in the passed block to the address index.
+func (idx *AddrIndex) connectBlockDeltas(dbTx database.Tx, block *acmutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	addrIdxBucket := dbTx.Metadata().Bucket(addrIndexKey)
+	deltas := addrIdxBucket.Bucket(addrDeltasBucketName)
+	utxos := addrIdxBucket.Bucket(addrUtxosBucketName)
+	spentBy := addrIdxBucket.Bucket(addrSpentByBucketName)
+
+	// The transactions are processed in order so outputs which are created
+	// and spent in the same block end up removed from the unspent outputs.
+	height := block.Height()
+	stxoIndex := 0
+	for txIdx, tx := range block.Transactions() {
+		txHash := tx.Hash()
+		blockIndex := uint32(txIdx)
+
+		// Coinbases do not reference any inputs.
+		if txIdx != 0 {
+			for txInIdx, txIn := range tx.MsgTx().TxIn {
+				stxo := &stxos[stxoIndex]
+				stxoIndex++
+
+				prevOut := &txIn.PreviousOutPoint
+				spend := AddrSpend{
+					TxHash: *txHash,
+					Index:  uint32(txInIdx),
+					Height: height,
+				}
+				err := spentBy.Put(outPointKey(prevOut),
+					serializeAddrSpend(&spend))
+				if err != nil {
+					return err
+				}
+
+				for _, addrKey := range idx.pkScriptAddrKeys(stxo.PkScript) {
+					key := addrDeltaKey(addrKey, height,
+						blockIndex, addrDeltaDirInput,
+						uint32(txInIdx))
+					err := deltas.Put(key, serializeAddrDelta(
+						txHash, -stxo.Amount, prevOut))
+					if err != nil {
+						return err
+					}
+
+					err = utxos.Delete(addrUtxoKey(addrKey, prevOut))
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		for txOutIdx, txOut := range tx.MsgTx().TxOut {
+			outPoint := wire.OutPoint{
+				Hash:  *txHash,
+				Index: uint32(txOutIdx),
+			}
+			for _, addrKey := range idx.pkScriptAddrKeys(txOut.PkScript) {
+				key := addrDeltaKey(addrKey, height, blockIndex,
+					addrDeltaDirOutput, uint32(txOutIdx))
+				err := deltas.Put(key, serializeAddrDelta(txHash,
+					txOut.Value, nil))
+				if err != nil {
+					return err
+				}
+
+				err = utxos.Put(addrUtxoKey(addrKey, &outPoint),
+					serializeAddrUtxo(height, txOut.Value,
+						txOut.PkScript))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// disconnectBlockDeltas removes the deltas and spent-by links of all
+// transactions in the passed block from the address index and restores the
+// unspent outputs they spent.
+func (idx *AddrIndex) disconnectBlockDeltas(dbTx database.Tx, block *acmutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	addrIdxBucket := dbTx.Metadata().Bucket(addrIndexKey)
+	deltas := addrIdxBucket.Bucket(addrDeltasBucketName)
+	utxos := addrIdxBucket.Bucket(addrUtxosBucketName)
+	spentBy := addrIdxBucket.Bucket(addrSpentByBucketName)
+
+	// The transactions are processed in reverse order so outputs which are
+	// created and spent in the same block end up removed from the unspent
+	// outputs.
+	height := block.Height()
+	stxoIndex := len(stxos)
+	transactions := block.Transactions()
+	for txIdx := len(transactions) - 1; txIdx >= 0; txIdx-- {
+		tx := transactions[txIdx]
+		txHash := tx.Hash()
+		blockIndex := uint32(txIdx)
+
+		for txOutIdx, txOut := range tx.MsgTx().TxOut {
+			outPoint := wire.OutPoint{
+				Hash:  *txHash,
+				Index: uint32(txOutIdx),
+			}
+			for _, addrKey := range idx.pkScriptAddrKeys(txOut.PkScript) {
+				key := addrDeltaKey(addrKey, height, blockIndex,
+					addrDeltaDirOutput, uint32(txOutIdx))
+				if err := deltas.Delete(key); err != nil {
+					return err
+				}
+
+				err := utxos.Delete(addrUtxoKey(addrKey, &outPoint))
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Coinbases do not reference any inputs.
+		if txIdx == 0 {
+			continue
+		}
+		txIns := tx.MsgTx().TxIn
+		stxoIndex -= len(txIns)
+		for txInIdx, txIn := range txIns {
+			stxo := &stxos[stxoIndex+txInIdx]
+			prevOut := &txIn.PreviousOutPoint
+			if err := spentBy.Delete(outPointKey(prevOut)); err != nil {
+				return err
+			}
+
+			for _, addrKey := range idx.pkScriptAddrKeys(stxo.PkScript) {
+				key := addrDeltaKey(addrKey, height, blockIndex,
+					addrDeltaDirInput, uint32(txInIdx))
+				if err := deltas.Delete(key); err != nil {
+					return err
+				}
+
+				err := utxos.Put(addrUtxoKey(addrKey, prevOut),
+					serializeAddrUtxo(stxo.Height, stxo.Amount,
+						stxo.PkScript))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeltasForAddress returns all changes to the balance of the passed address
+// made by transactions in blocks with a height between the passed start and
+// end heights, inclusive.  The deltas are ordered according to their order of
+// appearance in the blockchain.  Credits which have been spent in the main
+// chain include the input spending them.
+//
+// NOTE: These results only include transactions confirmed in blocks.  See the
+// UnconfirmedDeltasForAddress method for obtaining the deltas of unconfirmed
+// transactions.
+//
+// This function is safe for concurrent access.
+func (idx *AddrIndex) DeltasForAddress(addr acmutil.Address, startHeight, endHeight int32) ([]AddrDelta, error) {
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	if startHeight < 0 {
+		startHeight = 0
+	}
+
+	var deltas []AddrDelta
+	err = idx.db.View(func(dbTx database.Tx) error {
+		addrIdxBucket := dbTx.Metadata().Bucket(addrIndexKey)
+		spentBy := addrIdxBucket.Bucket(addrSpentByBucketName)
+		cursor := addrIdxBucket.Bucket(addrDeltasBucketName).Cursor()
+		seekKey := addrDeltaKey(addrKey, startHeight, 0, 0, 0)
+		for ok := cursor.Seek(seekKey); ok; ok = cursor.Next() {
+			key := cursor.Key()
+			if !bytes.HasPrefix(key, addrKey[:]) {
+				break
+			}
+
+			var delta AddrDelta
+			err := deserializeAddrDelta(key, cursor.Value(), &delta)
+			if err != nil {
+				return corruptionError(addrKey[:], err)
+			}
+			if delta.Height > endHeight {
+				break
+			}
+
+			// Link credits to the input spending them.
+			if delta.PrevOut == nil {
+				outPoint := wire.OutPoint{
+					Hash:  delta.TxHash,
+					Index: delta.Index,
+				}
+				serialized := spentBy.Get(outPointKey(&outPoint))
+				if serialized != nil {
+					spend, err := deserializeAddrSpend(serialized)
+					if err != nil {
+						return corruptionError(addrKey[:], err)
+					}
+					delta.SpentBy = spend
+				}
+			}
+
+			deltas = append(deltas, delta)
+		}
+		return nil
+	})
+
+	return deltas, err
+}
+
+// UtxosForAddress returns all unspent outputs which pay to the passed address
+// ordered by the height of the block containing them.  When the unconfirmed
+// flag is set, outputs created by transactions in the unconfirmed
+// (memory-only) address index are included and outputs they spend are
+// excluded.
+//
+// This function is safe for concurrent access.
+func (idx *AddrIndex) UtxosForAddress(addr acmutil.Address, unconfirmed bool) ([]AddrUtxo, error) {
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var utxos []AddrUtxo
+	err = idx.db.View(func(dbTx database.Tx) error {
+		addrIdxBucket := dbTx.Metadata().Bucket(addrIndexKey)
+		cursor := addrIdxBucket.Bucket(addrUtxosBucketName).Cursor()
+		for ok := cursor.Seek(addrKey[:]); ok; ok = cursor.Next() {
+			key := cursor.Key()
+			if !bytes.HasPrefix(key, addrKey[:]) {
+				break
+			}
+
+			var utxo AddrUtxo
+			err := deserializeAddrUtxo(key, cursor.Value(), &utxo)
+			if err != nil {
+				return corruptionError(addrKey[:], err)
+			}
+			utxos = append(utxos, utxo)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(utxos, func(i, j int) bool {
+		return utxos[i].Height < utxos[j].Height
+	})
+
+	if !unconfirmed {
+		return utxos, nil
+	}
+
+	// Apply the deltas of the unconfirmed transactions.
+	spent := make(map[wire.OutPoint]struct{})
+	var unconfirmedUtxos []AddrUtxo
+	idx.unconfirmedLock.RLock()
+	for txHash := range idx.txnsByAddr[addrKey] {
+		for _, entry := range idx.deltasByTx[txHash] {
+			if entry.addrKey != addrKey {
+				continue
+			}
+			if entry.delta.PrevOut != nil {
+				spent[*entry.delta.PrevOut] = struct{}{}
+				continue
+			}
+			unconfirmedUtxos = append(unconfirmedUtxos, AddrUtxo{
+				OutPoint: wire.OutPoint{
+					Hash:  entry.delta.TxHash,
+					Index: entry.delta.Index,
+				},
+				Amount:   entry.delta.Amount,
+				PkScript: entry.pkScript,
+				Height:   unconfirmedHeight,
+			})
+		}
+	}
+	idx.unconfirmedLock.RUnlock()
+
+	result := make([]AddrUtxo, 0, len(utxos)+len(unconfirmedUtxos))
+	for _, utxo := range append(utxos, unconfirmedUtxos...) {
+		if _, ok := spent[utxo.OutPoint]; !ok {
+			result = append(result, utxo)
+		}
+	}
+	return result, nil
+}
+
+// indexUnconfirmedDeltas records the deltas the passed unconfirmed transaction
+// makes to the balances of the addresses its inputs spend from and its outputs
+// pay to.
+//
+// This function MUST be called with the unconfirmed lock held (for writes).
+func (idx *AddrIndex) indexUnconfirmedDeltas(tx *acmutil.Tx, utxoView *blockchain.UtxoViewpoint) {
+	var deltas []unconfirmedDelta
+	for txInIdx, txIn := range tx.MsgTx().TxIn {
+		entry := utxoView.LookupEntry(txIn.PreviousOutPoint)
+		if entry == nil {
+			continue
+		}
+		prevOut := txIn.PreviousOutPoint
+		for _, addrKey := range idx.pkScriptAddrKeys(entry.PkScript()) {
+			deltas = append(deltas, unconfirmedDelta{
+				addrKey: addrKey,
+				delta: AddrDelta{
+					TxHash:  *tx.Hash(),
+					Index:   uint32(txInIdx),
+					Amount:  -entry.Amount(),
+					Height:  unconfirmedHeight,
+					PrevOut: &prevOut,
+				},
+			})
+		}
+	}
+	for txOutIdx, txOut := range tx.MsgTx().TxOut {
+		for _, addrKey := range idx.pkScriptAddrKeys(txOut.PkScript) {
+			deltas = append(deltas, unconfirmedDelta{
+				addrKey: addrKey,
+				delta: AddrDelta{
+					TxHash: *tx.Hash(),
+					Index:  uint32(txOutIdx),
+					Amount: txOut.Value,
+					Height: unconfirmedHeight,
+				},
+				pkScript: txOut.PkScript,
+			})
+		}
+	}
+	idx.deltasByTx[*tx.Hash()] = deltas
+}
+
+// UnconfirmedDeltasForAddress returns the changes to the balance of the passed
+// address made by transactions currently in the unconfirmed (memory-only)
+// address index.  Unsupported address types are ignored and will result in no
+// results.
+//
+// This function is safe for concurrent access.
+func (idx *AddrIndex) UnconfirmedDeltasForAddress(addr acmutil.Address) []AddrDelta {
+	// Ignore unsupported address types.
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		return nil
+	}
+
+	idx.unconfirmedLock.RLock()
+	defer idx.unconfirmedLock.RUnlock()
+
+	var deltas []AddrDelta
+	for txHash := range idx.txnsByAddr[addrKey] {
+		for _, entry := range idx.deltasByTx[txHash] {
+			if entry.addrKey == addrKey {
+				deltas = append(deltas, entry.delta)
+			}
+		}
+	}
+	return deltas
+}
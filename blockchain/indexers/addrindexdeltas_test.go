@@ -0,0 +1,121 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/wire"
+)
+
+// TestAddrDeltaSerialization ensures address deltas, unspent outputs and
+// spent-by links round trip through their serialized forms and that delta keys
+// sort in the order the deltas appear in the blockchain.
+func TestAddrDeltaSerialization(t *testing.T) {
+	t.Parallel()
+
+	addrKey := [addrKeySize]byte{addrKeyTypeScriptHash, 0x01, 0x02}
+	txHash := chainhash.Hash{0x03}
+	prevOut := wire.OutPoint{Hash: chainhash.Hash{0x04}, Index: 5}
+
+	tests := []struct {
+		name  string
+		key   []byte
+		delta AddrDelta
+	}{
+		{
+			name: "credit",
+			key:  addrDeltaKey(addrKey, 100, 2, addrDeltaDirOutput, 1),
+			delta: AddrDelta{
+				TxHash:     txHash,
+				Index:      1,
+				Amount:     5000000000,
+				Height:     100,
+				BlockIndex: 2,
+			},
+		},
+		{
+			name: "debit",
+			key:  addrDeltaKey(addrKey, 100, 2, addrDeltaDirInput, 3),
+			delta: AddrDelta{
+				TxHash:     txHash,
+				Index:      3,
+				Amount:     -5000000000,
+				Height:     100,
+				BlockIndex: 2,
+				PrevOut:    &prevOut,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		serialized := serializeAddrDelta(&test.delta.TxHash,
+			test.delta.Amount, test.delta.PrevOut)
+		var delta AddrDelta
+		err := deserializeAddrDelta(test.key, serialized, &delta)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(delta, test.delta) {
+			t.Errorf("%s: mismatched delta - got %+v, want %+v",
+				test.name, delta, test.delta)
+		}
+
+		// Truncated values must be rejected.
+		err = deserializeAddrDelta(test.key, serialized[:len(serialized)-1],
+			&delta)
+		if !isDeserializeErr(err) {
+			t.Errorf("%s: unexpected error for truncated value: %v",
+				test.name, err)
+		}
+	}
+
+	// Inputs must sort before outputs of the same transaction, which must
+	// sort before later transactions and blocks.
+	keys := [][]byte{
+		addrDeltaKey(addrKey, 1, 0, addrDeltaDirOutput, 0),
+		addrDeltaKey(addrKey, 255, 1, addrDeltaDirInput, 1),
+		addrDeltaKey(addrKey, 255, 1, addrDeltaDirOutput, 0),
+		addrDeltaKey(addrKey, 255, 2, addrDeltaDirInput, 0),
+		addrDeltaKey(addrKey, 256, 0, addrDeltaDirOutput, 0),
+	}
+	for i := 1; i < len(keys); i++ {
+		if bytes.Compare(keys[i-1], keys[i]) >= 0 {
+			t.Errorf("delta key %d does not sort before key %d", i-1, i)
+		}
+	}
+
+	utxo := AddrUtxo{
+		OutPoint: prevOut,
+		Amount:   1234,
+		PkScript: []byte{0xa9, 0x14, 0x01, 0x87},
+		Height:   200,
+	}
+	var gotUtxo AddrUtxo
+	err := deserializeAddrUtxo(addrUtxoKey(addrKey, &utxo.OutPoint),
+		serializeAddrUtxo(utxo.Height, utxo.Amount, utxo.PkScript),
+		&gotUtxo)
+	if err != nil {
+		t.Fatalf("deserializeAddrUtxo: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(gotUtxo, utxo) {
+		t.Errorf("deserializeAddrUtxo: mismatched utxo - got %+v, "+
+			"want %+v", gotUtxo, utxo)
+	}
+
+	spend := AddrSpend{TxHash: txHash, Index: 7, Height: 300}
+	gotSpend, err := deserializeAddrSpend(serializeAddrSpend(&spend))
+	if err != nil {
+		t.Fatalf("deserializeAddrSpend: unexpected error: %v", err)
+	}
+	if *gotSpend != spend {
+		t.Errorf("deserializeAddrSpend: mismatched spend - got %+v, "+
+			"want %+v", *gotSpend, spend)
+	}
+}
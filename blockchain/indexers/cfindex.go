@@ -6,6 +6,7 @@ package indexers
 
 import (
 	"errors"
+	"sync/atomic"
 
 	"github.com/Actinium-project/acmd/blockchain"
 	"github.com/Actinium-project/acmd/chaincfg"
@@ -25,6 +26,10 @@ const (
 // Committed filters come in one flavor currently: basic. They are generated
 // and dropped in pairs, and both are indexed by a block's hash.  Besides
 // holding different content, they also live in different buckets.
+//
+// A second slot is reserved for an extended filter type covering witness
+// scripts.  Its buckets are created along with those of the basic filter, so
+// it can be populated later without having to drop the existing index.
 var (
 	// cfIndexParentBucketKey is the name of the parent bucket used to
 	// house the index. The rest of the buckets live below this bucket.
@@ -34,21 +39,27 @@ var (
 	// block hashes to cfilters.
 	cfIndexKeys = [][]byte{
 		[]byte("cf0byhashidx"),
+		[]byte("cf1byhashidx"),
 	}
 
 	// cfHeaderKeys is an array of db bucket names used to house indexes of
 	// block hashes to cf headers.
 	cfHeaderKeys = [][]byte{
 		[]byte("cf0headerbyhashidx"),
+		[]byte("cf1headerbyhashidx"),
 	}
 
 	// cfHashKeys is an array of db bucket names used to house indexes of
 	// block hashes to cf hashes.
 	cfHashKeys = [][]byte{
 		[]byte("cf0hashbyhashidx"),
+		[]byte("cf1hashbyhashidx"),
 	}
 
-	maxFilterType = uint8(len(cfHeaderKeys) - 1)
+	// maxFilterType is the highest filter type which is built by the
+	// index.  Filter types above it have reserved buckets, but are not
+	// built or served yet.
+	maxFilterType = uint8(wire.GCSFilterRegular)
 
 	// zeroHash is the chainhash.Hash value of all zero bytes, defined here
 	// for convenience.
@@ -76,6 +87,8 @@ func dbDeleteFilterIdxEntry(dbTx database.Tx, key []byte, h *chainhash.Hash) err
 
 // CfIndex implements a committed filter (cf) by hash index.
 type CfIndex struct {
+	caughtUp int32 // atomic
+
 	db          database.DB
 	chainParams *chaincfg.Params
 }
@@ -86,6 +99,9 @@ var _ Indexer = (*CfIndex)(nil)
 // Ensure the CfIndex type implements the NeedsInputser interface.
 var _ NeedsInputser = (*CfIndex)(nil)
 
+// Ensure the CfIndex type implements the BackgroundIndexer interface.
+var _ BackgroundIndexer = (*CfIndex)(nil)
+
 // NeedsInputs signals that the index requires the referenced inputs in order
 // to properly create the index.
 //
@@ -94,10 +110,47 @@ func (idx *CfIndex) NeedsInputs() bool {
 	return true
 }
 
-// Init initializes the hash-based cf index. This is part of the Indexer
-// interface.
+// IndexInBackground signals that the index is caught up to the main chain in
+// the background, so the node does not have to wait for the filters of all
+// blocks to be built when the index is first enabled.
+//
+// This implements the BackgroundIndexer interface.
+func (idx *CfIndex) IndexInBackground() bool {
+	return true
+}
+
+// CaughtUp marks the index as caught up to the main chain.
+//
+// This implements the BackgroundIndexer interface.
+func (idx *CfIndex) CaughtUp() {
+	atomic.StoreInt32(&idx.caughtUp, 1)
+}
+
+// IsCaughtUp returns whether or not the index has caught up to the main chain.
+// Filters must not be served to peers before then since they are missing for
+// the blocks that haven't been indexed yet.
+//
+// This function is safe for concurrent access.
+func (idx *CfIndex) IsCaughtUp() bool {
+	return atomic.LoadInt32(&idx.caughtUp) == 1
+}
+
+// Init initializes the hash-based cf index.  It creates any buckets for filter
+// types which were added after the index was created.  This is part of the
+// Indexer interface.
 func (idx *CfIndex) Init() error {
-	return nil // Nothing to do.
+	return idx.db.Update(func(dbTx database.Tx) error {
+		parent := dbTx.Metadata().Bucket(cfIndexParentBucketKey)
+		for _, keys := range [][][]byte{cfIndexKeys, cfHeaderKeys, cfHashKeys} {
+			for _, bucketName := range keys {
+				_, err := parent.CreateBucketIfNotExists(bucketName)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
 }
 
 // Key returns the database key to use for the index as a byte slice. This is
@@ -340,6 +393,20 @@ func (idx *CfIndex) FilterHashesByBlockHashes(blockHashes []*chainhash.Hash,
 	return idx.entriesByBlockHashes(cfHashKeys, filterType, blockHashes)
 }
 
+// Tip returns the hash and height of the most recent block which has been
+// indexed.  The height is -1 when no blocks have been indexed yet.  It can be
+// used to track the progress of catching up the index.
+func (idx *CfIndex) Tip() (*chainhash.Hash, int32, error) {
+	var hash *chainhash.Hash
+	var height int32
+	err := idx.db.View(func(dbTx database.Tx) error {
+		var err error
+		hash, height, err = dbFetchIndexerTip(dbTx, idx.Key())
+		return err
+	})
+	return hash, height, err
+}
+
 // NewCfIndex returns a new instance of an indexer that is used to create a
 // mapping of the hashes of all blocks in the blockchain to their respective
 // committed filters.
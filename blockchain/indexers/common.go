@@ -32,6 +32,27 @@ type NeedsInputser interface {
 	NeedsInputs() bool
 }
 
+// BackgroundIndexer provides a generic interface for an indexer to specify that
+// it is caught up to the main chain in the background once the node is running
+// rather than during initialization.
+type BackgroundIndexer interface {
+	IndexInBackground() bool
+
+	// CaughtUp is invoked once the index has caught up to the main chain
+	// and is kept up to date along with the other indexes from then on.
+	CaughtUp()
+}
+
+// VersionedIndexer provides a generic interface for an indexer to specify the
+// version of its database layout.  An existing index with an older version is
+// dropped and rebuilt by the index manager since the entries of the blocks that
+// are already indexed would not match the current layout.
+type VersionedIndexer interface {
+	// Version returns the current version of the index.  Indexes created
+	// before versions were recorded have version 1.
+	Version() uint32
+}
+
 // Indexer provides a generic interface for an indexer that is managed by an
 // index manager such as the Manager type provided by this package.
 type Indexer interface {
@@ -7,6 +7,7 @@ package indexers
 import (
 	"bytes"
 	"fmt"
+	"sync"
 
 	"github.com/Actinium-project/acmd/blockchain"
 	"github.com/Actinium-project/acmd/chaincfg/chainhash"
@@ -132,6 +133,12 @@ func dbIndexDisconnectBlock(dbTx database.Tx, indexer Indexer, block *acmutil.Bl
 type Manager struct {
 	db             database.DB
 	enabledIndexes []Indexer
+
+	// The following fields are used to catch up indexes which are indexed
+	// in the background.
+	chain *blockchain.BlockChain
+	quit  chan struct{}
+	wg    sync.WaitGroup
 }
 
 // Ensure the Manager type implements the blockchain.IndexManager interface.
@@ -196,6 +203,85 @@ func (m *Manager) maybeFinishDrops(interrupt <-chan struct{}) error {
 	return nil
 }
 
+// indexVersionKey returns the key for the version of an index.
+func indexVersionKey(idxKey []byte) []byte {
+	versionKey := make([]byte, len(idxKey)+1)
+	versionKey[0] = 'v'
+	copy(versionKey[1:], idxKey)
+	return versionKey
+}
+
+// dbFetchIndexVersion uses an existing database transaction to retrieve the
+// version of the index with the passed key.  Indexes created before versions
+// were recorded have version 1.
+func dbFetchIndexVersion(dbTx database.Tx, idxKey []byte) uint32 {
+	indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+	serialized := indexesBucket.Get(indexVersionKey(idxKey))
+	if len(serialized) < 4 {
+		return 1
+	}
+	return byteOrder.Uint32(serialized)
+}
+
+// dbPutIndexVersion uses an existing database transaction to store the version
+// of the index with the passed key.
+func dbPutIndexVersion(dbTx database.Tx, idxKey []byte, version uint32) error {
+	serialized := make([]byte, 4)
+	byteOrder.PutUint32(serialized, version)
+	indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+	return indexesBucket.Put(indexVersionKey(idxKey), serialized)
+}
+
+// maybeUpgradeIndexes determines if each of the enabled indexes was created by
+// an older version with a different database layout and drops them when they
+// were, so they are rebuilt from scratch.
+func (m *Manager) maybeUpgradeIndexes(interrupt <-chan struct{}) error {
+	indexNeedsRebuild := make([]bool, len(m.enabledIndexes))
+	err := m.db.View(func(dbTx database.Tx) error {
+		// None of the indexes needs to be rebuilt if the index tips
+		// bucket hasn't been created yet.
+		indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+		if indexesBucket == nil {
+			return nil
+		}
+
+		for i, indexer := range m.enabledIndexes {
+			versioned, ok := indexer.(VersionedIndexer)
+			if !ok || indexesBucket.Get(indexer.Key()) == nil {
+				continue
+			}
+			version := dbFetchIndexVersion(dbTx, indexer.Key())
+			if version < versioned.Version() {
+				indexNeedsRebuild[i] = true
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, indexer := range m.enabledIndexes {
+		if !indexNeedsRebuild[i] {
+			continue
+		}
+
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		log.Infof("The %s was created by an older version and needs to "+
+			"be rebuilt", indexer.Name())
+		err := dropIndex(m.db, indexer.Key(), indexer.Name(), interrupt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // maybeCreateIndexes determines if each of the enabled indexes have already
 // been created and creates them if not.
 func (m *Manager) maybeCreateIndexes(dbTx database.Tx) error {
@@ -220,8 +306,119 @@ func (m *Manager) maybeCreateIndexes(dbTx database.Tx) error {
 		if err != nil {
 			return err
 		}
+
+		// Record the version of the index layout it was created with.
+		if versioned, ok := indexer.(VersionedIndexer); ok {
+			err := dbPutIndexVersion(dbTx, idxKey, versioned.Version())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// rollbackOrphanedTip disconnects blocks from the passed index until its tip is
+// a block in the main chain.
+//
+// Whether the tip is in the main chain is checked again while holding the chain
+// lock before each block is disconnected, so an index which is kept up to date
+// along with the other indexes can't have a block disconnected twice due to a
+// concurrent reorganization.
+func (m *Manager) rollbackOrphanedTip(chain *blockchain.BlockChain, indexer Indexer,
+	interrupt <-chan struct{}) error {
+
+	var initialHeight, height int32
+	var removed bool
+	for {
+		// Fetch the current tip for the index.
+		var hash *chainhash.Hash
+		err := m.db.View(func(dbTx database.Tx) error {
+			var err error
+			hash, height, err = dbFetchIndexerTip(dbTx, indexer.Key())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !removed {
+			initialHeight = height
+		}
+
+		// Nothing to do if the index does not have any entries yet or
+		// the tip is a block that exists in the main chain.
+		if height == -1 || chain.MainChainHasBlock(hash) {
+			break
+		}
+
+		// At this point the index tip is orphaned, so load the
+		// orphaned block from the database directly and disconnect it
+		// from the index.  The block has to be loaded directly since it
+		// is no longer in the main chain and thus the chain.BlockByHash
+		// function would error.
+		var block *acmutil.Block
+		err = m.db.View(func(dbTx database.Tx) error {
+			blockBytes, err := dbTx.FetchBlock(hash)
+			if err != nil {
+				return err
+			}
+			block, err = acmutil.NewBlockFromBytes(blockBytes)
+			if err != nil {
+				return err
+			}
+			block.SetHeight(height)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		// We'll also grab the set of outputs spent by this block so we
+		// can remove them from the index.
+		spentTxos, err := chain.FetchSpendJournal(block)
+		if err != nil {
+			return err
+		}
+
+		// With the block and stxo set for that block retrieved, we can
+		// now update the index itself unless the tip changed or the
+		// block was connected to the main chain again in the meantime,
+		// in which case the tip is checked again.
+		err = chain.WithChainLock(func() error {
+			return m.db.Update(func(dbTx database.Tx) error {
+				curTipHash, _, err := dbFetchIndexerTip(dbTx,
+					indexer.Key())
+				if err != nil {
+					return err
+				}
+				if !curTipHash.IsEqual(hash) ||
+					chain.MainChainHasBlock(hash) {
+
+					return nil
+				}
+
+				// Remove all of the index entries associated
+				// with the block and update the indexer tip.
+				removed = true
+				return dbIndexDisconnectBlock(dbTx, indexer,
+					block, spentTxos)
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
 	}
 
+	if removed && initialHeight > height {
+		log.Infof("Removed %d orphaned blocks from %s "+
+			"(heights %d to %d)", initialHeight-height,
+			indexer.Name(), height+1, initialHeight)
+	}
 	return nil
 }
 
@@ -238,6 +435,7 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 	if len(m.enabledIndexes) == 0 {
 		return nil
 	}
+	m.chain = chain
 
 	if interruptRequested(interrupt) {
 		return errInterruptRequested
@@ -248,6 +446,12 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 		return err
 	}
 
+	// Drop the indexes that were created by an older version so they are
+	// rebuilt below.
+	if err := m.maybeUpgradeIndexes(interrupt); err != nil {
+		return err
+	}
+
 	// Create the initial state for the indexes as needed.
 	err := m.db.Update(func(dbTx database.Tx) error {
 		// Create the bucket for the current tips as needed.
@@ -276,89 +480,10 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 	// reverse order because later indexes can depend on earlier ones.
 	for i := len(m.enabledIndexes); i > 0; i-- {
 		indexer := m.enabledIndexes[i-1]
-
-		// Fetch the current tip for the index.
-		var height int32
-		var hash *chainhash.Hash
-		err := m.db.View(func(dbTx database.Tx) error {
-			idxKey := indexer.Key()
-			hash, height, err = dbFetchIndexerTip(dbTx, idxKey)
-			return err
-		})
+		err := m.rollbackOrphanedTip(chain, indexer, interrupt)
 		if err != nil {
 			return err
 		}
-
-		// Nothing to do if the index does not have any entries yet.
-		if height == -1 {
-			continue
-		}
-
-		// Loop until the tip is a block that exists in the main chain.
-		initialHeight := height
-		for !chain.MainChainHasBlock(hash) {
-			// At this point the index tip is orphaned, so load the
-			// orphaned block from the database directly and
-			// disconnect it from the index.  The block has to be
-			// loaded directly since it is no longer in the main
-			// chain and thus the chain.BlockByHash function would
-			// error.
-			var block *acmutil.Block
-			err := m.db.View(func(dbTx database.Tx) error {
-				blockBytes, err := dbTx.FetchBlock(hash)
-				if err != nil {
-					return err
-				}
-				block, err = acmutil.NewBlockFromBytes(blockBytes)
-				if err != nil {
-					return err
-				}
-				block.SetHeight(height)
-				return err
-			})
-			if err != nil {
-				return err
-			}
-
-			// We'll also grab the set of outputs spent by this
-			// block so we can remove them from the index.
-			spentTxos, err := chain.FetchSpendJournal(block)
-			if err != nil {
-				return err
-			}
-
-			// With the block and stxo set for that block retrieved,
-			// we can now update the index itself.
-			err = m.db.Update(func(dbTx database.Tx) error {
-				// Remove all of the index entries associated
-				// with the block and update the indexer tip.
-				err = dbIndexDisconnectBlock(
-					dbTx, indexer, block, spentTxos,
-				)
-				if err != nil {
-					return err
-				}
-
-				// Update the tip to the previous block.
-				hash = &block.MsgBlock().Header.PrevBlock
-				height--
-
-				return nil
-			})
-			if err != nil {
-				return err
-			}
-
-			if interruptRequested(interrupt) {
-				return errInterruptRequested
-			}
-		}
-
-		if initialHeight != height {
-			log.Infof("Removed %d orphaned blocks from %s "+
-				"(heights %d to %d)", initialHeight-height,
-				indexer.Name(), height+1, initialHeight)
-		}
 	}
 
 	// Fetch the current tip heights for each index along with tracking the
@@ -378,6 +503,20 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 
 			log.Debugf("Current %s tip (height %d, hash %v)",
 				indexer.Name(), height, hash)
+
+			// Indexes which are caught up in the background are
+			// skipped here.
+			if indexInBackground(indexer) {
+				if height < bestHeight {
+					log.Infof("Catching up %s from height "+
+						"%d to %d in the background",
+						indexer.Name(), height,
+						bestHeight)
+				}
+				indexerHeights[i] = bestHeight
+				continue
+			}
+
 			indexerHeights[i] = height
 			if height < lowestHeight {
 				lowestHeight = height
@@ -456,6 +595,16 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 	return nil
 }
 
+// indexInBackground returns whether or not the index is caught up to the main
+// chain in the background.
+func indexInBackground(index Indexer) bool {
+	if idx, ok := index.(BackgroundIndexer); ok {
+		return idx.IndexInBackground()
+	}
+
+	return false
+}
+
 // indexNeedsInputs returns whether or not the index needs access to the txouts
 // referenced by the transaction inputs being indexed.
 func indexNeedsInputs(index Indexer) bool {
@@ -503,8 +652,20 @@ func (m *Manager) ConnectBlock(dbTx database.Tx, block *acmutil.Block,
 	stxos []blockchain.SpentTxOut) error {
 
 	// Call each of the currently active optional indexes with the block
-	// being connected so they can update accordingly.
+	// being connected so they can update accordingly.  Indexes which are
+	// still being caught up in the background are skipped since they will
+	// index the block once they reach it.
 	for _, index := range m.enabledIndexes {
+		if indexInBackground(index) {
+			tipHash, _, err := dbFetchIndexerTip(dbTx, index.Key())
+			if err != nil {
+				return err
+			}
+			if !tipHash.IsEqual(&block.MsgBlock().Header.PrevBlock) {
+				continue
+			}
+		}
+
 		err := dbIndexConnectBlock(dbTx, index, block, stxos)
 		if err != nil {
 			return err
@@ -523,8 +684,20 @@ func (m *Manager) DisconnectBlock(dbTx database.Tx, block *acmutil.Block,
 	stxo []blockchain.SpentTxOut) error {
 
 	// Call each of the currently active optional indexes with the block
-	// being disconnected so they can update accordingly.
+	// being disconnected so they can update accordingly.  Indexes which are
+	// still being caught up in the background and have not indexed the
+	// block yet are skipped.
 	for _, index := range m.enabledIndexes {
+		if indexInBackground(index) {
+			tipHash, _, err := dbFetchIndexerTip(dbTx, index.Key())
+			if err != nil {
+				return err
+			}
+			if !tipHash.IsEqual(block.Hash()) {
+				continue
+			}
+		}
+
 		err := dbIndexDisconnectBlock(dbTx, index, block, stxo)
 		if err != nil {
 			return err
@@ -541,6 +714,151 @@ func NewManager(db database.DB, enabledIndexes []Indexer) *Manager {
 	return &Manager{
 		db:             db,
 		enabledIndexes: enabledIndexes,
+		quit:           make(chan struct{}),
+	}
+}
+
+// Start begins catching up the indexes which are indexed in the background to
+// the main chain.  It must only be called after the manager has been
+// initialized.
+func (m *Manager) Start() {
+	if m.chain == nil {
+		return
+	}
+
+	for _, indexer := range m.enabledIndexes {
+		if !indexInBackground(indexer) {
+			continue
+		}
+
+		m.wg.Add(1)
+		go func(indexer Indexer) {
+			defer m.wg.Done()
+
+			err := m.catchUp(indexer)
+			if err != nil && err != errInterruptRequested {
+				log.Errorf("Unable to catch up %s: %v",
+					indexer.Name(), err)
+			}
+		}(indexer)
+	}
+}
+
+// Stop stops catching up indexes in the background and waits for it to finish.
+func (m *Manager) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// catchUp indexes the blocks of the main chain the passed index is missing one
+// at a time until it reaches the best chain tip, after which the index is kept
+// up to date along with the other indexes as blocks are connected.  The tip of
+// the index is stored with every block, so catching up resumes where it left
+// off after a restart.
+//
+// The index tip is only compared against the main chain and updated while
+// holding the chain lock, since the chain only updates its best state after the
+// index manager is invoked with a connected or disconnected block.
+func (m *Manager) catchUp(indexer Indexer) error {
+	progressLogger := newBlockProgressLogger("Indexed", log)
+	for {
+		if interruptRequested(m.quit) {
+			return errInterruptRequested
+		}
+
+		// Disconnect any blocks the index has which are no longer in
+		// the main chain due to a reorganization.
+		err := m.rollbackOrphanedTip(m.chain, indexer, m.quit)
+		if err != nil {
+			return err
+		}
+
+		var tipHash *chainhash.Hash
+		var tipHeight int32
+		err = m.db.View(func(dbTx database.Tx) error {
+			var err error
+			tipHash, tipHeight, err = dbFetchIndexerTip(dbTx,
+				indexer.Key())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		// The next block is loaded without holding the chain lock since
+		// loading it and the outputs it spends can take a while, so it
+		// might be stale by the time it is indexed.
+		var block *acmutil.Block
+		var spentTxos []blockchain.SpentTxOut
+		if tipHeight < m.chain.BestSnapshot().Height {
+			block, err = m.chain.BlockByHeight(tipHeight + 1)
+			if err != nil {
+				// The main chain might have been reorganized
+				// to a shorter one in the meantime.
+				if m.chain.BestSnapshot().Height <= tipHeight {
+					continue
+				}
+				return err
+			}
+			if indexNeedsInputs(indexer) {
+				spentTxos, err = m.chain.FetchSpendJournal(block)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// The index is kept up to date along with the other indexes
+		// once it reaches the best chain tip.  Otherwise, the block is
+		// indexed unless the tip of the index changed or the block was
+		// disconnected from the main chain in the meantime, in which
+		// case the tip is checked again.
+		var caughtUp, connected bool
+		err = m.chain.WithChainLock(func() error {
+			return m.db.Update(func(dbTx database.Tx) error {
+				curTipHash, curTipHeight, err :=
+					dbFetchIndexerTip(dbTx, indexer.Key())
+				if err != nil {
+					return err
+				}
+				if !curTipHash.IsEqual(tipHash) || (curTipHeight != -1 &&
+					!m.chain.MainChainHasBlock(curTipHash)) {
+
+					return nil
+				}
+				bestHeight := m.chain.BestSnapshot().Height
+				if curTipHeight >= bestHeight {
+					caughtUp = true
+					return nil
+				}
+
+				if block == nil {
+					return nil
+				}
+				prevHash := &block.MsgBlock().Header.PrevBlock
+				if !curTipHash.IsEqual(prevHash) ||
+					!m.chain.MainChainHasBlock(block.Hash()) {
+
+					return nil
+				}
+
+				connected = true
+				return dbIndexConnectBlock(dbTx, indexer, block,
+					spentTxos)
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if caughtUp {
+			indexer.(BackgroundIndexer).CaughtUp()
+			log.Infof("Caught up %s to height %d", indexer.Name(),
+				tipHeight)
+			return nil
+		}
+		if connected {
+			progressLogger.LogBlockHeight(block)
+		}
 	}
 }
 
@@ -677,14 +995,18 @@ func dropIndex(db database.DB, idxKey []byte, idxName string, interrupt <-chan s
 		}
 	}
 
-	// Remove the index tip, index bucket, and in-progress drop flag now
-	// that all index entries have been removed.
+	// Remove the index tip, version, index bucket, and in-progress drop
+	// flag now that all index entries have been removed.
 	err = db.Update(func(dbTx database.Tx) error {
 		meta := dbTx.Metadata()
 		indexesBucket := meta.Bucket(indexTipsBucketName)
 		if err := indexesBucket.Delete(idxKey); err != nil {
 			return err
 		}
+		err := indexesBucket.Delete(indexVersionKey(idxKey))
+		if err != nil {
+			return err
+		}
 
 		return indexesBucket.Delete(indexDropKey(idxKey))
 	})
@@ -0,0 +1,115 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Actinium-project/acmd/chaincfg"
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/database"
+	_ "github.com/Actinium-project/acmd/database/ffldb"
+	"github.com/Actinium-project/acmd/wire"
+)
+
+// TestMaybeUpgradeIndexes ensures an address index created before versions
+// were recorded is dropped so it is rebuilt, while an index with the current
+// version is left alone.
+func TestMaybeUpgradeIndexes(t *testing.T) {
+	dbPath, err := ioutil.TempDir("", "upgradeindexes")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+
+	db, err := database.Create("ffldb", filepath.Join(dbPath, "db"),
+		wire.MainNet)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Create an address index the way versions prior to the address deltas
+	// did, with neither the sub buckets nor a version.
+	tipHash := chainhash.Hash{0x01}
+	err = db.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		if _, err := meta.CreateBucket(indexTipsBucketName); err != nil {
+			return err
+		}
+		if _, err := meta.CreateBucket(addrIndexKey); err != nil {
+			return err
+		}
+		return dbPutIndexerTip(dbTx, addrIndexKey, &tipHash, 100)
+	})
+	if err != nil {
+		t.Fatalf("unable to create old address index: %v", err)
+	}
+
+	addrIndex := NewAddrIndex(db, &chaincfg.MainNetParams)
+	m := NewManager(db, []Indexer{addrIndex})
+	if err := m.maybeUpgradeIndexes(nil); err != nil {
+		t.Fatalf("maybeUpgradeIndexes: unexpected error: %v", err)
+	}
+	err = db.View(func(dbTx database.Tx) error {
+		if dbTx.Metadata().Bucket(addrIndexKey) != nil {
+			t.Error("maybeUpgradeIndexes: old address index not " +
+				"dropped")
+		}
+		indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+		if indexesBucket.Get(addrIndexKey) != nil {
+			t.Error("maybeUpgradeIndexes: old address index tip " +
+				"not removed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected view error: %v", err)
+	}
+
+	// Recreate the index and ensure it is created with the current version
+	// along with all of its buckets.
+	err = db.Update(m.maybeCreateIndexes)
+	if err != nil {
+		t.Fatalf("maybeCreateIndexes: unexpected error: %v", err)
+	}
+	err = db.View(func(dbTx database.Tx) error {
+		version := dbFetchIndexVersion(dbTx, addrIndexKey)
+		if version != addrIndexVersion {
+			t.Errorf("unexpected address index version - got %d, "+
+				"want %d", version, addrIndexVersion)
+		}
+		addrIdxBucket := dbTx.Metadata().Bucket(addrIndexKey)
+		for _, bucketName := range addrIndexSubBucketNames {
+			if addrIdxBucket.Bucket(bucketName) == nil {
+				t.Errorf("address index bucket %s not created",
+					bucketName)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected view error: %v", err)
+	}
+
+	// Ensure an index with the current version is not dropped.
+	if err := m.maybeUpgradeIndexes(nil); err != nil {
+		t.Fatalf("maybeUpgradeIndexes: unexpected error: %v", err)
+	}
+	err = db.View(func(dbTx database.Tx) error {
+		indexesBucket := dbTx.Metadata().Bucket(indexTipsBucketName)
+		if indexesBucket.Get(addrIndexKey) == nil {
+			t.Error("maybeUpgradeIndexes: current address index " +
+				"dropped")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected view error: %v", err)
+	}
+}
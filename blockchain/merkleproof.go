@@ -0,0 +1,234 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/wire"
+	"github.com/Actinium-project/acmutil"
+)
+
+// maxMerkleProofTxns is the maximum number of transactions a block committed to
+// by a merkle proof can possibly contain.  It is based on the minimum possible
+// size of a transaction.
+const maxMerkleProofTxns = MaxBlockWeight / (WitnessScaleFactor * 60)
+
+// partialMerkleTree houses the state used to build and traverse a partial
+// merkle tree as described by BIP0037.
+type partialMerkleTree struct {
+	numTx     uint32
+	allHashes []*chainhash.Hash
+	matches   []bool
+	bits      []bool
+	hashes    []*chainhash.Hash
+
+	// These fields track the position while extracting the matches.
+	bitsUsed   int
+	hashesUsed int
+}
+
+// calcTreeWidth calculates the number of nodes at the passed height of the
+// tree, where the leaves are at height zero.
+func (t *partialMerkleTree) calcTreeWidth(height uint32) uint32 {
+	return (t.numTx + (1 << height) - 1) >> height
+}
+
+// calcHash returns the hash of the node at the passed height and position of
+// the full merkle tree.
+func (t *partialMerkleTree) calcHash(height, pos uint32) *chainhash.Hash {
+	if height == 0 {
+		return t.allHashes[pos]
+	}
+
+	// When there is no right node, the left node is duplicated.
+	left := t.calcHash(height-1, pos*2)
+	right := left
+	if pos*2+1 < t.calcTreeWidth(height-1) {
+		right = t.calcHash(height-1, pos*2+1)
+	}
+	return HashMerkleBranches(left, right)
+}
+
+// traverseAndBuild builds the partial merkle tree by traversing the full tree
+// depth-first.  Only the nodes which are ancestors of matched transactions are
+// descended into, while the hashes of all other nodes are included directly.
+func (t *partialMerkleTree) traverseAndBuild(height, pos uint32) {
+	// Determine whether this node is the parent of at least one matched
+	// transaction.
+	var isParent bool
+	for i := pos << height; i < (pos+1)<<height && i < t.numTx; i++ {
+		if t.matches[i] {
+			isParent = true
+			break
+		}
+	}
+	t.bits = append(t.bits, isParent)
+
+	// Include the hash of leaves and nodes which are not ancestors of a
+	// matched transaction and descend into the rest.
+	if height == 0 || !isParent {
+		t.hashes = append(t.hashes, t.calcHash(height, pos))
+		return
+	}
+	t.traverseAndBuild(height-1, pos*2)
+	if pos*2+1 < t.calcTreeWidth(height-1) {
+		t.traverseAndBuild(height-1, pos*2+1)
+	}
+}
+
+// traverseAndExtract traverses the partial merkle tree in the same order it was
+// built in order to calculate the hash of the node at the passed height and
+// position.  The hashes of the matched transactions are appended to the passed
+// slice.
+func (t *partialMerkleTree) traverseAndExtract(height, pos uint32,
+	matches *[]*chainhash.Hash) (*chainhash.Hash, error) {
+
+	if t.bitsUsed >= len(t.bits) {
+		str := "merkle proof does not contain enough flag bits"
+		return nil, ruleError(ErrBadMerkleProof, str)
+	}
+	isParent := t.bits[t.bitsUsed]
+	t.bitsUsed++
+
+	if height == 0 || !isParent {
+		if t.hashesUsed >= len(t.hashes) {
+			str := "merkle proof does not contain enough hashes"
+			return nil, ruleError(ErrBadMerkleProof, str)
+		}
+		hash := t.hashes[t.hashesUsed]
+		t.hashesUsed++
+		if height == 0 && isParent {
+			*matches = append(*matches, hash)
+		}
+		return hash, nil
+	}
+
+	left, err := t.traverseAndExtract(height-1, pos*2, matches)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if pos*2+1 < t.calcTreeWidth(height-1) {
+		right, err = t.traverseAndExtract(height-1, pos*2+1, matches)
+		if err != nil {
+			return nil, err
+		}
+
+		// Identical left and right nodes would make it possible to
+		// prove the inclusion of duplicated transactions (CVE-2012-2459).
+		if left.IsEqual(right) {
+			str := "merkle proof contains identical sibling hashes"
+			return nil, ruleError(ErrBadMerkleProof, str)
+		}
+	}
+	return HashMerkleBranches(left, right), nil
+}
+
+// BuildMerkleProof returns a merkle block which proves the inclusion of the
+// transactions with the passed hashes in the passed block.  The merkle block
+// consists of the block header along with a partial merkle tree as described
+// by BIP0037, which is the same format used to respond to SPV clients with a
+// filter loaded.
+func BuildMerkleProof(block *acmutil.Block, txHashes map[chainhash.Hash]struct{}) *wire.MsgMerkleBlock {
+	transactions := block.Transactions()
+	t := partialMerkleTree{
+		numTx:     uint32(len(transactions)),
+		allHashes: make([]*chainhash.Hash, 0, len(transactions)),
+		matches:   make([]bool, 0, len(transactions)),
+	}
+	for _, tx := range transactions {
+		_, matched := txHashes[*tx.Hash()]
+		t.allHashes = append(t.allHashes, tx.Hash())
+		t.matches = append(t.matches, matched)
+	}
+
+	// Calculate the height of the tree and build the partial tree starting
+	// at the root.
+	var height uint32
+	for t.calcTreeWidth(height) > 1 {
+		height++
+	}
+	t.traverseAndBuild(height, 0)
+
+	// Pack the flag bits into bytes with the least significant bit first.
+	msg := wire.MsgMerkleBlock{
+		Header:       block.MsgBlock().Header,
+		Transactions: t.numTx,
+		Hashes:       t.hashes,
+		Flags:        make([]byte, (len(t.bits)+7)/8),
+	}
+	for i, bit := range t.bits {
+		if bit {
+			msg.Flags[i/8] |= 1 << (uint(i) % 8)
+		}
+	}
+	return &msg
+}
+
+// ExtractMerkleProofMatches verifies the partial merkle tree of the passed
+// merkle block commits to the merkle root in its header and returns the hashes
+// of the transactions it proves the inclusion of.
+//
+// Note that this only ensures the proof is internally consistent.  It is up to
+// the caller to ensure the block header is part of the chain.
+func ExtractMerkleProofMatches(msg *wire.MsgMerkleBlock) ([]*chainhash.Hash, error) {
+	// Reject proofs which can't possibly be valid before doing any work.
+	switch {
+	case msg.Transactions == 0:
+		str := "merkle proof does not contain any transactions"
+		return nil, ruleError(ErrBadMerkleProof, str)
+
+	case msg.Transactions > maxMerkleProofTxns:
+		str := fmt.Sprintf("merkle proof contains too many transactions "+
+			"- got %d, max %d", msg.Transactions, maxMerkleProofTxns)
+		return nil, ruleError(ErrBadMerkleProof, str)
+
+	case uint32(len(msg.Hashes)) > msg.Transactions:
+		str := fmt.Sprintf("merkle proof contains more hashes than "+
+			"transactions - got %d, max %d", len(msg.Hashes),
+			msg.Transactions)
+		return nil, ruleError(ErrBadMerkleProof, str)
+
+	case len(msg.Flags)*8 < len(msg.Hashes):
+		str := "merkle proof does not contain enough flag bits"
+		return nil, ruleError(ErrBadMerkleProof, str)
+	}
+
+	t := partialMerkleTree{
+		numTx:  msg.Transactions,
+		bits:   make([]bool, len(msg.Flags)*8),
+		hashes: msg.Hashes,
+	}
+	for i := range t.bits {
+		t.bits[i] = msg.Flags[i/8]&(1<<(uint(i)%8)) != 0
+	}
+
+	var height uint32
+	for t.calcTreeWidth(height) > 1 {
+		height++
+	}
+	var matches []*chainhash.Hash
+	root, err := t.traverseAndExtract(height, 0, &matches)
+	if err != nil {
+		return nil, err
+	}
+
+	// All of the hashes and all but the padding of the flag bits must have
+	// been consumed.
+	if (t.bitsUsed+7)/8 != len(msg.Flags) || t.hashesUsed != len(msg.Hashes) {
+		str := "merkle proof contains unused hashes or flag bits"
+		return nil, ruleError(ErrBadMerkleProof, str)
+	}
+
+	if !root.IsEqual(&msg.Header.MerkleRoot) {
+		str := fmt.Sprintf("merkle proof commits to merkle root %v "+
+			"instead of %v", root, msg.Header.MerkleRoot)
+		return nil, ruleError(ErrBadMerkleProof, str)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmutil"
+)
+
+// TestMerkleProof ensures merkle proofs built for every combination of
+// transactions in a block prove exactly those transactions and that tampered
+// proofs are rejected.
+func TestMerkleProof(t *testing.T) {
+	block := acmutil.NewBlock(&Block100000)
+	transactions := block.Transactions()
+
+	for mask := 0; mask < 1<<uint(len(transactions)); mask++ {
+		txHashes := make(map[chainhash.Hash]struct{})
+		var want []*chainhash.Hash
+		for i, tx := range transactions {
+			if mask&(1<<uint(i)) != 0 {
+				txHashes[*tx.Hash()] = struct{}{}
+				want = append(want, tx.Hash())
+			}
+		}
+
+		proof := BuildMerkleProof(block, txHashes)
+		matches, err := ExtractMerkleProofMatches(proof)
+		if err != nil {
+			t.Errorf("ExtractMerkleProofMatches #%d: unexpected error: %v",
+				mask, err)
+			continue
+		}
+		if len(matches) != len(want) {
+			t.Errorf("ExtractMerkleProofMatches #%d: got %d matches, "+
+				"want %d", mask, len(matches), len(want))
+			continue
+		}
+		for i := range matches {
+			if !matches[i].IsEqual(want[i]) {
+				t.Errorf("ExtractMerkleProofMatches #%d: match %d "+
+					"is %v, want %v", mask, i, matches[i], want[i])
+			}
+		}
+	}
+
+	// Build a proof for a single transaction to tamper with.
+	txHashes := map[chainhash.Hash]struct{}{*transactions[2].Hash(): {}}
+	proof := BuildMerkleProof(block, txHashes)
+
+	// Changing any of the hashes must result in a merkle root mismatch.
+	for i := range proof.Hashes {
+		tampered := *proof
+		tampered.Hashes = append(tampered.Hashes[:0:0], proof.Hashes...)
+		badHash := *tampered.Hashes[i]
+		badHash[0] ^= 0xff
+		tampered.Hashes[i] = &badHash
+		_, err := ExtractMerkleProofMatches(&tampered)
+		if !isRuleErrorCode(err, ErrBadMerkleProof) {
+			t.Errorf("ExtractMerkleProofMatches: tampered hash %d: "+
+				"unexpected error: %v", i, err)
+		}
+	}
+
+	// Extra hashes, missing hashes, and a wrong number of transactions must
+	// all be rejected.
+	tampered := *proof
+	tampered.Hashes = append(proof.Hashes[:len(proof.Hashes):len(proof.Hashes)],
+		proof.Hashes[0])
+	if _, err := ExtractMerkleProofMatches(&tampered); err == nil {
+		t.Error("ExtractMerkleProofMatches: accepted extra hash")
+	}
+	tampered = *proof
+	tampered.Hashes = proof.Hashes[:len(proof.Hashes)-1]
+	if _, err := ExtractMerkleProofMatches(&tampered); err == nil {
+		t.Error("ExtractMerkleProofMatches: accepted missing hash")
+	}
+	tampered = *proof
+	tampered.Transactions = 0
+	if _, err := ExtractMerkleProofMatches(&tampered); err == nil {
+		t.Error("ExtractMerkleProofMatches: accepted zero transactions")
+	}
+	tampered = *proof
+	tampered.Transactions++
+	if _, err := ExtractMerkleProofMatches(&tampered); err == nil {
+		t.Error("ExtractMerkleProofMatches: accepted wrong number of " +
+			"transactions")
+	}
+}
+
+// isRuleErrorCode returns whether or not the passed error is a rule error with
+// the passed error code.
+func isRuleErrorCode(err error, code ErrorCode) bool {
+	rerr, ok := err.(RuleError)
+	return ok && rerr.ErrorCode == code
+}
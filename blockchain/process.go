@@ -29,6 +29,12 @@ const (
 	// not be performed.
 	BFNoPoWCheck
 
+	// BFAssumeValid may be set to indicate the block is an ancestor of the
+	// assumed valid block in the best known header chain, so its
+	// transaction scripts are not validated.  All other checks are still
+	// performed.  This is primarily used for headers-first mode.
+	BFAssumeValid
+
 	// BFNone is a convenience value to specifically indicate no flags.
 	BFNone BehaviorFlags = 0
 )
@@ -165,7 +171,8 @@ func (b *BlockChain) ProcessBlock(block *acmutil.Block, flags BehaviorFlags) (bo
 	}
 
 	// Perform preliminary sanity checks on the block and its transactions.
-	err = checkBlockSanity(block, b.chainParams.PowLimit, b.timeSource, flags)
+	err = checkBlockSanity(block, b.chainParams.PowLimit,
+		b.chainParams.PowAlgorithms(), b.timeSource, flags)
 	if err != nil {
 		return false, false, err
 	}
@@ -238,6 +245,15 @@ func (b *BlockChain) ProcessBlock(block *acmutil.Block, flags BehaviorFlags) (bo
 		return false, false, err
 	}
 
+	// Prune old block data when the stored blocks exceed the target size.
+	// Failing to prune does not affect the validity of the block, so the
+	// error is only logged.
+	if isMainChain {
+		if err := b.maybePruneBlocks(); err != nil {
+			log.Errorf("Unable to prune block data: %v", err)
+		}
+	}
+
 	log.Debugf("Accepted block %v", blockHash)
 
 	return isMainChain, false, nil
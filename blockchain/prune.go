@@ -0,0 +1,158 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/database"
+)
+
+const (
+	// MinBlocksToKeep is the number of most recent blocks in the main
+	// chain whose data is never pruned.  It ensures reorganizations that
+	// are deep enough to be plausible can still be handled and matches
+	// the number of blocks pruned nodes are expected to serve (BIP0159).
+	MinBlocksToKeep = 288
+
+	// pruneCheckInterval is the number of blocks between automatic
+	// attempts to prune block data.  Pruning needs to look at the stored
+	// block files, so it is not attempted for every block.
+	pruneCheckInterval = 12
+)
+
+// IsPruneMode returns whether or not pruning of old block data is enabled.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsPruneMode() bool {
+	return b.prune
+}
+
+// PrunedHeight returns the height of the most recent block whose data has been
+// pruned.  Zero is returned when no blocks have been pruned.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PrunedHeight() int32 {
+	b.chainLock.RLock()
+	prunedHeight := b.prunedHeight
+	b.chainLock.RUnlock()
+	return prunedHeight
+}
+
+// pruneBlocks deletes stored block data until it is at or below the provided
+// target size in bytes, without deleting any block above the provided height.
+// The block index is updated to reflect that the data of the pruned blocks is
+// no longer available and the new pruned height is recorded in the database.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) pruneBlocks(targetSize uint64, maxHeight int32) error {
+	if maxHeight <= 0 {
+		return nil
+	}
+
+	keep := func(hash *chainhash.Hash) bool {
+		node := b.index.LookupNode(hash)
+		return node != nil && node.height > maxHeight
+	}
+
+	prunedHeight := b.prunedHeight
+	var prunedNodes []*blockNode
+	err := b.db.Update(func(dbTx database.Tx) error {
+		pruned, err := dbTx.PruneBlocks(targetSize, keep)
+		if err != nil {
+			return err
+		}
+
+		for i := range pruned {
+			node := b.index.LookupNode(&pruned[i])
+			if node == nil {
+				continue
+			}
+			prunedNodes = append(prunedNodes, node)
+			if node.height > prunedHeight {
+				prunedHeight = node.height
+			}
+		}
+		if prunedHeight == b.prunedHeight {
+			return nil
+		}
+		return dbPutPrunedHeight(dbTx, prunedHeight)
+	})
+	if err != nil {
+		return err
+	}
+	if len(prunedNodes) == 0 {
+		return nil
+	}
+
+	// Mark the data of the pruned blocks as no longer stored.
+	for _, node := range prunedNodes {
+		b.index.UnsetStatusFlags(node, statusDataStored)
+	}
+	if err := b.index.flushToDB(); err != nil {
+		return err
+	}
+
+	log.Infof("Pruned %d blocks up to height %d", len(prunedNodes),
+		prunedHeight)
+	b.prunedHeight = prunedHeight
+	return nil
+}
+
+// maybePruneBlocks prunes old block data when automatic pruning is enabled and
+// the stored block data exceeds the target size.  The most recent
+// MinBlocksToKeep blocks are always retained.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) maybePruneBlocks() error {
+	if !b.prune || b.pruneTarget == 0 {
+		return nil
+	}
+
+	height := b.bestChain.Height()
+	if height-b.lastPruneCheck < pruneCheckInterval &&
+		height >= b.lastPruneCheck {
+
+		return nil
+	}
+	b.lastPruneCheck = height
+
+	return b.pruneBlocks(b.pruneTarget, height-MinBlocksToKeep)
+}
+
+// PruneBlockchain deletes the data of the blocks in the main chain up to the
+// provided height.  The most recent MinBlocksToKeep blocks are always retained,
+// and since block data is deleted in groups, the data of some blocks up to the
+// provided height might remain.  The height of the most recent block whose
+// data has been pruned is returned.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PruneBlockchain(height int32) (int32, error) {
+	if !b.prune {
+		return 0, fmt.Errorf("cannot prune blocks because pruning is " +
+			"not enabled")
+	}
+
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	tip := b.bestChain.Height()
+	if height > tip {
+		return 0, fmt.Errorf("cannot prune blocks beyond the best "+
+			"chain height %d", tip)
+	}
+	if height > tip-MinBlocksToKeep {
+		height = tip - MinBlocksToKeep
+		log.Debugf("Attempting to prune up to height %d instead to "+
+			"retain the most recent %d blocks", height,
+			MinBlocksToKeep)
+	}
+
+	if err := b.pruneBlocks(0, height); err != nil {
+		return 0, err
+	}
+	return b.prunedHeight, nil
+}
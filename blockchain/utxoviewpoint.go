@@ -640,3 +640,61 @@ func (b *BlockChain) FetchUtxoEntry(outpoint wire.OutPoint) (*UtxoEntry, error)
 
 	return entry, nil
 }
+
+// FetchUtxoStats calculates statistics about the entire unspent transaction
+// output set from the point of view of the end of the main chain.  Since this
+// requires iterating the whole set, it can take a long time.  It may be
+// cancelled by closing the interrupt channel.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) FetchUtxoStats(interrupt <-chan struct{}) (*UtxoStats, error) {
+	// The database transaction provides a consistent snapshot of the utxo
+	// set, so there is no need to hold the chain lock, which would block
+	// block processing while the set is iterated.
+	var stats *UtxoStats
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		stats, err = dbFetchUtxoStats(dbTx, interrupt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// ScanUtxoSet calls the passed function for every unspent transaction output
+// in the utxo set from the point of view of the end of the main chain.  The
+// outputs are ordered by their outpoints.  The hash and height of the best
+// block the utxo set represents are returned.
+//
+// The iteration stops when the function returns an error, which is returned.
+// It may also be cancelled by closing the interrupt channel.  The hash and
+// height of the best block are also returned when the scan is cancelled so
+// any partial results can be attributed to a block.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ScanUtxoSet(interrupt <-chan struct{},
+	fn func(outpoint wire.OutPoint, entry *UtxoEntry) error) (*chainhash.Hash, int32, error) {
+
+	var state *bestChainState
+	err := b.db.View(func(dbTx database.Tx) error {
+		bestState, err := dbFetchBestChainState(dbTx)
+		if err != nil {
+			return err
+		}
+		state = &bestState
+
+		return dbForEachUtxo(dbTx, interrupt, func(outpoint wire.OutPoint,
+			entry *UtxoEntry, size int) error {
+
+			return fn(outpoint, entry)
+		})
+	})
+	if state == nil || (err != nil && err != errInterruptRequested) {
+		return nil, 0, err
+	}
+
+	return &state.hash, int32(state.height), err
+}
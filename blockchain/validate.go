@@ -304,14 +304,9 @@ func CheckTransactionSanity(tx *acmutil.Tx) error {
 	return nil
 }
 
-// checkProofOfWork ensures the block header bits which indicate the target
-// difficulty is in min/max range and that the block hash is less than the
-// target difficulty as claimed.
-//
-// The flags modify the behavior of this function as follows:
-//  - BFNoPoWCheck: The check to ensure the block hash is less than the target
-//    difficulty is not performed.
-func checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, flags BehaviorFlags) error {
+// checkProofOfWorkTarget ensures the block header bits which indicate the
+// target difficulty is in min/max range.
+func checkProofOfWorkTarget(header *wire.BlockHeader, powLimit *big.Int) error {
 	// The target difficulty must be larger than zero.
 	target := CompactToBig(header.Bits)
 	if target.Sign() <= 0 {
@@ -320,39 +315,91 @@ func checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, flags Behavio
 		return ruleError(ErrUnexpectedDifficulty, str)
 	}
 
-	//Actinium: disable max target diff check
 	// The target difficulty must be less than the maximum allowed.
-	/*if target.Cmp(powLimit) > 0 {
+	if target.Cmp(powLimit) > 0 {
 		str := fmt.Sprintf("block target difficulty of %064x is "+
 			"higher than max of %064x", target, powLimit)
 		return ruleError(ErrUnexpectedDifficulty, str)
-	}*/
+	}
+
+	return nil
+}
+
+// checkProofOfWork ensures the block header bits which indicate the target
+// difficulty is in min/max range and that the proof-of-work hash of the block
+// header, calculated with the passed algorithm, is less than the target
+// difficulty as claimed.
+//
+// The flags modify the behavior of this function as follows:
+//  - BFNoPoWCheck: The check to ensure the block hash is less than the target
+//    difficulty is not performed.
+func checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, powAlgo wire.PowAlgorithm, flags BehaviorFlags) error {
+	err := checkProofOfWorkTarget(header, powLimit)
+	if err != nil {
+		return err
+	}
 
 	// The block hash must be less than the claimed target unless the flag
 	// to avoid proof of work checks is set.
 	if flags&BFNoPoWCheck != BFNoPoWCheck {
-		//Actinium: deactivate PoW checks
 		// The block hash must be less than the claimed target.
-		/*hash, err := header.PowHash()
+		hash, err := header.PowHash(powAlgo)
 		if err != nil {
 			return err
 		}
+		target := CompactToBig(header.Bits)
 		hashNum := HashToBig(hash)
 		if hashNum.Cmp(target) > 0 {
-			str := fmt.Sprintf("block hash of %064x is higher than "+
-				"expected max of %064x", hashNum, target)
+			str := fmt.Sprintf("block %v hash of %064x is higher "+
+				"than expected max of %064x", powAlgo, hashNum,
+				target)
 			return ruleError(ErrHighHash, str)
-		}*/
+		}
 	}
 
 	return nil
 }
 
+// checkAnyProofOfWork ensures the block header bits which indicate the target
+// difficulty is in min/max range and that the proof-of-work hash of the block
+// header, calculated with at least one of the passed algorithms, is less than
+// the target difficulty as claimed.  This allows the proof of work to be
+// checked without knowing the height of the block, while checkProofOfWork
+// ensures the algorithm in effect at the height was used once it is known.
+//
+// The flags modify the behavior of this function as follows:
+//  - BFNoPoWCheck: The check to ensure the block hash is less than the target
+//    difficulty is not performed.
+func checkAnyProofOfWork(header *wire.BlockHeader, powLimit *big.Int, powAlgos []wire.PowAlgorithm, flags BehaviorFlags) error {
+	err := checkProofOfWorkTarget(header, powLimit)
+	if err != nil {
+		return err
+	}
+
+	for _, powAlgo := range powAlgos {
+		err = checkProofOfWork(header, powLimit, powAlgo, flags)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
 // CheckProofOfWork ensures the block header bits which indicate the target
-// difficulty is in min/max range and that the block hash is less than the
-// target difficulty as claimed.
-func CheckProofOfWork(block *acmutil.Block, powLimit *big.Int) error {
-	return checkProofOfWork(&block.MsgBlock().Header, powLimit, BFNone)
+// difficulty is in min/max range and that the proof-of-work hash of the block,
+// calculated with the passed algorithm, is less than the target difficulty as
+// claimed.  The algorithm depends on the height of the block and is provided
+// by chaincfg.Params.PowAlgorithm.
+func CheckProofOfWork(block *acmutil.Block, powLimit *big.Int, powAlgo wire.PowAlgorithm) error {
+	return CheckHeaderProofOfWork(&block.MsgBlock().Header, powLimit, powAlgo)
+}
+
+// CheckHeaderProofOfWork ensures the block header bits which indicate the
+// target difficulty is in min/max range and that the proof-of-work hash of the
+// header, calculated with the passed algorithm, is less than the target
+// difficulty as claimed.  See CheckProofOfWork for details.
+func CheckHeaderProofOfWork(header *wire.BlockHeader, powLimit *big.Int, powAlgo wire.PowAlgorithm) error {
+	return checkProofOfWork(header, powLimit, powAlgo, BFNone)
 }
 
 // CountSigOps returns the number of signature operations for all transaction
@@ -438,12 +485,14 @@ func CountP2SHSigOps(tx *acmutil.Tx, isCoinBaseTx bool, utxoView *UtxoViewpoint)
 // context free.
 //
 // The flags do not modify the behavior of this function directly, however they
-// are needed to pass along to checkProofOfWork.
-func checkBlockHeaderSanity(header *wire.BlockHeader, powLimit *big.Int, timeSource MedianTimeSource, flags BehaviorFlags) error {
+// are needed to pass along to checkAnyProofOfWork.
+func checkBlockHeaderSanity(header *wire.BlockHeader, powLimit *big.Int, powAlgos []wire.PowAlgorithm, timeSource MedianTimeSource, flags BehaviorFlags) error {
 	// Ensure the proof of work bits in the block header is in min/max range
 	// and the block hash is less than the target value described by the
-	// bits.
-	err := checkProofOfWork(header, powLimit, flags)
+	// bits.  The algorithm in effect depends on the height of the block,
+	// which is not known here, so any of the network's algorithms is
+	// accepted.  checkBlockHeaderContext ensures the right one was used.
+	err := checkAnyProofOfWork(header, powLimit, powAlgos, flags)
 	if err != nil {
 		return err
 	}
@@ -476,10 +525,11 @@ func checkBlockHeaderSanity(header *wire.BlockHeader, powLimit *big.Int, timeSou
 //
 // The flags do not modify the behavior of this function directly, however they
 // are needed to pass along to checkBlockHeaderSanity.
-func checkBlockSanity(block *acmutil.Block, powLimit *big.Int, timeSource MedianTimeSource, flags BehaviorFlags) error {
+func checkBlockSanity(block *acmutil.Block, powLimit *big.Int, powAlgos []wire.PowAlgorithm, timeSource MedianTimeSource, flags BehaviorFlags) error {
 	msgBlock := block.MsgBlock()
 	header := &msgBlock.Header
-	err := checkBlockHeaderSanity(header, powLimit, timeSource, flags)
+	err := checkBlockHeaderSanity(header, powLimit, powAlgos, timeSource,
+		flags)
 	if err != nil {
 		return err
 	}
@@ -583,8 +633,10 @@ func checkBlockSanity(block *acmutil.Block, powLimit *big.Int, timeSource Median
 
 // CheckBlockSanity performs some preliminary checks on a block to ensure it is
 // sane before continuing with block processing.  These checks are context free.
-func CheckBlockSanity(block *acmutil.Block, powLimit *big.Int, timeSource MedianTimeSource) error {
-	return checkBlockSanity(block, powLimit, timeSource, BFNone)
+// The proof of work must be valid for one of the passed algorithms, which are
+// provided by chaincfg.Params.PowAlgorithms.
+func CheckBlockSanity(block *acmutil.Block, powLimit *big.Int, powAlgos []wire.PowAlgorithm, timeSource MedianTimeSource) error {
+	return checkBlockSanity(block, powLimit, powAlgos, timeSource, BFNone)
 }
 
 // ExtractCoinbaseHeight attempts to extract the height of the block from the
@@ -650,29 +702,49 @@ func checkSerializedHeight(coinbaseTx *acmutil.Tx, wantHeight int32) error {
 //
 // The flags modify the behavior of this function as follows:
 //  - BFFastAdd: All checks except those involving comparing the header against
-//    the checkpoints are not performed.
+//    the checkpoints and the proof of work are not performed.
+//  - BFNoPoWCheck: The check to ensure the block hash is less than the target
+//    difficulty is not performed.
 //
 // This function MUST be called with the chain state lock held (for writes).
 func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode *blockNode, flags BehaviorFlags) error {
+	// The height of this block is one more than the referenced previous
+	// block.
+	blockHeight := prevNode.height + 1
+
+	// Ensure the block hash, calculated with the proof-of-work algorithm
+	// in effect at the height of the block, is less than the target value
+	// described by the bits.
+	powAlgo := b.chainParams.PowAlgorithm(blockHeight)
+	err := checkProofOfWork(header, b.chainParams.PowLimit, powAlgo, flags)
+	if err != nil {
+		return err
+	}
+
 	fastAdd := flags&BFFastAdd == BFFastAdd
 	if !fastAdd {
 		// Ensure the difficulty specified in the block header matches
 		// the calculated difficulty based on the previous block and
 		// difficulty retarget rules.
-		//Actinium: commented out because not used (check "deactivate block diff checks" below)
-		// expectedDifficulty, err := b.calcNextRequiredDifficulty(prevNode,
-		// 	header.Timestamp)
-		// if err != nil {
-		// 	return err
-		// }
-
-		//Actinium: deactivate block diff checks
-		//blockDifficulty := header.Bits
-		/*if blockDifficulty != expectedDifficulty {
-			str := "block difficulty of %d is not the expected value of %d"
-			str = fmt.Sprintf(str, blockDifficulty, expectedDifficulty)
-			return ruleError(ErrUnexpectedDifficulty, str)
-		}*/
+		//
+		// Actinium: the difficulty is only enforced once the LWMA
+		// retarget is active since the rules in effect prior to it are
+		// not reproduced by calcNextRequiredDifficulty.
+		if blockHeight >= b.chainParams.ACMZawyLWMAHeight {
+			expectedDifficulty, err := b.calcNextRequiredDifficulty(
+				prevNode, header.Timestamp)
+			if err != nil {
+				return err
+			}
+			blockDifficulty := header.Bits
+			if blockDifficulty != expectedDifficulty {
+				str := "block difficulty of %d is not the expected " +
+					"value of %d"
+				str = fmt.Sprintf(str, blockDifficulty,
+					expectedDifficulty)
+				return ruleError(ErrUnexpectedDifficulty, str)
+			}
+		}
 
 		// Ensure the timestamp for the block header is after the
 		// median time of the last several blocks (medianTimeBlocks).
@@ -684,10 +756,6 @@ func (b *BlockChain) checkBlockHeaderContext(header *wire.BlockHeader, prevNode
 		}
 	}
 
-	// The height of this block is one more than the referenced previous
-	// block.
-	blockHeight := prevNode.height + 1
-
 	// Ensure chain matches up to predetermined checkpoints.
 	blockHash := header.BlockHash()
 	if !b.verifyCheckpoint(blockHeight, &blockHash) {
@@ -1000,8 +1068,11 @@ func CheckTransactionInputs(tx *acmutil.Tx, txHeight int32, utxoView *UtxoViewpo
 // connects to the end of the current main chain and then calls this function
 // with that node.
 //
+// The flags modify the behavior of this function as follows:
+//  - BFAssumeValid: The transaction scripts are not validated.
+//
 // This function MUST be called with the chain state lock held (for writes).
-func (b *BlockChain) checkConnectBlock(node *blockNode, block *acmutil.Block, view *UtxoViewpoint, stxos *[]SpentTxOut) error {
+func (b *BlockChain) checkConnectBlock(node *blockNode, block *acmutil.Block, view *UtxoViewpoint, stxos *[]SpentTxOut, flags BehaviorFlags) error {
 	// If the side chain blocks end up in the database, a call to
 	// CheckBlockSanity should be done here in case a previous version
 	// allowed a block that is no longer valid.  However, since the
@@ -1168,6 +1239,13 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *acmutil.Block, vi
 		runScripts = false
 	}
 
+	// Likewise, don't run scripts when the block is known to be an
+	// ancestor of the assumed valid block since it vouches for the scripts
+	// of all of its ancestors in the same way.
+	if flags&BFAssumeValid == BFAssumeValid {
+		runScripts = false
+	}
+
 	// Blocks created after the BIP0016 activation time need to have the
 	// pay-to-script-hash checks enabled.
 	var scriptFlags txscript.ScriptFlags
@@ -1275,7 +1353,8 @@ func (b *BlockChain) CheckConnectBlockTemplate(block *acmutil.Block) error {
 		return ruleError(ErrPrevBlockNotBest, str)
 	}
 
-	err := checkBlockSanity(block, b.chainParams.PowLimit, b.timeSource, flags)
+	err := checkBlockSanity(block, b.chainParams.PowLimit,
+		b.chainParams.PowAlgorithms(), b.timeSource, flags)
 	if err != nil {
 		return err
 	}
@@ -1290,5 +1369,5 @@ func (b *BlockChain) CheckConnectBlockTemplate(block *acmutil.Block) error {
 	view := NewUtxoViewpoint()
 	view.SetBestHash(&tip.hash)
 	newNode := newBlockNode(&header, tip)
-	return b.checkConnectBlock(newNode, block, view, nil)
+	return b.checkConnectBlock(newNode, block, view, nil, BFNone)
 }
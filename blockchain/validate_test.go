@@ -12,6 +12,7 @@ import (
 
 	"github.com/Actinium-project/acmd/chaincfg"
 	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/txscript"
 	"github.com/Actinium-project/acmd/wire"
 	"github.com/Actinium-project/acmutil"
 )
@@ -148,22 +149,125 @@ func TestCheckConnectBlockTemplate(t *testing.T) {
 	}
 }
 
+// TestAssumeValid ensures the transaction scripts of blocks processed with the
+// BFAssumeValid flag are not validated while they are otherwise.
+func TestAssumeValid(t *testing.T) {
+	// Create a new database and chain instance to run tests against.
+	params := &chaincfg.RegressionNetParams
+	chain, teardownFunc, err := chainSetup("assumevalid", params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Since we're not dealing with the real block chain, set the coinbase
+	// maturity to 1.
+	chain.TstSetCoinbaseMaturity(1)
+
+	b1 := newTestBlock(params, params.GenesisBlock, 1)
+	b2 := newTestBlock(params, b1.MsgBlock(), 2)
+	for i, block := range []*acmutil.Block{b1, b2} {
+		_, _, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("ProcessBlock fail on block %d: %v", i+1, err)
+		}
+	}
+
+	// Create a block which spends the coinbase of the first block with a
+	// signature script that fails to execute, but is otherwise valid.
+	spend := newSpendTx(wire.OutPoint{Hash: b1.MsgBlock().Transactions[0].TxHash()},
+		CalcBlockSubsidy(1, params), 1)
+	spend.TxIn[0].SignatureScript = []byte{txscript.OP_RETURN}
+	invalidBlock := newTestBlock(params, b2.MsgBlock(), 3, spend)
+
+	// The scripts are validated without the flag.
+	err = chain.CheckConnectBlockTemplate(invalidBlock)
+	if !isRuleErrorCode(err, ErrScriptValidation) {
+		t.Fatalf("CheckConnectBlockTemplate: unexpected error for block "+
+			"with invalid signature script -- got %v, want %v", err,
+			ErrScriptValidation)
+	}
+
+	// The block is connected when the scripts are assumed to be valid.
+	isMainChain, _, err := chain.ProcessBlock(invalidBlock, BFAssumeValid)
+	if err != nil {
+		t.Fatalf("ProcessBlock: unexpected error for assumed valid "+
+			"block: %v", err)
+	}
+	if !isMainChain {
+		t.Fatal("ProcessBlock: expected assumed valid block to connect " +
+			"to the main chain")
+	}
+}
+
 // TestCheckBlockSanity tests the CheckBlockSanity function to ensure it works
 // as expected.
 func TestCheckBlockSanity(t *testing.T) {
-	powLimit := chaincfg.MainNetParams.PowLimit
-	block := acmutil.NewBlock(&Block100000)
+	// Block100000 was not mined with the proof-of-work algorithms of the
+	// network, so copies of it with the easiest target of the regression
+	// test network are used along with nonces which are known to result in
+	// hashes that do or do not meet it.
+	regNetParams := &chaincfg.RegressionNetParams
+	withNonce := func(nonce uint32) *acmutil.Block {
+		msgBlock := Block100000
+		msgBlock.Header.Bits = regNetParams.PowLimitBits
+		msgBlock.Header.Nonce = nonce
+		return acmutil.NewBlock(&msgBlock)
+	}
+	const (
+		scryptNonce = 0  // Only the scrypt hash meets the target.
+		lyra2zNonce = 12 // Only the Lyra2Z hash meets the target.
+		badNonce    = 6  // Neither hash meets the target.
+	)
+
+	powLimit := regNetParams.PowLimit
+	powAlgos := chaincfg.MainNetParams.PowAlgorithms()
+	block := withNonce(scryptNonce)
 	timeSource := NewMedianTime()
-	err := CheckBlockSanity(block, powLimit, timeSource)
+	err := CheckBlockSanity(block, powLimit, powAlgos, timeSource)
 	if err != nil {
 		t.Errorf("CheckBlockSanity: %v", err)
 	}
 
+	// Ensure a block that was hashed with any of the passed proof-of-work
+	// algorithms passes.
+	err = CheckBlockSanity(withNonce(lyra2zNonce), powLimit, powAlgos,
+		timeSource)
+	if err != nil {
+		t.Errorf("CheckBlockSanity: unexpected error for Lyra2Z "+
+			"block: %v", err)
+	}
+
+	// Ensure a block that was not hashed with any of the passed
+	// proof-of-work algorithms fails.
+	lyra2zOnly := []wire.PowAlgorithm{wire.PowLyra2Z}
+	err = CheckBlockSanity(block, powLimit, lyra2zOnly, timeSource)
+	if !isRuleErrorCode(err, ErrHighHash) {
+		t.Errorf("CheckBlockSanity: unexpected error for wrong "+
+			"algorithm - got %v, want %v", err, ErrHighHash)
+	}
+
+	// Ensure a block whose hash does not meet its target fails.
+	err = CheckBlockSanity(withNonce(badNonce), powLimit, powAlgos,
+		timeSource)
+	if !isRuleErrorCode(err, ErrHighHash) {
+		t.Errorf("CheckBlockSanity: unexpected error for high hash - "+
+			"got %v, want %v", err, ErrHighHash)
+	}
+
+	// Ensure a block with a target above the proof-of-work limit fails.
+	err = CheckBlockSanity(block, chaincfg.MainNetParams.PowLimit,
+		powAlgos, timeSource)
+	if !isRuleErrorCode(err, ErrUnexpectedDifficulty) {
+		t.Errorf("CheckBlockSanity: unexpected error for easy target "+
+			"- got %v, want %v", err, ErrUnexpectedDifficulty)
+	}
+
 	// Ensure a block that has a timestamp with a precision higher than one
 	// second fails.
 	timestamp := block.MsgBlock().Header.Timestamp
 	block.MsgBlock().Header.Timestamp = timestamp.Add(time.Nanosecond)
-	err = CheckBlockSanity(block, powLimit, timeSource)
+	err = CheckBlockSanity(block, powLimit, powAlgos, timeSource)
 	if err == nil {
 		t.Errorf("CheckBlockSanity: error is nil when it shouldn't be")
 	}
@@ -82,6 +82,17 @@ type ConsensusDeployment struct {
 	ExpireTime uint64
 }
 
+// PowActivation defines the height from which blocks must be hashed with a
+// specific proof-of-work algorithm.
+type PowActivation struct {
+	// Height is the first block height the algorithm applies to.
+	Height int32
+
+	// Algorithm is the proof-of-work algorithm blocks from Height on must
+	// be hashed with.
+	Algorithm wire.PowAlgorithm
+}
+
 // Constants that define the deployment offset in the deployments field of the
 // parameters for each deployment.  This is useful to be able to get the details
 // of a specific deployment by name.
@@ -148,6 +159,23 @@ type Params struct {
 	GPUSupportHeight  int32
 	ACMZawyLWMAHeight int32
 
+	// PowSchedule defines the proof-of-work algorithms blocks must be
+	// hashed with ordered from oldest to newest activation height.  When
+	// it is not set, blocks are hashed with scrypt up to GPUSupportHeight
+	// and with Lyra2Z from it on.
+	PowSchedule []PowActivation
+
+	// ACMZawyLWMAWindow is the number of previous blocks averaged by the
+	// Zawy linearly weighted moving average (LWMA) difficulty algorithm
+	// which replaces the interval based retarget from ACMZawyLWMAHeight.
+	ACMZawyLWMAWindow int32
+
+	// ACMZawyLWMAMaxSolveTimeFactor limits each solve time considered by
+	// the LWMA difficulty algorithm to this many multiples of
+	// TargetTimePerBlock.  It prevents single blocks with long solve times
+	// from causing large drops in difficulty.
+	ACMZawyLWMAMaxSolveTimeFactor int64
+
 	// CoinbaseMaturity is the number of blocks required before newly mined
 	// coins (coinbase transactions) can be spent.
 	CoinbaseMaturity uint16
@@ -183,12 +211,31 @@ type Params struct {
 	// NOTE: This only applies if ReduceMinDifficulty is true.
 	MinDiffReductionTime time.Duration
 
+	// PoWNoRetargeting defines whether the network keeps the difficulty of
+	// the previous block instead of running the LWMA difficulty algorithm.
+	// This is really only useful for the regression test network.
+	PoWNoRetargeting bool
+
 	// GenerateSupported specifies whether or not CPU mining is allowed.
 	GenerateSupported bool
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints []Checkpoint
 
+	// AssumeValid is the hash of a block whose ancestors are assumed to
+	// have valid transaction scripts during the initial block download,
+	// provided it is part of the best known header chain with sufficient
+	// work built on top of it.  Scripts are always validated when it is
+	// nil.
+	AssumeValid *chainhash.Hash
+
+	// MinimumChainWork is the minimum amount of cumulative work the best
+	// chain must have before the initial block download is considered
+	// complete.  Header chains with less work are not downloaded in full,
+	// which protects against low-work chains fed by malicious peers.  There
+	// is no minimum when it is nil.
+	MinimumChainWork *big.Int
+
 	// These fields are related to voting on consensus rule changes as
 	// defined by BIP0009.
 	//
@@ -229,6 +276,52 @@ type Params struct {
 	HDCoinType uint32
 }
 
+// PowAlgorithm returns the proof-of-work algorithm the block at the provided
+// height must be hashed with according to the network's PowSchedule.  Heights
+// prior to the first scheduled activation use the first algorithm.
+func (p *Params) PowAlgorithm(height int32) wire.PowAlgorithm {
+	if len(p.PowSchedule) == 0 {
+		if height >= p.GPUSupportHeight {
+			return wire.PowLyra2Z
+		}
+		return wire.PowScrypt
+	}
+
+	algo := p.PowSchedule[0].Algorithm
+	for _, activation := range p.PowSchedule[1:] {
+		if height < activation.Height {
+			break
+		}
+		algo = activation.Algorithm
+	}
+	return algo
+}
+
+// PowAlgorithms returns the distinct proof-of-work algorithms of the network's
+// PowSchedule ordered from the most to the least recently activated one.  Every
+// block is hashed with one of them, which allows checking the proof of work of
+// blocks whose height is not known yet, such as orphans.
+func (p *Params) PowAlgorithms() []wire.PowAlgorithm {
+	if len(p.PowSchedule) == 0 {
+		if p.GPUSupportHeight <= 0 {
+			return []wire.PowAlgorithm{wire.PowLyra2Z}
+		}
+		return []wire.PowAlgorithm{wire.PowLyra2Z, wire.PowScrypt}
+	}
+
+	algos := make([]wire.PowAlgorithm, 0, len(p.PowSchedule))
+	seen := make(map[wire.PowAlgorithm]struct{}, len(p.PowSchedule))
+	for i := len(p.PowSchedule) - 1; i >= 0; i-- {
+		algo := p.PowSchedule[i].Algorithm
+		if _, ok := seen[algo]; ok {
+			continue
+		}
+		seen[algo] = struct{}{}
+		algos = append(algos, algo)
+	}
+	return algos
+}
+
 // MainNetParams defines the network parameters for the main Actinium network.
 var MainNetParams = Params{
 	Name:        "mainnet",
@@ -242,23 +335,25 @@ var MainNetParams = Params{
 	},
 
 	// Chain parameters
-	GenesisBlock:             &genesisBlock,
-	GenesisHash:              &genesisHash,
-	PowLimit:                 mainPowLimit,
-	PowLimitBits:             504365055,
-	BIP0034Height:            1000,
-	BIP0065Height:            1000,
-	BIP0066Height:            1000,
-	GPUSupportHeight:         55000,
-	ACMZawyLWMAHeight:        85000,
-	CoinbaseMaturity:         100,
-	SubsidyReductionInterval: 840000,
-	TargetTimespan:           (time.Hour * 24 * 3) + (time.Hour * 12), // 3.5 days
-	TargetTimePerBlock:       (time.Minute * 2) + (time.Second * 30),  // 2.5 minutes
-	RetargetAdjustmentFactor: 4,                                       // 25% less, 400% more
-	ReduceMinDifficulty:      false,
-	MinDiffReductionTime:     0,
-	GenerateSupported:        false,
+	GenesisBlock:                  &genesisBlock,
+	GenesisHash:                   &genesisHash,
+	PowLimit:                      mainPowLimit,
+	PowLimitBits:                  504365055,
+	BIP0034Height:                 1000,
+	BIP0065Height:                 1000,
+	BIP0066Height:                 1000,
+	GPUSupportHeight:              55000,
+	ACMZawyLWMAHeight:             85000,
+	ACMZawyLWMAWindow:             45,
+	ACMZawyLWMAMaxSolveTimeFactor: 6,
+	CoinbaseMaturity:              100,
+	SubsidyReductionInterval:      840000,
+	TargetTimespan:                (time.Hour * 24 * 3) + (time.Hour * 12), // 3.5 days
+	TargetTimePerBlock:            (time.Minute * 2) + (time.Second * 30),  // 2.5 minutes
+	RetargetAdjustmentFactor:      4,                                       // 25% less, 400% more
+	ReduceMinDifficulty:           false,
+	MinDiffReductionTime:          0,
+	GenerateSupported:             false,
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{
@@ -288,6 +383,17 @@ var MainNetParams = Params{
 		{170520, newHashFromStr("8816236b6a91806a4d8826fbe4ae400e6a9d594062d225ad074e03f235a6c6ef")},
 	},
 
+	// The latest checkpoint is assumed valid by default.  This should be
+	// moved to a recent block of the best chain for each release.
+	AssumeValid: newHashFromStr("8816236b6a91806a4d8826fbe4ae400e6a9d594062d225ad074e03f235a6c6ef"), // 170520
+
+	// The minimum chain work is a conservative floor of the work of the
+	// chain up to the latest checkpoint, which is 170521 blocks at the
+	// proof of work limit.  The actual work is considerably higher, so
+	// this must be replaced with the cumulative work of the block assumed
+	// valid above for each release.
+	MinimumChainWork: hexToBig("29a1929a19"),
+
 	// Consensus rule change deployments.
 	//
 	// The miner confirmation window is defined as:
@@ -345,23 +451,26 @@ var RegressionNetParams = Params{
 	DNSSeeds:    []DNSSeed{},
 
 	// Chain parameters
-	GenesisBlock:             &regTestGenesisBlock,
-	GenesisHash:              &regTestGenesisHash,
-	PowLimit:                 regressionPowLimit,
-	PowLimitBits:             0x207fffff,
-	CoinbaseMaturity:         100,
-	BIP0034Height:            500,
-	BIP0065Height:            1351, // Used by regression tests
-	BIP0066Height:            1251, // Used by regression tests
-	GPUSupportHeight:         12,
-	ACMZawyLWMAHeight:        65,
-	SubsidyReductionInterval: 150,
-	TargetTimespan:           time.Hour * 24 * 14, // 14 days
-	TargetTimePerBlock:       time.Minute * 10,    // 10 minutes
-	RetargetAdjustmentFactor: 4,                   // 25% less, 400% more
-	ReduceMinDifficulty:      true,
-	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
-	GenerateSupported:        true,
+	GenesisBlock:                  &regTestGenesisBlock,
+	GenesisHash:                   &regTestGenesisHash,
+	PowLimit:                      regressionPowLimit,
+	PowLimitBits:                  0x207fffff,
+	CoinbaseMaturity:              100,
+	BIP0034Height:                 500,
+	BIP0065Height:                 1351, // Used by regression tests
+	BIP0066Height:                 1251, // Used by regression tests
+	GPUSupportHeight:              12,
+	ACMZawyLWMAHeight:             65,
+	ACMZawyLWMAWindow:             45,
+	ACMZawyLWMAMaxSolveTimeFactor: 6,
+	SubsidyReductionInterval:      150,
+	TargetTimespan:                time.Hour * 24 * 14, // 14 days
+	TargetTimePerBlock:            time.Minute * 10,    // 10 minutes
+	RetargetAdjustmentFactor:      4,                   // 25% less, 400% more
+	ReduceMinDifficulty:           true,
+	MinDiffReductionTime:          time.Minute * 20, // TargetTimePerBlock * 2
+	PoWNoRetargeting:              true,
+	GenerateSupported:             true,
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
@@ -421,23 +530,25 @@ var TestNet4Params = Params{
 	DNSSeeds:    []DNSSeed{},
 
 	// Chain parameters
-	GenesisBlock:             &testNet4GenesisBlock,
-	GenesisHash:              &testNet4GenesisHash,
-	PowLimit:                 testNet4PowLimit,
-	PowLimitBits:             504365055,
-	BIP0034Height:            0,
-	BIP0065Height:            0,
-	BIP0066Height:            0,
-	GPUSupportHeight:         2,
-	ACMZawyLWMAHeight:        55,
-	CoinbaseMaturity:         100,
-	SubsidyReductionInterval: 840000,
-	TargetTimespan:           (time.Hour * 24 * 3) + (time.Hour * 12), // 3.5 days
-	TargetTimePerBlock:       (time.Minute * 2) + (time.Second * 30),  // 2.5 minutes
-	RetargetAdjustmentFactor: 4,                                       // 25% less, 400% more
-	ReduceMinDifficulty:      true,
-	MinDiffReductionTime:     time.Minute * 5, // TargetTimePerBlock * 2
-	GenerateSupported:        false,
+	GenesisBlock:                  &testNet4GenesisBlock,
+	GenesisHash:                   &testNet4GenesisHash,
+	PowLimit:                      testNet4PowLimit,
+	PowLimitBits:                  504365055,
+	BIP0034Height:                 0,
+	BIP0065Height:                 0,
+	BIP0066Height:                 0,
+	GPUSupportHeight:              2,
+	ACMZawyLWMAHeight:             55,
+	ACMZawyLWMAWindow:             45,
+	ACMZawyLWMAMaxSolveTimeFactor: 6,
+	CoinbaseMaturity:              100,
+	SubsidyReductionInterval:      840000,
+	TargetTimespan:                (time.Hour * 24 * 3) + (time.Hour * 12), // 3.5 days
+	TargetTimePerBlock:            (time.Minute * 2) + (time.Second * 30),  // 2.5 minutes
+	RetargetAdjustmentFactor:      4,                                       // 25% less, 400% more
+	ReduceMinDifficulty:           true,
+	MinDiffReductionTime:          time.Minute * 5, // TargetTimePerBlock * 2
+	GenerateSupported:             false,
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{
@@ -505,23 +616,25 @@ var SimNetParams = Params{
 	DNSSeeds:    []DNSSeed{}, // NOTE: There must NOT be any seeds.
 
 	// Chain parameters
-	GenesisBlock:             &simNetGenesisBlock,
-	GenesisHash:              &simNetGenesisHash,
-	PowLimit:                 simNetPowLimit,
-	PowLimitBits:             0x207fffff,
-	BIP0034Height:            0, // Always active on simnet
-	BIP0065Height:            0, // Always active on simnet
-	BIP0066Height:            0, // Always active on simnet
-	GPUSupportHeight:         5,
-	ACMZawyLWMAHeight:        200,
-	CoinbaseMaturity:         100,
-	SubsidyReductionInterval: 210000,
-	TargetTimespan:           time.Hour * 24 * 14, // 14 days
-	TargetTimePerBlock:       time.Minute * 10,    // 10 minutes
-	RetargetAdjustmentFactor: 4,                   // 25% less, 400% more
-	ReduceMinDifficulty:      true,
-	MinDiffReductionTime:     time.Minute * 20, // TargetTimePerBlock * 2
-	GenerateSupported:        true,
+	GenesisBlock:                  &simNetGenesisBlock,
+	GenesisHash:                   &simNetGenesisHash,
+	PowLimit:                      simNetPowLimit,
+	PowLimitBits:                  0x207fffff,
+	BIP0034Height:                 0, // Always active on simnet
+	BIP0065Height:                 0, // Always active on simnet
+	BIP0066Height:                 0, // Always active on simnet
+	GPUSupportHeight:              5,
+	ACMZawyLWMAHeight:             200,
+	ACMZawyLWMAWindow:             45,
+	ACMZawyLWMAMaxSolveTimeFactor: 6,
+	CoinbaseMaturity:              100,
+	SubsidyReductionInterval:      210000,
+	TargetTimespan:                time.Hour * 24 * 14, // 14 days
+	TargetTimePerBlock:            time.Minute * 10,    // 10 minutes
+	RetargetAdjustmentFactor:      4,                   // 25% less, 400% more
+	ReduceMinDifficulty:           true,
+	MinDiffReductionTime:          time.Minute * 20, // TargetTimePerBlock * 2
+	GenerateSupported:             true,
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
@@ -698,6 +811,17 @@ func newHashFromStr(hexStr string) *chainhash.Hash {
 	return hash
 }
 
+// hexToBig converts the passed big-endian hex string into a big.Int.  Like
+// newHashFromStr, it panics on an error since it must only be called with
+// hard-coded, and therefore known good, values.
+func hexToBig(hexStr string) *big.Int {
+	n, ok := new(big.Int).SetString(hexStr, 16)
+	if !ok {
+		panic("invalid hex in source file: " + hexStr)
+	}
+	return n
+}
+
 func init() {
 	// Register all default networks when the package is initialized.
 	mustRegister(&MainNetParams)
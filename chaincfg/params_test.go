@@ -4,7 +4,13 @@
 
 package chaincfg
 
-import "testing"
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/Actinium-project/acmd/wire"
+)
 
 // TestInvalidHashStr ensures the newShaHashFromStr function panics when used to
 // with an invalid hash string.
@@ -17,6 +23,33 @@ func TestInvalidHashStr(t *testing.T) {
 	newHashFromStr("banana")
 }
 
+// TestMinimumChainWork ensures the minimum chain work of the main network is
+// not above the work of the chain up to the block assumed valid at the proof
+// of work limit, which the chain can't have less of.
+func TestMinimumChainWork(t *testing.T) {
+	params := &MainNetParams
+	var height int32
+	for _, checkpoint := range params.Checkpoints {
+		if *checkpoint.Hash == *params.AssumeValid {
+			height = checkpoint.Height
+		}
+	}
+	if height == 0 {
+		t.Fatalf("assumed valid block %v is not a checkpoint",
+			params.AssumeValid)
+	}
+
+	oneLsh256 := new(big.Int).Lsh(big.NewInt(1), 256)
+	denominator := new(big.Int).Add(params.PowLimit, big.NewInt(1))
+	workPerBlock := new(big.Int).Div(oneLsh256, denominator)
+	minWork := new(big.Int).Mul(workPerBlock, big.NewInt(int64(height)+1))
+	if params.MinimumChainWork.Cmp(minWork) > 0 {
+		t.Fatalf("minimum chain work %x above the work of %d blocks at "+
+			"the proof of work limit %x", params.MinimumChainWork,
+			height+1, minWork)
+	}
+}
+
 // TestMustRegisterPanic ensures the mustRegister function panics when used to
 // register an invalid network.
 func TestMustRegisterPanic(t *testing.T) {
@@ -33,3 +66,83 @@ func TestMustRegisterPanic(t *testing.T) {
 	// Intentionally try to register duplicate params to force a panic.
 	mustRegister(&MainNetParams)
 }
+
+// TestPowAlgorithm ensures the proof-of-work algorithm switches at the
+// expected heights both for networks relying on GPUSupportHeight and for those
+// with an explicit schedule.
+func TestPowAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	scheduled := Params{
+		GPUSupportHeight: 10,
+		PowSchedule: []PowActivation{
+			{Height: 0, Algorithm: wire.PowScrypt},
+			{Height: 100, Algorithm: wire.PowLyra2Z},
+			{Height: 200, Algorithm: wire.PowScrypt},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		params *Params
+		height int32
+		want   wire.PowAlgorithm
+	}{
+		{"mainnet genesis", &MainNetParams, 0, wire.PowScrypt},
+		{"mainnet before switch", &MainNetParams, 54999, wire.PowScrypt},
+		{"mainnet switch", &MainNetParams, 55000, wire.PowLyra2Z},
+		{"mainnet after switch", &MainNetParams, 170520, wire.PowLyra2Z},
+		{"testnet before switch", &TestNet4Params, 1, wire.PowScrypt},
+		{"testnet switch", &TestNet4Params, 2, wire.PowLyra2Z},
+		{"simnet before switch", &SimNetParams, 4, wire.PowScrypt},
+		{"simnet switch", &SimNetParams, 5, wire.PowLyra2Z},
+		{"schedule ignores GPUSupportHeight", &scheduled, 10, wire.PowScrypt},
+		{"schedule before activation", &scheduled, 99, wire.PowScrypt},
+		{"schedule activation", &scheduled, 100, wire.PowLyra2Z},
+		{"schedule second activation", &scheduled, 200, wire.PowScrypt},
+	}
+
+	for _, test := range tests {
+		got := test.params.PowAlgorithm(test.height)
+		if got != test.want {
+			t.Errorf("%s: wrong algorithm for height %d - got %v, "+
+				"want %v", test.name, test.height, got, test.want)
+		}
+	}
+}
+
+// TestPowAlgorithms ensures the distinct proof-of-work algorithms of a network
+// are returned from the most to the least recently activated one.
+func TestPowAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	scheduled := Params{
+		PowSchedule: []PowActivation{
+			{Height: 0, Algorithm: wire.PowScrypt},
+			{Height: 100, Algorithm: wire.PowLyra2Z},
+			{Height: 200, Algorithm: wire.PowScrypt},
+		},
+	}
+	lyra2zOnly := Params{GPUSupportHeight: 0}
+
+	tests := []struct {
+		name   string
+		params *Params
+		want   []wire.PowAlgorithm
+	}{
+		{"mainnet", &MainNetParams, []wire.PowAlgorithm{wire.PowLyra2Z,
+			wire.PowScrypt}},
+		{"lyra2z from genesis", &lyra2zOnly, []wire.PowAlgorithm{
+			wire.PowLyra2Z}},
+		{"schedule", &scheduled, []wire.PowAlgorithm{wire.PowScrypt,
+			wire.PowLyra2Z}},
+	}
+
+	for _, test := range tests {
+		got := test.params.PowAlgorithms()
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: wrong algorithms - got %v, want %v",
+				test.name, got, test.want)
+		}
+	}
+}
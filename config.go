@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"os"
 	"path/filepath"
@@ -40,6 +41,7 @@ const (
 	defaultLogDirname            = "logs"
 	defaultLogFilename           = "acmd.log"
 	defaultMaxPeers              = 125
+	defaultBlockRelayOnlyPeers   = 2
 	defaultBanDuration           = time.Hour * 24
 	defaultBanThreshold          = 100
 	defaultConnectTimeout        = time.Second * 30
@@ -60,6 +62,10 @@ const (
 	defaultGenerate              = false
 	defaultMaxOrphanTransactions = 100
 	defaultMaxOrphanTxSize       = 100000
+	defaultMaxMempool            = mempool.DefaultMaxMempoolSize / 1000000
+	defaultMempoolExpiry         = mempool.DefaultMempoolExpiry
+	maxMempoolMin                = 5
+	pruneTargetMin               = 550
 	defaultSigCacheMaxSize       = 100000
 	sampleConfigFilename         = "sample-acmd.conf"
 	defaultTxIndex               = false
@@ -102,6 +108,7 @@ type config struct {
 	DisableListen        bool          `long:"nolisten" description:"Disable listening for incoming connections -- NOTE: Listening is automatically disabled if the --connect or --proxy options are used without also specifying listen interfaces via --listen"`
 	Listeners            []string      `long:"listen" description:"Add an interface/port to listen for connections (default all interfaces port: 8333, testnet: 18333)"`
 	MaxPeers             int           `long:"maxpeers" description:"Max number of inbound and outbound peers"`
+	BlockRelayOnlyPeers  int           `long:"blockrelayonlypeers" description:"Number of outbound block-relay-only peers to maintain in addition to the regular outbound peers -- Transactions and addresses are not relayed to or from them, which makes eclipse attacks harder"`
 	DisableBanning       bool          `long:"nobanning" description:"Disable banning of misbehaving peers"`
 	BanDuration          time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	BanThreshold         uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
@@ -136,6 +143,8 @@ type config struct {
 	SimNet               bool          `long:"simnet" description:"Use the simulation test network"`
 	AddCheckpoints       []string      `long:"addcheckpoint" description:"Add a custom checkpoint.  Format: '<height>:<hash>'"`
 	DisableCheckpoints   bool          `long:"nocheckpoints" description:"Disable built-in checkpoints.  Don't do this unless you know what you're doing."`
+	AssumeValid          string        `long:"assumevalid" description:"Hash of a block whose ancestors are assumed to have valid signatures during the initial block download -- Use 0 to validate all signatures (default: network specific)"`
+	MinimumChainWork     string        `long:"minimumchainwork" description:"Minimum cumulative work in hex the best chain must have before the initial block download is considered complete -- Don't lower this unless you know what you're doing (default: network specific)"`
 	DbType               string        `long:"dbtype" description:"Database backend to use for the Block Chain"`
 	Profile              string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	CPUProfile           string        `long:"cpuprofile" description:"Write CPU profile to the specified file"`
@@ -146,6 +155,9 @@ type config struct {
 	NoRelayPriority      bool          `long:"norelaypriority" description:"Do not require free or low-fee transactions to have high priority for relaying"`
 	TrickleInterval      time.Duration `long:"trickleinterval" description:"Minimum time between attempts to send new inventory to a connected peer"`
 	MaxOrphanTxs         int           `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
+	MaxMempool           uint          `long:"maxmempool" description:"Keep the transaction memory pool below the given size in megabytes by evicting the transactions with the lowest fee rate"`
+	MempoolExpiry        time.Duration `long:"mempoolexpiry" description:"Evict transactions that have not been mined within the given duration from the memory pool -- Valid time units are {s, m, h}"`
+	NoPersistMempool     bool          `long:"nopersistmempool" description:"Do not save the memory pool on shutdown and restore it on start up"`
 	Generate             bool          `long:"generate" description:"Generate (mine) bitcoins using the CPU"`
 	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set"`
 	BlockMinSize         uint32        `long:"blockminsize" description:"Mininum block size in bytes to be used when creating a block"`
@@ -163,6 +175,7 @@ type config struct {
 	DropTxIndex          bool          `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits."`
 	AddrIndex            bool          `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available"`
 	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
+	Prune                uint64        `long:"prune" description:"Reduce storage requirements by deleting old block data, keeping the stored blocks below the given size in MiB (0 = disabled, 1 = only prune manually via the pruneblockchain RPC, >=550 = automatically prune)"`
 	RelayNonStd          bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd         bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
 	RejectReplacement    bool          `long:"rejectreplacement" description:"Reject transactions that attempt to replace existing transactions within the mempool through the Replace-By-Fee (RBF) signaling policy."`
@@ -170,6 +183,8 @@ type config struct {
 	oniondial            func(string, string, time.Duration) (net.Conn, error)
 	dial                 func(string, string, time.Duration) (net.Conn, error)
 	addCheckpoints       []chaincfg.Checkpoint
+	assumeValid          *chainhash.Hash
+	minimumChainWork     *big.Int
 	miningAddrs          []acmutil.Address
 	minRelayTxFee        acmutil.Amount
 	whitelists           []*net.IPNet
@@ -372,6 +387,17 @@ func parseCheckpoints(checkpointStrings []string) ([]chaincfg.Checkpoint, error)
 	return checkpoints, nil
 }
 
+// parseMinimumChainWork parses a minimum chain work given as a hexadecimal
+// number with an optional '0x' prefix.
+func parseMinimumChainWork(workStr string) (*big.Int, error) {
+	work, ok := new(big.Int).SetString(strings.TrimPrefix(workStr, "0x"), 16)
+	if !ok || work.Sign() < 0 {
+		return nil, fmt.Errorf("unable to parse minimum chain work %q "+
+			"-- use a hexadecimal number", workStr)
+	}
+	return work, nil
+}
+
 // filesExists reports whether the named file or directory exists.
 func fileExists(name string) bool {
 	if _, err := os.Stat(name); err != nil {
@@ -409,6 +435,7 @@ func loadConfig() (*config, []string, error) {
 		ConfigFile:           defaultConfigFile,
 		DebugLevel:           defaultLogLevel,
 		MaxPeers:             defaultMaxPeers,
+		BlockRelayOnlyPeers:  defaultBlockRelayOnlyPeers,
 		BanDuration:          defaultBanDuration,
 		BanThreshold:         defaultBanThreshold,
 		RPCMaxClients:        defaultMaxRPCClients,
@@ -428,6 +455,8 @@ func loadConfig() (*config, []string, error) {
 		BlockMaxWeight:       defaultBlockMaxWeight,
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MaxMempool:           defaultMaxMempool,
+		MempoolExpiry:        defaultMempoolExpiry,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
 		Generate:             defaultGenerate,
 		TxIndex:              defaultTxIndex,
@@ -632,6 +661,16 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// Don't allow a negative number of block-relay-only peers.
+	if cfg.BlockRelayOnlyPeers < 0 {
+		str := "%s: The blockrelayonlypeers option may not be less " +
+			"than 0 -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.BlockRelayOnlyPeers)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Don't allow ban durations that are too short.
 	if cfg.BanDuration < time.Second {
 		str := "%s: The banduration option may not be less than 1s -- parsed [%v]"
@@ -801,6 +840,26 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Limit the max mempool size to a sane value.
+	if cfg.MaxMempool < maxMempoolMin {
+		str := "%s: The maxmempool option may not be less than %d " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, maxMempoolMin, cfg.MaxMempool)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The mempool expiry may not be negative.
+	if cfg.MempoolExpiry < 0 {
+		str := "%s: The mempoolexpiry option may not be negative " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.MempoolExpiry)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the block priority and minimum block sizes to max block size.
 	cfg.BlockPrioritySize = minUint32(cfg.BlockPrioritySize, cfg.BlockMaxSize)
 	cfg.BlockMinSize = minUint32(cfg.BlockMinSize, cfg.BlockMaxSize)
@@ -867,6 +926,37 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The prune target must leave enough room for the most recent blocks.
+	// A value of 1 enables pruning on request through the RPC server only.
+	if cfg.Prune > 1 && cfg.Prune < pruneTargetMin {
+		str := "%s: The prune option may not be less than %d " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, pruneTargetMin, cfg.Prune)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --prune does not mix with the transaction and address indexes since
+	// they refer to block data that is deleted when pruning.
+	if cfg.Prune != 0 && (cfg.TxIndex || cfg.AddrIndex) {
+		err := fmt.Errorf("%s: the --prune option may not be "+
+			"activated at the same time as the --txindex or "+
+			"--addrindex options because the indexes rely on "+
+			"the full block data", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The committed filter index is built from the full block data as well,
+	// so it can't be caught up once blocks are pruned.  It is enabled by
+	// default, so disable it rather than rejecting the option, which also
+	// stops advertising the filters to peers.
+	if cfg.Prune != 0 {
+		cfg.NoCFilters = true
+	}
+
 	// Check mining addresses are valid and saved parsed versions.
 	cfg.miningAddrs = make([]acmutil.Address, 0, len(cfg.MiningAddrs))
 	for _, strAddr := range cfg.MiningAddrs {
@@ -965,6 +1055,38 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Use the assumed valid block of the active network unless one was
+	// specified, in which case 0 disables it.
+	switch cfg.AssumeValid {
+	case "":
+		cfg.assumeValid = activeNetParams.AssumeValid
+	case "0":
+		cfg.assumeValid = nil
+	default:
+		cfg.assumeValid, err = chainhash.NewHashFromStr(cfg.AssumeValid)
+		if err != nil {
+			str := "%s: Error parsing assumevalid: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
+	// Use the minimum chain work of the active network unless one was
+	// specified.
+	cfg.minimumChainWork = activeNetParams.MinimumChainWork
+	if cfg.MinimumChainWork != "" {
+		work, err := parseMinimumChainWork(cfg.MinimumChainWork)
+		if err != nil {
+			err := fmt.Errorf("%s: %v", funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.minimumChainWork = work
+	}
+
 	// Tor stream isolation requires either proxy or onion proxy to be set.
 	if cfg.TorIsolation && cfg.Proxy == "" && cfg.OnionProxy == "" {
 		str := "%s: Tor stream isolation requires either proxy or " +
@@ -2,6 +2,7 @@ package main
 
 import (
 	"io/ioutil"
+	"math/big"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -70,3 +71,44 @@ func TestCreateDefaultConfigFile(t *testing.T) {
 		t.Error("Could not find rpcpass in generated default config file.")
 	}
 }
+
+// TestParseMinimumChainWork ensures minimum chain work values are parsed as
+// hexadecimal numbers with an optional prefix and invalid values are rejected.
+func TestParseMinimumChainWork(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "0", want: 0},
+		{in: "ff", want: 255},
+		{in: "0x100", want: 256},
+		{in: "00000000000000000000000000000000000000000000000000000001a2b3c4d5",
+			want: 0x1a2b3c4d5},
+		{in: "", wantErr: true},
+		{in: "0x", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "xyz", wantErr: true},
+		{in: "123 ", wantErr: true},
+	}
+
+	for i, test := range tests {
+		work, err := parseMinimumChainWork(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseMinimumChainWork #%d (%q): expected "+
+					"error, got %v", i, test.in, work)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseMinimumChainWork #%d (%q): unexpected "+
+				"error: %v", i, test.in, err)
+			continue
+		}
+		if work.Cmp(big.NewInt(test.want)) != 0 {
+			t.Errorf("parseMinimumChainWork #%d (%q): got %v, want %v",
+				i, test.in, work, test.want)
+		}
+	}
+}
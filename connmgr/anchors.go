@@ -0,0 +1,61 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// anchorsVersion is the current version of the serialized anchors.
+const anchorsVersion = 1
+
+// serializedAnchors is the JSON representation of the anchors.
+type serializedAnchors struct {
+	Version int      `json:"version"`
+	Anchors []string `json:"anchors"`
+}
+
+// LoadAnchors reads the addresses of the anchors, the block-relay-only peers
+// saved on the last shutdown, from the passed file and removes the file
+// afterwards.  Removing it ensures the anchors are only reused when the node
+// shuts down cleanly while connected to them, so a crash caused by one of them
+// can't be repeated on every start.  A missing file is not an error and simply
+// results in no anchors.
+func LoadAnchors(filePath string) ([]string, error) {
+	r, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s error opening file: %v", filePath, err)
+	}
+	defer os.Remove(filePath)
+	defer r.Close()
+
+	var sa serializedAnchors
+	err = json.NewDecoder(r).Decode(&sa)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", filePath, err)
+	}
+	if sa.Version > anchorsVersion {
+		return nil, fmt.Errorf("unknown version %v in serialized anchors",
+			sa.Version)
+	}
+
+	log.Infof("Loaded %d anchors from file '%s'", len(sa.Anchors), filePath)
+	return sa.Anchors, nil
+}
+
+// SaveAnchors writes the passed addresses of the block-relay-only peers to the
+// passed file so they can be loaded as anchors on the next start.
+func SaveAnchors(filePath string, anchors []string) error {
+	sa := serializedAnchors{
+		Version: anchorsVersion,
+		Anchors: anchors,
+	}
+	return writeJSONFile(filePath, &sa)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestAnchors ensures anchors round trip through their file and that the file
+// is removed once they are loaded.
+func TestAnchors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "anchors")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "anchors.json")
+
+	// A missing file results in no anchors.
+	anchors, err := LoadAnchors(filePath)
+	if err != nil {
+		t.Fatalf("LoadAnchors: unexpected error: %v", err)
+	}
+	if len(anchors) != 0 {
+		t.Fatalf("LoadAnchors: got %v, want no anchors", anchors)
+	}
+
+	want := []string{"1.2.3.4:9333", "[2001:db8::1]:9333",
+		"aaaaaaaaaaaaaaaa.onion:9333"}
+	if err := SaveAnchors(filePath, want); err != nil {
+		t.Fatalf("SaveAnchors: unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filePath + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temporary anchors file still exists: %v", err)
+	}
+	anchors, err = LoadAnchors(filePath)
+	if err != nil {
+		t.Fatalf("LoadAnchors: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(anchors, want) {
+		t.Fatalf("LoadAnchors: got %v, want %v", anchors, want)
+	}
+
+	// The anchors must only be used once.
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Fatalf("anchors file still exists after loading: %v", err)
+	}
+
+	// Malformed files are rejected and removed as well.
+	err = ioutil.WriteFile(filePath, []byte("{"), 0600)
+	if err != nil {
+		t.Fatalf("unable to write anchors file: %v", err)
+	}
+	if _, err := LoadAnchors(filePath); err == nil {
+		t.Fatalf("LoadAnchors: expected error for malformed file")
+	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Fatalf("malformed anchors file still exists: %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// banListVersion is the current version of the serialized ban list.
+const banListVersion = 1
+
+var (
+	// ErrSubnetBanned is returned when attempting to ban a subnet which is
+	// already banned.
+	ErrSubnetBanned = errors.New("subnet is already banned")
+
+	// ErrSubnetNotBanned is returned when attempting to unban a subnet
+	// which is not banned.
+	ErrSubnetNotBanned = errors.New("subnet is not banned")
+)
+
+// BanEntry describes a banned subnet along with the time it was banned and the
+// time the ban expires.
+type BanEntry struct {
+	Subnet  *net.IPNet
+	Created time.Time
+	Until   time.Time
+}
+
+// serializedBanEntry is the JSON representation of a ban entry.
+type serializedBanEntry struct {
+	Subnet  string `json:"subnet"`
+	Created int64  `json:"created"`
+	Until   int64  `json:"until"`
+}
+
+// serializedBanList is the JSON representation of a ban list.
+type serializedBanList struct {
+	Version int                   `json:"version"`
+	Bans    []*serializedBanEntry `json:"bans"`
+}
+
+// BanList houses the banned subnets along with their ban expiration times.
+// Every change is written to the file the ban list was created with, so the
+// bans survive restarts.  Expired bans are removed as they are encountered.
+//
+// It is safe for concurrent access.
+type BanList struct {
+	mtx      sync.Mutex
+	filePath string
+	bans     map[string]*BanEntry
+}
+
+// ParseSubnet parses the passed string as either a subnet in CIDR notation or a
+// single IP address, which is treated as a subnet containing only that
+// address.
+func ParseSubnet(s string) (*net.IPNet, error) {
+	if _, subnet, err := net.ParseCIDR(s); err == nil {
+		return subnet, nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address or subnet %q", s)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		bits = 8 * net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// Load reads the bans from the file the ban list was created with.  A missing
+// file is not an error and simply results in an empty ban list.
+func (bl *BanList) Load() error {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	if bl.filePath == "" {
+		return nil
+	}
+	r, err := os.Open(bl.filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s error opening file: %v", bl.filePath, err)
+	}
+	defer r.Close()
+
+	var sbl serializedBanList
+	err = json.NewDecoder(r).Decode(&sbl)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", bl.filePath, err)
+	}
+	if sbl.Version > banListVersion {
+		return fmt.Errorf("unknown version %v in serialized ban list",
+			sbl.Version)
+	}
+
+	now := time.Now()
+	for _, sbe := range sbl.Bans {
+		subnet, err := ParseSubnet(sbe.Subnet)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", bl.filePath,
+				err)
+		}
+		until := time.Unix(sbe.Until, 0)
+		if !now.Before(until) {
+			continue
+		}
+		bl.bans[subnet.String()] = &BanEntry{
+			Subnet:  subnet,
+			Created: time.Unix(sbe.Created, 0),
+			Until:   until,
+		}
+	}
+
+	log.Infof("Loaded %d banned subnets from file '%s'", len(bl.bans),
+		bl.filePath)
+	return nil
+}
+
+// save writes the bans to the file the ban list was created with.
+//
+// This function MUST be called with the ban list lock held.
+func (bl *BanList) save() error {
+	if bl.filePath == "" {
+		return nil
+	}
+
+	sbl := serializedBanList{
+		Version: banListVersion,
+		Bans:    make([]*serializedBanEntry, 0, len(bl.bans)),
+	}
+	for _, entry := range bl.bans {
+		sbl.Bans = append(sbl.Bans, &serializedBanEntry{
+			Subnet:  entry.Subnet.String(),
+			Created: entry.Created.Unix(),
+			Until:   entry.Until.Unix(),
+		})
+	}
+
+	return writeJSONFile(bl.filePath, &sbl)
+}
+
+// removeExpired removes all bans which have expired and returns whether or not
+// any were removed.
+//
+// This function MUST be called with the ban list lock held.
+func (bl *BanList) removeExpired() bool {
+	now := time.Now()
+	var removed bool
+	for key, entry := range bl.bans {
+		if now.Before(entry.Until) {
+			continue
+		}
+
+		log.Infof("Subnet %s is no longer banned", key)
+		delete(bl.bans, key)
+		removed = true
+	}
+	return removed
+}
+
+// Ban bans the passed subnet until the given time.  ErrSubnetBanned is returned
+// if the exact subnet is already banned.
+func (bl *BanList) Ban(subnet *net.IPNet, until time.Time) error {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	bl.removeExpired()
+	key := subnet.String()
+	if _, ok := bl.bans[key]; ok {
+		return ErrSubnetBanned
+	}
+
+	bl.bans[key] = &BanEntry{
+		Subnet:  subnet,
+		Created: time.Now(),
+		Until:   until,
+	}
+	return bl.save()
+}
+
+// Unban removes the ban of the passed subnet.  ErrSubnetNotBanned is returned
+// if the exact subnet is not banned.
+func (bl *BanList) Unban(subnet *net.IPNet) error {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	bl.removeExpired()
+	key := subnet.String()
+	if _, ok := bl.bans[key]; !ok {
+		return ErrSubnetNotBanned
+	}
+
+	delete(bl.bans, key)
+	return bl.save()
+}
+
+// Clear removes all bans.
+func (bl *BanList) Clear() error {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	bl.bans = make(map[string]*BanEntry)
+	return bl.save()
+}
+
+// IsBanned returns whether or not the passed IP address is part of any of the
+// banned subnets.
+func (bl *BanList) IsBanned(ip net.IP) bool {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	if bl.removeExpired() {
+		if err := bl.save(); err != nil {
+			log.Errorf("Unable to save ban list: %v", err)
+		}
+	}
+
+	for _, entry := range bl.bans {
+		if entry.Subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Entries returns all of the bans which have not expired yet ordered by their
+// subnets.
+func (bl *BanList) Entries() []BanEntry {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	if bl.removeExpired() {
+		if err := bl.save(); err != nil {
+			log.Errorf("Unable to save ban list: %v", err)
+		}
+	}
+
+	entries := make([]BanEntry, 0, len(bl.bans))
+	for _, entry := range bl.bans {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Subnet.String() < entries[j].Subnet.String()
+	})
+	return entries
+}
+
+// NewBanList returns an empty ban list which is persisted to the passed file.
+// Persistence is disabled when the file path is empty.  Load must be called to
+// read any previously saved bans.
+func NewBanList(filePath string) *BanList {
+	return &BanList{
+		filePath: filePath,
+		bans:     make(map[string]*BanEntry),
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestParseSubnet ensures single IP addresses and subnets in CIDR notation are
+// parsed properly.
+func TestParseSubnet(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "1.2.3.4", want: "1.2.3.4/32"},
+		{in: "1.2.3.4/24", want: "1.2.3.0/24"},
+		{in: "::ffff:1.2.3.4", want: "1.2.3.4/32"},
+		{in: "2001:db8::1", want: "2001:db8::1/128"},
+		{in: "2001:db8::1/32", want: "2001:db8::/32"},
+		{in: "1.2.3", err: true},
+		{in: "1.2.3.4/33", err: true},
+	}
+
+	for _, test := range tests {
+		subnet, err := ParseSubnet(test.in)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseSubnet(%q): expected error", test.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSubnet(%q): unexpected error: %v",
+				test.in, err)
+			continue
+		}
+		if subnet.String() != test.want {
+			t.Errorf("ParseSubnet(%q): got %v, want %v", test.in,
+				subnet, test.want)
+		}
+	}
+}
+
+// TestBanList ensures banning and unbanning subnets works as expected and that
+// the bans are persisted.
+func TestBanList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "banlist")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "banlist.json")
+
+	mustParseSubnet := func(s string) *net.IPNet {
+		subnet, err := ParseSubnet(s)
+		if err != nil {
+			t.Fatalf("unable to parse subnet %q: %v", s, err)
+		}
+		return subnet
+	}
+	checkBanned := func(bl *BanList, ip string, want bool) {
+		t.Helper()
+		if got := bl.IsBanned(net.ParseIP(ip)); got != want {
+			t.Fatalf("IsBanned(%s): got %v, want %v", ip, got, want)
+		}
+	}
+
+	bl := NewBanList(filePath)
+	if err := bl.Load(); err != nil {
+		t.Fatalf("unable to load missing ban list: %v", err)
+	}
+	until := time.Now().Add(time.Hour)
+	if err := bl.Ban(mustParseSubnet("10.0.0.0/8"), until); err != nil {
+		t.Fatalf("unable to ban subnet: %v", err)
+	}
+	if err := bl.Ban(mustParseSubnet("2001:db8::1"), until); err != nil {
+		t.Fatalf("unable to ban address: %v", err)
+	}
+	err = bl.Ban(mustParseSubnet("10.1.2.3/8"), until)
+	if err != ErrSubnetBanned {
+		t.Fatalf("unexpected error banning subnet twice: got %v, "+
+			"want %v", err, ErrSubnetBanned)
+	}
+	checkBanned(bl, "10.1.2.3", true)
+	checkBanned(bl, "::ffff:10.1.2.3", true)
+	checkBanned(bl, "11.1.2.3", false)
+	checkBanned(bl, "2001:db8::1", true)
+	checkBanned(bl, "2001:db8::2", false)
+
+	// Expired bans must no longer apply and must be removed.
+	expired := mustParseSubnet("192.168.1.1")
+	if err := bl.Ban(expired, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("unable to ban address: %v", err)
+	}
+	checkBanned(bl, "192.168.1.1", false)
+	if entries := bl.Entries(); len(entries) != 2 {
+		t.Fatalf("unexpected number of entries: got %d, want 2",
+			len(entries))
+	}
+
+	// The bans are written through a temporary file which must not be
+	// left behind.
+	if _, err := os.Stat(filePath + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temporary ban list file still exists: %v", err)
+	}
+
+	// The bans must survive reloading the ban list.
+	bl = NewBanList(filePath)
+	if err := bl.Load(); err != nil {
+		t.Fatalf("unable to load ban list: %v", err)
+	}
+	entries := bl.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("unexpected number of loaded entries: got %d, want 2",
+			len(entries))
+	}
+	if entries[0].Subnet.String() != "10.0.0.0/8" ||
+		entries[0].Until.Unix() != until.Unix() {
+
+		t.Fatalf("unexpected loaded entry: %v until %v",
+			entries[0].Subnet, entries[0].Until)
+	}
+
+	// Unbanning must only succeed for banned subnets.
+	if err := bl.Unban(mustParseSubnet("10.0.0.0/8")); err != nil {
+		t.Fatalf("unable to unban subnet: %v", err)
+	}
+	checkBanned(bl, "10.1.2.3", false)
+	err = bl.Unban(mustParseSubnet("10.0.0.0/8"))
+	if err != ErrSubnetNotBanned {
+		t.Fatalf("unexpected error unbanning subnet twice: got %v, "+
+			"want %v", err, ErrSubnetNotBanned)
+	}
+
+	// Clearing the bans must remove them from the file as well.
+	if err := bl.Clear(); err != nil {
+		t.Fatalf("unable to clear bans: %v", err)
+	}
+	bl = NewBanList(filePath)
+	if err := bl.Load(); err != nil {
+		t.Fatalf("unable to load ban list: %v", err)
+	}
+	if entries := bl.Entries(); len(entries) != 0 {
+		t.Fatalf("unexpected entries after clearing: %v", entries)
+	}
+}
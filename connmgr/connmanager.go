@@ -53,13 +53,16 @@ const (
 )
 
 // ConnReq is the connection request to a network address. If permanent, the
-// connection will be retried on disconnection.
+// connection will be retried on disconnection.  Block-relay-only connections
+// are only meant to relay blocks, so neither transactions nor addresses are
+// relayed over them, and they count toward a separate target.
 type ConnReq struct {
 	// The following variables must only be used atomically.
 	id uint64
 
-	Addr      net.Addr
-	Permanent bool
+	Addr           net.Addr
+	Permanent      bool
+	BlockRelayOnly bool
 
 	conn       net.Conn
 	state      ConnState
@@ -124,6 +127,18 @@ type Config struct {
 	// maintain. Defaults to 8.
 	TargetOutbound uint32
 
+	// TargetBlockRelayOnly is the number of outbound block-relay-only
+	// network connections to maintain in addition to TargetOutbound.  They
+	// make eclipse attacks harder since they are not revealed through the
+	// relay of transactions and addresses.  Defaults to 0.
+	TargetBlockRelayOnly uint32
+
+	// Anchors are the addresses the first block-relay-only connections are
+	// made to, which are normally the block-relay-only peers of the
+	// previous run.  Only up to TargetBlockRelayOnly of them are used and
+	// new addresses are used in their place when the connections fail.
+	Anchors []net.Addr
+
 	// RetryDuration is the duration to wait before retrying connection
 	// requests. Defaults to 5s.
 	RetryDuration time.Duration
@@ -142,6 +157,11 @@ type Config struct {
 
 	// Dial connects to the address on the named network. It cannot be nil.
 	Dial func(net.Addr) (net.Conn, error)
+
+	// BanList is the list of banned subnets.  Inbound connections from
+	// banned subnets are closed right away without invoking the OnAccept
+	// handler.  It may be nil if no connections should be refused.
+	BanList *BanList
 }
 
 // registerPending is used to register a pending connection attempt. By
@@ -211,14 +231,26 @@ func (cm *ConnManager) handleFailedConn(c *ConnReq) {
 				"-- retrying connection in: %v", maxFailedAttempts,
 				cm.cfg.RetryDuration)
 			time.AfterFunc(cm.cfg.RetryDuration, func() {
-				cm.NewConnReq()
+				cm.newConnReq(c.BlockRelayOnly)
 			})
 		} else {
-			go cm.NewConnReq()
+			go cm.newConnReq(c.BlockRelayOnly)
 		}
 	}
 }
 
+// numConns returns the number of connections of the given class, that is
+// either block-relay-only or full-relay connections.
+func numConns(conns map[uint64]*ConnReq, blockRelayOnly bool) uint32 {
+	var n uint32
+	for _, connReq := range conns {
+		if connReq.BlockRelayOnly == blockRelayOnly {
+			n++
+		}
+	}
+	return n
+}
+
 // connHandler handles all connection related requests.  It must be run as a
 // goroutine.
 //
@@ -317,12 +349,17 @@ out:
 				}
 
 				// Otherwise, we will attempt a reconnection if
-				// we do not have enough peers, or if this is a
-				// persistent peer. The connection request is
-				// re added to the pending map, so that
-				// subsequent processing of connections and
-				// failures do not ignore the request.
-				if uint32(len(conns)) < cm.cfg.TargetOutbound ||
+				// we do not have enough peers of the same
+				// class, or if this is a persistent peer. The
+				// connection request is re added to the pending
+				// map, so that subsequent processing of
+				// connections and failures do not ignore the
+				// request.
+				target := cm.cfg.TargetOutbound
+				if connReq.BlockRelayOnly {
+					target = cm.cfg.TargetBlockRelayOnly
+				}
+				if numConns(conns, connReq.BlockRelayOnly) < target ||
 					connReq.Permanent {
 
 					connReq.updateState(ConnPending)
@@ -359,6 +396,18 @@ out:
 // NewConnReq creates a new connection request and connects to the
 // corresponding address.
 func (cm *ConnManager) NewConnReq() {
+	cm.newConnReq(false)
+}
+
+// NewBlockRelayOnlyConnReq creates a new block-relay-only connection request
+// and connects to the corresponding address.
+func (cm *ConnManager) NewBlockRelayOnlyConnReq() {
+	cm.newConnReq(true)
+}
+
+// newConnReq creates a new connection request of the given class and connects
+// to the corresponding address.
+func (cm *ConnManager) newConnReq(blockRelayOnly bool) {
 	if atomic.LoadInt32(&cm.stop) != 0 {
 		return
 	}
@@ -366,7 +415,7 @@ func (cm *ConnManager) NewConnReq() {
 		return
 	}
 
-	c := &ConnReq{}
+	c := &ConnReq{BlockRelayOnly: blockRelayOnly}
 	atomic.StoreUint64(&c.id, atomic.AddUint64(&cm.connReqCount, 1))
 
 	// Submit a request of a pending connection attempt to the connection
@@ -499,6 +548,18 @@ func (cm *ConnManager) listenHandler(listener net.Listener) {
 			}
 			continue
 		}
+
+		// Refuse connections from banned subnets.
+		if cm.cfg.BanList != nil {
+			addr, ok := conn.RemoteAddr().(*net.TCPAddr)
+			if ok && cm.cfg.BanList.IsBanned(addr.IP) {
+				log.Debugf("Refusing connection from banned "+
+					"address %s", addr)
+				conn.Close()
+				continue
+			}
+		}
+
 		go cm.cfg.OnAccept(conn)
 	}
 
@@ -526,9 +587,25 @@ func (cm *ConnManager) Start() {
 		}
 	}
 
-	for i := atomic.LoadUint64(&cm.connReqCount); i < uint64(cm.cfg.TargetOutbound); i++ {
+	// Connection requests made before starting, such as those for
+	// persistent peers, count toward the target number of outbound
+	// connections.
+	numReqs := atomic.LoadUint64(&cm.connReqCount)
+	for i := numReqs; i < uint64(cm.cfg.TargetOutbound); i++ {
 		go cm.NewConnReq()
 	}
+
+	// Make the block-relay-only connections, starting with the anchors.
+	for i := uint32(0); i < cm.cfg.TargetBlockRelayOnly; i++ {
+		if i < uint32(len(cm.cfg.Anchors)) {
+			go cm.Connect(&ConnReq{
+				Addr:           cm.cfg.Anchors[i],
+				BlockRelayOnly: true,
+			})
+			continue
+		}
+		go cm.NewBlockRelayOnlyConnReq()
+	}
 }
 
 // Wait blocks until the connection manager halts gracefully.
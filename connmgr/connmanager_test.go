@@ -216,6 +216,76 @@ func TestTargetOutbound(t *testing.T) {
 	cmgr.Stop()
 }
 
+// TestTargetBlockRelayOnly tests the target number of block-relay-only
+// connections and that the anchors are connected to first.
+func TestTargetBlockRelayOnly(t *testing.T) {
+	targetOutbound := uint32(3)
+	targetBlockRelayOnly := uint32(2)
+	anchor := &net.TCPAddr{IP: net.ParseIP("127.0.0.2"), Port: 18555}
+	connected := make(chan *ConnReq)
+	disconnected := make(chan *ConnReq)
+	cmgr, err := New(&Config{
+		TargetOutbound:       targetOutbound,
+		TargetBlockRelayOnly: targetBlockRelayOnly,
+		Anchors:              []net.Addr{anchor},
+		Dial:                 mockDialer,
+		GetNewAddress: func() (net.Addr, error) {
+			return &net.TCPAddr{
+				IP:   net.ParseIP("127.0.0.1"),
+				Port: 18555,
+			}, nil
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+		OnDisconnection: func(c *ConnReq) {
+			disconnected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+
+	var numBlockRelayOnly uint32
+	var anchorReq *ConnReq
+	for i := uint32(0); i < targetOutbound+targetBlockRelayOnly; i++ {
+		c := <-connected
+		if !c.BlockRelayOnly {
+			continue
+		}
+		numBlockRelayOnly++
+		if c.Addr.String() == anchor.String() {
+			anchorReq = c
+		}
+	}
+	if numBlockRelayOnly != targetBlockRelayOnly {
+		t.Fatalf("block-relay-only connections: got %d, want %d",
+			numBlockRelayOnly, targetBlockRelayOnly)
+	}
+	if anchorReq == nil {
+		t.Fatal("no block-relay-only connection to the anchor")
+	}
+
+	select {
+	case c := <-connected:
+		t.Fatalf("target block-relay-only: got unexpected connection - "+
+			"%v", c.Addr)
+	case <-time.After(time.Millisecond):
+		break
+	}
+
+	// A lost block-relay-only connection must be replaced by another one.
+	cmgr.Disconnect(anchorReq.ID())
+	<-disconnected
+	c := <-connected
+	if !c.BlockRelayOnly {
+		t.Fatalf("replacement connection %v is not block-relay-only",
+			c.Addr)
+	}
+	cmgr.Stop()
+}
+
 // TestRetryPermanent tests that permanent connection requests are retried.
 //
 // We make a permanent connection request using Connect, disconnect it using
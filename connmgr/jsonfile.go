@@ -0,0 +1,39 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// writeJSONFile encodes the passed value as JSON to the passed file.  The value
+// is written to a temporary file first which then replaces the file, so a
+// crash while writing never leaves a truncated file behind.
+func writeJSONFile(filePath string, v interface{}) error {
+	tmpPath := filePath + ".tmp"
+	w, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("%s error opening file: %v", tmpPath, err)
+	}
+	err = json.NewEncoder(w).Encode(v)
+	if err == nil {
+		err = w.Sync()
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to encode file %s: %v", filePath, err)
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace file %s: %v", filePath, err)
+	}
+	return nil
+}
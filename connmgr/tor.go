@@ -5,9 +5,11 @@
 package connmgr
 
 import (
+	"encoding/base32"
 	"encoding/binary"
 	"errors"
 	"net"
+	"strings"
 )
 
 const (
@@ -20,6 +22,14 @@ const (
 	torTTLExpired        = 0x06
 	torCmdNotSupported   = 0x07
 	torAddrNotSupported  = 0x08
+
+	// onionSuffix is the suffix of all Tor onion service host names.
+	onionSuffix = ".onion"
+
+	// torV2HostLen and torV3HostLen are the lengths of the base32 encoded
+	// part of Tor v2 and v3 onion service host names respectively.
+	torV2HostLen = 16
+	torV3HostLen = 56
 )
 
 var (
@@ -48,6 +58,26 @@ var (
 	}
 )
 
+// IsOnionHost returns whether or not the passed host is a Tor onion service
+// host name, that is either a 16 character v2 or a 56 character v3 base32
+// encoded address followed by ".onion".  Such hosts can't be resolved and must
+// be dialed through a Tor proxy.
+func IsOnionHost(host string) bool {
+	if !strings.HasSuffix(host, onionSuffix) {
+		return false
+	}
+
+	label := strings.TrimSuffix(host, onionSuffix)
+	if len(label) != torV2HostLen && len(label) != torV3HostLen {
+		return false
+	}
+
+	// Tor uses lowercase host names while the go base32 encoding uses
+	// capitals, so switch case before decoding.
+	_, err := base32.StdEncoding.DecodeString(strings.ToUpper(label))
+	return err == nil
+}
+
 // TorLookupIP uses Tor to resolve DNS via the SOCKS extension they provide for
 // resolution over the Tor network. Tor itself doesn't support ipv6 so this
 // doesn't either.
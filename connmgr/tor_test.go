@@ -0,0 +1,33 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import "testing"
+
+// TestIsOnionHost ensures Tor v2 and v3 onion service host names are detected
+// while other host names are not.
+func TestIsOnionHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"aaaqeayeaudaocaj.onion", true},
+		{"duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion", true},
+		{"DUCKDUCKGOGG42XJOC72X3SJASOWOARFBGCMVFIMAFTT6TWAGSWZCZAD.onion", true},
+		{"aaaqeayeaudaoca.onion", false},
+		{"aaaqeayeaudaoca1.onion", false},
+		{"aaaqeayeaudaocaj.onion.com", false},
+		{"aaaqeayeaudaocaj", false},
+		{"127.0.0.1", false},
+		{"seed.example.com", false},
+	}
+
+	for _, test := range tests {
+		if got := IsOnionHost(test.host); got != test.want {
+			t.Errorf("IsOnionHost(%q): got %v, want %v", test.host,
+				got, test.want)
+		}
+	}
+}
@@ -8,6 +8,7 @@
 package ffldb
 
 import (
+	"bytes"
 	"container/list"
 	"encoding/binary"
 	"fmt"
@@ -15,6 +16,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/Actinium-project/acmd/chaincfg/chainhash"
@@ -307,6 +310,31 @@ func (s *blockStore) deleteFile(fileNum uint32) error {
 	return nil
 }
 
+// removeFile closes the block file for the passed flat file number if it is
+// open and removes it from disk.  It is used when pruning block data and must
+// never be called for the current write file.
+func (s *blockStore) removeFile(fileNum uint32) error {
+	// Close the file under the write lock for the file in case any readers
+	// are currently reading from it so it's not closed out from under
+	// them.
+	s.obfMutex.Lock()
+	if obf, ok := s.openBlockFiles[fileNum]; ok {
+		s.lruMutex.Lock()
+		s.openBlocksLRU.Remove(s.fileNumToLRUElem[fileNum])
+		delete(s.fileNumToLRUElem, fileNum)
+		s.lruMutex.Unlock()
+
+		obf.Lock()
+		_ = obf.file.Close()
+		obf.Unlock()
+
+		delete(s.openBlockFiles, fileNum)
+	}
+	s.obfMutex.Unlock()
+
+	return s.deleteFileFunc(fileNum)
+}
+
 // blockFile attempts to return an existing file handle for the passed flat file
 // number if it is already open as well as marking it as most recently used.  It
 // will also open the file when it's not already open subject to the rules
@@ -587,6 +615,52 @@ func (s *blockStore) readBlockRegion(loc blockLocation, offset, numBytes uint32)
 	return serializedData, nil
 }
 
+// blockFileHashes returns the hashes of all of the blocks stored in the flat
+// file for the passed file number by walking the block records in the file and
+// hashing their headers.  Any partially written record at the end of the file
+// is ignored.
+//
+// Format: <network><block length><serialized block><checksum>
+func (s *blockStore) blockFileHashes(fileNum uint32) ([]chainhash.Hash, error) {
+	blockFile, err := s.blockFile(fileNum)
+	if err != nil {
+		return nil, err
+	}
+	defer blockFile.RUnlock()
+
+	var hashes []chainhash.Hash
+	var record [8 + blockHdrSize]byte
+	var offset int64
+	for {
+		n, err := blockFile.file.ReadAt(record[:], offset)
+		if n < len(record) {
+			if err == nil || err == io.EOF {
+				break
+			}
+			str := fmt.Sprintf("failed to read block record from "+
+				"file %d, offset %d: %v", fileNum, offset, err)
+			return nil, makeDbErr(database.ErrDriverSpecific, str, err)
+		}
+
+		var header wire.BlockHeader
+		err = header.Deserialize(bytes.NewReader(record[8:]))
+		if err != nil {
+			str := fmt.Sprintf("failed to deserialize block header "+
+				"from file %d, offset %d: %v", fileNum, offset,
+				err)
+			return nil, makeDbErr(database.ErrCorruption, str, err)
+		}
+		hashes = append(hashes, header.BlockHash())
+
+		// Skip to the next record which follows the serialized block
+		// and checksum.
+		blockLen := byteOrder.Uint32(record[4:8])
+		offset += 12 + int64(blockLen)
+	}
+
+	return hashes, nil
+}
+
 // syncBlocks performs a file system sync on the flat file associated with the
 // store's current write cursor.  It is safe to call even when there is not a
 // current write file in which case it will have no effect.
@@ -712,6 +786,39 @@ func (s *blockStore) handleRollback(oldBlockFileNum, oldBlockOffset uint32) {
 	}
 }
 
+// oldestBlockFile searches the database directory for the flat block file with
+// the lowest file number.  Block files before it may have been removed by
+// pruning.  It returns -1 when there are no block files.
+func oldestBlockFile(dbPath string) int {
+	filePaths, err := filepath.Glob(filepath.Join(dbPath, "*.fdb"))
+	if err != nil {
+		return -1
+	}
+
+	oldestFile := -1
+	for _, filePath := range filePaths {
+		fileName := strings.TrimSuffix(filepath.Base(filePath), ".fdb")
+		fileNum, err := strconv.ParseUint(fileName, 10, 32)
+		if err != nil {
+			continue
+		}
+		if oldestFile == -1 || int(fileNum) < oldestFile {
+			oldestFile = int(fileNum)
+		}
+	}
+	return oldestFile
+}
+
+// blockFileSize returns the size of the flat block file for the passed file
+// number.  Zero is returned when the file does not exist.
+func blockFileSize(dbPath string, fileNum uint32) uint64 {
+	st, err := os.Stat(blockFilePath(dbPath, fileNum))
+	if err != nil {
+		return 0
+	}
+	return uint64(st.Size())
+}
+
 // scanBlockFiles searches the database directory for all flat block files to
 // find the end of the most recent file.  This position is considered the
 // current write cursor which is also stored in the metadata.  Thus, it is used
@@ -720,7 +827,14 @@ func (s *blockStore) handleRollback(oldBlockFileNum, oldBlockOffset uint32) {
 func scanBlockFiles(dbPath string) (int, uint32) {
 	lastFile := -1
 	fileLen := uint32(0)
-	for i := 0; ; i++ {
+
+	// Start from the oldest block file since the ones before it might have
+	// been pruned.
+	firstFile := oldestBlockFile(dbPath)
+	if firstFile == -1 {
+		firstFile = 0
+	}
+	for i := firstFile; ; i++ {
 		filePath := blockFilePath(dbPath, uint32(i))
 		st, err := os.Stat(filePath)
 		if err != nil {
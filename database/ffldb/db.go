@@ -966,6 +966,9 @@ type transaction struct {
 	pendingBlocks    map[chainhash.Hash]int
 	pendingBlockData []pendingBlock
 
+	// Block files that need to be removed on commit due to pruning.
+	pendingPrunedFiles []uint32
+
 	// Keys that need to be stored or deleted on commit.
 	pendingKeys   *treap.Mutable
 	pendingRemove *treap.Mutable
@@ -1583,6 +1586,106 @@ func (tx *transaction) FetchBlockRegions(regions []database.BlockRegion) ([][]by
 	return blockRegions, nil
 }
 
+// PruneBlocks deletes the oldest flat block files until the total size of all
+// block files is at or below the provided target size in bytes.  Pruning stops
+// at the first block file that contains a block for which the provided keep
+// function returns true.  The current write file is never deleted.  The
+// hashes of the blocks in the deleted files are returned.
+//
+// The entries for the deleted blocks are removed from the block index as part
+// of the transaction while the files themselves are only removed once the
+// transaction has been committed.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxNotWritable if attempted against a read-only transaction
+//   - ErrTxClosed if the transaction has already been closed
+//
+// In addition, returns ErrDriverSpecific if any failures occur when reading the
+// block files.
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) PruneBlocks(targetSize uint64, keep func(hash *chainhash.Hash) bool) ([]chainhash.Hash, error) {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	// Ensure the transaction is writable.
+	if !tx.writable {
+		str := "prune blocks requires a writable database transaction"
+		return nil, makeDbErr(database.ErrTxNotWritable, str, nil)
+	}
+
+	store := tx.db.store
+	oldestFile := oldestBlockFile(store.basePath)
+	if oldestFile == -1 {
+		return nil, nil
+	}
+	wc := store.writeCursor
+	wc.RLock()
+	curFileNum := wc.curFileNum
+	wc.RUnlock()
+
+	// Calculate the total size of all of the block files that have not
+	// already been pruned.
+	pendingPruned := make(map[uint32]struct{}, len(tx.pendingPrunedFiles))
+	for _, fileNum := range tx.pendingPrunedFiles {
+		pendingPruned[fileNum] = struct{}{}
+	}
+	var totalSize uint64
+	for fileNum := uint32(oldestFile); fileNum <= curFileNum; fileNum++ {
+		if _, ok := pendingPruned[fileNum]; ok {
+			continue
+		}
+		totalSize += blockFileSize(store.basePath, fileNum)
+	}
+
+	var pruned []chainhash.Hash
+	for fileNum := uint32(oldestFile); fileNum < curFileNum; fileNum++ {
+		if totalSize <= targetSize {
+			break
+		}
+		if _, ok := pendingPruned[fileNum]; ok {
+			continue
+		}
+
+		hashes, err := store.blockFileHashes(fileNum)
+		if err != nil {
+			return pruned, err
+		}
+		if keep != nil {
+			for i := range hashes {
+				if keep(&hashes[i]) {
+					return pruned, nil
+				}
+			}
+		}
+
+		// Remove the block index entries for all blocks that are
+		// stored in the file.  Blocks that were written to the file by
+		// a write that was later rolled back have no entry.
+		for i := range hashes {
+			hash := &hashes[i]
+			blockRow := tx.blockIdxBucket.Get(hash[:])
+			if blockRow == nil {
+				continue
+			}
+			if deserializeBlockLoc(blockRow).blockFileNum != fileNum {
+				continue
+			}
+			if err := tx.blockIdxBucket.Delete(hash[:]); err != nil {
+				return pruned, err
+			}
+			pruned = append(pruned, *hash)
+		}
+
+		tx.pendingPrunedFiles = append(tx.pendingPrunedFiles, fileNum)
+		totalSize -= blockFileSize(store.basePath, fileNum)
+	}
+
+	return pruned, nil
+}
+
 // close marks the transaction closed then releases any pending data, the
 // underlying snapshot, the transaction read lock, and the write lock when the
 // transaction is writable.
@@ -1592,6 +1695,7 @@ func (tx *transaction) close() {
 	// Clear pending blocks that would have been written on commit.
 	tx.pendingBlocks = nil
 	tx.pendingBlockData = nil
+	tx.pendingPrunedFiles = nil
 
 	// Clear pending keys that would have been written or deleted on commit.
 	tx.pendingKeys = nil
@@ -1666,7 +1770,20 @@ func (tx *transaction) writePendingAndCommit() error {
 
 	// Atomically update the database cache.  The cache automatically
 	// handles flushing to the underlying persistent storage database.
-	return tx.db.cache.commitTx(tx)
+	if err := tx.db.cache.commitTx(tx); err != nil {
+		return err
+	}
+
+	// Remove the block files that were pruned now that the block index no
+	// longer references them.  Failures are only logged since the files
+	// are no longer used and will be removed again on the next prune.
+	for _, fileNum := range tx.pendingPrunedFiles {
+		if err := tx.db.store.removeFile(fileNum); err != nil {
+			log.Warnf("Failed to remove pruned block file %d: %v",
+				fileNum, err)
+		}
+	}
+	return nil
 }
 
 // Commit commits all changes that have been made to the root metadata bucket
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/Actinium-project/acmd/chaincfg"
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
 	"github.com/Actinium-project/acmd/database"
 	"github.com/Actinium-project/acmd/wire"
 	"github.com/Actinium-project/acmutil"
@@ -704,3 +705,133 @@ func TestFailureScenarios(t *testing.T) {
 	// Test various corruption scenarios.
 	testCorruption(tc)
 }
+
+// TestPruneBlocks ensures pruning removes the oldest block files along with
+// their block index entries, stops at blocks that must be kept, and that the
+// database can be reopened with the oldest block files missing.
+func TestPruneBlocks(t *testing.T) {
+	// Create a new database to run tests against.
+	dbPath := filepath.Join(os.TempDir(), "ffldb-pruneblocks")
+	_ = os.RemoveAll(dbPath)
+	idb, err := database.Create(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer os.RemoveAll(dbPath)
+	defer func() {
+		idb.Close()
+	}()
+
+	// Change the maximum file size to a small value to force multiple flat
+	// files with the test data set.
+	store := idb.(*db).store
+	store.maxBlockFileSize = 8192
+
+	blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+	if err != nil {
+		t.Errorf("loadBlocks: Unexpected error: %v", err)
+		return
+	}
+	err = idb.Update(func(tx database.Tx) error {
+		for i, block := range blocks {
+			if err := tx.StoreBlock(block); err != nil {
+				return fmt.Errorf("StoreBlock #%d: %v", i, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Update: unexpected error: %v", err)
+		return
+	}
+
+	// Pruning against a read-only transaction must fail.
+	err = idb.View(func(tx database.Tx) error {
+		_, err := tx.PruneBlocks(0, nil)
+		return err
+	})
+	if !checkDbError(t, "PruneBlocks on read-only tx", err,
+		database.ErrTxNotWritable) {
+		return
+	}
+
+	// Prune everything that is allowed while keeping the blocks from
+	// height 200 onwards.
+	keepHeight := 200
+	keepHashes := make(map[chainhash.Hash]struct{})
+	for _, block := range blocks[keepHeight:] {
+		keepHashes[*block.Hash()] = struct{}{}
+	}
+	var pruned []chainhash.Hash
+	err = idb.Update(func(tx database.Tx) error {
+		var err error
+		pruned, err = tx.PruneBlocks(0, func(hash *chainhash.Hash) bool {
+			_, ok := keepHashes[*hash]
+			return ok
+		})
+		return err
+	})
+	if err != nil {
+		t.Errorf("PruneBlocks: unexpected error: %v", err)
+		return
+	}
+	if len(pruned) == 0 || len(pruned) >= keepHeight {
+		t.Errorf("PruneBlocks: unexpected number of pruned blocks %d",
+			len(pruned))
+		return
+	}
+	for i, hash := range pruned {
+		if hash != *blocks[i].Hash() {
+			t.Errorf("PruneBlocks: pruned block #%d is %v, want %v",
+				i, hash, blocks[i].Hash())
+			return
+		}
+	}
+	if _, err := os.Stat(blockFilePath(dbPath, 0)); !os.IsNotExist(err) {
+		t.Errorf("PruneBlocks: block file 0 was not removed: %v", err)
+		return
+	}
+
+	// Ensure the pruned blocks are gone while the remaining ones are still
+	// available, including after reopening the database.
+	checkBlocks := func() bool {
+		err := idb.View(func(tx database.Tx) error {
+			for i, block := range blocks {
+				_, err := tx.FetchBlock(block.Hash())
+				if i < len(pruned) {
+					if !checkDbError(t, "FetchBlock pruned",
+						err, database.ErrBlockNotFound) {
+						return errSubTestFail
+					}
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("FetchBlock #%d: %v",
+						i, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			if err != errSubTestFail {
+				t.Errorf("%v", err)
+			}
+			return false
+		}
+		return true
+	}
+	if !checkBlocks() {
+		return
+	}
+	if err := idb.Close(); err != nil {
+		t.Errorf("Close: unexpected error: %v", err)
+		return
+	}
+	idb, err = database.Open(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Errorf("Open: unexpected error: %v", err)
+		return
+	}
+	checkBlocks()
+}
@@ -389,6 +389,26 @@ type Tx interface {
 	// implementations.
 	FetchBlockRegions(regions []BlockRegion) ([][]byte, error)
 
+	// PruneBlocks deletes the oldest stored blocks until the total size of
+	// the block storage is at or below the provided target size in bytes.
+	// Blocks are deleted in the order they were stored, and pruning stops
+	// early at the first block for which the provided keep function
+	// returns true, so callers can ensure recent blocks are retained.  The
+	// hashes of the deleted blocks are returned.
+	//
+	// Depending on the backend implementation, blocks might only be
+	// deleted in groups, so the storage can remain somewhat above the
+	// target size.  The deleted blocks are no longer available once the
+	// transaction has been committed.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxNotWritable if attempted against a read-only transaction
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// Other errors are possible depending on the implementation.
+	PruneBlocks(targetSize uint64, keep func(hash *chainhash.Hash) bool) ([]chainhash.Hash, error)
+
 	// ******************************************************************
 	// Methods related to both atomic metadata storage and block storage.
 	// ******************************************************************
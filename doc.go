@@ -34,6 +34,10 @@ Application Options:
       --listen=             Add an interface/port to listen for connections
                             (default all interfaces port: 9333, testnet: 19333)
       --maxpeers=           Max number of inbound and outbound peers (125)
+      --blockrelayonlypeers= Number of outbound block-relay-only peers to
+                            maintain in addition to the regular outbound peers
+                            -- Transactions and addresses are not relayed to or
+                            from them, which makes eclipse attacks harder (2)
       --nobanning           Disable banning of misbehaving peers
       --banduration=        How long to ban misbehaving peers.  Valid time units
                             are {s, m, h}.  Minimum 1 second (24h0m0s)
@@ -79,6 +83,14 @@ Application Options:
       --addcheckpoint=      Add a custom checkpoint.  Format: '<height>:<hash>'
       --nocheckpoints       Disable built-in checkpoints.  Don't do this unless
                             you know what you're doing.
+      --assumevalid=        Hash of a block whose ancestors are assumed to have
+                            valid signatures during the initial block download
+                            -- Use 0 to validate all signatures (default:
+                            network specific)
+      --minimumchainwork=   Minimum cumulative work in hex the best chain must
+                            have before the initial block download is
+                            considered complete -- Don't lower this unless you
+                            know what you're doing (default: network specific)
       --uacomment=          Comment to add to the user agent --
                             See BIP 14 for more information.
       --dbtype=             Database backend to use for the Block Chain (ffldb)
@@ -100,6 +112,14 @@ Application Options:
                             high priority for relaying
       --maxorphantx=        Max number of orphan transactions to keep in memory
                             (100)
+      --maxmempool=         Keep the transaction memory pool below the given
+                            size in megabytes by evicting the transactions with
+                            the lowest fee rate (300)
+      --mempoolexpiry=      Evict transactions that have not been mined within
+                            the given duration from the memory pool -- Valid
+                            time units are {s, m, h} (336h0m0s)
+      --nopersistmempool    Do not save the memory pool on shutdown and restore
+                            it on start up
       --generate            Generate (mine) bitcoins using the CPU
       --miningaddr=         Add the specified payment address to the list of
                             addresses to use for generated blocks -- At least
@@ -116,6 +136,11 @@ Application Options:
       --sigcachemaxsize=    The maximum number of entries in the signature
                             verification cache.
       --blocksonly          Do not accept transactions from remote peers.
+      --prune=              Reduce storage requirements by deleting old block
+                            data, keeping the stored blocks below the given size
+                            in MiB (0 = disabled, 1 = only prune manually via
+                            the pruneblockchain RPC, >=550 = automatically
+                            prune)
       --relaynonstd         Relay non-standard transactions regardless of the
                             default settings for the active network.
       --rejectnonstd        Reject non-standard transactions regardless of the
@@ -19,11 +19,20 @@ import (
 	"github.com/Actinium-project/acmutil"
 )
 
-// solveBlock attempts to find a nonce which makes the passed block header hash
-// to a value less than the target difficulty. When a successful solution is
-// found true is returned and the nonce field of the passed header is updated
-// with the solution. False is returned if no solution exists.
-func solveBlock(header *wire.BlockHeader, targetDifficulty *big.Int) bool {
+// solveBlock attempts to find a nonce which makes the proof-of-work hash of the
+// passed block header, computed with the provided algorithm, a value less than
+// the target difficulty. When a successful solution is found true is returned
+// and the nonce field of the passed header is updated with the solution. False
+// is returned if no solution exists.
+func solveBlock(header *wire.BlockHeader, targetDifficulty *big.Int,
+	powAlgo wire.PowAlgorithm) bool {
+
+	// Ensure the proof-of-work algorithm is supported before spinning up
+	// the solvers.
+	if _, err := header.PowHash(powAlgo); err != nil {
+		return false
+	}
+
 	// sbResult is used by the solver goroutines to send results.
 	type sbResult struct {
 		found bool
@@ -43,8 +52,10 @@ func solveBlock(header *wire.BlockHeader, targetDifficulty *big.Int) bool {
 				return
 			default:
 				hdr.Nonce = i
-				hash := hdr.BlockHash()
-				if blockchain.HashToBig(&hash).Cmp(targetDifficulty) <= 0 {
+				// The algorithm was validated before the solvers
+				// were started, so the hash can't fail here.
+				hash, _ := hdr.PowHash(powAlgo)
+				if blockchain.HashToBig(hash).Cmp(targetDifficulty) <= 0 {
 					select {
 					case results <- sbResult{true, i}:
 						return
@@ -196,7 +207,8 @@ func CreateBlock(prevBlock *acmutil.Block, inclusionTxs []*acmutil.Tx,
 		}
 	}
 
-	found := solveBlock(&block.Header, net.PowLimit)
+	found := solveBlock(&block.Header, net.PowLimit,
+		net.PowAlgorithm(blockHeight))
 	if !found {
 		return nil, errors.New("Unable to solve block")
 	}
@@ -0,0 +1,138 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package lyra2z
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// blake256BlockSize is the block size of BLAKE-256 in bytes.
+const blake256BlockSize = 64
+
+// blake256IV is the initial chain value of BLAKE-256.
+var blake256IV = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+// blake256Constants are the round constants of BLAKE-256 which are the first
+// digits of pi.
+var blake256Constants = [16]uint32{
+	0x243f6a88, 0x85a308d3, 0x13198a2e, 0x03707344,
+	0xa4093822, 0x299f31d0, 0x082efa98, 0xec4e6c89,
+	0x452821e6, 0x38d01377, 0xbe5466cf, 0x34e90c6c,
+	0xc0ac29b7, 0xc97c50dd, 0x3f84d5b5, 0xb5470917,
+}
+
+// blake256Sigma holds the message word permutations used by each round.
+// Rounds past the tenth reuse them from the start.
+var blake256Sigma = [10][16]uint8{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// blake256Rounds is the number of rounds of the full BLAKE-256 function.
+const blake256Rounds = 14
+
+// blake256G is the BLAKE-256 G function applied to the state words at indices
+// a, b, c and d with message words selected by the i-th pair of the passed
+// permutation.
+func blake256G(v *[16]uint32, m *[16]uint32, s *[16]uint8, i, a, b, c, d int) {
+	x, y := s[2*i], s[2*i+1]
+	v[a] += v[b] + (m[x] ^ blake256Constants[y])
+	v[d] = bits.RotateLeft32(v[d]^v[a], -16)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -12)
+	v[a] += v[b] + (m[y] ^ blake256Constants[x])
+	v[d] = bits.RotateLeft32(v[d]^v[a], -8)
+	v[c] += v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -7)
+}
+
+// blake256Compress updates the chain value h with the passed 64-byte block.
+// The counter is the number of message bits hashed so far, including those in
+// the block, or zero when the block only consists of padding.
+func blake256Compress(h *[8]uint32, block []byte, counter uint64) {
+	var m [16]uint32
+	for i := range m {
+		m[i] = binary.BigEndian.Uint32(block[i*4:])
+	}
+
+	var v [16]uint32
+	copy(v[:8], h[:])
+	copy(v[8:], blake256Constants[:8])
+	v[12] ^= uint32(counter)
+	v[13] ^= uint32(counter)
+	v[14] ^= uint32(counter >> 32)
+	v[15] ^= uint32(counter >> 32)
+
+	for r := 0; r < blake256Rounds; r++ {
+		s := &blake256Sigma[r%10]
+		blake256G(&v, &m, s, 0, 0, 4, 8, 12)
+		blake256G(&v, &m, s, 1, 1, 5, 9, 13)
+		blake256G(&v, &m, s, 2, 2, 6, 10, 14)
+		blake256G(&v, &m, s, 3, 3, 7, 11, 15)
+		blake256G(&v, &m, s, 4, 0, 5, 10, 15)
+		blake256G(&v, &m, s, 5, 1, 6, 11, 12)
+		blake256G(&v, &m, s, 6, 2, 7, 8, 13)
+		blake256G(&v, &m, s, 7, 3, 4, 9, 14)
+	}
+
+	for i := range h {
+		h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+// blake256 returns the BLAKE-256 hash of the passed data.  The salt is always
+// zero.
+func blake256(data []byte) [32]byte {
+	h := blake256IV
+	var counter uint64
+	for len(data) >= blake256BlockSize {
+		counter += blake256BlockSize * 8
+		blake256Compress(&h, data[:blake256BlockSize], counter)
+		data = data[blake256BlockSize:]
+	}
+
+	// Pad the remaining data with a one bit, zeros, a one bit which marks
+	// the end of the padding and the 64-bit message length.  A second
+	// block is needed when the remaining data doesn't leave room for the
+	// padding.  Blocks which do not contain any message bits are
+	// compressed with a zero counter.
+	var final [2 * blake256BlockSize]byte
+	n := copy(final[:], data)
+	msgBits := counter + uint64(n)*8
+	final[n] = 0x80
+	if n == 0 {
+		counter = 0
+	} else {
+		counter = msgBits
+	}
+	if n < blake256BlockSize-8 {
+		final[blake256BlockSize-9] |= 0x01
+		binary.BigEndian.PutUint64(final[blake256BlockSize-8:], msgBits)
+		blake256Compress(&h, final[:blake256BlockSize], counter)
+	} else {
+		final[2*blake256BlockSize-9] |= 0x01
+		binary.BigEndian.PutUint64(final[2*blake256BlockSize-8:], msgBits)
+		blake256Compress(&h, final[:blake256BlockSize], counter)
+		blake256Compress(&h, final[blake256BlockSize:], 0)
+	}
+
+	var digest [32]byte
+	for i, word := range h {
+		binary.BigEndian.PutUint32(digest[i*4:], word)
+	}
+	return digest
+}
@@ -0,0 +1,17 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package lyra2z implements the Lyra2Z proof-of-work hash function.
+
+Lyra2Z is the hash Actinium blocks are mined with from GPUSupportHeight on.  It
+hashes the input with BLAKE-256 and then runs the resulting digest through the
+Lyra2 password hashing scheme, using it as both the password and the salt, with
+a time cost of 8 and a memory matrix of 8 rows by 8 columns.
+
+This package is a pure Go implementation which produces the same results as the
+reference C implementation used by the Actinium network and the common mining
+software.
+*/
+package lyra2z
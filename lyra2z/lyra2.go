@@ -0,0 +1,271 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package lyra2z
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	// blockLenInt64 is the number of 64-bit words in the bitrate of the
+	// Lyra2 sponge and hence in each block of the memory matrix.
+	blockLenInt64 = 12
+
+	// blockLenBytes is the number of bytes in each block of the memory
+	// matrix.
+	blockLenBytes = blockLenInt64 * 8
+
+	// blockLenBlake2SafeInt64 is the number of 64-bit words absorbed at a
+	// time while absorbing the password, salt and parameters.
+	blockLenBlake2SafeInt64 = 8
+
+	// blockLenBlake2SafeBytes is the number of bytes absorbed at a time
+	// while absorbing the password, salt and parameters.
+	blockLenBlake2SafeBytes = blockLenBlake2SafeInt64 * 8
+)
+
+// blake2bIV is the BLAKE2b initialization vector which the capacity of the
+// Lyra2 sponge starts out with.
+var blake2bIV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b,
+	0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f,
+	0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+// sponge is the state of the BLAKE2b based sponge Lyra2 is built on.  The
+// first blockLenInt64 words are the bitrate and the remaining words are the
+// capacity.
+type sponge [16]uint64
+
+// newSponge returns a sponge with a zero bitrate and the BLAKE2b IV as the
+// capacity.
+func newSponge() *sponge {
+	var s sponge
+	copy(s[8:], blake2bIV[:])
+	return &s
+}
+
+// g is the BLAKE2b G function without the message words.
+func (s *sponge) g(a, b, c, d int) {
+	s[a] += s[b]
+	s[d] = bits.RotateLeft64(s[d]^s[a], -32)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft64(s[b]^s[c], -24)
+	s[a] += s[b]
+	s[d] = bits.RotateLeft64(s[d]^s[a], -16)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft64(s[b]^s[c], -63)
+}
+
+// round applies a single round of the sponge permutation.
+func (s *sponge) round() {
+	s.g(0, 4, 8, 12)
+	s.g(1, 5, 9, 13)
+	s.g(2, 6, 10, 14)
+	s.g(3, 7, 11, 15)
+	s.g(0, 5, 10, 15)
+	s.g(1, 6, 11, 12)
+	s.g(2, 7, 8, 13)
+	s.g(3, 4, 9, 14)
+}
+
+// permute applies the full 12 round sponge permutation.
+func (s *sponge) permute() {
+	for i := 0; i < 12; i++ {
+		s.round()
+	}
+}
+
+// absorbBlock absorbs a full block of the memory matrix and applies the full
+// permutation.
+func (s *sponge) absorbBlock(in []uint64) {
+	for i := 0; i < blockLenInt64; i++ {
+		s[i] ^= in[i]
+	}
+	s.permute()
+}
+
+// absorbBlockBlake2Safe absorbs a block of the padded password, salt and
+// parameters and applies the full permutation.
+func (s *sponge) absorbBlockBlake2Safe(in []uint64) {
+	for i := 0; i < blockLenBlake2SafeInt64; i++ {
+		s[i] ^= in[i]
+	}
+	s.permute()
+}
+
+// squeeze fills out with output from the sponge.
+func (s *sponge) squeeze(out []byte) {
+	var block [blockLenBytes]byte
+	for len(out) > 0 {
+		for i := 0; i < blockLenInt64; i++ {
+			binary.LittleEndian.PutUint64(block[i*8:], s[i])
+		}
+		n := copy(out, block[:])
+		out = out[n:]
+		if n == blockLenBytes {
+			s.permute()
+		}
+	}
+}
+
+// reducedSqueezeRow0 initializes the first row of the memory matrix with
+// output from the sponge, filling its columns from last to first.
+func (s *sponge) reducedSqueezeRow0(rowOut []uint64, nCols int) {
+	for i := 0; i < nCols; i++ {
+		out := rowOut[(nCols-1-i)*blockLenInt64:]
+		copy(out[:blockLenInt64], s[:blockLenInt64])
+		s.round()
+	}
+}
+
+// reducedDuplexRow1 absorbs the first row of the memory matrix and
+// initializes the second row as the first row XORed with the sponge output,
+// filling its columns from last to first.
+func (s *sponge) reducedDuplexRow1(rowIn, rowOut []uint64, nCols int) {
+	for i := 0; i < nCols; i++ {
+		in := rowIn[i*blockLenInt64:]
+		out := rowOut[(nCols-1-i)*blockLenInt64:]
+		for j := 0; j < blockLenInt64; j++ {
+			s[j] ^= in[j]
+		}
+		s.round()
+		for j := 0; j < blockLenInt64; j++ {
+			out[j] = in[j] ^ s[j]
+		}
+	}
+}
+
+// reducedDuplexRowSetup absorbs the wordwise sum of the rows rowIn and
+// rowInOut, initializes rowOut, filling its columns from last to first, as
+// rowIn XORed with the sponge output and XORs rowInOut with the sponge output
+// rotated by one word.
+func (s *sponge) reducedDuplexRowSetup(rowIn, rowInOut, rowOut []uint64, nCols int) {
+	for i := 0; i < nCols; i++ {
+		in := rowIn[i*blockLenInt64:]
+		inOut := rowInOut[i*blockLenInt64:]
+		out := rowOut[(nCols-1-i)*blockLenInt64:]
+		for j := 0; j < blockLenInt64; j++ {
+			s[j] ^= in[j] + inOut[j]
+		}
+		s.round()
+		for j := 0; j < blockLenInt64; j++ {
+			out[j] = in[j] ^ s[j]
+		}
+		inOut[0] ^= s[blockLenInt64-1]
+		for j := 1; j < blockLenInt64; j++ {
+			inOut[j] ^= s[j-1]
+		}
+	}
+}
+
+// reducedDuplexRow absorbs the wordwise sum of the rows rowIn and rowInOut,
+// XORs rowOut with the sponge output and XORs rowInOut with the sponge output
+// rotated by one word.  Note that rowInOut and rowOut may be the same row.
+func (s *sponge) reducedDuplexRow(rowIn, rowInOut, rowOut []uint64, nCols int) {
+	for i := 0; i < nCols; i++ {
+		in := rowIn[i*blockLenInt64:]
+		inOut := rowInOut[i*blockLenInt64:]
+		out := rowOut[i*blockLenInt64:]
+		for j := 0; j < blockLenInt64; j++ {
+			s[j] ^= in[j] + inOut[j]
+		}
+		s.round()
+		for j := 0; j < blockLenInt64; j++ {
+			out[j] ^= s[j]
+		}
+		inOut[0] ^= s[blockLenInt64-1]
+		for j := 1; j < blockLenInt64; j++ {
+			inOut[j] ^= s[j-1]
+		}
+	}
+}
+
+// lyra2 fills key with the output of the Lyra2 password hashing scheme for the
+// passed password, salt and cost parameters.  The number of rows must be a
+// power of 2.
+func lyra2(key, pwd, salt []byte, timeCost, nRows, nCols int) {
+	// Pad the password, salt and the parameters (the "basil") with 10*1
+	// into whole blocks.
+	nBlocksInput := (len(salt)+len(pwd)+6*8)/blockLenBlake2SafeBytes + 1
+	input := make([]byte, nBlocksInput*blockLenBlake2SafeBytes)
+	offset := copy(input, pwd)
+	offset += copy(input[offset:], salt)
+	basil := [6]int{len(key), len(pwd), len(salt), timeCost, nRows, nCols}
+	for _, param := range basil {
+		binary.LittleEndian.PutUint64(input[offset:], uint64(param))
+		offset += 8
+	}
+	input[offset] = 0x80
+	input[len(input)-1] ^= 0x01
+
+	// Setup phase.  Absorb the padded input and initialize the first two
+	// rows of the memory matrix.
+	rowLen := blockLenInt64 * nCols
+	matrix := make([][]uint64, nRows)
+	for i := range matrix {
+		matrix[i] = make([]uint64, rowLen)
+	}
+	state := newSponge()
+	var block [blockLenBlake2SafeInt64]uint64
+	for i := 0; i < nBlocksInput; i++ {
+		in := input[i*blockLenBlake2SafeBytes:]
+		for j := range block {
+			block[j] = binary.LittleEndian.Uint64(in[j*8:])
+		}
+		state.absorbBlockBlake2Safe(block[:])
+	}
+	state.reducedSqueezeRow0(matrix[0], nCols)
+	state.reducedDuplexRow1(matrix[0], matrix[1], nCols)
+
+	// Fill the remaining rows, revisiting a deterministically chosen
+	// previous row for each one.
+	row, prev, rowa := 2, 1, 0
+	step, window, gap := 1, 2, 1
+	for row < nRows {
+		state.reducedDuplexRowSetup(matrix[prev], matrix[rowa],
+			matrix[row], nCols)
+
+		rowa = (rowa + step) & (window - 1)
+		prev = row
+		row++
+
+		// Double the visitation window once all rows in it have been
+		// visited.
+		if rowa == 0 {
+			step = window + gap
+			window *= 2
+			gap = -gap
+		}
+	}
+
+	// Wandering phase.  Visit the rows in a fixed order while revisiting
+	// pseudorandomly chosen rows.
+	mask := nRows - 1
+	row = 0
+	for tau := 1; tau <= timeCost; tau++ {
+		step = nRows/2 - 1
+		if tau%2 == 0 {
+			step = -1
+		}
+		for {
+			rowa = int(state[0] & uint64(mask))
+			state.reducedDuplexRow(matrix[prev], matrix[rowa],
+				matrix[row], nCols)
+
+			prev = row
+			row = (row + step) & mask
+			if row == 0 {
+				break
+			}
+		}
+	}
+
+	// Wrap-up phase.  Absorb the last revisited row and squeeze the key.
+	state.absorbBlock(matrix[rowa])
+	state.squeeze(key)
+}
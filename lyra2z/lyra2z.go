@@ -0,0 +1,30 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package lyra2z
+
+// Size is the size of a Lyra2Z hash in bytes.
+const Size = 32
+
+const (
+	// timeCost is the number of wandering phase iterations Lyra2Z runs.
+	timeCost = 8
+
+	// numRows is the number of rows in the Lyra2Z memory matrix.  It must
+	// be a power of 2.
+	numRows = 8
+
+	// numCols is the number of blocks in each row of the Lyra2Z memory
+	// matrix.
+	numCols = 8
+)
+
+// Sum returns the Lyra2Z hash of the passed data.
+func Sum(data []byte) [Size]byte {
+	digest := blake256(data)
+
+	var hash [Size]byte
+	lyra2(hash[:], digest[:], digest[:], timeCost, numRows, numCols)
+	return hash
+}
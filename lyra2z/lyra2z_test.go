@@ -0,0 +1,96 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package lyra2z
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// TestBlake256 ensures the BLAKE-256 implementation produces the test vectors
+// from the BLAKE specification, including inputs which require a separate
+// padding block.
+func TestBlake256(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{
+			name: "empty",
+			in:   nil,
+			want: "716f6e863f744b9ac22c97ec7b76ea5f5908bc5b2f67c61510bfc4751384ea7a",
+		},
+		{
+			name: "one zero byte",
+			in:   []byte{0x00},
+			want: "0ce8d4ef4dd7cd8d62dfded9d4edb0a774ae6a41929a74da23109e8f11139c87",
+		},
+		{
+			name: "72 zero bytes",
+			in:   make([]byte, 72),
+			want: "d419bad32d504fb7d44d460c42c5593fe544fa4c135dec31e21bd9abdcc22d41",
+		},
+	}
+
+	for _, test := range tests {
+		got := blake256(test.in)
+		if hex.EncodeToString(got[:]) != test.want {
+			t.Errorf("%s: wrong hash - got %x, want %s", test.name,
+				got, test.want)
+		}
+	}
+}
+
+// TestSum ensures Lyra2Z produces the expected hashes for 80-byte inputs the
+// size of a serialized block header.
+func TestSum(t *testing.T) {
+	sequential := make([]byte, 80)
+	for i := range sequential {
+		sequential[i] = byte(i)
+	}
+
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{
+			name: "zero header",
+			in:   make([]byte, 80),
+			want: "9b63bf262ec6f678d73e101f57dadcfe07b6d1f01c2b6ebfbc84ed3fa2be947d",
+		},
+		{
+			name: "sequential header",
+			in:   sequential,
+			want: "6b0ded5afb3b27cf0e601243ffd9b37ee65331a2d46c7add2a6a826958ab1c0b",
+		},
+	}
+
+	for _, test := range tests {
+		want, err := hex.DecodeString(test.want)
+		if err != nil {
+			t.Errorf("%s: unable to decode expected hash: %v",
+				test.name, err)
+			continue
+		}
+		got := Sum(test.in)
+		if !bytes.Equal(got[:], want) {
+			t.Errorf("%s: wrong hash - got %x, want %s", test.name,
+				got, test.want)
+		}
+	}
+}
+
+// BenchmarkSum benchmarks how long it takes to hash a block header sized
+// input with Lyra2Z.
+func BenchmarkSum(b *testing.B) {
+	header := make([]byte, 80)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Sum(header)
+	}
+}
@@ -178,6 +178,9 @@ type FeeEstimator struct {
 	// Transactions that have been removed from the bins. This allows us to
 	// revert in case of an orphaned block.
 	dropped []*registeredBlock
+
+	// smart provides the estimates returned by EstimateSmartFee.
+	smart *smartFeeEstimator
 }
 
 // NewFeeEstimator creates a FeeEstimator for which at most maxRollback blocks
@@ -192,6 +195,7 @@ func NewFeeEstimator(maxRollback, minRegisteredBlocks uint32) *FeeEstimator {
 		maxReplacements:     estimateFeeMaxReplacements,
 		observed:            make(map[chainhash.Hash]*observedTransaction),
 		dropped:             make([]*registeredBlock, 0, maxRollback),
+		smart:               newSmartFeeEstimator(),
 	}
 }
 
@@ -209,13 +213,15 @@ func (ef *FeeEstimator) ObserveTransaction(t *TxDesc) {
 	hash := *t.Tx.Hash()
 	if _, ok := ef.observed[hash]; !ok {
 		size := uint32(GetTxVirtualSize(t.Tx))
+		feeRate := NewSatoshiPerByte(acmutil.Amount(t.Fee), size)
 
 		ef.observed[hash] = &observedTransaction{
 			hash:     hash,
-			feeRate:  NewSatoshiPerByte(acmutil.Amount(t.Fee), size),
+			feeRate:  feeRate,
 			observed: t.Height,
 			mined:    mining.UnminedHeight,
 		}
+		ef.smart.observeTx(&hash, t.Height, float64(feeRate)*bytePerKb)
 	}
 }
 
@@ -237,6 +243,12 @@ func (ef *FeeEstimator) RegisterBlock(block *acmutil.Block) error {
 	ef.lastKnownHeight = height
 	ef.numBlocksRegistered++
 
+	txHashes := make([]*chainhash.Hash, 0, len(block.Transactions()))
+	for _, t := range block.Transactions() {
+		txHashes = append(txHashes, t.Hash())
+	}
+	ef.smart.processBlock(block.Hash(), height, txHashes)
+
 	// Randomly order txs in block.
 	transactions := make(map[*acmutil.Tx]struct{})
 	for _, t := range block.Transactions() {
@@ -343,10 +355,16 @@ func (ef *FeeEstimator) LastKnownHeight() int32 {
 // deleted if they have been observed too long ago. That means the result
 // of Rollback won't always be exactly the same as if the last block had not
 // happened, but it should be close enough.
+//
+// The smart fee estimates are rolled back independently since they track more
+// blocks, so blocks must be rolled back one at a time starting with the most
+// recent one as they are disconnected from the main chain.
 func (ef *FeeEstimator) Rollback(hash *chainhash.Hash) error {
 	ef.mtx.Lock()
 	defer ef.mtx.Unlock()
 
+	ef.smart.disconnectBlock(hash)
+
 	// Find this block in the stack of recent registered blocks.
 	var n int
 	for n = 1; n <= len(ef.dropped); n++ {
@@ -574,11 +592,37 @@ func (ef *FeeEstimator) EstimateFee(numBlocks uint32) (BtcPerKilobyte, error) {
 	return ef.cached[int(numBlocks)-1].ToBtcPerKb(), nil
 }
 
+// EstimateSmartFee estimates the fee per kilobyte needed for a transaction to
+// be confirmed within the given number of blocks from now based on how quickly
+// transactions paying different fee rates have been confirmed recently.  The
+// number of blocks the estimate is actually for is returned as well, which is
+// lower than the requested number when not enough blocks have been observed
+// yet.  Conservative estimates are less likely to be too low, at the cost of
+// often being higher than necessary.
+func (ef *FeeEstimator) EstimateSmartFee(confTarget uint32,
+	conservative bool) (BtcPerKilobyte, uint32, error) {
+
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	if confTarget > MaxSmartFeeTarget {
+		return -1, 0, fmt.Errorf("can only estimate fees for up to "+
+			"%d blocks from now", MaxSmartFeeTarget)
+	}
+
+	feeRate, blocks, err := ef.smart.estimateSmartFee(int32(confTarget),
+		conservative)
+	if err != nil {
+		return -1, uint32(blocks), err
+	}
+	return BtcPerKilobyte(feeRate * btcPerSatoshi), uint32(blocks), nil
+}
+
 // In case the format for the serialized version of the FeeEstimator changes,
 // we use a version number. If the version number changes, it does not make
 // sense to try to upgrade a previous version to a new version. Instead, just
 // start fee estimation over.
-const estimateFeeSaveVersion = 1
+const estimateFeeSaveVersion = 2
 
 func deserializeRegisteredBlock(r io.Reader, txs map[uint32]*observedTransaction) (*registeredBlock, error) {
 	var lenTransactions uint32
@@ -671,6 +715,9 @@ func (ef *FeeEstimator) Save() FeeEstimatorState {
 		registered.serialize(w, observed)
 	}
 
+	// Smart fee estimator.
+	ef.smart.serialize(w)
+
 	// Commit the tx and return.
 	return FeeEstimatorState(w.Bytes())
 }
@@ -745,5 +792,11 @@ func RestoreFeeEstimator(data FeeEstimatorState) (*FeeEstimator, error) {
 		}
 	}
 
+	// Read the smart fee estimator.
+	ef.smart, err = deserializeSmartFeeEstimator(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return ef, nil
 }
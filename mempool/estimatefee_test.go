@@ -6,6 +6,7 @@ package mempool
 
 import (
 	"bytes"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -26,6 +27,7 @@ func newTestFeeEstimator(binSize, maxReplacements, maxRollback uint32) *FeeEstim
 		maxReplacements:     int32(maxReplacements),
 		observed:            make(map[chainhash.Hash]*observedTransaction),
 		dropped:             make([]*registeredBlock, 0, maxRollback),
+		smart:               newSmartFeeEstimator(),
 	}
 }
 
@@ -422,3 +424,157 @@ func TestDatabase(t *testing.T) {
 		eft.checkSaveAndRestore(estimateHistory[len(estimateHistory)-round-1])
 	}
 }
+
+// TestEstimateSmartFee tests the estimates provided by EstimateSmartFee.
+func TestEstimateSmartFee(t *testing.T) {
+	ef := newTestFeeEstimator(5, 3, 1)
+	eft := estimateFeeTester{ef: ef, t: t}
+
+	// No estimate should be available before any transactions have been
+	// confirmed.
+	if _, _, err := ef.EstimateSmartFee(6, false); err == nil {
+		t.Fatal("EstimateSmartFee: expected an error for an empty " +
+			"estimator")
+	}
+
+	// Targets beyond the longest horizon are invalid.
+	if _, _, err := ef.EstimateSmartFee(MaxSmartFeeTarget+1, false); err == nil {
+		t.Fatal("EstimateSmartFee: expected an error for a target " +
+			"beyond the longest horizon")
+	}
+
+	// Confirm a number of transactions which all pay the same fee rate in
+	// the block after they were observed.
+	const numBlocks = 40
+	var expected BtcPerKilobyte
+	for i := 0; i < numBlocks; i++ {
+		var txs []*wire.MsgTx
+		for j := 0; j < 20; j++ {
+			tx := eft.testTx(1000)
+			ef.ObserveTransaction(tx)
+			txs = append(txs, tx.Tx.MsgTx())
+			expected = expectedFeePerKilobyte(tx)
+		}
+		eft.newBlock(txs)
+	}
+
+	tests := []struct {
+		target       uint32
+		conservative bool
+		blocks       uint32
+	}{
+		// A target of one block is raised to two.
+		{target: 1, conservative: false, blocks: 2},
+		{target: 6, conservative: false, blocks: 6},
+		{target: 6, conservative: true, blocks: 6},
+		// Targets are limited by the number of blocks observed.
+		{target: MaxSmartFeeTarget, conservative: false, blocks: 19},
+	}
+	for _, test := range tests {
+		feeRate, blocks, err := ef.EstimateSmartFee(test.target,
+			test.conservative)
+		if err != nil {
+			t.Errorf("EstimateSmartFee(%d, %v): unexpected error: %v",
+				test.target, test.conservative, err)
+			continue
+		}
+		if blocks != test.blocks {
+			t.Errorf("EstimateSmartFee(%d, %v): got estimate for %d "+
+				"blocks, want %d", test.target, test.conservative,
+				blocks, test.blocks)
+		}
+		if math.Abs(float64(feeRate-expected)) > 1e-12 {
+			t.Errorf("EstimateSmartFee(%d, %v): got fee rate %v, "+
+				"want %v", test.target, test.conservative,
+				feeRate, expected)
+		}
+	}
+
+	// The estimates must survive saving and restoring the estimator.
+	restored, err := RestoreFeeEstimator(ef.Save())
+	if err != nil {
+		t.Fatalf("RestoreFeeEstimator: unexpected error: %v", err)
+	}
+	want, _, _ := ef.EstimateSmartFee(6, true)
+	got, _, err := restored.EstimateSmartFee(6, true)
+	if err != nil || got != want {
+		t.Fatalf("EstimateSmartFee after restore: got %v (err %v), "+
+			"want %v", got, err, want)
+	}
+}
+
+// TestSmartFeeDisconnectBlock ensures disconnecting blocks from the smart fee
+// estimator reverses their confirmations so the blocks replacing them in a
+// reorg are taken into account instead.
+func TestSmartFeeDisconnectBlock(t *testing.T) {
+	se := newSmartFeeEstimator()
+	hash := func(b byte) *chainhash.Hash {
+		return &chainhash.Hash{b}
+	}
+	txHash := hash(0xff)
+	const feeRate = 5000
+	bucket := feeBucketIndex(feeRate)
+
+	// Observe a transaction at height 1 and confirm it at height 2.
+	se.processBlock(hash(1), 1, nil)
+	se.observeTx(txHash, 1, feeRate)
+	se.processBlock(hash(2), 2, []*chainhash.Hash{txHash})
+	if _, ok := se.tracked[*txHash]; ok {
+		t.Fatal("confirmed transaction is still tracked")
+	}
+	if se.short.txCtAvg[bucket] != 1 || se.firstRecordedHeight != 2 {
+		t.Fatalf("unexpected statistics after confirmation: count %v, "+
+			"first recorded height %d", se.short.txCtAvg[bucket],
+			se.firstRecordedHeight)
+	}
+
+	// Disconnecting the block must remove the confirmation and track the
+	// transaction again.
+	se.disconnectBlock(hash(2))
+	if tx, ok := se.tracked[*txHash]; !ok || tx.height != 1 {
+		t.Fatalf("transaction not tracked again after disconnect: %v",
+			tx)
+	}
+	if se.bestHeight != 1 || se.firstRecordedHeight != 0 {
+		t.Fatalf("unexpected best height %d and first recorded height "+
+			"%d after disconnect", se.bestHeight,
+			se.firstRecordedHeight)
+	}
+	for _, stats := range se.allStats() {
+		if math.Abs(stats.txCtAvg[bucket]) > 1e-9 ||
+			math.Abs(stats.feeSum[bucket]) > 1e-6 {
+
+			t.Fatalf("confirmation still counted after disconnect: "+
+				"count %v, fee sum %v", stats.txCtAvg[bucket],
+				stats.feeSum[bucket])
+		}
+		if stats.unconfTxs[stats.unconfIndex(1)][bucket] != 1 {
+			t.Fatal("transaction not counted as unconfirmed after " +
+				"disconnect")
+		}
+	}
+
+	// The replacement blocks at the same height and above must be
+	// processed and confirm the transaction instead.
+	se.processBlock(hash(3), 2, nil)
+	se.processBlock(hash(4), 3, []*chainhash.Hash{txHash})
+	if se.bestHeight != 3 || se.firstRecordedHeight != 3 {
+		t.Fatalf("unexpected best height %d and first recorded height "+
+			"%d after reorg", se.bestHeight, se.firstRecordedHeight)
+	}
+	if math.Abs(se.short.confAvg[1][bucket]-1) > 1e-9 ||
+		se.short.confAvg[0][bucket] != 0 {
+
+		t.Fatalf("unexpected confirmations after reorg: %v within one "+
+			"block, %v within two blocks", se.short.confAvg[0][bucket],
+			se.short.confAvg[1][bucket])
+	}
+
+	// Disconnecting an unknown block must still allow its replacement to
+	// be processed.
+	se.disconnectBlock(hash(5))
+	if se.bestHeight != 2 {
+		t.Fatalf("unexpected best height %d after disconnecting an "+
+			"unknown block", se.bestHeight)
+	}
+}
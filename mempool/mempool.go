@@ -5,6 +5,7 @@
 package mempool
 
 import (
+	"container/heap"
 	"container/list"
 	"fmt"
 	"math"
@@ -48,6 +49,41 @@ const (
 	// can be evicted from the mempool when accepting a transaction
 	// replacement.
 	MaxReplacementEvictions = 100
+
+	// DefaultMaxMempoolSize is the default maximum total size in bytes of
+	// all transactions in the main pool.
+	DefaultMaxMempoolSize = 300 * 1000 * 1000
+
+	// DefaultMempoolExpiry is the default maximum amount of time a
+	// transaction is allowed to stay in the main pool before it expires
+	// and is evicted along with all transactions which depend on it.
+	DefaultMempoolExpiry = time.Hour * 24 * 14
+
+	// maxAncestorCount and maxDescendantCount are the maximum number of
+	// transactions in the main pool a transaction is allowed to have as
+	// unconfirmed ancestors and descendants respectively, including itself.
+	maxAncestorCount   = 25
+	maxDescendantCount = 25
+
+	// maxAncestorSize and maxDescendantSize are the maximum total virtual
+	// size of a transaction along with all of its unconfirmed ancestors and
+	// descendants respectively in the main pool.
+	maxAncestorSize   = 101000
+	maxDescendantSize = 101000
+
+	// txExpireScanInterval is the minimum amount of time in between scans
+	// of the main pool to evict expired transactions.
+	txExpireScanInterval = time.Minute * 5
+
+	// rollingMinFeeHalfLife is the amount of time it takes the rolling
+	// minimum fee to decay to half its value once a block has been
+	// connected since it was last raised.  The decay is sped up while the
+	// pool is well below its maximum size.
+	rollingMinFeeHalfLife = time.Hour * 12
+
+	// rollingMinFeeUpdateInterval is the minimum amount of time in between
+	// updates of the decaying rolling minimum fee.
+	rollingMinFeeUpdateInterval = time.Second * 10
 )
 
 // Tag represents an identifier to use for tagging orphan transactions.  The
@@ -148,6 +184,18 @@ type Policy struct {
 	// transactions using the Replace-By-Fee (RBF) signaling policy into
 	// the mempool.
 	RejectReplacement bool
+
+	// MaxMempoolSize is the maximum total size in bytes of all of the
+	// transactions in the main pool.  When it is exceeded, the packages
+	// with the lowest descendant fee rate are evicted and the minimum fee
+	// required to enter the pool is raised accordingly.  A value of 0
+	// disables the limit.
+	MaxMempoolSize int64
+
+	// MempoolExpiry is the maximum amount of time a transaction is allowed
+	// to stay in the main pool before it is evicted along with all of the
+	// transactions which depend on it.  A value of 0 disables expiry.
+	MempoolExpiry time.Duration
 }
 
 // TxDesc is a descriptor containing a transaction in the mempool along with
@@ -158,6 +206,58 @@ type TxDesc struct {
 	// StartingPriority is the priority of the transaction when it was added
 	// to the pool.
 	StartingPriority float64
+
+	// descendantCount, descendantSize and descendantFees track the number,
+	// total virtual size and total modified fees of the transaction along
+	// with all of its unconfirmed descendants in the pool.  They are used
+	// to evict the packages with the lowest fee rate when the pool is full.
+	descendantCount int64
+	descendantSize  int64
+	descendantFees  int64
+
+	// ancestorCount, ancestorSize and ancestorFees track the number, total
+	// virtual size and total modified fees of the transaction along with
+	// all of its unconfirmed ancestors in the pool.
+	ancestorCount int64
+	ancestorSize  int64
+	ancestorFees  int64
+}
+
+// modifiedFee returns the fee of the transaction modified by its fee delta.
+func (txD *TxDesc) modifiedFee() int64 {
+	return txD.Fee + txD.FeeDelta
+}
+
+// addDescendant adds the passed descendant to the descendant statistics of the
+// transaction.
+func (txD *TxDesc) addDescendant(descendant *TxDesc) {
+	txD.descendantCount++
+	txD.descendantSize += GetTxVirtualSize(descendant.Tx)
+	txD.descendantFees += descendant.modifiedFee()
+}
+
+// removeDescendant removes the passed descendant from the descendant statistics
+// of the transaction.
+func (txD *TxDesc) removeDescendant(descendant *TxDesc) {
+	txD.descendantCount--
+	txD.descendantSize -= GetTxVirtualSize(descendant.Tx)
+	txD.descendantFees -= descendant.modifiedFee()
+}
+
+// addAncestor adds the passed ancestor to the ancestor statistics of the
+// transaction.
+func (txD *TxDesc) addAncestor(ancestor *TxDesc) {
+	txD.ancestorCount++
+	txD.ancestorSize += GetTxVirtualSize(ancestor.Tx)
+	txD.ancestorFees += ancestor.modifiedFee()
+}
+
+// removeAncestor removes the passed ancestor from the ancestor statistics of
+// the transaction.
+func (txD *TxDesc) removeAncestor(ancestor *TxDesc) {
+	txD.ancestorCount--
+	txD.ancestorSize -= GetTxVirtualSize(ancestor.Tx)
+	txD.ancestorFees -= ancestor.modifiedFee()
 }
 
 // orphanTx is normal transaction that references an ancestor transaction
@@ -184,12 +284,26 @@ type TxPool struct {
 	outpoints     map[wire.OutPoint]*acmutil.Tx
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
+	poolSize      int64   // total serialized size of the main pool.
 
 	// nextExpireScan is the time after which the orphan pool will be
 	// scanned in order to evict orphans.  This is NOT a hard deadline as
 	// the scan will only run when an orphan is added to the pool as opposed
 	// to on an unconditional timer.
 	nextExpireScan time.Time
+
+	// nextTxExpireScan is the time after which the main pool will be
+	// scanned in order to evict expired transactions.  Much like the
+	// orphan scan, it only runs when a transaction is added to the pool.
+	nextTxExpireScan time.Time
+
+	// rollingMinFee is the fee rate in atoms/kB a transaction must pay to
+	// be accepted into the pool after packages have been evicted to keep
+	// it within its maximum size.  It is raised on every eviction and
+	// decays back to zero over time once a new block has been connected.
+	rollingMinFee        float64
+	rollingMinFeeHeight  int32
+	lastRollingFeeUpdate time.Time
 }
 
 // Ensure the TxPool type implements the mining.TxSource interface.
@@ -486,12 +600,45 @@ func (mp *TxPool) removeTransaction(tx *acmutil.Tx, removeRedeemers bool) {
 			mp.cfg.AddrIndex.RemoveUnconfirmedTx(txHash)
 		}
 
+		ancestors := mp.txAncestors(tx, nil)
+		var descendants map[chainhash.Hash]*acmutil.Tx
+		if txDesc.descendantCount > 1 {
+			descendants = mp.txDescendants(tx, nil)
+		}
+
 		// Mark the referenced outpoints as unspent by the pool.
 		for _, txIn := range txDesc.Tx.MsgTx().TxIn {
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
 		delete(mp.pool, *txHash)
+		mp.poolSize -= int64(tx.MsgTx().SerializeSize())
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+
+		// The descendant statistics of the ancestors and the ancestor
+		// statistics of any remaining descendants, which only happens
+		// when the transaction is removed without its redeemers, are
+		// reduced by the transaction.  Those ancestors and descendants
+		// which are no longer connected without it are also removed
+		// from the statistics of each other.
+		for hash := range ancestors {
+			mp.pool[hash].removeDescendant(txDesc)
+		}
+		for hash, descendant := range descendants {
+			descendantDesc := mp.pool[hash]
+			descendantDesc.removeAncestor(txDesc)
+			if len(ancestors) == 0 {
+				continue
+			}
+			remaining := mp.txAncestors(descendant, nil)
+			for ancestorHash := range ancestors {
+				if _, ok := remaining[ancestorHash]; ok {
+					continue
+				}
+				ancestorDesc := mp.pool[ancestorHash]
+				ancestorDesc.removeDescendant(descendantDesc)
+				descendantDesc.removeAncestor(ancestorDesc)
+			}
+		}
 	}
 }
 
@@ -528,6 +675,234 @@ func (mp *TxPool) RemoveDoubleSpends(tx *acmutil.Tx) {
 	mp.mtx.Unlock()
 }
 
+// expireTransactions removes all transactions which have been in the main pool
+// for longer than the configured expiry, along with all of the transactions
+// which depend on them, when it's time to scan the pool.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) expireTransactions() {
+	expiry := mp.cfg.Policy.MempoolExpiry
+	now := time.Now()
+	if expiry <= 0 || now.Before(mp.nextTxExpireScan) {
+		return
+	}
+
+	origNumTxns := len(mp.pool)
+	for _, txD := range mp.pool {
+		if now.Sub(txD.Added) > expiry {
+			mp.removeTransaction(txD.Tx, true)
+		}
+	}
+
+	// Set next expiration scan to occur after the scan interval.
+	mp.nextTxExpireScan = now.Add(txExpireScanInterval)
+
+	numTxns := len(mp.pool)
+	if numExpired := origNumTxns - numTxns; numExpired > 0 {
+		log.Debugf("Expired %d %s (remaining: %d)", numExpired,
+			pickNoun(numExpired, "transaction", "transactions"),
+			numTxns)
+	}
+}
+
+// evictionCandidate is a transaction in the main pool along with its
+// descendant fees and size at the time it was added to an eviction heap.
+type evictionCandidate struct {
+	txD  *TxDesc
+	fees int64
+	size int64
+}
+
+// evictionHeap implements heap.Interface for eviction candidates such that the
+// candidate with the lowest descendant fee rate is popped first.  The fee rates
+// are compared by cross multiplying in order to avoid rounding errors.
+type evictionHeap []evictionCandidate
+
+// Len returns the number of candidates in the heap.  It is part of the
+// heap.Interface implementation.
+func (h evictionHeap) Len() int { return len(h) }
+
+// Less returns whether the candidate with index i has a lower descendant fee
+// rate than the candidate with index j.  It is part of the heap.Interface
+// implementation.
+func (h evictionHeap) Less(i, j int) bool {
+	return h[i].fees*h[j].size < h[j].fees*h[i].size
+}
+
+// Swap swaps the candidates at the passed indices in the heap.  It is part of
+// the heap.Interface implementation.
+func (h evictionHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+// Push pushes the passed candidate onto the heap.  It is part of the
+// heap.Interface implementation.
+func (h *evictionHeap) Push(x interface{}) {
+	*h = append(*h, x.(evictionCandidate))
+}
+
+// Pop removes the candidate with the lowest descendant fee rate from the heap
+// and returns it.  It is part of the heap.Interface implementation.
+func (h *evictionHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	candidate := old[n-1]
+	old[n-1] = evictionCandidate{}
+	*h = old[0 : n-1]
+	return candidate
+}
+
+// limitPoolSize evicts the transaction packages with the lowest descendant fee
+// rate, that is the fee rate of a transaction combined with all of its
+// descendants, until the main pool is within its maximum allowed size.  The
+// rolling minimum fee is raised above the fee rate of every evicted package so
+// that transactions paying less than what was just evicted are not accepted
+// again until it decays.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) limitPoolSize() {
+	maxSize := mp.cfg.Policy.MaxMempoolSize
+	if maxSize <= 0 {
+		return
+	}
+
+	if mp.poolSize <= maxSize {
+		return
+	}
+
+	// Order the transactions by their descendant fee rate once.  Evicting
+	// the package with the lowest descendant fee rate only removes
+	// descendants with an even lower fee rate from the packages of its
+	// ancestors, so the descendant fee rates of the remaining transactions
+	// can only increase.  A candidate whose statistics changed since it was
+	// added is therefore pushed again with its current statistics before
+	// the next candidate is considered.
+	candidates := make(evictionHeap, 0, len(mp.pool))
+	for _, txD := range mp.pool {
+		candidates = append(candidates, evictionCandidate{
+			txD:  txD,
+			fees: txD.descendantFees,
+			size: txD.descendantSize,
+		})
+	}
+	heap.Init(&candidates)
+
+	var numEvicted int
+	for mp.poolSize > maxSize && candidates.Len() > 0 {
+		candidate := heap.Pop(&candidates).(evictionCandidate)
+		worst := candidate.txD
+		if txD, ok := mp.pool[*worst.Tx.Hash()]; !ok || txD != worst {
+			continue
+		}
+		if worst.descendantFees != candidate.fees ||
+			worst.descendantSize != candidate.size {
+
+			candidate.fees = worst.descendantFees
+			candidate.size = worst.descendantSize
+			heap.Push(&candidates, candidate)
+			continue
+		}
+
+		// Raise the rolling minimum fee so new transactions must pay
+		// at least the minimum relay fee more than the package being
+		// evicted.
+		feeRate := float64(worst.descendantFees) * 1000 /
+			float64(worst.descendantSize)
+		mp.raiseRollingMinFee(feeRate +
+			float64(mp.cfg.Policy.MinRelayTxFee))
+
+		log.Debugf("Evicting transaction %v and %d descendants "+
+			"(fee_rate=%v sat/kb) to limit pool size",
+			worst.Tx.Hash(), worst.descendantCount-1, int64(feeRate))
+
+		numEvicted += int(worst.descendantCount)
+		mp.removeTransaction(worst.Tx, true)
+	}
+
+	if numEvicted > 0 {
+		log.Debugf("Evicted %d %s to limit pool size (size: %d bytes, "+
+			"minimum fee: %v sat/kb)", numEvicted,
+			pickNoun(numEvicted, "transaction", "transactions"),
+			mp.poolSize, int64(mp.rollingMinFee))
+	}
+}
+
+// raiseRollingMinFee raises the rolling minimum fee to the passed fee rate in
+// atoms/kB if it is higher than the current one.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) raiseRollingMinFee(feeRate float64) {
+	if feeRate <= mp.rollingMinFee {
+		return
+	}
+
+	mp.rollingMinFee = feeRate
+	mp.rollingMinFeeHeight = mp.cfg.BestHeight()
+	mp.lastRollingFeeUpdate = time.Now()
+}
+
+// minFee returns the minimum fee rate in atoms/kB a transaction must pay to be
+// accepted into the main pool due to previous evictions, or zero when there is
+// no such requirement beyond the usual relay policy.  The rolling minimum fee
+// only starts to decay once a block has been connected since it was last
+// raised, and it decays faster when the pool is well below its maximum size.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) minFee() acmutil.Amount {
+	if mp.rollingMinFee == 0 {
+		return 0
+	}
+
+	now := time.Now()
+	if mp.cfg.BestHeight() > mp.rollingMinFeeHeight &&
+		now.Sub(mp.lastRollingFeeUpdate) > rollingMinFeeUpdateInterval {
+
+		halfLife := rollingMinFeeHalfLife
+		maxSize := mp.cfg.Policy.MaxMempoolSize
+		switch {
+		case mp.poolSize < maxSize/4:
+			halfLife /= 4
+		case mp.poolSize < maxSize/2:
+			halfLife /= 2
+		}
+
+		elapsed := now.Sub(mp.lastRollingFeeUpdate)
+		mp.rollingMinFee /= math.Pow(2, elapsed.Seconds()/
+			halfLife.Seconds())
+		mp.lastRollingFeeUpdate = now
+
+		// Drop the requirement altogether once it has decayed to the
+		// point it no longer matters.
+		minRelayTxFee := float64(mp.cfg.Policy.MinRelayTxFee)
+		if mp.rollingMinFee < minRelayTxFee/2 {
+			mp.rollingMinFee = 0
+			return 0
+		}
+	}
+
+	minFee := acmutil.Amount(mp.rollingMinFee)
+	if minFee < mp.cfg.Policy.MinRelayTxFee {
+		minFee = mp.cfg.Policy.MinRelayTxFee
+	}
+	return minFee
+}
+
+// MinFee returns the minimum fee rate in atoms/kB a transaction must currently
+// pay to be accepted into the main pool.  It is the minimum relay fee unless
+// the pool has recently been full, in which case it is the decaying fee rate
+// of the packages that were evicted.  It is suitable for use as the fee filter
+// announced to peers.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MinFee() acmutil.Amount {
+	mp.mtx.Lock()
+	minFee := mp.minFee()
+	mp.mtx.Unlock()
+
+	if minFee < mp.cfg.Policy.MinRelayTxFee {
+		minFee = mp.cfg.Policy.MinRelayTxFee
+	}
+	return minFee
+}
+
 // addTransaction adds the passed transaction to the memory pool.  It should
 // not be called directly as it doesn't perform any validation.  This is a
 // helper for maybeAcceptTransaction.
@@ -547,12 +922,56 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *acmutil
 		StartingPriority: mining.CalcPriority(tx.MsgTx(), utxoView, height),
 	}
 
+	txD.descendantCount = 1
+	txD.descendantSize = GetTxVirtualSize(tx)
+	txD.descendantFees = fee
+	txD.ancestorCount = 1
+	txD.ancestorSize = txD.descendantSize
+	txD.ancestorFees = fee
+
+	// A newly seen transaction can't have any descendants in the pool.
+	// However, transactions that are added back to the pool from
+	// disconnected blocks might already be spent by others in the pool, so
+	// the ancestors those descendants are already connected to without the
+	// transaction are determined before it is added.
+	ancestors := mp.txAncestors(tx, nil)
+	descendants := mp.txDescendants(tx, nil)
+	prevAncestors := make(map[chainhash.Hash]map[chainhash.Hash]*acmutil.Tx,
+		len(descendants))
+	for hash, descendant := range descendants {
+		prevAncestors[hash] = mp.txAncestors(descendant, nil)
+	}
+
 	mp.pool[*tx.Hash()] = txD
 	for _, txIn := range tx.MsgTx().TxIn {
 		mp.outpoints[txIn.PreviousOutPoint] = tx
 	}
+	mp.poolSize += int64(tx.MsgTx().SerializeSize())
 	atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 
+	// Update the statistics of the transaction along with the descendant
+	// statistics of its ancestors and the ancestor statistics of its
+	// descendants, which grow by the transaction and by each other where
+	// they weren't connected before.
+	for hash := range ancestors {
+		ancestor := mp.pool[hash]
+		ancestor.addDescendant(txD)
+		txD.addAncestor(ancestor)
+	}
+	for hash := range descendants {
+		descendant := mp.pool[hash]
+		txD.addDescendant(descendant)
+		descendant.addAncestor(txD)
+		for ancestorHash := range ancestors {
+			if _, ok := prevAncestors[hash][ancestorHash]; ok {
+				continue
+			}
+			ancestor := mp.pool[ancestorHash]
+			ancestor.addDescendant(descendant)
+			descendant.addAncestor(ancestor)
+		}
+	}
+
 	// Add unconfirmed address index entries associated with the transaction
 	// if enabled.
 	if mp.cfg.AddrIndex != nil {
@@ -741,6 +1160,68 @@ func (mp *TxPool) txDescendants(tx *acmutil.Tx,
 	return descendants
 }
 
+// checkPackageLimits ensures the passed transaction would neither have too many
+// or too large unconfirmed ancestors in the pool, nor cause any of them to have
+// too many or too large unconfirmed descendants once it is added.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) checkPackageLimits(tx *acmutil.Tx) error {
+	ancestors := mp.txAncestors(tx, nil)
+	if count := int64(len(ancestors)) + 1; count > maxAncestorCount {
+		str := fmt.Sprintf("transaction %v has too many unconfirmed "+
+			"ancestors (%d > %d)", tx.Hash(), count,
+			maxAncestorCount)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	size := GetTxVirtualSize(tx)
+	ancestorSize := size
+	for hash, ancestor := range ancestors {
+		ancestorSize += GetTxVirtualSize(ancestor)
+
+		ancestorDesc := mp.pool[hash]
+		if count := ancestorDesc.descendantCount + 1; count > maxDescendantCount {
+			str := fmt.Sprintf("transaction %v would give ancestor "+
+				"%v too many unconfirmed descendants (%d > %d)",
+				tx.Hash(), hash, count, maxDescendantCount)
+			return txRuleError(wire.RejectNonstandard, str)
+		}
+		descendantSize := ancestorDesc.descendantSize + size
+		if descendantSize > maxDescendantSize {
+			str := fmt.Sprintf("transaction %v would give ancestor "+
+				"%v unconfirmed descendants which are too large "+
+				"(%d > %d virtual bytes)", tx.Hash(), hash,
+				descendantSize, maxDescendantSize)
+			return txRuleError(wire.RejectNonstandard, str)
+		}
+	}
+	if ancestorSize > maxAncestorSize {
+		str := fmt.Sprintf("transaction %v has unconfirmed ancestors "+
+			"which are too large (%d > %d virtual bytes)", tx.Hash(),
+			ancestorSize, maxAncestorSize)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	return nil
+}
+
+// prioritiseTransaction adds the passed amount to the fee delta of the passed
+// transaction descriptor in the pool and updates the descendant statistics of
+// its ancestors and the ancestor statistics of its descendants accordingly.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) prioritiseTransaction(txD *TxDesc, delta int64) {
+	txD.FeeDelta += delta
+	txD.descendantFees += delta
+	txD.ancestorFees += delta
+	for hash := range mp.txAncestors(txD.Tx, nil) {
+		mp.pool[hash].descendantFees += delta
+	}
+	for hash := range mp.txDescendants(txD.Tx, nil) {
+		mp.pool[hash].ancestorFees += delta
+	}
+}
+
 // txConflicts returns all of the unconfirmed transactions that would become
 // conflicts if we were to accept the given transaction into the mempool. An
 // unconfirmed conflict is known as a transaction that spends an output already
@@ -919,12 +1400,29 @@ func (mp *TxPool) validateReplacement(tx *acmutil.Tx,
 	return conflicts, nil
 }
 
-// maybeAcceptTransaction is the internal function which implements the public
-// MaybeAcceptTransaction.  See the comment for MaybeAcceptTransaction for
-// more details.
+// txValidation houses the details of a transaction that has been deemed valid
+// for acceptance into the pool by validateTransaction.
+type txValidation struct {
+	utxoView   *blockchain.UtxoViewpoint
+	bestHeight int32
+	fee        int64
+	size       int64
+	conflicts  map[chainhash.Hash]*acmutil.Tx
+}
+
+// validateTransaction performs all of the checks required to accept the passed
+// transaction into the pool without modifying the pool, other than updating
+// the rate limiter when rateLimit is set.  The outputs of the optional package
+// transactions are treated as if they were unconfirmed outputs in the pool
+// which allows validating a chain of dependent transactions up front.
+//
+// If the transaction is an orphan, each unknown referenced parent is returned.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) maybeAcceptTransaction(tx *acmutil.Tx, isNew, rateLimit, rejectDupOrphans bool) ([]*chainhash.Hash, *TxDesc, error) {
+func (mp *TxPool) validateTransaction(tx *acmutil.Tx,
+	pkgTxns map[chainhash.Hash]*acmutil.Tx, isNew, rateLimit,
+	rejectDupOrphans bool) ([]*chainhash.Hash, *txValidation, error) {
+
 	txHash := tx.Hash()
 
 	// If a transaction has iwtness data, and segwit isn't active yet, If
@@ -1026,6 +1524,21 @@ func (mp *TxPool) maybeAcceptTransaction(tx *acmutil.Tx, isNew, rateLimit, rejec
 		return nil, nil, err
 	}
 
+	// Populate any inputs which are still missing from the outputs of the
+	// package transactions.
+	for _, txIn := range tx.MsgTx().TxIn {
+		prevOut := &txIn.PreviousOutPoint
+		entry := utxoView.LookupEntry(*prevOut)
+		if entry != nil && !entry.IsSpent() {
+			continue
+		}
+
+		if pkgTx, exists := pkgTxns[prevOut.Hash]; exists {
+			utxoView.AddTxOut(pkgTx, prevOut.Index,
+				mining.UnminedHeight)
+		}
+	}
+
 	// Don't allow the transaction if it exists in the main chain and is not
 	// not already fully spent.
 	prevOut := wire.OutPoint{Hash: *txHash}
@@ -1149,6 +1662,22 @@ func (mp *TxPool) maybeAcceptTransaction(tx *acmutil.Tx, isNew, rateLimit, rejec
 		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
 	}
 
+	// Don't allow new transactions which pay less than the fee rate of
+	// the transactions which were recently evicted to keep the pool within
+	// its maximum size.  Transactions which are being added back to the
+	// memory pool from blocks that have been disconnected during a reorg
+	// are exempted.
+	if mempoolMinFee := mp.minFee(); isNew && mempoolMinFee > 0 {
+		requiredFee := calcMinRequiredTxRelayFee(serializedSize,
+			mempoolMinFee)
+		if txFee < requiredFee {
+			str := fmt.Sprintf("transaction %v has %d fees which is "+
+				"under the required mempool minimum fee of %d",
+				txHash, txFee, requiredFee)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+		}
+	}
+
 	// Require that free transactions have sufficient priority to be mined
 	// in the next block.  Transactions which are being added back to the
 	// memory pool from blocks that have been disconnected during a reorg
@@ -1198,6 +1727,12 @@ func (mp *TxPool) maybeAcceptTransaction(tx *acmutil.Tx, isNew, rateLimit, rejec
 		}
 	}
 
+	// Don't allow the transaction into the mempool when it would make a
+	// package of unconfirmed transactions too long or too large.
+	if err := mp.checkPackageLimits(tx); err != nil {
+		return nil, nil, err
+	}
+
 	// Verify crypto signatures for each input and reject the transaction if
 	// any don't verify.
 	err = blockchain.ValidateTransactionScripts(tx, utxoView,
@@ -1210,21 +1745,55 @@ func (mp *TxPool) maybeAcceptTransaction(tx *acmutil.Tx, isNew, rateLimit, rejec
 		return nil, nil, err
 	}
 
+	return nil, &txValidation{
+		utxoView:   utxoView,
+		bestHeight: bestHeight,
+		fee:        txFee,
+		size:       serializedSize,
+		conflicts:  conflicts,
+	}, nil
+}
+
+// maybeAcceptTransaction is the internal function which implements the public
+// MaybeAcceptTransaction.  See the comment for MaybeAcceptTransaction for
+// more details.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) maybeAcceptTransaction(tx *acmutil.Tx, isNew, rateLimit, rejectDupOrphans bool) ([]*chainhash.Hash, *TxDesc, error) {
+	txHash := tx.Hash()
+
+	missingParents, v, err := mp.validateTransaction(tx, nil, isNew,
+		rateLimit, rejectDupOrphans)
+	if err != nil || len(missingParents) > 0 {
+		return missingParents, nil, err
+	}
+
 	// Now that we've deemed the transaction as valid, we can add it to the
 	// mempool. If it ended up replacing any transactions, we'll remove them
 	// first.
-	for _, conflict := range conflicts {
+	for _, conflict := range v.conflicts {
 		log.Debugf("Replacing transaction %v (fee_rate=%v sat/kb) "+
 			"with %v (fee_rate=%v sat/kb)\n", conflict.Hash(),
 			mp.pool[*conflict.Hash()].FeePerKB, tx.Hash(),
-			txFee*1000/serializedSize)
+			v.fee*1000/v.size)
 
 		// The conflict set should already include the descendants for
 		// each one, so we don't need to remove the redeemers within
 		// this call as they'll be removed eventually.
 		mp.removeTransaction(conflict, false)
 	}
-	txD := mp.addTransaction(utxoView, tx, bestHeight, txFee)
+	txD := mp.addTransaction(v.utxoView, tx, v.bestHeight, v.fee)
+
+	// Evict any expired transactions along with the lowest fee rate
+	// packages if the pool grew beyond its maximum size.  The transaction
+	// is rejected when it ends up being evicted itself.
+	mp.expireTransactions()
+	mp.limitPoolSize()
+	if !mp.isTransactionInPool(txHash) {
+		str := fmt.Sprintf("transaction %v was evicted due to the "+
+			"mempool being full", txHash)
+		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
 
 	log.Debugf("Accepted transaction %v (pool size: %v)", txHash,
 		len(mp.pool))
@@ -1252,6 +1821,84 @@ func (mp *TxPool) MaybeAcceptTransaction(tx *acmutil.Tx, isNew, rateLimit bool)
 	return hashes, txD, err
 }
 
+// TestMempoolAccept determines whether or not the passed transactions would be
+// accepted into the pool without actually adding them to it.  The transactions
+// are treated as a package that is validated in order, so they may spend the
+// outputs of the transactions which precede them.  The results are returned in
+// the same order as the transactions.
+//
+// An error is only returned when something unexpected goes wrong.  Rejected
+// transactions are reported by their results instead.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TestMempoolAccept(txns []*acmutil.Tx) ([]*acmjson.TestMempoolAcceptResult, error) {
+	// Protect concurrent access.
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	pkgTxns := make(map[chainhash.Hash]*acmutil.Tx, len(txns))
+	pkgSpent := make(map[wire.OutPoint]*acmutil.Tx)
+	results := make([]*acmjson.TestMempoolAcceptResult, 0, len(txns))
+	for _, tx := range txns {
+		result := &acmjson.TestMempoolAcceptResult{
+			Txid:  tx.Hash().String(),
+			Wtxid: tx.WitnessHash().String(),
+		}
+		results = append(results, result)
+
+		// The transactions of the package may not double spend each
+		// other since the pool checks only cover the transactions
+		// that are actually in it.
+		var err error
+		for _, txIn := range tx.MsgTx().TxIn {
+			spender, ok := pkgSpent[txIn.PreviousOutPoint]
+			if !ok {
+				continue
+			}
+
+			str := fmt.Sprintf("output %v already spent by "+
+				"transaction %v in the package",
+				txIn.PreviousOutPoint, spender.Hash())
+			err = txRuleError(wire.RejectDuplicate, str)
+			break
+		}
+
+		var missingParents []*chainhash.Hash
+		var v *txValidation
+		if err == nil {
+			missingParents, v, err = mp.validateTransaction(tx,
+				pkgTxns, true, false, true)
+		}
+		if err == nil && len(missingParents) > 0 {
+			str := fmt.Sprintf("transaction %v spends unknown "+
+				"inputs", tx.Hash())
+			err = txRuleError(wire.RejectInvalid, str)
+		}
+		if err != nil {
+			if _, ok := err.(RuleError); !ok {
+				return nil, err
+			}
+			result.RejectReason = err.Error()
+			continue
+		}
+
+		result.Allowed = true
+		result.Vsize = v.size
+		result.Fees = &acmjson.TestMempoolAcceptFees{
+			Base: acmutil.Amount(v.fee).ToBTC(),
+		}
+
+		// Make the outputs of the transaction available to the
+		// remaining transactions of the package.
+		pkgTxns[*tx.Hash()] = tx
+		for _, txIn := range tx.MsgTx().TxIn {
+			pkgSpent[txIn.PreviousOutPoint] = tx
+		}
+	}
+
+	return results, nil
+}
+
 // processOrphans is the internal function which implements the public
 // ProcessOrphans.  See the comment for ProcessOrphans for more details.
 //
@@ -1474,7 +2121,13 @@ func (mp *TxPool) MiningDescs() []*mining.TxDesc {
 	descs := make([]*mining.TxDesc, len(mp.pool))
 	i := 0
 	for _, desc := range mp.pool {
-		descs[i] = &desc.TxDesc
+		// The descriptors are copied since the ancestor statistics
+		// change as transactions are added to and removed from the
+		// pool.
+		miningDesc := desc.TxDesc
+		miningDesc.AncestorFees = desc.ancestorFees
+		miningDesc.AncestorSize = desc.ancestorSize
+		descs[i] = &miningDesc
 		i++
 	}
 	mp.mtx.RUnlock()
@@ -1531,6 +2184,113 @@ func (mp *TxPool) RawMempoolVerbose() map[string]*acmjson.GetRawMempoolVerboseRe
 	return result
 }
 
+// mempoolEntry returns the passed transaction descriptor as a fully populated
+// acmjson result.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) mempoolEntry(desc *TxDesc, bestHeight int32) *acmjson.GetMempoolEntryResult {
+	// Calculate the current priority based on the inputs to the
+	// transaction.  Use zero if one or more of the input transactions
+	// can't be found for some reason.
+	tx := desc.Tx
+	var currentPriority float64
+	utxos, err := mp.fetchInputUtxos(tx)
+	if err == nil {
+		currentPriority = mining.CalcPriority(tx.MsgTx(), utxos,
+			bestHeight+1)
+	}
+
+	entry := &acmjson.GetMempoolEntryResult{
+		Size:             int32(GetTxVirtualSize(tx)),
+		Fee:              acmutil.Amount(desc.Fee).ToBTC(),
+		ModifiedFee:      acmutil.Amount(desc.modifiedFee()).ToBTC(),
+		Time:             desc.Added.Unix(),
+		Height:           int64(desc.Height),
+		StartingPriority: desc.StartingPriority,
+		CurrentPriority:  currentPriority,
+		DescendantCount:  desc.descendantCount,
+		DescendantSize:   desc.descendantSize,
+		DescendantFees:   acmutil.Amount(desc.descendantFees).ToBTC(),
+		AncestorCount:    desc.ancestorCount,
+		AncestorSize:     desc.ancestorSize,
+		AncestorFees:     acmutil.Amount(desc.ancestorFees).ToBTC(),
+		Depends:          make([]string, 0),
+	}
+	for _, txIn := range tx.MsgTx().TxIn {
+		hash := &txIn.PreviousOutPoint.Hash
+		if mp.haveTransaction(hash) {
+			entry.Depends = append(entry.Depends, hash.String())
+		}
+	}
+
+	return entry
+}
+
+// MempoolEntry returns the entry of the passed transaction in the mempool as a
+// fully populated acmjson result.  An error is returned if the transaction is
+// not in the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MempoolEntry(txHash *chainhash.Hash) (*acmjson.GetMempoolEntryResult, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	desc, exists := mp.pool[*txHash]
+	if !exists {
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+
+	return mp.mempoolEntry(desc, mp.cfg.BestHeight()), nil
+}
+
+// MempoolAncestors returns the entries of all of the unconfirmed ancestors of
+// the passed transaction in the mempool keyed by their transaction hashes.  An
+// error is returned if the transaction is not in the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MempoolAncestors(txHash *chainhash.Hash) (map[string]*acmjson.GetMempoolEntryResult, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	desc, exists := mp.pool[*txHash]
+	if !exists {
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+
+	return mp.mempoolEntries(mp.txAncestors(desc.Tx, nil)), nil
+}
+
+// MempoolDescendants returns the entries of all of the unconfirmed descendants
+// of the passed transaction in the mempool keyed by their transaction hashes.
+// An error is returned if the transaction is not in the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MempoolDescendants(txHash *chainhash.Hash) (map[string]*acmjson.GetMempoolEntryResult, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	desc, exists := mp.pool[*txHash]
+	if !exists {
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+
+	return mp.mempoolEntries(mp.txDescendants(desc.Tx, nil)), nil
+}
+
+// mempoolEntries returns the entries of the passed transactions in the mempool
+// keyed by their transaction hashes.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) mempoolEntries(txns map[chainhash.Hash]*acmutil.Tx) map[string]*acmjson.GetMempoolEntryResult {
+	bestHeight := mp.cfg.BestHeight()
+	result := make(map[string]*acmjson.GetMempoolEntryResult, len(txns))
+	for hash := range txns {
+		result[hash.String()] = mp.mempoolEntry(mp.pool[hash], bestHeight)
+	}
+
+	return result
+}
+
 // LastUpdated returns the last time a transaction was added to or removed from
 // the main pool.  It does not include the orphan pool.
 //
@@ -1549,5 +2309,7 @@ func New(cfg *Config) *TxPool {
 		orphansByPrev:  make(map[wire.OutPoint]map[chainhash.Hash]*acmutil.Tx),
 		nextExpireScan: time.Now().Add(orphanExpireScanInterval),
 		outpoints:      make(map[wire.OutPoint]*acmutil.Tx),
+
+		nextTxExpireScan: time.Now().Add(txExpireScanInterval),
 	}
 }
@@ -5,6 +5,8 @@
 package mempool
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"reflect"
 	"strings"
@@ -1528,37 +1530,44 @@ func TestRBF(t *testing.T) {
 			// replaced.
 			name: "exceeds maximum conflicts",
 			setup: func(ctx *testContext) (*acmutil.Tx, []*acmutil.Tx) {
-				const numDescendants = 100
+				// Since a transaction can only have a limited
+				// number of descendants, we'll create several
+				// parents with as many descendants as allowed.
+				const numParents = 5
+				const numDescendants = maxDescendantCount - 1
 				coinbaseOuts := make(
-					[]spendableOutput, numDescendants,
+					[]spendableOutput, numParents,
 				)
-				for i := 0; i < numDescendants; i++ {
+				for i := 0; i < numParents; i++ {
 					tx := ctx.addCoinbaseTx(1)
 					coinbaseOuts[i] = txOutToSpendableOut(tx, 0)
-				}
-				parent := ctx.addSignedTx(
-					coinbaseOuts, numDescendants,
-					defaultFee, true, false,
-				)
-
-				// We'll then spend each output of the parent
-				// transaction with a distinct transaction.
-				for i := uint32(0); i < numDescendants; i++ {
-					out := txOutToSpendableOut(parent, i)
-					outs := []spendableOutput{out}
-					ctx.addSignedTx(
-						outs, 1, defaultFee, false, false,
+					parent := ctx.addSignedTx(
+						coinbaseOuts[i:i+1],
+						numDescendants, defaultFee,
+						true, false,
 					)
+
+					// We'll then spend each output of the
+					// parent transaction with a distinct
+					// transaction.
+					for j := uint32(0); j < numDescendants; j++ {
+						out := txOutToSpendableOut(parent, j)
+						outs := []spendableOutput{out}
+						ctx.addSignedTx(
+							outs, 1, defaultFee,
+							false, false,
+						)
+					}
 				}
 
 				// We'll then create a replacement transaction
-				// by spending one of the coinbase outputs.
-				// Replacing the original spender of the
-				// coinbase output would evict the maximum
-				// number of transactions from the mempool,
-				// however, so we should reject it.
+				// by spending all of the coinbase outputs.
+				// Replacing the original spenders of the
+				// coinbase outputs would evict more than the
+				// maximum number of transactions from the
+				// mempool, however, so we should reject it.
 				tx, err := ctx.harness.CreateSignedTx(
-					coinbaseOuts[:1], 1, defaultFee, false,
+					coinbaseOuts, 1, defaultFee, false,
 				)
 				if err != nil {
 					ctx.t.Fatalf("unable to create "+
@@ -1808,3 +1817,688 @@ func TestRBF(t *testing.T) {
 		}
 	}
 }
+
+// TestMempoolSizeLimit ensures the descendant statistics of transactions are
+// tracked properly and that the packages with the lowest descendant fee rate
+// are evicted once the pool exceeds its maximum size, raising the minimum fee
+// required to enter the pool accordingly.
+func TestMempoolSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+
+	coinbase := ctx.addCoinbaseTx(5)
+	coinbaseOut := func(i uint32) []spendableOutput {
+		return []spendableOutput{txOutToSpendableOut(coinbase, i)}
+	}
+
+	// Create a low fee transaction, a high fee transaction and a low fee
+	// parent whose child pays enough fees to make up for it.
+	lowFeeTx := ctx.addSignedTx(coinbaseOut(0), 1, 1000, false, false)
+	ctx.addSignedTx(coinbaseOut(1), 1, 5000, false, false)
+	parent := ctx.addSignedTx(coinbaseOut(2), 1, 500, false, false)
+	parentOut := []spendableOutput{txOutToSpendableOut(parent, 0)}
+	child := ctx.addSignedTx(parentOut, 1, 10000, false, false)
+
+	// The descendant statistics of the parent must include the child.
+	parentDesc := txPool.pool[*parent.Hash()]
+	wantSize := GetTxVirtualSize(parent) + GetTxVirtualSize(child)
+	if parentDesc.descendantCount != 2 ||
+		parentDesc.descendantSize != wantSize ||
+		parentDesc.descendantFees != 10500 {
+
+		t.Fatalf("unexpected parent descendant stats: count %d, "+
+			"size %d, fees %d", parentDesc.descendantCount,
+			parentDesc.descendantSize, parentDesc.descendantFees)
+	}
+
+	// Removing the child must restore the statistics of the parent and
+	// adding it back must update them once again.
+	txPool.RemoveTransaction(child, false)
+	if parentDesc.descendantCount != 1 || parentDesc.descendantFees != 500 {
+		t.Fatalf("unexpected parent descendant stats after removing "+
+			"child: count %d, fees %d", parentDesc.descendantCount,
+			parentDesc.descendantFees)
+	}
+	_, err = txPool.ProcessTransaction(child, false, false, 0)
+	if err != nil {
+		t.Fatalf("unable to process child transaction: %v", err)
+	}
+	if parentDesc.descendantCount != 2 || parentDesc.descendantFees != 10500 {
+		t.Fatalf("unexpected parent descendant stats after adding "+
+			"child: count %d, fees %d", parentDesc.descendantCount,
+			parentDesc.descendantFees)
+	}
+
+	// No minimum fee is imposed beyond the relay fee before any evictions.
+	if minFee := txPool.MinFee(); minFee != txPool.cfg.Policy.MinRelayTxFee {
+		t.Fatalf("unexpected minimum fee before eviction: got %v, "+
+			"want %v", minFee, txPool.cfg.Policy.MinRelayTxFee)
+	}
+
+	// Limit the pool to roughly its current size, leaving room for the
+	// slight differences in signature sizes, and add another transaction.
+	// The low fee transaction has the lowest descendant fee rate, so it
+	// must be evicted while the parent is protected by its child.
+	txPool.cfg.Policy.MaxMempoolSize = txPool.poolSize + 10
+	newTx := ctx.addSignedTx(coinbaseOut(3), 1, 3000, false, false)
+	testPoolMembership(ctx, lowFeeTx, false, false)
+	testPoolMembership(ctx, parent, false, true)
+	testPoolMembership(ctx, child, false, true)
+	testPoolMembership(ctx, newTx, false, true)
+	if txPool.poolSize > txPool.cfg.Policy.MaxMempoolSize {
+		t.Fatalf("pool size %d exceeds the maximum of %d",
+			txPool.poolSize, txPool.cfg.Policy.MaxMempoolSize)
+	}
+
+	// The minimum fee must now exceed the fee rate of the evicted
+	// transaction by the minimum relay fee.
+	evictedRate := acmutil.Amount(1000 * 1000 / GetTxVirtualSize(lowFeeTx))
+	wantMinFee := evictedRate + txPool.cfg.Policy.MinRelayTxFee
+	if minFee := txPool.MinFee(); minFee < wantMinFee {
+		t.Fatalf("unexpected minimum fee after eviction: got %v, "+
+			"want at least %v", minFee, wantMinFee)
+	}
+
+	// A transaction paying the same fee as the evicted one must now be
+	// rejected due to an insufficient fee.
+	tx, err := harness.CreateSignedTx(coinbaseOut(4), 1, 1000, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	_, err = txPool.ProcessTransaction(tx, false, false, 0)
+	if err == nil {
+		t.Fatalf("expected low fee transaction to be rejected")
+	}
+	code, _ := extractRejectCode(err)
+	if code != wire.RejectInsufficientFee {
+		t.Fatalf("unexpected reject code: got %v, want %v", code,
+			wire.RejectInsufficientFee)
+	}
+	testPoolMembership(ctx, tx, false, false)
+}
+
+// TestMempoolExpiry ensures transactions which have been in the pool for longer
+// than the configured expiry are evicted along with their descendants.
+func TestMempoolExpiry(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+	txPool.cfg.Policy.MempoolExpiry = time.Hour
+
+	coinbase := ctx.addCoinbaseTx(2)
+	coinbaseOut := func(i uint32) []spendableOutput {
+		return []spendableOutput{txOutToSpendableOut(coinbase, i)}
+	}
+	parent := ctx.addSignedTx(coinbaseOut(0), 1, 1000, false, false)
+	parentOut := []spendableOutput{txOutToSpendableOut(parent, 0)}
+	child := ctx.addSignedTx(parentOut, 1, 1000, false, false)
+
+	// Age the parent beyond the expiry and force the next scan.  Adding
+	// another transaction must evict the parent along with its child.
+	txPool.pool[*parent.Hash()].Added = time.Now().Add(-2 * time.Hour)
+	txPool.nextTxExpireScan = time.Now().Add(-time.Second)
+	other := ctx.addSignedTx(coinbaseOut(1), 1, 1000, false, false)
+
+	testPoolMembership(ctx, parent, false, false)
+	testPoolMembership(ctx, child, false, false)
+	testPoolMembership(ctx, other, false, true)
+}
+
+// TestRollingMinFeeDecay ensures the rolling minimum fee only decays once a
+// block has been connected since it was raised and eventually drops back to the
+// minimum relay fee.
+func TestRollingMinFeeDecay(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	txPool.cfg.Policy.MaxMempoolSize = 1000000
+
+	txPool.mtx.Lock()
+	txPool.raiseRollingMinFee(64000)
+	txPool.lastRollingFeeUpdate = time.Now().Add(-3 * time.Hour)
+	txPool.mtx.Unlock()
+
+	// The fee must not decay until a new block is connected.
+	if minFee := txPool.MinFee(); minFee != 64000 {
+		t.Fatalf("unexpected minimum fee: got %v, want %v", minFee,
+			acmutil.Amount(64000))
+	}
+
+	// The pool is empty, so the fee decays with a quarter of the half-life
+	// which means it must have been halved after three hours.
+	harness.chain.SetHeight(harness.chain.BestHeight() + 1)
+	minFee := txPool.MinFee()
+	if minFee < 31900 || minFee > 32000 {
+		t.Fatalf("unexpected decayed minimum fee: got %v, want %v",
+			minFee, acmutil.Amount(32000))
+	}
+
+	// The fee drops back to the relay fee once it has decayed far enough.
+	txPool.mtx.Lock()
+	txPool.lastRollingFeeUpdate = time.Now().Add(-48 * time.Hour)
+	txPool.mtx.Unlock()
+	if minFee := txPool.MinFee(); minFee != txPool.cfg.Policy.MinRelayTxFee {
+		t.Fatalf("unexpected minimum fee: got %v, want %v", minFee,
+			txPool.cfg.Policy.MinRelayTxFee)
+	}
+	if txPool.rollingMinFee != 0 {
+		t.Fatalf("rolling minimum fee was not reset: %v",
+			txPool.rollingMinFee)
+	}
+}
+
+// TestSaveLoad ensures transactions saved from the pool are restored along with
+// the time they were added, while transactions which are no longer valid or
+// would have expired are skipped.
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+	txPool.cfg.Policy.MempoolExpiry = 24 * time.Hour
+
+	coinbase := ctx.addCoinbaseTx(3)
+	coinbaseOut := func(i uint32) []spendableOutput {
+		return []spendableOutput{txOutToSpendableOut(coinbase, i)}
+	}
+	parent := ctx.addSignedTx(coinbaseOut(0), 1, 1000, false, false)
+	parentOut := []spendableOutput{txOutToSpendableOut(parent, 0)}
+	child := ctx.addSignedTx(parentOut, 1, 1000, false, false)
+	spent := ctx.addSignedTx(coinbaseOut(1), 1, 1000, false, false)
+	expired := ctx.addSignedTx(coinbaseOut(2), 1, 1000, false, false)
+
+	// Make the child appear older than its parent to ensure parents are
+	// always saved first, and age another transaction beyond the expiry.
+	// Times are saved with a resolution of one second.
+	parentAdded := time.Unix(time.Now().Add(-time.Hour).Unix(), 0)
+	childAdded := parentAdded.Add(-time.Minute)
+	txPool.pool[*parent.Hash()].Added = parentAdded
+	txPool.pool[*child.Hash()].Added = childAdded
+	txPool.pool[*expired.Hash()].Added = time.Now().Add(-48 * time.Hour)
+
+	// Prioritise the child so its fee delta must be restored as well.
+	const childFeeDelta = 5000
+	txPool.prioritiseTransaction(txPool.pool[*child.Hash()], childFeeDelta)
+
+	var buf bytes.Buffer
+	numSaved, err := txPool.Save(&buf)
+	if err != nil {
+		t.Fatalf("unable to save pool: %v", err)
+	}
+	if numSaved != 4 {
+		t.Fatalf("unexpected number of saved transactions: got %d, "+
+			"want %d", numSaved, 4)
+	}
+
+	// Empty the pool and spend the input of one of the transactions in
+	// the chain so it is no longer valid when restored.
+	for _, tx := range []*acmutil.Tx{parent, spent, expired} {
+		txPool.RemoveTransaction(tx, true)
+	}
+	if count := txPool.Count(); count != 0 {
+		t.Fatalf("unexpected pool count after removal: %d", count)
+	}
+	harness.chain.utxos.LookupEntry(wire.OutPoint{
+		Hash:  *coinbase.Hash(),
+		Index: 1,
+	}).Spend()
+
+	numLoaded, err := txPool.Load(&buf)
+	if err != nil {
+		t.Fatalf("unable to load pool: %v", err)
+	}
+	if numLoaded != 2 {
+		t.Fatalf("unexpected number of loaded transactions: got %d, "+
+			"want %d", numLoaded, 2)
+	}
+	testPoolMembership(ctx, parent, false, true)
+	testPoolMembership(ctx, child, false, true)
+	testPoolMembership(ctx, spent, false, false)
+	testPoolMembership(ctx, expired, false, false)
+
+	if added := txPool.pool[*parent.Hash()].Added; !added.Equal(parentAdded) {
+		t.Fatalf("unexpected parent added time: got %v, want %v",
+			added, parentAdded)
+	}
+	if added := txPool.pool[*child.Hash()].Added; !added.Equal(childAdded) {
+		t.Fatalf("unexpected child added time: got %v, want %v",
+			added, childAdded)
+	}
+	childDesc := txPool.pool[*child.Hash()]
+	if childDesc.FeeDelta != childFeeDelta {
+		t.Fatalf("unexpected child fee delta: got %d, want %d",
+			childDesc.FeeDelta, childFeeDelta)
+	}
+	parentDesc := txPool.pool[*parent.Hash()]
+	wantFees := parentDesc.Fee + childDesc.Fee + childFeeDelta
+	if parentDesc.descendantFees != wantFees {
+		t.Fatalf("unexpected parent descendant fees: got %d, want %d",
+			parentDesc.descendantFees, wantFees)
+	}
+	if childDesc.ancestorFees != wantFees {
+		t.Fatalf("unexpected child ancestor fees: got %d, want %d",
+			childDesc.ancestorFees, wantFees)
+	}
+
+	// Loading data written by an unknown version must fail.
+	_, err = txPool.Load(bytes.NewReader([]byte{0, 0, 0, 3, 0, 0, 0, 0}))
+	if err == nil {
+		t.Fatalf("expected error loading unknown version")
+	}
+
+	// A child saved before its parent must be rejected instead of being
+	// added to the orphan pool, while the parent is still restored.
+	txPool.RemoveTransaction(parent, true)
+	buf.Reset()
+	binary.Write(&buf, binary.BigEndian, uint32(mempoolSaveVersion))
+	binary.Write(&buf, binary.BigEndian, uint32(2))
+	for _, tx := range []*acmutil.Tx{child, parent} {
+		binary.Write(&buf, binary.BigEndian, parentAdded.Unix())
+		binary.Write(&buf, binary.BigEndian, int64(0))
+		tx.MsgTx().Serialize(&buf)
+	}
+	numLoaded, err = txPool.Load(&buf)
+	if err != nil {
+		t.Fatalf("unable to load pool: %v", err)
+	}
+	if numLoaded != 1 {
+		t.Fatalf("unexpected number of loaded transactions: got %d, "+
+			"want %d", numLoaded, 1)
+	}
+	testPoolMembership(ctx, parent, false, true)
+	testPoolMembership(ctx, child, false, false)
+}
+
+// TestMempoolEntryAncestry ensures the ancestor statistics of transactions are
+// tracked properly as transactions are added to and removed from the pool and
+// that the ancestors and descendants of a transaction are reported properly.
+func TestMempoolEntryAncestry(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+
+	// Create a chain of transactions A -> B -> C.
+	coinbase := ctx.addCoinbaseTx(1)
+	coinbaseOut := []spendableOutput{txOutToSpendableOut(coinbase, 0)}
+	a := ctx.addSignedTx(coinbaseOut, 1, 1000, false, false)
+	aOut := []spendableOutput{txOutToSpendableOut(a, 0)}
+	b := ctx.addSignedTx(aOut, 1, 2000, false, false)
+	bOut := []spendableOutput{txOutToSpendableOut(b, 0)}
+	c := ctx.addSignedTx(bOut, 1, 3000, false, false)
+
+	checkAncestorStats := func(tx *acmutil.Tx, count, size, fees int64) {
+		t.Helper()
+
+		desc := txPool.pool[*tx.Hash()]
+		if desc.ancestorCount != count || desc.ancestorSize != size ||
+			desc.ancestorFees != fees {
+
+			t.Fatalf("unexpected ancestor stats for %v: got count %d, "+
+				"size %d, fees %d, want count %d, size %d, "+
+				"fees %d", tx.Hash(), desc.ancestorCount,
+				desc.ancestorSize, desc.ancestorFees, count, size,
+				fees)
+		}
+	}
+	sizeA := GetTxVirtualSize(a)
+	sizeB := GetTxVirtualSize(b)
+	sizeC := GetTxVirtualSize(c)
+	checkAncestorStats(a, 1, sizeA, 1000)
+	checkAncestorStats(b, 2, sizeA+sizeB, 3000)
+	checkAncestorStats(c, 3, sizeA+sizeB+sizeC, 6000)
+
+	// The mining descriptors must report the ancestor statistics too.
+	for _, desc := range txPool.MiningDescs() {
+		poolDesc := txPool.pool[*desc.Tx.Hash()]
+		if desc.AncestorFees != poolDesc.ancestorFees ||
+			desc.AncestorSize != poolDesc.ancestorSize {
+
+			t.Fatalf("unexpected mining descriptor ancestor stats "+
+				"for %v: got fees %d, size %d, want fees %d, "+
+				"size %d", desc.Tx.Hash(), desc.AncestorFees,
+				desc.AncestorSize, poolDesc.ancestorFees,
+				poolDesc.ancestorSize)
+		}
+	}
+
+	// The entry of the middle transaction must report both its ancestor
+	// and descendant statistics.
+	entry, err := txPool.MempoolEntry(b.Hash())
+	if err != nil {
+		t.Fatalf("unable to fetch mempool entry: %v", err)
+	}
+	if entry.AncestorCount != 2 || entry.AncestorSize != sizeA+sizeB ||
+		entry.AncestorFees != acmutil.Amount(3000).ToBTC() {
+
+		t.Fatalf("unexpected entry ancestor stats: count %d, size %d, "+
+			"fees %v", entry.AncestorCount, entry.AncestorSize,
+			entry.AncestorFees)
+	}
+	if entry.DescendantCount != 2 || entry.DescendantSize != sizeB+sizeC ||
+		entry.DescendantFees != acmutil.Amount(5000).ToBTC() {
+
+		t.Fatalf("unexpected entry descendant stats: count %d, size %d, "+
+			"fees %v", entry.DescendantCount, entry.DescendantSize,
+			entry.DescendantFees)
+	}
+	if len(entry.Depends) != 1 || entry.Depends[0] != a.Hash().String() {
+		t.Fatalf("unexpected entry depends: %v", entry.Depends)
+	}
+
+	// The ancestors of C are A and B while the descendants of A are B and
+	// C.
+	ancestors, err := txPool.MempoolAncestors(c.Hash())
+	if err != nil {
+		t.Fatalf("unable to fetch mempool ancestors: %v", err)
+	}
+	if len(ancestors) != 2 || ancestors[a.Hash().String()] == nil ||
+		ancestors[b.Hash().String()] == nil {
+
+		t.Fatalf("unexpected ancestors of C: %v", ancestors)
+	}
+	descendants, err := txPool.MempoolDescendants(a.Hash())
+	if err != nil {
+		t.Fatalf("unable to fetch mempool descendants: %v", err)
+	}
+	if len(descendants) != 2 || descendants[b.Hash().String()] == nil ||
+		descendants[c.Hash().String()] == nil {
+
+		t.Fatalf("unexpected descendants of A: %v", descendants)
+	}
+
+	// Removing B without its redeemers disconnects C from A, so C must no
+	// longer count any ancestors.  Adding B back must restore them.
+	txPool.RemoveTransaction(b, false)
+	checkAncestorStats(c, 1, sizeC, 3000)
+	_, err = txPool.ProcessTransaction(b, false, false, 0)
+	if err != nil {
+		t.Fatalf("unable to process transaction B: %v", err)
+	}
+	checkAncestorStats(b, 2, sizeA+sizeB, 3000)
+	checkAncestorStats(c, 3, sizeA+sizeB+sizeC, 6000)
+
+	// Transactions which are not in the pool must be rejected.
+	if _, err := txPool.MempoolEntry(coinbase.Hash()); err == nil {
+		t.Fatalf("expected error fetching entry of transaction not " +
+			"in the pool")
+	}
+}
+
+// TestDescendantStats ensures the descendant and ancestor statistics of
+// transactions are updated properly when a transaction which is already spent
+// by others in the pool is added back, including descendants which are
+// connected to an ancestor through more than one path.
+func TestDescendantStats(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+
+	// Create A with two outputs, B spending the first one and C spending
+	// both the second output of A and the output of B.
+	coinbase := ctx.addCoinbaseTx(1)
+	coinbaseOut := []spendableOutput{txOutToSpendableOut(coinbase, 0)}
+	a := ctx.addSignedTx(coinbaseOut, 2, 1000, false, false)
+	aOut := []spendableOutput{txOutToSpendableOut(a, 0)}
+	b := ctx.addSignedTx(aOut, 1, 2000, false, false)
+	cIn := []spendableOutput{
+		txOutToSpendableOut(a, 1), txOutToSpendableOut(b, 0),
+	}
+	c := ctx.addSignedTx(cIn, 1, 3000, false, false)
+
+	checkDescendantStats := func(tx *acmutil.Tx, count, size, fees int64) {
+		t.Helper()
+
+		desc := txPool.pool[*tx.Hash()]
+		if desc.descendantCount != count ||
+			desc.descendantSize != size ||
+			desc.descendantFees != fees {
+
+			t.Fatalf("unexpected descendant stats for %v: got "+
+				"count %d, size %d, fees %d, want count %d, "+
+				"size %d, fees %d", tx.Hash(),
+				desc.descendantCount, desc.descendantSize,
+				desc.descendantFees, count, size, fees)
+		}
+	}
+	checkAncestorStats := func(tx *acmutil.Tx, count, size, fees int64) {
+		t.Helper()
+
+		desc := txPool.pool[*tx.Hash()]
+		if desc.ancestorCount != count || desc.ancestorSize != size ||
+			desc.ancestorFees != fees {
+
+			t.Fatalf("unexpected ancestor stats for %v: got count %d, "+
+				"size %d, fees %d, want count %d, size %d, "+
+				"fees %d", tx.Hash(), desc.ancestorCount,
+				desc.ancestorSize, desc.ancestorFees, count, size,
+				fees)
+		}
+	}
+	sizeA := GetTxVirtualSize(a)
+	sizeB := GetTxVirtualSize(b)
+	sizeC := GetTxVirtualSize(c)
+	checkDescendantStats(a, 3, sizeA+sizeB+sizeC, 6000)
+	checkDescendantStats(b, 2, sizeB+sizeC, 5000)
+	checkDescendantStats(c, 1, sizeC, 3000)
+
+	// Removing B without its redeemers leaves C connected to A directly,
+	// so A must still count C.  Adding B back must count it without
+	// counting C twice.
+	txPool.RemoveTransaction(b, false)
+	checkDescendantStats(a, 2, sizeA+sizeC, 4000)
+	checkAncestorStats(c, 2, sizeA+sizeC, 4000)
+	_, err = txPool.ProcessTransaction(b, false, false, 0)
+	if err != nil {
+		t.Fatalf("unable to process transaction B: %v", err)
+	}
+	checkDescendantStats(a, 3, sizeA+sizeB+sizeC, 6000)
+	checkDescendantStats(b, 2, sizeB+sizeC, 5000)
+	checkAncestorStats(b, 2, sizeA+sizeB, 3000)
+	checkAncestorStats(c, 3, sizeA+sizeB+sizeC, 6000)
+
+	// Removing A without its redeemers leaves B and C without any
+	// ancestors in the pool.
+	txPool.RemoveTransaction(a, false)
+	checkDescendantStats(b, 2, sizeB+sizeC, 5000)
+	checkDescendantStats(c, 1, sizeC, 3000)
+	checkAncestorStats(b, 1, sizeB, 2000)
+	checkAncestorStats(c, 2, sizeB+sizeC, 5000)
+}
+
+// TestPackageLimits ensures transactions are rejected when they would have too
+// many unconfirmed ancestors or give one of their ancestors too many
+// unconfirmed descendants.
+func TestPackageLimits(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+
+	checkRejected := func(tx *acmutil.Tx) {
+		t.Helper()
+
+		_, err := txPool.ProcessTransaction(tx, false, false, 0)
+		if err == nil {
+			t.Fatalf("expected transaction %v to be rejected",
+				tx.Hash())
+		}
+		code, _ := extractRejectCode(err)
+		if code != wire.RejectNonstandard {
+			t.Fatalf("unexpected reject code: got %v, want %v",
+				code, wire.RejectNonstandard)
+		}
+		testPoolMembership(ctx, tx, false, false)
+	}
+
+	// A chain of the maximum number of transactions is accepted, while a
+	// transaction extending it any further is not.
+	coinbase := ctx.addCoinbaseTx(2)
+	outs := []spendableOutput{txOutToSpendableOut(coinbase, 0)}
+	for i := 0; i < maxAncestorCount; i++ {
+		tx := ctx.addSignedTx(outs, 1, 1000, false, false)
+		outs = []spendableOutput{txOutToSpendableOut(tx, 0)}
+	}
+	tx, err := harness.CreateSignedTx(outs, 1, 1000, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	checkRejected(tx)
+
+	// A transaction can be spent by as many transactions as the maximum
+	// number of descendants allows, but not by any more.
+	coinbaseOut := []spendableOutput{txOutToSpendableOut(coinbase, 1)}
+	parent := ctx.addSignedTx(coinbaseOut, maxDescendantCount, 1000,
+		false, false)
+	for i := uint32(0); i < maxDescendantCount-1; i++ {
+		out := []spendableOutput{txOutToSpendableOut(parent, i)}
+		ctx.addSignedTx(out, 1, 1000, false, false)
+	}
+	out := []spendableOutput{
+		txOutToSpendableOut(parent, maxDescendantCount-1),
+	}
+	tx, err = harness.CreateSignedTx(out, 1, 1000, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	checkRejected(tx)
+}
+
+// TestTestMempoolAccept ensures transactions and packages of dependent
+// transactions are validated properly without being added to the pool.
+func TestTestMempoolAccept(t *testing.T) {
+	t.Parallel()
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+
+	coinbase := ctx.addCoinbaseTx(2)
+	createTx := func(inputs []spendableOutput, fee acmutil.Amount) *acmutil.Tx {
+		t.Helper()
+
+		tx, err := harness.CreateSignedTx(inputs, 1, fee, false)
+		if err != nil {
+			t.Fatalf("unable to create transaction: %v", err)
+		}
+		return tx
+	}
+	parent := createTx([]spendableOutput{txOutToSpendableOut(coinbase, 0)}, 1000)
+	child := createTx([]spendableOutput{txOutToSpendableOut(parent, 0)}, 2000)
+	doubleSpend := createTx([]spendableOutput{txOutToSpendableOut(coinbase, 0)}, 3000)
+	inPool := ctx.addSignedTx([]spendableOutput{txOutToSpendableOut(coinbase, 1)},
+		1, 1000, false, false)
+
+	tests := []struct {
+		name    string
+		txns    []*acmutil.Tx
+		allowed []bool
+	}{
+		{
+			name:    "single transaction",
+			txns:    []*acmutil.Tx{parent},
+			allowed: []bool{true},
+		},
+		{
+			name:    "package with child",
+			txns:    []*acmutil.Tx{parent, child},
+			allowed: []bool{true, true},
+		},
+		{
+			name:    "child without parent",
+			txns:    []*acmutil.Tx{child},
+			allowed: []bool{false},
+		},
+		{
+			name:    "double spend within package",
+			txns:    []*acmutil.Tx{parent, doubleSpend},
+			allowed: []bool{true, false},
+		},
+		{
+			name:    "transaction already in pool",
+			txns:    []*acmutil.Tx{inPool},
+			allowed: []bool{false},
+		},
+	}
+
+	for _, test := range tests {
+		results, err := txPool.TestMempoolAccept(test.txns)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if len(results) != len(test.txns) {
+			t.Fatalf("%s: unexpected number of results: got %d, "+
+				"want %d", test.name, len(results),
+				len(test.txns))
+		}
+		for i, result := range results {
+			tx := test.txns[i]
+			if result.Txid != tx.Hash().String() {
+				t.Fatalf("%s: unexpected txid for result %d: "+
+					"got %v, want %v", test.name, i,
+					result.Txid, tx.Hash())
+			}
+			if result.Allowed != test.allowed[i] {
+				t.Fatalf("%s: unexpected allowed for result "+
+					"%d: got %v, want %v (reason %q)",
+					test.name, i, result.Allowed,
+					test.allowed[i], result.RejectReason)
+			}
+			if !result.Allowed {
+				if result.RejectReason == "" {
+					t.Fatalf("%s: missing reject reason for "+
+						"result %d", test.name, i)
+				}
+				continue
+			}
+			if result.Vsize != GetTxVirtualSize(tx) {
+				t.Fatalf("%s: unexpected vsize for result %d: "+
+					"got %d, want %d", test.name, i,
+					result.Vsize, GetTxVirtualSize(tx))
+			}
+		}
+
+		// None of the transactions may have been added to the pool.
+		for _, tx := range test.txns {
+			if tx == inPool {
+				continue
+			}
+			testPoolMembership(ctx, tx, false, false)
+		}
+	}
+}
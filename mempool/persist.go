@@ -0,0 +1,247 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/wire"
+	"github.com/Actinium-project/acmutil"
+)
+
+// mempoolSaveVersion is the version of the serialized format written by Save.
+// It must be bumped whenever the format changes.
+const mempoolSaveVersion = 1
+
+// saveOrder returns the transactions in the main pool ordered by the time they
+// were added, with every transaction following all of the transactions in the
+// pool it spends from, so they can be added back to the pool in order.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) saveOrder() []*TxDesc {
+	descs := make([]*TxDesc, 0, len(mp.pool))
+	for _, txD := range mp.pool {
+		descs = append(descs, txD)
+	}
+	sort.Slice(descs, func(i, j int) bool {
+		return descs[i].Added.Before(descs[j].Added)
+	})
+
+	ordered := make([]*TxDesc, 0, len(descs))
+	visited := make(map[chainhash.Hash]struct{}, len(descs))
+	var visit func(txD *TxDesc)
+	visit = func(txD *TxDesc) {
+		hash := *txD.Tx.Hash()
+		if _, ok := visited[hash]; ok {
+			return
+		}
+		visited[hash] = struct{}{}
+		for _, txIn := range txD.Tx.MsgTx().TxIn {
+			parent, ok := mp.pool[txIn.PreviousOutPoint.Hash]
+			if ok {
+				visit(parent)
+			}
+		}
+		ordered = append(ordered, txD)
+	}
+	for _, txD := range descs {
+		visit(txD)
+	}
+	return ordered
+}
+
+// Save serializes all transactions in the main pool along with the time they
+// were added and their fee delta to w, so they can be restored with Load after
+// a restart.  The orphan pool is not saved.  It returns the number of saved
+// transactions.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) Save(w io.Writer) (int, error) {
+	mp.mtx.RLock()
+	descs := mp.saveOrder()
+	mp.mtx.RUnlock()
+
+	err := binary.Write(w, binary.BigEndian, uint32(mempoolSaveVersion))
+	if err != nil {
+		return 0, err
+	}
+	err = binary.Write(w, binary.BigEndian, uint32(len(descs)))
+	if err != nil {
+		return 0, err
+	}
+	for _, txD := range descs {
+		err := binary.Write(w, binary.BigEndian, txD.Added.Unix())
+		if err != nil {
+			return 0, err
+		}
+		err = binary.Write(w, binary.BigEndian, txD.FeeDelta)
+		if err != nil {
+			return 0, err
+		}
+		if err := txD.Tx.MsgTx().Serialize(w); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(descs), nil
+}
+
+// Load reads transactions previously written by Save from r and adds them back
+// to the main pool, restoring the time they were originally added and their fee
+// delta.  Since the chain and the policy might have changed in the meantime,
+// each transaction is processed like a new transaction that is not allowed to
+// be an orphan, so it is subject to the usual acceptance rules.  Save writes
+// parents before the transactions spending them, so transactions whose inputs
+// are not available, such as those that were mined or double spent, are no
+// longer valid and are skipped along with those which would have expired by
+// now.  It returns the number of transactions that were added to the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) Load(r io.Reader) (int, error) {
+	var version uint32
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return 0, err
+	}
+	if version != mempoolSaveVersion {
+		return 0, fmt.Errorf("unsupported saved mempool version %d",
+			version)
+	}
+	var numTxns uint32
+	if err := binary.Read(r, binary.BigEndian, &numTxns); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	expiry := mp.cfg.Policy.MempoolExpiry
+	var accepted, expired, failed int
+	for i := uint32(0); i < numTxns; i++ {
+		var addedUnix, feeDelta int64
+		err := binary.Read(r, binary.BigEndian, &addedUnix)
+		if err != nil {
+			return accepted, err
+		}
+		err = binary.Read(r, binary.BigEndian, &feeDelta)
+		if err != nil {
+			return accepted, err
+		}
+		var msgTx wire.MsgTx
+		if err := msgTx.Deserialize(r); err != nil {
+			return accepted, err
+		}
+
+		added := time.Unix(addedUnix, 0)
+		if expiry > 0 && now.Sub(added) > expiry {
+			expired++
+			continue
+		}
+
+		tx := acmutil.NewTx(&msgTx)
+		err = mp.loadTransaction(tx, added, feeDelta)
+		if err != nil {
+			log.Debugf("Not restoring transaction %v: %v",
+				tx.Hash(), err)
+			failed++
+			continue
+		}
+		accepted++
+	}
+
+	log.Infof("Restored %d of %d saved mempool %s (%d expired, %d "+
+		"failed)", accepted, numTxns,
+		pickNoun(int(numTxns), "transaction", "transactions"),
+		expired, failed)
+	return accepted, nil
+}
+
+// loadTransaction processes a transaction restored by Load like a new
+// transaction which is not allowed to be an orphan and, once it has been added
+// to the main pool, restores the provided time it was originally added and fee
+// delta.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) loadTransaction(tx *acmutil.Tx, added time.Time,
+	feeDelta int64) error {
+
+	// The restored transactions are not associated with any peer.
+	acceptedTxs, err := mp.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		return err
+	}
+
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+	for _, txD := range acceptedTxs {
+		// Only the restored transaction itself is updated, unless it
+		// was already removed again, in which case it is no longer
+		// reflected in the statistics of the pool.
+		if txD.Tx != tx || mp.pool[*tx.Hash()] != txD {
+			continue
+		}
+		txD.Added = added
+		if feeDelta != 0 {
+			mp.prioritiseTransaction(txD, feeDelta)
+		}
+	}
+	return nil
+}
+
+// SaveFile saves the main pool to the file at the provided path as described by
+// Save.  The file is written under a temporary name first and then renamed, so
+// an existing file is never left partially written.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) SaveFile(path string) (int, error) {
+	tmpPath := path + ".new"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+
+	w := bufio.NewWriter(f)
+	numTxns, err := mp.Save(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	return numTxns, nil
+}
+
+// LoadFile restores the main pool from the file at the provided path as
+// described by Load.  It is not an error for the file to not exist.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) LoadFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return mp.Load(bufio.NewReader(f))
+}
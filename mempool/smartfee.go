@@ -0,0 +1,763 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+)
+
+const (
+	// minBucketFeeRate and maxBucketFeeRate are the lowest and highest fee
+	// rates in satoshis per kilobyte that are tracked in separate buckets
+	// by the smart fee estimator.  Fee rates between them are grouped into
+	// buckets that are feeBucketSpacing times apart.
+	minBucketFeeRate = 1000
+	maxBucketFeeRate = 1e7
+	feeBucketSpacing = 1.05
+
+	// The short, medium and long horizons track confirmations over
+	// different numbers of blocks.  Each horizon tracks the given number
+	// of periods of scale blocks each, and its moving averages decay by
+	// the given factor per block, which corresponds to a half life of
+	// roughly 18 blocks, 144 blocks and 1008 blocks respectively.
+	shortBlockPeriods = 12
+	shortScale        = 1
+	shortDecay        = .962
+
+	medBlockPeriods = 24
+	medScale        = 2
+	medDecay        = .9952
+
+	longBlockPeriods = 42
+	longScale        = 24
+	longDecay        = .99931
+
+	// halfSuccessPct, successPct and doubleSuccessPct are the minimum
+	// ratios of transactions that must have been confirmed within half,
+	// exactly and twice the target number of blocks for a fee rate to be
+	// considered sufficient.
+	halfSuccessPct   = .6
+	successPct       = .85
+	doubleSuccessPct = .95
+
+	// sufficientFeeTxs and sufficientTxsShort are the number of
+	// transactions per block that need to be seen in a range of buckets
+	// for its confirmation ratio to be meaningful for the medium and long
+	// horizons and the short horizon respectively.
+	sufficientFeeTxs   = .1
+	sufficientTxsShort = .5
+
+	// MaxSmartFeeTarget is the highest confirmation target in blocks that
+	// fees can be estimated for by EstimateSmartFee.
+	MaxSmartFeeTarget = longBlockPeriods * longScale
+
+	// smartFeeMaxRollback is the number of most recently processed blocks
+	// which can be disconnected from the smart fee estimator while
+	// reversing their effect on the statistics.
+	smartFeeMaxRollback = 6
+)
+
+// feeBuckets are the upper bounds of the fee rate buckets in satoshis per
+// kilobyte.  The last bucket catches all fee rates above maxBucketFeeRate.
+var feeBuckets = func() []float64 {
+	var buckets []float64
+	for rate := float64(minBucketFeeRate); rate <= maxBucketFeeRate; rate *= feeBucketSpacing {
+		buckets = append(buckets, rate)
+	}
+	return append(buckets, math.Inf(1))
+}()
+
+// feeBucketIndex returns the index of the bucket the passed fee rate in
+// satoshis per kilobyte belongs to.
+func feeBucketIndex(feeRate float64) int {
+	return sort.SearchFloat64s(feeBuckets, feeRate)
+}
+
+// confirmStats tracks how many transactions in each fee rate bucket have been
+// confirmed within a number of periods over one horizon.  All counts are
+// exponentially decaying moving averages, so recent blocks carry more weight.
+type confirmStats struct {
+	decay float64
+	scale int32
+
+	// txCtAvg is the number of confirmed transactions and feeSum the sum
+	// of their fee rates per bucket.
+	txCtAvg []float64
+	feeSum  []float64
+
+	// confAvg and failAvg are the number of transactions per period and
+	// bucket that were confirmed within and failed to be confirmed within
+	// that many periods.
+	confAvg [][]float64
+	failAvg [][]float64
+
+	// unconfTxs is a circular buffer of the number of unconfirmed
+	// transactions per block height and bucket that entered the mempool
+	// within the number of blocks tracked by the horizon.  oldUnconfTxs
+	// is the number of older unconfirmed transactions per bucket.
+	unconfTxs    [][]int32
+	oldUnconfTxs []int32
+}
+
+// newConfirmStats returns confirmation statistics tracking the given number of
+// periods of scale blocks each with moving averages that decay by the passed
+// factor per block.
+func newConfirmStats(periods, scale int32, decay float64) *confirmStats {
+	numBuckets := len(feeBuckets)
+	stats := &confirmStats{
+		decay:        decay,
+		scale:        scale,
+		txCtAvg:      make([]float64, numBuckets),
+		feeSum:       make([]float64, numBuckets),
+		confAvg:      make([][]float64, periods),
+		failAvg:      make([][]float64, periods),
+		unconfTxs:    make([][]int32, periods*scale),
+		oldUnconfTxs: make([]int32, numBuckets),
+	}
+	for i := range stats.confAvg {
+		stats.confAvg[i] = make([]float64, numBuckets)
+		stats.failAvg[i] = make([]float64, numBuckets)
+	}
+	for i := range stats.unconfTxs {
+		stats.unconfTxs[i] = make([]int32, numBuckets)
+	}
+	return stats
+}
+
+// maxConfirms returns the highest confirmation target in blocks tracked by the
+// horizon.
+func (s *confirmStats) maxConfirms() int32 {
+	return int32(len(s.unconfTxs))
+}
+
+// unconfIndex returns the index into the circular buffer of unconfirmed
+// transactions for the passed block height.
+func (s *confirmStats) unconfIndex(height int32) int {
+	n := int32(len(s.unconfTxs))
+	return int(((height % n) + n) % n)
+}
+
+// newTx records a new unconfirmed transaction in the passed bucket which
+// entered the mempool at the passed height.
+func (s *confirmStats) newTx(height int32, bucket int) {
+	s.unconfTxs[s.unconfIndex(height)][bucket]++
+}
+
+// removeTx removes an unconfirmed transaction in the passed bucket which
+// entered the mempool at the passed height.  When the transaction was not
+// confirmed, it is recorded as having failed to confirm within every period
+// that has passed since.
+func (s *confirmStats) removeTx(height, bestHeight int32, bucket int, confirmed bool) {
+	blocksAgo := bestHeight - height
+	if blocksAgo < 0 {
+		return
+	}
+
+	if blocksAgo >= s.maxConfirms() {
+		if s.oldUnconfTxs[bucket] > 0 {
+			s.oldUnconfTxs[bucket]--
+		}
+	} else {
+		index := s.unconfIndex(height)
+		if s.unconfTxs[index][bucket] > 0 {
+			s.unconfTxs[index][bucket]--
+		}
+	}
+
+	if !confirmed && blocksAgo >= s.scale {
+		periodsAgo := int(blocksAgo / s.scale)
+		for i := 0; i < periodsAgo && i < len(s.failAvg); i++ {
+			s.failAvg[i][bucket]++
+		}
+	}
+}
+
+// clearCurrent moves the unconfirmed transactions which entered the mempool so
+// long ago that the circular buffer slot is reused for the passed height to the
+// old unconfirmed transactions.
+func (s *confirmStats) clearCurrent(height int32) {
+	index := s.unconfIndex(height)
+	for bucket, n := range s.unconfTxs[index] {
+		s.oldUnconfTxs[bucket] += n
+		s.unconfTxs[index][bucket] = 0
+	}
+}
+
+// record records a transaction with the passed fee rate in satoshis per
+// kilobyte that was confirmed the passed number of blocks after it entered the
+// mempool.
+func (s *confirmStats) record(blocksToConfirm int32, feeRate float64) {
+	if blocksToConfirm < 1 {
+		return
+	}
+
+	periodsToConfirm := int((blocksToConfirm + s.scale - 1) / s.scale)
+	bucket := feeBucketIndex(feeRate)
+	for i := periodsToConfirm; i <= len(s.confAvg); i++ {
+		s.confAvg[i-1][bucket]++
+	}
+	s.txCtAvg[bucket]++
+	s.feeSum[bucket] += feeRate
+}
+
+// unrecord reverses the effect of recording a transaction with the passed fee
+// rate that was confirmed the passed number of blocks after it entered the
+// mempool.
+func (s *confirmStats) unrecord(blocksToConfirm int32, feeRate float64) {
+	if blocksToConfirm < 1 {
+		return
+	}
+
+	periodsToConfirm := int((blocksToConfirm + s.scale - 1) / s.scale)
+	bucket := feeBucketIndex(feeRate)
+	for i := periodsToConfirm; i <= len(s.confAvg); i++ {
+		s.confAvg[i-1][bucket]--
+	}
+	s.txCtAvg[bucket]--
+	s.feeSum[bucket] -= feeRate
+}
+
+// restoreTx records an unconfirmed transaction in the passed bucket which
+// entered the mempool at the passed height again after it was removed at the
+// passed best height by a block which has been disconnected.
+func (s *confirmStats) restoreTx(height, bestHeight int32, bucket int) {
+	if bestHeight-height >= s.maxConfirms() {
+		s.oldUnconfTxs[bucket]++
+		return
+	}
+	s.newTx(height, bucket)
+}
+
+// updateMovingAverages decays all moving averages by one block.
+func (s *confirmStats) updateMovingAverages() {
+	for bucket := range feeBuckets {
+		for i := range s.confAvg {
+			s.confAvg[i][bucket] *= s.decay
+			s.failAvg[i][bucket] *= s.decay
+		}
+		s.feeSum[bucket] *= s.decay
+		s.txCtAvg[bucket] *= s.decay
+	}
+}
+
+// revertMovingAverages reverses the decay of all moving averages by one block.
+func (s *confirmStats) revertMovingAverages() {
+	for bucket := range feeBuckets {
+		for i := range s.confAvg {
+			s.confAvg[i][bucket] /= s.decay
+			s.failAvg[i][bucket] /= s.decay
+		}
+		s.feeSum[bucket] /= s.decay
+		s.txCtAvg[bucket] /= s.decay
+	}
+}
+
+// estimateMedianVal returns the median fee rate in satoshis per kilobyte of the
+// lowest range of buckets in which at least the passed ratio of transactions
+// was confirmed within the passed number of blocks, or -1 when there is no
+// such range.  Starting at the highest fee rates, buckets are grouped until
+// they contain enough transactions for the ratio to be meaningful.
+func (s *confirmStats) estimateMedianVal(confTarget int32, sufficientTxVal,
+	successBreakPoint float64, bestHeight int32) float64 {
+
+	periodTarget := int((confTarget + s.scale - 1) / s.scale)
+	maxBucket := len(feeBuckets) - 1
+
+	var nConf, totalNum, failNum, extraNum float64
+	curNearBucket, bestNearBucket := maxBucket, maxBucket
+	curFarBucket, bestFarBucket := maxBucket, maxBucket
+	foundAnswer := false
+	newBucketRange := true
+	for bucket := maxBucket; bucket >= 0; bucket-- {
+		if newBucketRange {
+			curNearBucket = bucket
+			newBucketRange = false
+		}
+		curFarBucket = bucket
+		nConf += s.confAvg[periodTarget-1][bucket]
+		totalNum += s.txCtAvg[bucket]
+		failNum += s.failAvg[periodTarget-1][bucket]
+
+		// Transactions that are still unconfirmed after the target
+		// count as failures as well.
+		for confct := confTarget; confct < s.maxConfirms(); confct++ {
+			index := s.unconfIndex(bestHeight - confct)
+			extraNum += float64(s.unconfTxs[index][bucket])
+		}
+		extraNum += float64(s.oldUnconfTxs[bucket])
+
+		// Only judge the range once it has enough data points.  When
+		// the ratio is too low, keep adding lower buckets, otherwise
+		// remember the range and start a new one.
+		if totalNum < sufficientTxVal/(1-s.decay) {
+			continue
+		}
+		curPct := nConf / (totalNum + failNum + extraNum)
+		if curPct < successBreakPoint {
+			continue
+		}
+
+		foundAnswer = true
+		nConf, totalNum, failNum, extraNum = 0, 0, 0, 0
+		bestNearBucket = curNearBucket
+		bestFarBucket = curFarBucket
+		newBucketRange = true
+	}
+	if !foundAnswer {
+		return -1
+	}
+
+	// Find the bucket with the median transaction of the range and return
+	// the average fee rate of that bucket.
+	minBucket, maxRangeBucket := bestFarBucket, bestNearBucket
+	var txSum float64
+	for j := minBucket; j <= maxRangeBucket; j++ {
+		txSum += s.txCtAvg[j]
+	}
+	if txSum == 0 {
+		return -1
+	}
+	txSum /= 2
+	for j := minBucket; j <= maxRangeBucket; j++ {
+		if s.txCtAvg[j] < txSum {
+			txSum -= s.txCtAvg[j]
+			continue
+		}
+		return s.feeSum[j] / s.txCtAvg[j]
+	}
+	return -1
+}
+
+// serialize writes the moving averages of the statistics to w.  Unconfirmed
+// transactions are not saved.
+func (s *confirmStats) serialize(w io.Writer) {
+	binary.Write(w, binary.BigEndian, s.txCtAvg)
+	binary.Write(w, binary.BigEndian, s.feeSum)
+	for i := range s.confAvg {
+		binary.Write(w, binary.BigEndian, s.confAvg[i])
+		binary.Write(w, binary.BigEndian, s.failAvg[i])
+	}
+}
+
+// deserialize reads statistics previously written by serialize from r.  The
+// statistics must have been created with the same parameters.
+func (s *confirmStats) deserialize(r io.Reader) error {
+	if err := binary.Read(r, binary.BigEndian, s.txCtAvg); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.BigEndian, s.feeSum); err != nil {
+		return err
+	}
+	for i := range s.confAvg {
+		err := binary.Read(r, binary.BigEndian, s.confAvg[i])
+		if err != nil {
+			return err
+		}
+		err = binary.Read(r, binary.BigEndian, s.failAvg[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trackedTx is an unconfirmed transaction tracked by the smart fee estimator.
+type trackedTx struct {
+	height  int32
+	feeRate float64
+}
+
+// confirmedTx is a tracked transaction which was confirmed by a block processed
+// by the smart fee estimator along with whether its confirmation was recorded.
+type confirmedTx struct {
+	hash     chainhash.Hash
+	tx       trackedTx
+	recorded bool
+}
+
+// smartFeeUndo houses the information needed to reverse the effect of a
+// processed block on the smart fee estimator when it is disconnected.
+type smartFeeUndo struct {
+	hash           chainhash.Hash
+	height         int32
+	prevBestHeight int32
+	confirmed      []confirmedTx
+}
+
+// smartFeeEstimator estimates fee rates from how quickly transactions in each
+// fee rate bucket were confirmed over a short, medium and long horizon, which
+// allows it to react quickly to recent changes for low targets while still
+// providing estimates for targets of up to a week worth of blocks.
+type smartFeeEstimator struct {
+	bestHeight          int32
+	firstRecordedHeight int32
+
+	short *confirmStats
+	med   *confirmStats
+	long  *confirmStats
+
+	tracked map[chainhash.Hash]trackedTx
+
+	// undo holds the information needed to disconnect the most recently
+	// processed blocks, the most recent one last.
+	undo []*smartFeeUndo
+}
+
+// newSmartFeeEstimator returns a new smart fee estimator without any data.
+func newSmartFeeEstimator() *smartFeeEstimator {
+	return &smartFeeEstimator{
+		short:   newConfirmStats(shortBlockPeriods, shortScale, shortDecay),
+		med:     newConfirmStats(medBlockPeriods, medScale, medDecay),
+		long:    newConfirmStats(longBlockPeriods, longScale, longDecay),
+		tracked: make(map[chainhash.Hash]trackedTx),
+	}
+}
+
+// allStats returns the statistics of all horizons.
+func (se *smartFeeEstimator) allStats() []*confirmStats {
+	return []*confirmStats{se.short, se.med, se.long}
+}
+
+// observeTx starts tracking a transaction with the passed fee rate in satoshis
+// per kilobyte which entered the mempool at the passed height.  Transactions
+// which entered the mempool while catching up are not tracked since it is
+// not known how long they have been waiting.
+func (se *smartFeeEstimator) observeTx(hash *chainhash.Hash, height int32, feeRate float64) {
+	if height != se.bestHeight {
+		return
+	}
+	if _, ok := se.tracked[*hash]; ok {
+		return
+	}
+
+	bucket := feeBucketIndex(feeRate)
+	for _, stats := range se.allStats() {
+		stats.newTx(height, bucket)
+	}
+	se.tracked[*hash] = trackedTx{height: height, feeRate: feeRate}
+}
+
+// removeTx stops tracking the passed transaction.  It returns whether the
+// transaction was tracked along with its tracking details.
+func (se *smartFeeEstimator) removeTx(hash *chainhash.Hash, confirmed bool) (trackedTx, bool) {
+	tx, ok := se.tracked[*hash]
+	if !ok {
+		return tx, false
+	}
+	for _, stats := range se.allStats() {
+		stats.removeTx(tx.height, se.bestHeight,
+			feeBucketIndex(tx.feeRate), confirmed)
+	}
+	delete(se.tracked, *hash)
+	return tx, true
+}
+
+// processBlock updates the statistics for a newly connected block with the
+// passed hash and height which contains transactions with the passed hashes.
+// Blocks at or below the height of the last processed block are ignored, so
+// the blocks of the old chain must be disconnected first in case of a reorg.
+func (se *smartFeeEstimator) processBlock(hash *chainhash.Hash, height int32,
+	txHashes []*chainhash.Hash) {
+
+	if height <= se.bestHeight {
+		return
+	}
+	undo := &smartFeeUndo{
+		hash:           *hash,
+		height:         height,
+		prevBestHeight: se.bestHeight,
+	}
+	se.bestHeight = height
+
+	for _, stats := range se.allStats() {
+		stats.clearCurrent(height)
+		stats.updateMovingAverages()
+	}
+
+	var counted int
+	for _, hash := range txHashes {
+		tx, ok := se.removeTx(hash, true)
+		if !ok {
+			continue
+		}
+		blocksToConfirm := height - tx.height
+		recorded := blocksToConfirm > 0
+		undo.confirmed = append(undo.confirmed, confirmedTx{
+			hash:     *hash,
+			tx:       tx,
+			recorded: recorded,
+		})
+		if !recorded {
+			continue
+		}
+		for _, stats := range se.allStats() {
+			stats.record(blocksToConfirm, tx.feeRate)
+		}
+		counted++
+	}
+
+	// Transactions which have not been confirmed within the longest
+	// horizon are no longer tracked and count as failures.
+	for hash, tx := range se.tracked {
+		if height-tx.height >= se.long.maxConfirms() {
+			se.removeTx(&hash, false)
+		}
+	}
+
+	if se.firstRecordedHeight == 0 && counted > 0 {
+		se.firstRecordedHeight = height
+	}
+
+	se.undo = append(se.undo, undo)
+	if len(se.undo) > smartFeeMaxRollback {
+		se.undo[0] = nil
+		se.undo = se.undo[1:]
+	}
+}
+
+// disconnectBlock reverses the effect of the processed block with the passed
+// hash, along with all blocks processed after it, so the blocks replacing them
+// in a reorg are processed.  The confirmations recorded for the blocks are
+// removed and the transactions they confirmed are tracked again.
+//
+// Transactions which stopped being tracked because they were not confirmed
+// within the longest horizon are not restored, so the result is not always
+// exactly the same as if the blocks had never been processed, but it is close
+// enough.  Blocks processed before the estimator was restored or more than
+// smartFeeMaxRollback blocks ago can't be reversed at all, so they only lower
+// the best height to allow their replacements to be processed.
+func (se *smartFeeEstimator) disconnectBlock(hash *chainhash.Hash) {
+	n := len(se.undo) - 1
+	for n >= 0 && se.undo[n].hash != *hash {
+		n--
+	}
+	if n < 0 {
+		se.undo = nil
+		if se.bestHeight > 0 {
+			se.bestHeight--
+		}
+		return
+	}
+
+	for len(se.undo) > n {
+		se.disconnectTip()
+	}
+}
+
+// disconnectTip reverses the effect of the most recently processed block.  It
+// must only be called when there is undo information for the block.
+func (se *smartFeeEstimator) disconnectTip() {
+	n := len(se.undo)
+	undo := se.undo[n-1]
+	se.undo[n-1] = nil
+	se.undo = se.undo[:n-1]
+
+	for i := len(undo.confirmed) - 1; i >= 0; i-- {
+		confirmed := &undo.confirmed[i]
+		tx := confirmed.tx
+		if confirmed.recorded {
+			for _, stats := range se.allStats() {
+				stats.unrecord(undo.height-tx.height, tx.feeRate)
+			}
+		}
+	}
+	for _, stats := range se.allStats() {
+		stats.revertMovingAverages()
+	}
+	for _, confirmed := range undo.confirmed {
+		tx := confirmed.tx
+		bucket := feeBucketIndex(tx.feeRate)
+		for _, stats := range se.allStats() {
+			stats.restoreTx(tx.height, undo.height, bucket)
+		}
+		se.tracked[confirmed.hash] = tx
+	}
+
+	se.bestHeight = undo.prevBestHeight
+	if se.firstRecordedHeight == undo.height {
+		se.firstRecordedHeight = 0
+	}
+}
+
+// maxUsableEstimate returns the highest confirmation target for which enough
+// blocks have been processed to provide an estimate.
+func (se *smartFeeEstimator) maxUsableEstimate() int32 {
+	if se.firstRecordedHeight == 0 {
+		return 0
+	}
+	usable := (se.bestHeight - se.firstRecordedHeight) / 2
+	if usable > se.long.maxConfirms() {
+		usable = se.long.maxConfirms()
+	}
+	return usable
+}
+
+// estimateCombinedFee returns the fee rate in satoshis per kilobyte needed for
+// the passed ratio of transactions to be confirmed within the passed target
+// using the shortest horizon which covers the target, or -1 when there is
+// not enough data.  When checkShorterHorizon is set, the highest targets of
+// shorter horizons are considered as well since they might have more recent
+// data with lower fee rates.
+func (se *smartFeeEstimator) estimateCombinedFee(confTarget int32,
+	successThreshold float64, checkShorterHorizon bool) float64 {
+
+	estimate := float64(-1)
+	if confTarget < 1 || confTarget > se.long.maxConfirms() {
+		return estimate
+	}
+
+	switch {
+	case confTarget <= se.short.maxConfirms():
+		estimate = se.short.estimateMedianVal(confTarget,
+			sufficientTxsShort, successThreshold, se.bestHeight)
+	case confTarget <= se.med.maxConfirms():
+		estimate = se.med.estimateMedianVal(confTarget,
+			sufficientFeeTxs, successThreshold, se.bestHeight)
+	default:
+		estimate = se.long.estimateMedianVal(confTarget,
+			sufficientFeeTxs, successThreshold, se.bestHeight)
+	}
+	if !checkShorterHorizon {
+		return estimate
+	}
+
+	if confTarget > se.med.maxConfirms() {
+		medMax := se.med.estimateMedianVal(se.med.maxConfirms(),
+			sufficientFeeTxs, successThreshold, se.bestHeight)
+		if medMax > 0 && (estimate == -1 || medMax < estimate) {
+			estimate = medMax
+		}
+	}
+	if confTarget > se.short.maxConfirms() {
+		shortMax := se.short.estimateMedianVal(se.short.maxConfirms(),
+			sufficientTxsShort, successThreshold, se.bestHeight)
+		if shortMax > 0 && (estimate == -1 || shortMax < estimate) {
+			estimate = shortMax
+		}
+	}
+	return estimate
+}
+
+// estimateConservativeFee returns the fee rate in satoshis per kilobyte needed
+// for transactions to be confirmed within the passed target with a very high
+// probability over the medium and long horizons, or -1 when there is not
+// enough data.
+func (se *smartFeeEstimator) estimateConservativeFee(doubleTarget int32) float64 {
+	estimate := float64(-1)
+	if doubleTarget <= se.short.maxConfirms() {
+		estimate = se.med.estimateMedianVal(doubleTarget,
+			sufficientFeeTxs, doubleSuccessPct, se.bestHeight)
+	}
+	if doubleTarget <= se.med.maxConfirms() {
+		longEstimate := se.long.estimateMedianVal(doubleTarget,
+			sufficientFeeTxs, doubleSuccessPct, se.bestHeight)
+		if longEstimate > estimate {
+			estimate = longEstimate
+		}
+	}
+	return estimate
+}
+
+// estimateSmartFee returns the fee rate in satoshis per kilobyte needed for a
+// transaction to be confirmed within the passed target along with the target
+// the estimate is actually for, which can be lower when there is not enough
+// data yet.  Conservative estimates also take the longer horizons into
+// account, so they are less likely to be too low when fee rates recently
+// dropped.
+func (se *smartFeeEstimator) estimateSmartFee(confTarget int32,
+	conservative bool) (float64, int32, error) {
+
+	if confTarget < 1 || confTarget > se.long.maxConfirms() {
+		return 0, 0, fmt.Errorf("confirmation target must be between "+
+			"1 and %d", se.long.maxConfirms())
+	}
+
+	// It is not possible to reliably estimate fees for the very next
+	// block, so use the lowest meaningful target instead.
+	if confTarget == 1 {
+		confTarget = 2
+	}
+	if maxUsable := se.maxUsableEstimate(); confTarget > maxUsable {
+		confTarget = maxUsable
+	}
+	if confTarget <= 1 {
+		return 0, 0, errors.New("insufficient data or no feerate found")
+	}
+
+	// The estimate is the highest fee rate needed for transactions to be
+	// confirmed with a low probability within half of the target, with a
+	// medium probability within the target, and with a high probability
+	// within twice the target.
+	estimate := se.estimateCombinedFee(confTarget/2, halfSuccessPct, true)
+	actual := se.estimateCombinedFee(confTarget, successPct, true)
+	if actual > estimate {
+		estimate = actual
+	}
+	double := se.estimateCombinedFee(2*confTarget, doubleSuccessPct,
+		!conservative)
+	if double > estimate {
+		estimate = double
+	}
+	if conservative || estimate == -1 {
+		consEstimate := se.estimateConservativeFee(2 * confTarget)
+		if consEstimate > estimate {
+			estimate = consEstimate
+		}
+	}
+	if estimate < 0 {
+		return 0, confTarget, errors.New("insufficient data or no " +
+			"feerate found")
+	}
+	return estimate, confTarget, nil
+}
+
+// serialize writes the state of the estimator to w.  Transactions that are
+// still unconfirmed are not saved, since it is unknown whether they are still
+// in the mempool after a restart, so they are no longer tracked once the
+// estimator is restored.
+func (se *smartFeeEstimator) serialize(w io.Writer) {
+	binary.Write(w, binary.BigEndian, se.bestHeight)
+	binary.Write(w, binary.BigEndian, se.firstRecordedHeight)
+	binary.Write(w, binary.BigEndian, uint32(len(feeBuckets)))
+	for _, stats := range se.allStats() {
+		stats.serialize(w)
+	}
+}
+
+// deserializeSmartFeeEstimator reads the state of an estimator previously
+// written by serialize from r.
+func deserializeSmartFeeEstimator(r io.Reader) (*smartFeeEstimator, error) {
+	se := newSmartFeeEstimator()
+	err := binary.Read(r, binary.BigEndian, &se.bestHeight)
+	if err != nil {
+		return nil, err
+	}
+	err = binary.Read(r, binary.BigEndian, &se.firstRecordedHeight)
+	if err != nil {
+		return nil, err
+	}
+	var numBuckets uint32
+	if err := binary.Read(r, binary.BigEndian, &numBuckets); err != nil {
+		return nil, err
+	}
+	if numBuckets != uint32(len(feeBuckets)) {
+		return nil, fmt.Errorf("unexpected number of fee buckets %d",
+			numBuckets)
+	}
+	for _, stats := range se.allStats() {
+		if err := stats.deserialize(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return se, nil
+}
@@ -219,6 +219,7 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 	// Create some convenience variables.
 	header := &msgBlock.Header
 	targetDifficulty := blockchain.CompactToBig(header.Bits)
+	powAlgo := m.cfg.ChainParams.PowAlgorithm(blockHeight)
 
 	// Initial state.
 	lastGenerated := time.Now()
@@ -269,12 +270,11 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 				// Non-blocking select to fall through
 			}
 
-			// Update the nonce and hash the block header.  Each
-			// hash is actually a double sha256 (two hashes), so
-			// increment the number of hashes completed for each
-			// attempt accordingly.
+			// Update the nonce and hash the block header with the
+			// proof-of-work algorithm in effect at the height of
+			// the block.
 			header.Nonce = i
-			hash, err := header.PowHash()
+			hash, err := header.PowHash(powAlgo)
 			if err != nil {
 				return false
 			}
@@ -51,6 +51,19 @@ type TxDesc struct {
 
 	// FeePerKB is the fee the transaction pays in Satoshi per 1000 bytes.
 	FeePerKB int64
+
+	// FeeDelta is the amount the fee of the transaction is modified by when
+	// it is compared with other transactions, such as when selecting the
+	// transactions to include in a block.
+	FeeDelta int64
+
+	// AncestorFees and AncestorSize are the total modified fees and virtual
+	// size of the transaction along with all of its unconfirmed ancestors
+	// in the source pool.  A zero size means the source pool does not
+	// track them, in which case the transaction is treated as if it had no
+	// ancestors.
+	AncestorFees int64
+	AncestorSize int64
 }
 
 // TxSource represents a source of transactions to consider for inclusion in
@@ -78,14 +91,46 @@ type TxSource interface {
 type txPrioItem struct {
 	tx       *acmutil.Tx
 	fee      int64
+	size     int64
 	priority float64
-	feePerKB int64
+
+	// modifiedFee is the fee of the transaction modified by its fee delta,
+	// which is what the fee per kilobyte of its package is based on.
+	modifiedFee int64
+
+	// feePerKB is the fee per kilobyte of the package formed by the
+	// transaction along with all of its ancestors in the source pool that
+	// have not been included in the block yet.  ancestorFees and
+	// ancestorSize are the total modified fees and virtual size of that
+	// package.  They start out with the statistics of the source pool and
+	// are reduced as ancestors are included so that a transaction paying a
+	// high fee raises the priority of the ancestors it depends on
+	// (child-pays-for-parent).
+	feePerKB     int64
+	ancestorFees int64
+	ancestorSize int64
 
 	// dependsOn holds a map of transaction hashes which this one depends
 	// on.  It will only be set when the transaction references other
 	// transactions in the source pool and hence must come after them in
-	// a block.
+	// a block.  Hashes are removed as the transactions are included.
 	dependsOn map[chainhash.Hash]struct{}
+
+	// index is the index of the item in the priority queue or -1 when it
+	// is not in the queue.
+	index int
+}
+
+// setAncestorStats sets the fees and size of the package formed by the item
+// along with the provided ancestors and updates the fee per kilobyte of the
+// item accordingly.
+func (item *txPrioItem) setAncestorStats(fees, size int64) {
+	item.ancestorFees = fees
+	item.ancestorSize = size
+	item.feePerKB = 0
+	if size > 0 {
+		item.feePerKB = fees * 1000 / size
+	}
 }
 
 // txPriorityQueueLessFunc describes a function that can be used as a compare
@@ -116,12 +161,16 @@ func (pq *txPriorityQueue) Less(i, j int) bool {
 // part of the heap.Interface implementation.
 func (pq *txPriorityQueue) Swap(i, j int) {
 	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.items[i].index = i
+	pq.items[j].index = j
 }
 
 // Push pushes the passed item onto the priority queue.  It is part of the
 // heap.Interface implementation.
 func (pq *txPriorityQueue) Push(x interface{}) {
-	pq.items = append(pq.items, x.(*txPrioItem))
+	item := x.(*txPrioItem)
+	item.index = len(pq.items)
+	pq.items = append(pq.items, item)
 }
 
 // Pop removes the highest priority item (according to Less) from the priority
@@ -129,6 +178,7 @@ func (pq *txPriorityQueue) Push(x interface{}) {
 func (pq *txPriorityQueue) Pop() interface{} {
 	n := len(pq.items)
 	item := pq.items[n-1]
+	item.index = -1
 	pq.items[n-1] = nil
 	pq.items = pq.items[0 : n-1]
 	return item
@@ -154,8 +204,8 @@ func txPQByPriority(pq *txPriorityQueue, i, j int) bool {
 
 }
 
-// txPQByFee sorts a txPriorityQueue by fees per kilobyte and then transaction
-// priority.
+// txPQByFee sorts a txPriorityQueue by the fees per kilobyte of the transaction
+// packages and then transaction priority.
 func txPQByFee(pq *txPriorityQueue, i, j int) bool {
 	// Using > here so that pop gives the highest fee item as opposed
 	// to the lowest.  Sort by fee first, then priority.
@@ -301,16 +351,121 @@ func spendTransaction(utxoView *blockchain.UtxoViewpoint, tx *acmutil.Tx, height
 	return nil
 }
 
-// logSkippedDeps logs any dependencies which are also skipped as a result of
-// skipping a transaction while generating a block template at the trace level.
-func logSkippedDeps(tx *acmutil.Tx, deps map[chainhash.Hash]*txPrioItem) {
-	if deps == nil {
+// txVirtualSize returns the virtual size of the passed transaction, which is
+// its weight scaled down by the witness scale factor and rounded up.
+func txVirtualSize(tx *acmutil.Tx) int64 {
+	weight := blockchain.GetTransactionWeight(tx)
+	return (weight + blockchain.WitnessScaleFactor - 1) /
+		blockchain.WitnessScaleFactor
+}
+
+// packageAncestors returns the ancestors of the passed item which have not been
+// included in the block yet, ordered such that every transaction comes after
+// the transactions it depends on.  False is returned when the item depends on
+// a transaction that is no longer a candidate for inclusion.
+func packageAncestors(item *txPrioItem,
+	candidates map[chainhash.Hash]*txPrioItem) ([]*txPrioItem, bool) {
+
+	var ancestors []*txPrioItem
+	visited := make(map[*txPrioItem]struct{})
+	var visit func(item *txPrioItem) bool
+	visit = func(item *txPrioItem) bool {
+		for hash := range item.dependsOn {
+			parent, ok := candidates[hash]
+			if !ok {
+				return false
+			}
+			if _, ok := visited[parent]; ok {
+				continue
+			}
+			visited[parent] = struct{}{}
+			if !visit(parent) {
+				return false
+			}
+			ancestors = append(ancestors, parent)
+		}
+		return true
+	}
+	if !visit(item) {
+		return nil, false
+	}
+	return ancestors, true
+}
+
+// skipTx removes the passed item along with all transactions which depend on
+// it from the candidates for inclusion in the block and from the priority
+// queue.  Skipped transactions are logged at the trace level.
+func skipTx(item *txPrioItem, candidates map[chainhash.Hash]*txPrioItem,
+	dependers map[chainhash.Hash]map[chainhash.Hash]*txPrioItem,
+	priorityQueue *txPriorityQueue) {
+
+	hash := *item.tx.Hash()
+	if _, ok := candidates[hash]; !ok {
 		return
 	}
+	delete(candidates, hash)
+	if item.index >= 0 {
+		heap.Remove(priorityQueue, item.index)
+	}
 
-	for _, item := range deps {
-		log.Tracef("Skipping tx %s since it depends on %s\n",
-			item.tx.Hash(), tx.Hash())
+	for _, dep := range dependers[hash] {
+		if _, ok := candidates[*dep.tx.Hash()]; !ok {
+			continue
+		}
+		log.Tracef("Skipping tx %s since it depends on %s",
+			dep.tx.Hash(), item.tx.Hash())
+		skipTx(dep, candidates, dependers, priorityQueue)
+	}
+}
+
+// includeTx removes the passed item, which has been included in the block, from
+// the candidates and from the priority queue.  The package statistics of all
+// transactions which depend on it are updated since the package they form no
+// longer contains it, and transactions which no longer depend on any other
+// transactions that have not been included are added to the priority queue.
+func includeTx(item *txPrioItem, candidates map[chainhash.Hash]*txPrioItem,
+	dependers map[chainhash.Hash]map[chainhash.Hash]*txPrioItem,
+	priorityQueue *txPriorityQueue) {
+
+	hash := *item.tx.Hash()
+	delete(candidates, hash)
+	if item.index >= 0 {
+		heap.Remove(priorityQueue, item.index)
+	}
+
+	// Update the package statistics of all descendants.
+	visited := make(map[chainhash.Hash]struct{})
+	var update func(hash chainhash.Hash)
+	update = func(hash chainhash.Hash) {
+		for depHash, dep := range dependers[hash] {
+			if _, ok := candidates[depHash]; !ok {
+				continue
+			}
+			if _, ok := visited[depHash]; ok {
+				continue
+			}
+			visited[depHash] = struct{}{}
+
+			dep.setAncestorStats(dep.ancestorFees-item.modifiedFee,
+				dep.ancestorSize-item.size)
+			if dep.index >= 0 {
+				heap.Fix(priorityQueue, dep.index)
+			}
+			update(depHash)
+		}
+	}
+	update(hash)
+
+	// Add transactions which depend on this one (and also do not have
+	// any other unsatisified dependencies) to the priority queue.
+	for depHash, dep := range dependers[hash] {
+		if _, ok := candidates[depHash]; !ok {
+			continue
+		}
+		delete(dep.dependsOn, hash)
+		if len(dep.dependsOn) == 0 && dep.index < 0 {
+			heap.Push(priorityQueue, dep)
+		}
 	}
 }
 
@@ -407,7 +562,13 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 // Once the high-priority area (if configured) has been filled with
 // transactions, or the priority falls below what is considered high-priority,
 // the priority queue is updated to prioritize by fees per kilobyte (then
-// priority).
+// priority) and all remaining transactions are added to it.  The fee per
+// kilobyte used when prioritizing by fee is that of the package formed by a
+// transaction along with all of its ancestors in the source pool which have
+// not been included yet, and the whole package is included at once.  As
+// ancestors are included, the packages of their descendants are updated
+// accordingly.  This allows a transaction paying a high fee to pull in the
+// low-fee transactions it depends on (child-pays-for-parent).
 //
 // When the fees per kilobyte drop below the TxMinFreeFee policy setting, the
 // transaction will be skipped unless the BlockMinSize policy setting is
@@ -490,6 +651,11 @@ func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress acmutil.Address) (*Bloc
 	// in the block once each transaction has been included.
 	dependers := make(map[chainhash.Hash]map[chainhash.Hash]*txPrioItem)
 
+	// candidates houses all transactions which are still eligible for
+	// inclusion in the block, that is those that have neither been
+	// included nor skipped yet.
+	candidates := make(map[chainhash.Hash]*txPrioItem, len(sourceTxns))
+
 	// Create slices to hold the fees and number of signature operations
 	// for each of the selected transactions and add an entry for the
 	// coinbase.  This allows the code below to simply append details about
@@ -535,7 +701,7 @@ mempoolLoop:
 		// Setup dependencies for any transactions which reference
 		// other transactions in the mempool so they can be properly
 		// ordered below.
-		prioItem := &txPrioItem{tx: tx}
+		prioItem := &txPrioItem{tx: tx, index: -1}
 		for _, txIn := range tx.MsgTx().TxIn {
 			originHash := &txIn.PreviousOutPoint.Hash
 			entry := utxos.LookupEntry(txIn.PreviousOutPoint)
@@ -575,15 +741,17 @@ mempoolLoop:
 		prioItem.priority = CalcPriority(tx.MsgTx(), utxos,
 			nextBlockHeight)
 
-		// Calculate the fee in Satoshi/kB.
-		prioItem.feePerKB = txDesc.FeePerKB
 		prioItem.fee = txDesc.Fee
-
-		// Add the transaction to the priority queue to mark it ready
-		// for inclusion in the block unless it has dependencies.
-		if prioItem.dependsOn == nil {
-			heap.Push(priorityQueue, prioItem)
+		prioItem.modifiedFee = txDesc.Fee + txDesc.FeeDelta
+		prioItem.size = txVirtualSize(tx)
+		if txDesc.AncestorSize > 0 {
+			prioItem.setAncestorStats(txDesc.AncestorFees,
+				txDesc.AncestorSize)
+		} else {
+			prioItem.setAncestorStats(prioItem.modifiedFee,
+				prioItem.size)
 		}
+		candidates[*tx.Hash()] = prioItem
 
 		// Merge the referenced outputs from the input transactions to
 		// this transaction into the block utxo view.  This allows the
@@ -591,6 +759,32 @@ mempoolLoop:
 		mergeUtxoView(blockUtxos, utxos)
 	}
 
+	// Transactions which depend on a transaction that is not a candidate
+	// for inclusion can never be included, so they are skipped along with
+	// all of the transactions which depend on them in turn.
+	for _, prioItem := range candidates {
+		for hash := range prioItem.dependsOn {
+			if _, ok := candidates[hash]; ok {
+				continue
+			}
+			log.Tracef("Skipping tx %s because it depends on a "+
+				"transaction which can't be included",
+				prioItem.tx.Hash())
+			skipTx(prioItem, candidates, dependers, priorityQueue)
+			break
+		}
+	}
+
+	// Add the transactions to the priority queue to mark them ready for
+	// inclusion in the block.  When sorting by fee, every transaction is
+	// added since it is included along with its ancestors.  Otherwise,
+	// transactions are only added once they no longer have dependencies.
+	for _, prioItem := range candidates {
+		if sortedByFee || len(prioItem.dependsOn) == 0 {
+			heap.Push(priorityQueue, prioItem)
+		}
+	}
+
 	log.Tracef("Priority queue len %d, dependers len %d",
 		priorityQueue.Len(), len(dependers))
 
@@ -614,24 +808,47 @@ mempoolLoop:
 
 	witnessIncluded := false
 
-	// Choose which transactions make it into the block.
+	// Choose which transactions make it into the block.  Each transaction
+	// is included along with all of its ancestors that have not been
+	// included yet, which is what allows a transaction paying a high fee to
+	// pull in the ancestors it depends on (child-pays-for-parent).
 	for priorityQueue.Len() > 0 {
-		// Grab the highest priority (or highest fee per kilobyte
-		// depending on the sort order) transaction.
+		// Grab the highest priority (or highest package fee per
+		// kilobyte depending on the sort order) transaction.
 		prioItem := heap.Pop(priorityQueue).(*txPrioItem)
 		tx := prioItem.tx
 
-		switch {
+		ancestors, ok := packageAncestors(prioItem, candidates)
+		if !ok {
+			skipTx(prioItem, candidates, dependers, priorityQueue)
+			continue
+		}
+		pkg := append(ancestors, prioItem)
+
 		// If segregated witness has not been activated yet, then we
 		// shouldn't include any witness transactions in the block.
-		case !segwitActive && tx.HasWitness():
+		var pkgHasWitness, skipped bool
+		for _, item := range pkg {
+			if !item.tx.HasWitness() {
+				continue
+			}
+			if !segwitActive {
+				skipTx(item, candidates, dependers,
+					priorityQueue)
+				skipped = true
+				break
+			}
+			pkgHasWitness = true
+		}
+		if skipped {
 			continue
+		}
 
-		// Otherwise, Keep track of if we've included a transaction
-		// with witness data or not. If so, then we'll need to include
-		// the witness commitment as the last output in the coinbase
+		// Otherwise, Keep track of if we've included a transaction with
+		// witness data or not. If so, then we'll need to include the
+		// witness commitment as the last output in the coinbase
 		// transaction.
-		case segwitActive && !witnessIncluded && tx.HasWitness():
+		if segwitActive && !witnessIncluded && pkgHasWitness {
 			// If we're about to include a transaction bearing
 			// witness data, then we'll also need to include a
 			// witness commitment in the coinbase transaction.
@@ -660,36 +877,18 @@ mempoolLoop:
 			witnessIncluded = true
 		}
 
-		// Grab any transactions which depend on this one.
-		deps := dependers[*tx.Hash()]
-
 		// Enforce maximum block size.  Also check for overflow.
-		txWeight := uint32(blockchain.GetTransactionWeight(tx))
-		blockPlusTxWeight := blockWeight + txWeight
+		var pkgWeight uint32
+		for _, item := range pkg {
+			pkgWeight += uint32(blockchain.GetTransactionWeight(item.tx))
+		}
+		blockPlusTxWeight := blockWeight + pkgWeight
 		if blockPlusTxWeight < blockWeight ||
 			blockPlusTxWeight >= g.policy.BlockMaxWeight {
 
 			log.Tracef("Skipping tx %s because it would exceed "+
 				"the max block weight", tx.Hash())
-			logSkippedDeps(tx, deps)
-			continue
-		}
-
-		// Enforce maximum signature operation cost per block.  Also
-		// check for overflow.
-		sigOpCost, err := blockchain.GetSigOpCost(tx, false,
-			blockUtxos, true, segwitActive)
-		if err != nil {
-			log.Tracef("Skipping tx %s due to error in "+
-				"GetSigOpCost: %v", tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
-		}
-		if blockSigOpCost+int64(sigOpCost) < blockSigOpCost ||
-			blockSigOpCost+int64(sigOpCost) > blockchain.MaxBlockSigOpsCost {
-			log.Tracef("Skipping tx %s because it would "+
-				"exceed the maximum sigops per block", tx.Hash())
-			logSkippedDeps(tx, deps)
+			skipTx(prioItem, candidates, dependers, priorityQueue)
 			continue
 		}
 
@@ -704,7 +903,7 @@ mempoolLoop:
 				"minBlockWeight %d", tx.Hash(), prioItem.feePerKB,
 				g.policy.TxMinFreeFee, blockPlusTxWeight,
 				g.policy.BlockMinWeight)
-			logSkippedDeps(tx, deps)
+			skipTx(prioItem, candidates, dependers, priorityQueue)
 			continue
 		}
 
@@ -720,7 +919,15 @@ mempoolLoop:
 				blockPlusTxWeight, g.policy.BlockPrioritySize,
 				prioItem.priority, MinHighPriority)
 
+			// Transactions are included along with their ancestors
+			// when sorting by fee, so all remaining transactions
+			// are eligible now.
 			sortedByFee = true
+			for _, item := range candidates {
+				if item != prioItem && item.index < 0 {
+					priorityQueue.Push(item)
+				}
+			}
 			priorityQueue.SetLessFunc(txPQByFee)
 
 			// Put the transaction back into the priority queue and
@@ -737,55 +944,80 @@ mempoolLoop:
 			}
 		}
 
-		// Ensure the transaction inputs pass all of the necessary
-		// preconditions before allowing it to be added to the block.
-		_, err = blockchain.CheckTransactionInputs(tx, nextBlockHeight,
-			blockUtxos, g.chainParams)
-		if err != nil {
-			log.Tracef("Skipping tx %s due to error in "+
-				"CheckTransactionInputs: %v", tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
-		}
-		err = blockchain.ValidateTransactionScripts(tx, blockUtxos,
-			txscript.StandardVerifyFlags, g.sigCache,
-			g.hashCache)
-		if err != nil {
-			log.Tracef("Skipping tx %s due to error in "+
-				"ValidateTransactionScripts: %v", tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
-		}
+		// Add the transactions of the package in order.  Should one of
+		// them turn out to be invalid, the transactions before it
+		// remain in the block since they don't depend on it.
+		for _, item := range pkg {
+			tx := item.tx
+
+			// Enforce maximum signature operation cost per block.
+			// Also check for overflow.
+			sigOpCost, err := blockchain.GetSigOpCost(tx, false,
+				blockUtxos, true, segwitActive)
+			if err != nil {
+				log.Tracef("Skipping tx %s due to error in "+
+					"GetSigOpCost: %v", tx.Hash(), err)
+				skipTx(item, candidates, dependers,
+					priorityQueue)
+				break
+			}
+			if blockSigOpCost+int64(sigOpCost) < blockSigOpCost ||
+				blockSigOpCost+int64(sigOpCost) > blockchain.MaxBlockSigOpsCost {
+				log.Tracef("Skipping tx %s because it would "+
+					"exceed the maximum sigops per block",
+					tx.Hash())
+				skipTx(item, candidates, dependers,
+					priorityQueue)
+				break
+			}
 
-		// Spend the transaction inputs in the block utxo view and add
-		// an entry for it to ensure any transactions which reference
-		// this one have it available as an input and can ensure they
-		// aren't double spending.
-		spendTransaction(blockUtxos, tx, nextBlockHeight)
-
-		// Add the transaction to the block, increment counters, and
-		// save the fees and signature operation counts to the block
-		// template.
-		blockTxns = append(blockTxns, tx)
-		blockWeight += txWeight
-		blockSigOpCost += int64(sigOpCost)
-		totalFees += prioItem.fee
-		txFees = append(txFees, prioItem.fee)
-		txSigOpCosts = append(txSigOpCosts, int64(sigOpCost))
-
-		log.Tracef("Adding tx %s (priority %.2f, feePerKB %.2f)",
-			prioItem.tx.Hash(), prioItem.priority, prioItem.feePerKB)
-
-		// Add transactions which depend on this one (and also do not
-		// have any other unsatisified dependencies) to the priority
-		// queue.
-		for _, item := range deps {
-			// Add the transaction to the priority queue if there
-			// are no more dependencies after this one.
-			delete(item.dependsOn, *tx.Hash())
-			if len(item.dependsOn) == 0 {
-				heap.Push(priorityQueue, item)
+			// Ensure the transaction inputs pass all of the
+			// necessary preconditions before allowing it to be
+			// added to the block.
+			_, err = blockchain.CheckTransactionInputs(tx,
+				nextBlockHeight, blockUtxos, g.chainParams)
+			if err != nil {
+				log.Tracef("Skipping tx %s due to error in "+
+					"CheckTransactionInputs: %v", tx.Hash(),
+					err)
+				skipTx(item, candidates, dependers,
+					priorityQueue)
+				break
 			}
+			err = blockchain.ValidateTransactionScripts(tx,
+				blockUtxos, txscript.StandardVerifyFlags,
+				g.sigCache, g.hashCache)
+			if err != nil {
+				log.Tracef("Skipping tx %s due to error in "+
+					"ValidateTransactionScripts: %v",
+					tx.Hash(), err)
+				skipTx(item, candidates, dependers,
+					priorityQueue)
+				break
+			}
+
+			// Spend the transaction inputs in the block utxo view
+			// and add an entry for it to ensure any transactions
+			// which reference this one have it available as an
+			// input and can ensure they aren't double spending.
+			spendTransaction(blockUtxos, tx, nextBlockHeight)
+
+			// Add the transaction to the block, increment counters,
+			// and save the fees and signature operation counts to
+			// the block template.
+			blockTxns = append(blockTxns, tx)
+			blockWeight += uint32(blockchain.GetTransactionWeight(tx))
+			blockSigOpCost += int64(sigOpCost)
+			totalFees += item.fee
+			txFees = append(txFees, item.fee)
+			txSigOpCosts = append(txSigOpCosts, int64(sigOpCost))
+
+			log.Tracef("Adding tx %s (priority %.2f, feePerKB %d)",
+				tx.Hash(), item.priority, item.feePerKB)
+
+			// Update the transactions which depend on this one now
+			// that it has been included.
+			includeTx(item, candidates, dependers, priorityQueue)
 		}
 	}
 
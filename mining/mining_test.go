@@ -9,6 +9,8 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/wire"
 	"github.com/Actinium-project/acmutil"
 )
 
@@ -108,3 +110,141 @@ func TestTxFeePrioHeap(t *testing.T) {
 		highest = prioItem
 	}
 }
+
+// TestAncestorPackages ensures the package statistics used to prioritize
+// transactions by fee account for their ancestors, so a transaction paying a
+// high fee lifts the ancestors it depends on, and that the statistics are
+// updated as transactions are included or skipped.
+func TestAncestorPackages(t *testing.T) {
+	// newItem returns a priority item for a unique transaction which
+	// spends an output of each of the passed parents.  Its package
+	// statistics start out the way the source pool reports them, so they
+	// include the fees and size of all of its ancestors.
+	var lockTime uint32
+	itemAncestors := make(map[*txPrioItem]map[*txPrioItem]struct{})
+	newItem := func(fee, size int64, parents ...*txPrioItem) *txPrioItem {
+		lockTime++
+		msgTx := wire.NewMsgTx(wire.TxVersion)
+		msgTx.LockTime = lockTime
+		item := &txPrioItem{fee: fee, modifiedFee: fee, size: size,
+			index: -1}
+		ancestors := make(map[*txPrioItem]struct{})
+		for _, parent := range parents {
+			hash := *parent.tx.Hash()
+			msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&hash, 0),
+				nil, nil))
+			if item.dependsOn == nil {
+				item.dependsOn = make(map[chainhash.Hash]struct{})
+			}
+			item.dependsOn[hash] = struct{}{}
+			ancestors[parent] = struct{}{}
+			for ancestor := range itemAncestors[parent] {
+				ancestors[ancestor] = struct{}{}
+			}
+		}
+		item.tx = acmutil.NewTx(msgTx)
+		itemAncestors[item] = ancestors
+
+		fees, size := item.modifiedFee, item.size
+		for ancestor := range ancestors {
+			fees += ancestor.modifiedFee
+			size += ancestor.size
+		}
+		item.setAncestorStats(fees, size)
+		return item
+	}
+
+	// Create a low fee parent with a high fee child, a grandchild which
+	// also depends on an unrelated transaction, and an unrelated
+	// transaction paying a medium fee.
+	parent := newItem(100, 1000)
+	child := newItem(10000, 1000, parent)
+	other := newItem(500, 1000)
+	grandchild := newItem(100, 1000, child, other)
+	medium := newItem(3000, 1000)
+	items := []*txPrioItem{parent, child, other, grandchild, medium}
+
+	candidates := make(map[chainhash.Hash]*txPrioItem)
+	dependers := make(map[chainhash.Hash]map[chainhash.Hash]*txPrioItem)
+	for _, item := range items {
+		hash := *item.tx.Hash()
+		candidates[hash] = item
+		for parentHash := range item.dependsOn {
+			deps, ok := dependers[parentHash]
+			if !ok {
+				deps = make(map[chainhash.Hash]*txPrioItem)
+				dependers[parentHash] = deps
+			}
+			deps[hash] = item
+		}
+	}
+
+	priorityQueue := newTxPriorityQueue(len(items), true)
+	for _, item := range items {
+		heap.Push(priorityQueue, item)
+	}
+
+	// The ancestors of the grandchild must come before the transactions
+	// which depend on them.
+	ancestors, _ := packageAncestors(grandchild, candidates)
+	if len(ancestors) != 3 {
+		t.Fatalf("unexpected number of ancestors: got %d, want 3",
+			len(ancestors))
+	}
+	pos := make(map[*txPrioItem]int)
+	for i, ancestor := range ancestors {
+		pos[ancestor] = i
+	}
+	if pos[parent] > pos[child] {
+		t.Fatalf("parent ordered after child")
+	}
+
+	// The package of the child pays the most, so it must be chosen first
+	// even though the parent pays the lowest fee.
+	if got := heap.Pop(priorityQueue).(*txPrioItem); got != child {
+		t.Fatalf("unexpected first package: got fee %d, want fee %d",
+			got.fee, child.fee)
+	}
+	if child.feePerKB != 10100*1000/2000 {
+		t.Fatalf("unexpected child package fee rate: %d",
+			child.feePerKB)
+	}
+
+	// Including the parent must remove it from the packages of its
+	// descendants and make the child ready.
+	includeTx(parent, candidates, dependers, priorityQueue)
+	if child.ancestorFees != 10000 || child.ancestorSize != 1000 {
+		t.Fatalf("unexpected child package after including parent: "+
+			"fees %d, size %d", child.ancestorFees,
+			child.ancestorSize)
+	}
+	if grandchild.ancestorFees != 10600 ||
+		grandchild.ancestorSize != 3000 {
+
+		t.Fatalf("unexpected grandchild package after including "+
+			"parent: fees %d, size %d", grandchild.ancestorFees,
+			grandchild.ancestorSize)
+	}
+	if len(child.dependsOn) != 0 || child.index < 0 {
+		t.Fatalf("child not ready after including parent")
+	}
+
+	// Skipping the unrelated transaction must also skip the grandchild
+	// which depends on it, leaving only the child and the medium fee
+	// transaction in order.
+	skipTx(other, candidates, dependers, priorityQueue)
+	if _, ok := candidates[*grandchild.tx.Hash()]; ok {
+		t.Fatalf("grandchild not skipped along with its parent")
+	}
+	want := []*txPrioItem{child, medium}
+	if priorityQueue.Len() != len(want) {
+		t.Fatalf("unexpected queue length: got %d, want %d",
+			priorityQueue.Len(), len(want))
+	}
+	for i, wantItem := range want {
+		if got := heap.Pop(priorityQueue).(*txPrioItem); got != wantItem {
+			t.Fatalf("unexpected item %d: got fee %d, want fee %d",
+				i, got.fee, wantItem.fee)
+		}
+	}
+}
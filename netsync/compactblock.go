@@ -0,0 +1,140 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/mempool"
+	"github.com/Actinium-project/acmd/wire"
+	"github.com/Actinium-project/acmutil"
+)
+
+// maxHighBandwidthPeers is the maximum number of peers that are asked to
+// announce new blocks directly via cmpctblock messages (high-bandwidth mode)
+// at any given time.
+const maxHighBandwidthPeers = 3
+
+// errShortIDCollision indicates a compact block contains the same short
+// transaction id more than once, in which case it can't be reconstructed
+// reliably and the full block must be requested instead.
+var errShortIDCollision = errors.New("duplicate short transaction id")
+
+// partialBlock houses a block that is being reconstructed from a compact block
+// along with the positions of the transactions that are still missing.
+type partialBlock struct {
+	header  wire.BlockHeader
+	hash    chainhash.Hash
+	txns    []*wire.MsgTx
+	missing []uint32
+}
+
+// newPartialBlock returns a partial block for the provided compact block with
+// the prefilled transactions in place and all transactions the mempool can
+// provide filled in.  Short ids are calculated from witness hashes when
+// useWitnessHash is set (compact block version 2) and from transaction hashes
+// otherwise (version 1).
+//
+// Transactions in the mempool whose short ids collide with each other are
+// treated as missing so they are requested from the peer.
+func newPartialBlock(msg *wire.MsgCmpctBlock, txPool *mempool.TxPool,
+	useWitnessHash bool) (*partialBlock, error) {
+
+	totalTxns := msg.TotalTxns()
+	if totalTxns == 0 {
+		return nil, errors.New("compact block has no transactions")
+	}
+
+	pb := &partialBlock{
+		header: msg.Header,
+		hash:   msg.Header.BlockHash(),
+		txns:   make([]*wire.MsgTx, totalTxns),
+	}
+	for _, ptx := range msg.PrefilledTxs {
+		if int(ptx.Index) >= totalTxns || pb.txns[ptx.Index] != nil {
+			return nil, fmt.Errorf("invalid prefilled transaction "+
+				"index %d", ptx.Index)
+		}
+		pb.txns[ptx.Index] = ptx.Tx
+	}
+
+	// Map each short id to the position it takes in the block.  The short
+	// ids fill the positions not taken by prefilled transactions in order.
+	shortIDIndex := make(map[uint64]uint32, len(msg.ShortIDs))
+	pos := uint32(0)
+	for _, shortID := range msg.ShortIDs {
+		for pb.txns[pos] != nil {
+			pos++
+		}
+		if _, exists := shortIDIndex[shortID]; exists {
+			return nil, errShortIDCollision
+		}
+		shortIDIndex[shortID] = pos
+		pos++
+	}
+
+	// Fill in the transactions the mempool already knows about.
+	k0, k1 := msg.ShortIDKeys()
+	collided := make(map[uint32]struct{})
+	for _, txDesc := range txPool.TxDescs() {
+		hash := txDesc.Tx.Hash()
+		if useWitnessHash {
+			hash = txDesc.Tx.WitnessHash()
+		}
+		index, ok := shortIDIndex[wire.ShortTxID(k0, k1, hash)]
+		if !ok {
+			continue
+		}
+		if _, ok := collided[index]; ok {
+			continue
+		}
+		if pb.txns[index] != nil {
+			pb.txns[index] = nil
+			collided[index] = struct{}{}
+			continue
+		}
+		pb.txns[index] = txDesc.Tx.MsgTx()
+	}
+
+	for i, tx := range pb.txns {
+		if tx == nil {
+			pb.missing = append(pb.missing, uint32(i))
+		}
+	}
+	return pb, nil
+}
+
+// fill adds the transactions from the provided blocktxn message to the
+// positions of the partial block that are still missing.  An error is returned
+// when the message does not provide exactly the missing transactions.
+func (pb *partialBlock) fill(msg *wire.MsgBlockTxn) error {
+	if msg.BlockHash != pb.hash {
+		return fmt.Errorf("blocktxn for block %v does not match "+
+			"pending compact block %v", msg.BlockHash, pb.hash)
+	}
+	if len(msg.Transactions) != len(pb.missing) {
+		return fmt.Errorf("blocktxn for block %v provides %d "+
+			"transactions, expected %d", pb.hash,
+			len(msg.Transactions), len(pb.missing))
+	}
+
+	for i, index := range pb.missing {
+		pb.txns[index] = msg.Transactions[i]
+	}
+	pb.missing = nil
+	return nil
+}
+
+// block returns the reconstructed block.  It must only be called once no
+// transactions are missing.
+func (pb *partialBlock) block() *acmutil.Block {
+	msgBlock := wire.MsgBlock{
+		Header:       pb.header,
+		Transactions: pb.txns,
+	}
+	return acmutil.NewBlock(&msgBlock)
+}
@@ -5,6 +5,8 @@
 package netsync
 
 import (
+	"math/big"
+
 	"github.com/Actinium-project/acmd/blockchain"
 	"github.com/Actinium-project/acmd/chaincfg"
 	"github.com/Actinium-project/acmd/chaincfg/chainhash"
@@ -37,5 +39,15 @@ type Config struct {
 	DisableCheckpoints bool
 	MaxPeers           int
 
+	// AssumeValid is the hash of the block whose ancestors in the best
+	// header chain are not subject to script validation.  Scripts are
+	// always validated when it is nil.
+	AssumeValid *chainhash.Hash
+
+	// MinimumChainWork is the minimum amount of cumulative work a header
+	// chain must have before the blocks it describes are downloaded.  There
+	// is no minimum when it is nil.
+	MinimumChainWork *big.Int
+
 	FeeEstimator *mempool.FeeEstimator
 }
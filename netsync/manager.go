@@ -6,6 +6,7 @@ package netsync
 
 import (
 	"container/list"
+	"math/big"
 	"math/rand"
 	"net"
 	"sync"
@@ -23,10 +24,36 @@ import (
 )
 
 const (
-	// minInFlightBlocks is the minimum number of blocks that should be
-	// in the request queue for headers-first mode before requesting
-	// more.
-	minInFlightBlocks = 10
+	// blockDownloadWindow is the maximum number of blocks past the current
+	// best block that are requested in headers-first mode.  Blocks within
+	// the window are downloaded from several peers concurrently and may
+	// arrive out of order, so it also bounds the number of blocks held in
+	// memory until all of their ancestors are processed.
+	blockDownloadWindow = 512
+
+	// maxBlocksInFlightPerPeer is the maximum number of blocks requested
+	// from a single peer at once in headers-first mode.
+	maxBlocksInFlightPerPeer = 16
+
+	// maxPendingHeaders is the number of headers whose blocks still need
+	// to be processed after which no more headers are requested in
+	// headers-first mode until the block download catches up.
+	maxPendingHeaders = 8 * wire.MaxBlockHeadersPerMsg
+
+	// blockStallTimeout is the time a peer is given to deliver the block
+	// that is holding back the download window before it is disconnected
+	// for stalling the download.
+	blockStallTimeout = 15 * time.Second
+
+	// blockRequestTimeout is the time after which a peer that has not
+	// delivered a block requested in headers-first mode is disconnected.
+	blockRequestTimeout = 2 * time.Minute
+
+	// assumeValidMinBuriedTime is the amount of work, expressed as the time
+	// it takes to produce it, that must be built on top of the assumed
+	// valid block in the header chain before the scripts of its ancestors
+	// are no longer validated.
+	assumeValidMinBuriedTime = 14 * 24 * time.Hour
 
 	// maxRejectedTxns is the maximum number of rejected transactions
 	// hashes to store in memory.
@@ -79,6 +106,22 @@ type headersMsg struct {
 	peer    *peerpkg.Peer
 }
 
+// cmpctBlockMsg packages a bitcoin cmpctblock message and the peer it came
+// from together so the block handler has access to that information.
+type cmpctBlockMsg struct {
+	cmpctBlock *wire.MsgCmpctBlock
+	peer       *peerpkg.Peer
+	reply      chan struct{}
+}
+
+// blockTxnMsg packages a bitcoin blocktxn message and the peer it came from
+// together so the block handler has access to that information.
+type blockTxnMsg struct {
+	blockTxn *wire.MsgBlockTxn
+	peer     *peerpkg.Peer
+	reply    chan struct{}
+}
+
 // donePeerMsg signifies a newly disconnected peer to the block handler.
 type donePeerMsg struct {
 	peer *peerpkg.Peer
@@ -132,10 +175,17 @@ type pauseMsg struct {
 }
 
 // headerNode is used as a node in a list of headers that are linked together
-// between checkpoints.
+// in headers-first mode.
 type headerNode struct {
 	height int32
 	hash   *chainhash.Hash
+
+	// peer is the peer the block described by the header was requested
+	// from at requestTime, if any.  block houses the block once it has
+	// been received until all of its ancestors have been processed.
+	peer        *peerpkg.Peer
+	requestTime time.Time
+	block       *acmutil.Block
 }
 
 // peerSyncState stores additional information that the SyncManager tracks
@@ -145,6 +195,13 @@ type peerSyncState struct {
 	requestQueue    []*wire.InvVect
 	requestedTxns   map[chainhash.Hash]struct{}
 	requestedBlocks map[chainhash.Hash]struct{}
+	partialBlock    *partialBlock
+
+	// blocksStalled is set for the sync peer once it stalls the block
+	// download in headers-first mode while there are other peers to
+	// download the blocks from.  Blocks are no longer requested from it
+	// afterwards, so it is only used to download headers.
+	blocksStalled bool
 }
 
 // SyncManager is used to communicate block related messages with peers. The
@@ -159,6 +216,8 @@ type SyncManager struct {
 	chain          *blockchain.BlockChain
 	txMemPool      *mempool.TxPool
 	chainParams    *chaincfg.Params
+	assumeValid    *chainhash.Hash
+	minChainWork   *big.Int
 	progressLogger *blockProgressLogger
 	msgChan        chan interface{}
 	wg             sync.WaitGroup
@@ -172,11 +231,46 @@ type SyncManager struct {
 	peerStates       map[*peerpkg.Peer]*peerSyncState
 	lastProgressTime time.Time
 
-	// The following fields are used for headers-first mode.
-	headersFirstMode bool
-	headerList       *list.List
-	startHeader      *list.Element
-	nextCheckpoint   *chaincfg.Checkpoint
+	// highBandwidthPeers houses the peers which have been asked to
+	// announce new blocks via cmpctblock messages, ordered from the least
+	// to the most recent one to provide a new block.
+	highBandwidthPeers []*peerpkg.Peer
+
+	// The following fields are used for headers-first mode.  The front of
+	// headerList is always the latest processed block while the remaining
+	// entries are the headers whose blocks still need to be downloaded and
+	// processed.  Blocks up to fastAddHeight are known to lead to a
+	// verified checkpoint, while blocks up to assumeValidHeight are
+	// ancestors of the assumed valid block once the header chain has
+	// assumeValidWorkNeeded more work on top of it.  The assumed valid
+	// block is only searched for in presync mode, when the complete header
+	// chain is known, so the decision does not depend on how far the
+	// header download is ahead of the block download.
+	headersFirstMode      bool
+	headersRequested      bool
+	headersSynced         bool
+	headerList            *list.List
+	headerIndex           map[chainhash.Hash]*list.Element
+	nextCheckpoint        *chaincfg.Checkpoint
+	fastAddHeight         int32
+	assumeValidHeight     int32
+	assumeValidWorkNeeded *big.Int
+	searchAssumeValid     bool
+
+	// headerWork is the total work of the header chain.  While it is below
+	// the minimum chain work or the assumed valid block is searched for,
+	// headers are only checked in presync mode without being stored, apart
+	// from presyncTip and the hashes of the final header of every batch.
+	// Once the header chain has enough work and the assumed valid block is
+	// buried deep enough in it or the chain ends, the headers are
+	// downloaded again and verified against those hashes, while blocks are
+	// only requested up to the verifiedHeight.  This bounds the memory used
+	// by low-work header chains.
+	headerWork     *big.Int
+	headersPresync bool
+	presyncTip     *headerNode
+	presyncHashes  map[int32]chainhash.Hash
+	verifiedHeight int32
 
 	// An optional fee estimator.
 	feeEstimator *mempool.FeeEstimator
@@ -186,16 +280,43 @@ type SyncManager struct {
 // syncing from a new peer.
 func (sm *SyncManager) resetHeaderState(newestHash *chainhash.Hash, newestHeight int32) {
 	sm.headersFirstMode = false
+	sm.headersRequested = false
+	sm.headersSynced = false
 	sm.headerList.Init()
-	sm.startHeader = nil
-
-	// When there is a next checkpoint, add an entry for the latest known
-	// block into the header pool.  This allows the next downloaded header
-	// to prove it links to the chain properly.
-	if sm.nextCheckpoint != nil {
-		node := headerNode{height: newestHeight, hash: newestHash}
-		sm.headerList.PushBack(&node)
-	}
+	sm.headerIndex = make(map[chainhash.Hash]*list.Element)
+	sm.nextCheckpoint = sm.findNextHeaderCheckpoint(newestHeight)
+	sm.fastAddHeight = 0
+	sm.assumeValidHeight = 0
+	sm.assumeValidWorkNeeded = nil
+
+	// Check the headers in presync mode while the chain has less than the
+	// minimum chain work.
+	headerWork, err := sm.chain.ChainWork(newestHash)
+	if err != nil {
+		log.Errorf("Unable to determine the chain work of block %v: %v",
+			newestHash, err)
+		headerWork = new(big.Int)
+	}
+	sm.headerWork = headerWork
+
+	// Check the headers in presync mode until the assumed valid block is
+	// found as well unless it is already part of the main chain.
+	sm.searchAssumeValid = sm.assumeValid != nil &&
+		!sm.chain.MainChainHasBlock(sm.assumeValid)
+	sm.headersPresync = !sm.hasMinChainWork() || sm.searchAssumeValid
+	sm.presyncTip = nil
+	sm.presyncHashes = nil
+	if sm.headersPresync {
+		sm.presyncTip = &headerNode{height: newestHeight, hash: newestHash}
+		sm.presyncHashes = make(map[int32]chainhash.Hash)
+	}
+	sm.verifiedHeight = newestHeight
+
+	// Add an entry for the latest known block into the header pool.  This
+	// allows the next downloaded header to prove it links to the chain
+	// properly.
+	node := headerNode{height: newestHeight, hash: newestHash}
+	sm.headerList.PushBack(&node)
 }
 
 // findNextHeaderCheckpoint returns the next checkpoint after the passed height.
@@ -299,6 +420,22 @@ func (sm *SyncManager) startSync() {
 
 	// Start syncing from the best peer if one was selected.
 	if bestPeer != nil {
+		// Continue a headers-first sync that is in progress by only
+		// requesting the remaining headers from the new sync peer.  The
+		// headers and blocks downloaded so far remain valid regardless
+		// of the peer they were downloaded from.
+		if sm.headersFirstMode {
+			log.Infof("Continuing to download headers from peer %v",
+				bestPeer.Addr())
+			sm.syncPeer = bestPeer
+			sm.lastProgressTime = time.Now()
+			sm.headersRequested = false
+			sm.headersSynced = false
+			sm.fetchHeaders()
+			sm.fetchHeaderBlocks()
+			return
+		}
+
 		// Clear the requestedBlocks if the sync peer changes, otherwise
 		// we may ignore blocks we need that the last sync peer failed
 		// to send.
@@ -314,32 +451,36 @@ func (sm *SyncManager) startSync() {
 		log.Infof("Syncing to block height %d from peer %v",
 			bestPeer.LastBlock(), bestPeer.Addr())
 
-		// When the current height is less than a known checkpoint we
-		// can use block headers to learn about which blocks comprise
-		// the chain up to the checkpoint and perform less validation
-		// for them.  This is possible since each header contains the
-		// hash of the previous header and a merkle root.  Therefore if
-		// we validate all of the received headers link together
-		// properly and the checkpoint hashes match, we can be sure the
-		// hashes for the blocks in between are accurate.  Further, once
+		// When the peer knows about blocks we don't, use block headers
+		// to learn about which blocks comprise its chain up to the best
+		// header it knows about.  This is possible since each header
+		// contains the hash of the previous header and a merkle root.
+		// Knowing the hashes of the blocks ahead of time allows them to
+		// be downloaded from several peers concurrently.  Further, once
 		// the full blocks are downloaded, the merkle root is computed
 		// and compared against the value in the header which proves the
 		// full block hasn't been tampered with.
 		//
-		// Once we have passed the final checkpoint, or checkpoints are
-		// disabled, use standard inv messages learn about the blocks
-		// and fully validate them.  Finally, regression test mode does
-		// not support the headers-first approach so do normal block
+		// Blocks whose headers lead to a checkpoint are known to be
+		// accurate and therefore perform less validation, while all
+		// others are fully validated.  Regression test mode does not
+		// support the headers-first approach so do normal block
 		// downloads when in regression test mode.
-		if sm.nextCheckpoint != nil &&
-			best.Height < sm.nextCheckpoint.Height &&
+		if bestPeer.LastBlock() > best.Height &&
 			sm.chainParams != &chaincfg.RegressionNetParams {
 
-			bestPeer.PushGetHeadersMsg(locator, sm.nextCheckpoint.Hash)
+			sm.resetHeaderState(&best.Hash, best.Height)
+			bestPeer.PushGetHeadersMsg(locator, &zeroHash)
 			sm.headersFirstMode = true
+			sm.headersRequested = true
 			log.Infof("Downloading headers for blocks %d to "+
 				"%d from peer %s", best.Height+1,
-				sm.nextCheckpoint.Height, bestPeer.Addr())
+				bestPeer.LastBlock(), bestPeer.Addr())
+			if sm.headersPresync {
+				log.Infof("Verifying the header chain of peer "+
+					"%s before downloading blocks",
+					bestPeer.Addr())
+			}
 		} else {
 			bestPeer.PushGetBlocksMsg(locator, &zeroHash)
 		}
@@ -410,9 +551,20 @@ func (sm *SyncManager) handleNewPeerMsg(peer *peerpkg.Peer) {
 		requestedBlocks: make(map[chainhash.Hash]struct{}),
 	}
 
-	// Start syncing by choosing the best candidate if needed.
+	// Signal support for compact blocks in low-bandwidth mode.  Peers are
+	// only switched to high-bandwidth mode once they provide a new block.
+	if peer.ProtocolVersion() >= wire.ShortIDsBlocksVersion {
+		peer.QueueMessage(wire.NewMsgSendCmpct(false,
+			cmpctBlockVersion(peer)), nil)
+	}
+
+	// Start syncing by choosing the best candidate if needed.  Otherwise,
+	// put the peer to work downloading blocks when a headers-first sync
+	// is in progress.
 	if isSyncCandidate && sm.syncPeer == nil {
 		sm.startSync()
+	} else if isSyncCandidate && sm.headersFirstMode {
+		sm.fetchHeaderBlocks()
 	}
 }
 
@@ -430,6 +582,13 @@ func (sm *SyncManager) handleStallSample() {
 		return
 	}
 
+	// Deal with any peers that stall the block download in headers-first
+	// mode.  This is checked separately from the sync peer since blocks
+	// are downloaded from several peers.
+	if sm.headersFirstMode {
+		sm.fetchHeaderBlocks()
+	}
+
 	// If the stall timeout has not elapsed, exit early.
 	if time.Since(sm.lastProgressTime) <= maxStallDuration {
 		return
@@ -442,6 +601,9 @@ func (sm *SyncManager) handleStallSample() {
 	}
 
 	sm.clearRequestedState(state)
+	if sm.headersFirstMode {
+		sm.releaseHeaderBlocks(sm.syncPeer)
+	}
 
 	disconnectSyncPeer := sm.shouldDCStalledSyncPeer()
 	sm.updateSyncPeer(disconnectSyncPeer)
@@ -486,12 +648,23 @@ func (sm *SyncManager) handleDonePeerMsg(peer *peerpkg.Peer) {
 	log.Infof("Lost peer %s", peer)
 
 	sm.clearRequestedState(state)
+	sm.removeHighBandwidthPeer(peer)
+
+	// Hand the blocks that were in flight from the peer to the remaining
+	// peers when in headers-first mode.
+	if sm.headersFirstMode {
+		sm.releaseHeaderBlocks(peer)
+	}
 
 	if peer == sm.syncPeer {
 		// Update the sync peer. The server has already disconnected the
 		// peer before signaling to the sync manager.
 		sm.updateSyncPeer(false)
 	}
+
+	if sm.headersFirstMode {
+		sm.fetchHeaderBlocks()
+	}
 }
 
 // clearRequestedState wipes all expected transactions and blocks from the sync
@@ -515,8 +688,11 @@ func (sm *SyncManager) clearRequestedState(state *peerSyncState) {
 
 // updateSyncPeer choose a new sync peer to replace the current one. If
 // dcSyncPeer is true, this method will also disconnect the current sync peer.
-// If we are in header first mode, any header state related to prefetching is
-// also reset in preparation for the next sync peer.
+// If we are in header first mode, the headers and blocks downloaded so far are
+// kept and only the remaining headers are requested from the next sync peer,
+// unless the header chain of the current sync peer is checked in presync mode
+// or verified against the hashes seen in presync mode, since those are specific
+// to the sync peer.
 func (sm *SyncManager) updateSyncPeer(dcSyncPeer bool) {
 	log.Debugf("Updating sync peer, no progress for: %v",
 		time.Since(sm.lastProgressTime))
@@ -526,8 +702,11 @@ func (sm *SyncManager) updateSyncPeer(dcSyncPeer bool) {
 		sm.syncPeer.Disconnect()
 	}
 
-	// Reset any header state before we choose our next active sync peer.
-	if sm.headersFirstMode {
+	// Reset the header state before we choose our next active sync peer
+	// when it is specific to the current one.
+	if sm.headersFirstMode &&
+		(sm.headersPresync || sm.presyncHashes != nil) {
+
 		best := sm.chain.BestSnapshot()
 		sm.resetHeaderState(&best.Hash, best.Height)
 	}
@@ -650,39 +829,41 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		}
 	}
 
-	// When in headers-first mode, if the block matches the hash of the
-	// first header in the list of headers that are being fetched, it's
-	// eligible for less validation since the headers have already been
-	// verified to link together and are valid up to the next checkpoint.
-	// Also, remove the list entry for all blocks except the checkpoint
-	// since it is needed to verify the next round of headers links
-	// properly.
-	isCheckpointBlock := false
-	behaviorFlags := blockchain.BFNone
-	if sm.headersFirstMode {
-		firstNodeEl := sm.headerList.Front()
-		if firstNodeEl != nil {
-			firstNode := firstNodeEl.Value.(*headerNode)
-			if blockHash.IsEqual(firstNode.hash) {
-				behaviorFlags |= blockchain.BFFastAdd
-				if firstNode.hash.IsEqual(sm.nextCheckpoint.Hash) {
-					isCheckpointBlock = true
-				} else {
-					sm.headerList.Remove(firstNodeEl)
-				}
-			}
-		}
-	}
-
 	// Remove block from request maps. Either chain will know about it and
 	// so we shouldn't have any more instances of trying to fetch it, or we
 	// will fail the insert and thus we'll retry next time we get an inv.
 	delete(state.requestedBlocks, *blockHash)
 	delete(sm.requestedBlocks, *blockHash)
 
+	// When in headers-first mode, blocks described by the header list are
+	// downloaded from several peers concurrently and thus may arrive out of
+	// order.  Hold on to the block until all of its ancestors have been
+	// processed.
+	if sm.headersFirstMode {
+		if e, exists := sm.headerIndex[*blockHash]; exists {
+			node := e.Value.(*headerNode)
+			if node.block == nil {
+				node.block = bmsg.block
+				node.peer = peer
+			}
+			sm.processHeaderBlocks()
+			return
+		}
+	}
+
+	sm.processBlock(peer, bmsg.block, blockchain.BFNone)
+}
+
+// processBlock processes the passed block received from the peer and updates
+// the state of the peer accordingly.  It returns whether or not the block was
+// accepted into the block chain or the orphan pool.
+func (sm *SyncManager) processBlock(peer *peerpkg.Peer, block *acmutil.Block,
+	behaviorFlags blockchain.BehaviorFlags) bool {
+
 	// Process the block to include validation, best chain selection, orphan
 	// handling, etc.
-	_, isOrphan, err := sm.chain.ProcessBlock(bmsg.block, behaviorFlags)
+	blockHash := block.Hash()
+	_, isOrphan, err := sm.chain.ProcessBlock(block, behaviorFlags)
 	if err != nil {
 		// When the error is a rule error, it means the block was simply
 		// rejected as opposed to something actually going wrong, so log
@@ -704,7 +885,7 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		// send it.
 		code, reason := mempool.ErrToRejectErr(err)
 		peer.PushRejectMsg(wire.CmdBlock, code, reason, blockHash, false)
-		return
+		return false
 	}
 
 	// Meta-data about the new block this peer is reporting. We use this
@@ -726,9 +907,9 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		// block height from the scriptSig of the coinbase transaction.
 		// Extraction is only attempted if the block's version is
 		// high enough (ver 2+).
-		header := &bmsg.block.MsgBlock().Header
+		header := &block.MsgBlock().Header
 		if blockchain.ShouldHaveSerializedBlockHeight(header) {
-			coinbaseTx := bmsg.block.Transactions()[0]
+			coinbaseTx := block.Transactions()[0]
 			cbHeight, err := blockchain.ExtractCoinbaseHeight(coinbaseTx)
 			if err != nil {
 				log.Warnf("Unable to extract height from "+
@@ -750,13 +931,15 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 			peer.PushGetBlocksMsg(locator, orphanRoot)
 		}
 	} else {
-		if peer == sm.syncPeer {
+		// Blocks are downloaded from several peers in headers-first
+		// mode, so any processed block counts as progress.
+		if peer == sm.syncPeer || sm.headersFirstMode {
 			sm.lastProgressTime = time.Now()
 		}
 
 		// When the block is not an orphan, log information about it and
 		// update the chain state.
-		sm.progressLogger.LogBlockHeight(bmsg.block)
+		sm.progressLogger.LogBlockHeight(block)
 
 		// Update this peer's latest block height, for future
 		// potential sync node candidacy.
@@ -766,6 +949,12 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 
 		// Clear the rejected transactions.
 		sm.rejectedTxns = make(map[chainhash.Hash]struct{})
+
+		// Ask the peer to announce new blocks via cmpctblock messages
+		// since it was able to provide this one.
+		if sm.current() {
+			sm.addHighBandwidthPeer(peer)
+		}
 	}
 
 	// Update the block height for this peer. But only send a message to
@@ -773,121 +962,725 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	// chain is "current". This avoids sending a spammy amount of messages
 	// if we're syncing the chain from scratch.
 	if blkHashUpdate != nil && heightUpdate != 0 {
-		peer.UpdateLastBlockHeight(heightUpdate)
+		// Blocks are requested well below the height peers claim to
+		// have in headers-first mode, so never lower it then since it
+		// determines which blocks are requested from them.
+		if !sm.headersFirstMode || heightUpdate > peer.LastBlock() {
+			peer.UpdateLastBlockHeight(heightUpdate)
+		}
 		if isOrphan || sm.current() {
 			go sm.peerNotifier.UpdatePeerHeights(blkHashUpdate, heightUpdate,
 				peer)
 		}
 	}
 
-	// Nothing more to do if we aren't in headers-first mode.
-	if !sm.headersFirstMode {
+	return true
+}
+
+// cmpctBlockVersion returns the compact block version to negotiate with the
+// passed peer.  Version 2, which uses witness hashes for the short transaction
+// ids, is only used with peers that have segregated witness enabled.
+func cmpctBlockVersion(peer *peerpkg.Peer) uint64 {
+	if peer.IsWitnessEnabled() {
+		return 2
+	}
+	return 1
+}
+
+// requestFullBlock requests the block with the passed hash in full from the
+// peer.  It is used when a compact block can't be reconstructed.
+func (sm *SyncManager) requestFullBlock(peer *peerpkg.Peer, state *peerSyncState,
+	blockHash *chainhash.Hash) {
+
+	sm.requestedBlocks[*blockHash] = struct{}{}
+	sm.limitMap(sm.requestedBlocks, maxRequestedBlocks)
+	state.requestedBlocks[*blockHash] = struct{}{}
+
+	invType := wire.InvTypeBlock
+	if peer.IsWitnessEnabled() {
+		invType = wire.InvTypeWitnessBlock
+	}
+	gdmsg := wire.NewMsgGetData()
+	gdmsg.AddInvVect(wire.NewInvVect(invType, blockHash))
+	peer.QueueMessage(gdmsg, nil)
+}
+
+// processPartialBlock processes a block which has been fully reconstructed
+// from a compact block as if the block itself had been received from the peer.
+// The full block is requested instead when the reconstructed transactions do
+// not match the merkle root of the header, which happens when a mempool
+// transaction collides with the short id of a block transaction, or when their
+// witness data does not match the witness commitment of the block, which
+// happens when a mempool transaction has the same id as a block transaction but
+// a different witness.  Otherwise, the block would be rejected even though the
+// block itself might be valid.
+func (sm *SyncManager) processPartialBlock(peer *peerpkg.Peer, state *peerSyncState,
+	pb *partialBlock) {
+
+	block := pb.block()
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+	calculatedMerkleRoot := merkles[len(merkles)-1]
+	if !pb.header.MerkleRoot.IsEqual(calculatedMerkleRoot) {
+		log.Debugf("Reconstructed compact block %v from %s does not "+
+			"match its merkle root -- requesting full block",
+			pb.hash, peer)
+		sm.requestFullBlock(peer, state, &pb.hash)
+		return
+	}
+	if err := blockchain.ValidateWitnessCommitment(block); err != nil {
+		log.Debugf("Reconstructed compact block %v from %s does not "+
+			"match its witness commitment -- requesting full "+
+			"block: %v", pb.hash, peer, err)
+		sm.requestFullBlock(peer, state, &pb.hash)
 		return
 	}
 
-	// This is headers-first mode, so if the block is not a checkpoint
-	// request more blocks using the header list when the request queue is
-	// getting short.
-	if !isCheckpointBlock {
-		if sm.startHeader != nil &&
-			len(state.requestedBlocks) < minInFlightBlocks {
-			sm.fetchHeaderBlocks()
+	sm.handleBlockMsg(&blockMsg{block: block, peer: peer})
+}
+
+// handleCmpctBlockMsg handles cmpctblock messages from all peers.  The block
+// is reconstructed from the transactions in the mempool and any missing
+// transactions are requested from the peer with a getblocktxn message.
+func (sm *SyncManager) handleCmpctBlockMsg(cmsg *cmpctBlockMsg) {
+	peer := cmsg.peer
+	state, exists := sm.peerStates[peer]
+	if !exists {
+		log.Warnf("Received cmpctblock message from unknown peer %s", peer)
+		return
+	}
+
+	// Ignore compact blocks from peers that never negotiated them.
+	version := peer.CmpctBlockVersion()
+	if version == 0 {
+		log.Debugf("Ignoring unexpected cmpctblock from %s", peer)
+		return
+	}
+
+	header := &cmsg.cmpctBlock.Header
+	blockHash := header.BlockHash()
+	iv := wire.NewInvVect(wire.InvTypeBlock, &blockHash)
+	peer.AddKnownInventory(iv)
+	peer.UpdateLastAnnouncedBlock(&blockHash)
+
+	// Ignore the block when it is already known or it is being requested
+	// from another peer.
+	haveBlock, err := sm.chain.HaveBlock(&blockHash)
+	if err != nil {
+		log.Errorf("Unexpected failure when checking for existing "+
+			"block %v: %v", blockHash, err)
+		return
+	}
+	if haveBlock {
+		return
+	}
+	_, requested := state.requestedBlocks[blockHash]
+	if _, exists := sm.requestedBlocks[blockHash]; exists && !requested {
+		return
+	}
+
+	// The header of blocks that do not extend a known block can't be
+	// checked without its ancestors, so request the full block in that
+	// case and leave it to the regular orphan handling, which fully
+	// validates it, just like for a block announced via an inv.
+	parentHeight, err := sm.chain.HeaderHeightByHash(&header.PrevBlock)
+	if err != nil {
+		sm.requestFullBlock(peer, state, &blockHash)
+		return
+	}
+
+	// Ensure the header has the proof of work it claims using the
+	// algorithm in effect at its height before spending any effort on
+	// reconstructing the block.
+	powAlgo := sm.chainParams.PowAlgorithm(parentHeight + 1)
+	err = blockchain.CheckHeaderProofOfWork(header, sm.chainParams.PowLimit,
+		powAlgo)
+	if err != nil {
+		log.Warnf("Received compact block %v with invalid proof of "+
+			"work from peer %s -- disconnecting: %v", blockHash,
+			peer.Addr(), err)
+		peer.Disconnect()
+		return
+	}
+
+	// Ignore blocks on chains with less than the minimum chain work unless
+	// they were requested since they can't be part of the chain we need.
+	if sm.minChainWork != nil && !requested {
+		work, err := sm.chain.ChainWork(&header.PrevBlock)
+		if err == nil {
+			work.Add(work, blockchain.CalcWork(header.Bits))
+			if work.Cmp(sm.minChainWork) < 0 {
+				log.Debugf("Ignoring compact block %v from %s "+
+					"with less than the minimum chain work",
+					blockHash, peer)
+				return
+			}
 		}
+	}
+
+	// Blocks can't be reconstructed reliably from the mempool when the
+	// chain is not current, so request the full block in that case.
+	if !sm.current() {
+		sm.requestFullBlock(peer, state, &blockHash)
 		return
 	}
 
-	// This is headers-first mode and the block is a checkpoint.  When
-	// there is a next checkpoint, get the next round of headers by asking
-	// for headers starting from the block after this one up to the next
-	// checkpoint.
-	prevHeight := sm.nextCheckpoint.Height
-	prevHash := sm.nextCheckpoint.Hash
-	sm.nextCheckpoint = sm.findNextHeaderCheckpoint(prevHeight)
-	if sm.nextCheckpoint != nil {
-		locator := blockchain.BlockLocator([]*chainhash.Hash{prevHash})
-		err := peer.PushGetHeadersMsg(locator, sm.nextCheckpoint.Hash)
-		if err != nil {
-			log.Warnf("Failed to send getheaders message to "+
-				"peer %s: %v", peer.Addr(), err)
+	pb, err := newPartialBlock(cmsg.cmpctBlock, sm.txMemPool, version == 2)
+	if err != nil {
+		log.Debugf("Unable to reconstruct compact block %v from %s: "+
+			"%v -- requesting full block", blockHash, peer, err)
+		sm.requestFullBlock(peer, state, &blockHash)
+		return
+	}
+
+	// Track the block as requested from the peer so the reconstructed
+	// block is accepted as if it had been asked for in full.
+	sm.requestedBlocks[blockHash] = struct{}{}
+	sm.limitMap(sm.requestedBlocks, maxRequestedBlocks)
+	state.requestedBlocks[blockHash] = struct{}{}
+
+	if len(pb.missing) == 0 {
+		state.partialBlock = nil
+		sm.processPartialBlock(peer, state, pb)
+		return
+	}
+
+	log.Debugf("Requesting %d missing transactions of compact block "+
+		"%v from %s", len(pb.missing), blockHash, peer)
+	state.partialBlock = pb
+	getBlockTxn := wire.NewMsgGetBlockTxn(&blockHash)
+	for _, index := range pb.missing {
+		getBlockTxn.AddIndex(index)
+	}
+	peer.QueueMessage(getBlockTxn, nil)
+}
+
+// handleBlockTxnMsg handles blocktxn messages from all peers.  The provided
+// transactions complete the compact block previously received from the peer.
+func (sm *SyncManager) handleBlockTxnMsg(bmsg *blockTxnMsg) {
+	peer := bmsg.peer
+	state, exists := sm.peerStates[peer]
+	if !exists {
+		log.Warnf("Received blocktxn message from unknown peer %s", peer)
+		return
+	}
+
+	pb := state.partialBlock
+	if pb == nil || pb.hash != bmsg.blockTxn.BlockHash {
+		log.Debugf("Ignoring unexpected blocktxn for block %v from %s",
+			bmsg.blockTxn.BlockHash, peer)
+		return
+	}
+	state.partialBlock = nil
+
+	if err := pb.fill(bmsg.blockTxn); err != nil {
+		log.Debugf("Unable to complete compact block from %s: %v -- "+
+			"requesting full block", peer, err)
+		sm.requestFullBlock(peer, state, &pb.hash)
+		return
+	}
+	sm.processPartialBlock(peer, state, pb)
+}
+
+// addHighBandwidthPeer asks the passed peer to announce new blocks directly
+// via cmpctblock messages.  When this exceeds the maximum number of
+// high-bandwidth peers, the peer that least recently provided a new block is
+// switched back to low-bandwidth mode.
+func (sm *SyncManager) addHighBandwidthPeer(peer *peerpkg.Peer) {
+	version := peer.CmpctBlockVersion()
+	if version == 0 {
+		return
+	}
+
+	// Move the peer to the end of the list when it is already in
+	// high-bandwidth mode.
+	for i, p := range sm.highBandwidthPeers {
+		if p == peer {
+			copy(sm.highBandwidthPeers[i:], sm.highBandwidthPeers[i+1:])
+			sm.highBandwidthPeers[len(sm.highBandwidthPeers)-1] = peer
 			return
 		}
-		log.Infof("Downloading headers for blocks %d to %d from "+
-			"peer %s", prevHeight+1, sm.nextCheckpoint.Height,
-			sm.syncPeer.Addr())
+	}
+
+	sm.highBandwidthPeers = append(sm.highBandwidthPeers, peer)
+	peer.QueueMessage(wire.NewMsgSendCmpct(true, version), nil)
+
+	if len(sm.highBandwidthPeers) > maxHighBandwidthPeers {
+		evicted := sm.highBandwidthPeers[0]
+		sm.highBandwidthPeers[0] = nil
+		sm.highBandwidthPeers = sm.highBandwidthPeers[1:]
+		evicted.QueueMessage(wire.NewMsgSendCmpct(false,
+			evicted.CmpctBlockVersion()), nil)
+	}
+}
+
+// removeHighBandwidthPeer removes the passed peer from the high-bandwidth
+// peers, if present.
+func (sm *SyncManager) removeHighBandwidthPeer(peer *peerpkg.Peer) {
+	for i, p := range sm.highBandwidthPeers {
+		if p == peer {
+			sm.highBandwidthPeers = append(sm.highBandwidthPeers[:i],
+				sm.highBandwidthPeers[i+1:]...)
+			return
+		}
+	}
+}
+
+// processHeaderBlocks processes the downloaded blocks of the header list in
+// order for as long as all of their ancestors are known.  Afterwards, it
+// continues the headers-first sync by requesting more blocks and headers or
+// switches to normal mode once all blocks of the known headers have been
+// processed.
+func (sm *SyncManager) processHeaderBlocks() {
+	for {
+		frontEl := sm.headerList.Front()
+		nextEl := frontEl.Next()
+		if nextEl == nil {
+			break
+		}
+
+		// Blocks might already be part of the main chain regardless of
+		// whether or not they were downloaded, for instance when they
+		// were known as orphans or submitted by other means.
+		node := nextEl.Value.(*headerNode)
+		if !sm.chain.MainChainHasBlock(node.hash) {
+			if node.block == nil {
+				break
+			}
+
+			// Blocks which lead to a verified checkpoint are
+			// eligible for less validation since the headers have
+			// already been verified to link together.  Likewise,
+			// the scripts of the ancestors of the assumed valid
+			// block are not validated once it is buried deep
+			// enough.
+			behaviorFlags := blockchain.BFNone
+			if node.height <= sm.fastAddHeight {
+				behaviorFlags |= blockchain.BFFastAdd
+			} else if node.height <= sm.assumeValidHeight &&
+				sm.assumeValidBuried() {
+
+				behaviorFlags |= blockchain.BFAssumeValid
+			}
+			if !sm.processBlock(node.peer, node.block, behaviorFlags) {
+				// None of the remaining headers can become part
+				// of the chain when a block is invalid, so
+				// disconnect the peer that provided it and start
+				// over.  The sync peer considers the block part
+				// of its best chain, so it is not picked again.
+				node.peer.Disconnect()
+				if state, ok := sm.peerStates[sm.syncPeer]; ok {
+					state.syncCandidate = false
+				}
+				sm.restartSync()
+				return
+			}
+		}
+
+		// The processed block becomes the new front of the list that
+		// the remaining headers link to.
+		delete(sm.headerIndex, *frontEl.Value.(*headerNode).hash)
+		sm.headerList.Remove(frontEl)
+		node.block = nil
+		node.peer = nil
+	}
+
+	// Switch to normal mode once all blocks of the headers the sync peer
+	// knows about have been processed by requesting blocks after the
+	// final one up to the end of the chain (zero hash).  This picks up any
+	// blocks that were announced in the meantime.
+	if sm.headersSynced && sm.headerList.Len() == 1 {
+		finalNode := sm.headerList.Front().Value.(*headerNode)
+		best := sm.chain.BestSnapshot()
+		sm.resetHeaderState(&best.Hash, best.Height)
+		log.Infof("Processed the blocks of all known headers -- " +
+			"switching to normal mode")
+		if sm.syncPeer != nil {
+			locator := blockchain.BlockLocator(
+				[]*chainhash.Hash{finalNode.hash})
+			err := sm.syncPeer.PushGetBlocksMsg(locator, &zeroHash)
+			if err != nil {
+				log.Warnf("Failed to send getblocks message to "+
+					"peer %s: %v", sm.syncPeer.Addr(), err)
+			}
+		}
 		return
 	}
 
-	// This is headers-first mode, the block is a checkpoint, and there are
-	// no more checkpoints, so switch to normal mode by requesting blocks
-	// from the block after this one up to the end of the chain (zero hash).
-	sm.headersFirstMode = false
-	sm.headerList.Init()
-	log.Infof("Reached the final checkpoint -- switching to normal mode")
-	locator := blockchain.BlockLocator([]*chainhash.Hash{blockHash})
-	err = peer.PushGetBlocksMsg(locator, &zeroHash)
+	sm.fetchHeaderBlocks()
+	sm.fetchHeaders()
+}
+
+// restartSync resets the headers-first state and starts syncing from the best
+// sync candidate again.
+func (sm *SyncManager) restartSync() {
+	best := sm.chain.BestSnapshot()
+	sm.resetHeaderState(&best.Hash, best.Height)
+	sm.syncPeer = nil
+	sm.startSync()
+}
+
+// headerTip returns the final header of the header chain.  It is not part of
+// the header list in presync mode.
+func (sm *SyncManager) headerTip() *headerNode {
+	if sm.headersPresync {
+		return sm.presyncTip
+	}
+	return sm.headerList.Back().Value.(*headerNode)
+}
+
+// hasMinChainWork returns whether or not the header chain has the minimum chain
+// work.
+func (sm *SyncManager) hasMinChainWork() bool {
+	return sm.minChainWork == nil || sm.headerWork.Cmp(sm.minChainWork) >= 0
+}
+
+// assumeValidBuried returns whether or not the assumed valid block has been
+// found buried deep enough in the header chain.
+func (sm *SyncManager) assumeValidBuried() bool {
+	return sm.assumeValidWorkNeeded != nil &&
+		sm.assumeValidWorkNeeded.Sign() <= 0
+}
+
+// trackAssumeValid keeps track of the work built on top of the assumed valid
+// block in presync mode once the passed header of the header chain is found to
+// be the one of the assumed valid block.  It must be buried by the equivalent
+// of assumeValidMinBuriedTime worth of blocks at its difficulty before the
+// scripts of its ancestors are no longer validated.
+func (sm *SyncManager) trackAssumeValid(node *headerNode, header *wire.BlockHeader) {
+	if !sm.searchAssumeValid {
+		return
+	}
+
+	if sm.assumeValidWorkNeeded != nil {
+		sm.assumeValidWorkNeeded.Sub(sm.assumeValidWorkNeeded,
+			blockchain.CalcWork(header.Bits))
+		return
+	}
+	if !node.hash.IsEqual(sm.assumeValid) {
+		return
+	}
+
+	numBlocks := int64(assumeValidMinBuriedTime /
+		sm.chainParams.TargetTimePerBlock)
+	sm.assumeValidHeight = node.height
+	sm.assumeValidWorkNeeded = new(big.Int).Mul(
+		blockchain.CalcWork(header.Bits), big.NewInt(numBlocks))
+	log.Infof("Found assumed valid block %v at height %d in the header "+
+		"chain", node.hash, node.height)
+}
+
+// presyncDone returns whether or not the header chain checked in presync mode
+// has the minimum chain work and the assumed valid block, when it is searched
+// for, is buried deep enough in it.
+func (sm *SyncManager) presyncDone() bool {
+	if !sm.hasMinChainWork() {
+		return false
+	}
+	return !sm.searchAssumeValid || sm.assumeValidBuried()
+}
+
+// finishHeadersPresync switches from presync mode to downloading the headers of
+// the sync peer again once its header chain is known to have the minimum chain
+// work and the assumed valid block, if any, has been found buried in it or the
+// chain ends.  The headers are verified against the hashes seen in presync mode
+// as they are downloaded again, so the blocks of a different chain are never
+// requested.  This also ensures the blocks up to the assumed valid block are
+// its ancestors.
+func (sm *SyncManager) finishHeadersPresync() {
+	presyncTip, presyncHashes := sm.presyncTip, sm.presyncHashes
+	assumeValidHeight := sm.assumeValidHeight
+	assumeValidWorkNeeded := sm.assumeValidWorkNeeded
+	best := sm.chain.BestSnapshot()
+	sm.resetHeaderState(&best.Hash, best.Height)
+	sm.headersFirstMode = true
+	sm.headersPresync = false
+	sm.presyncTip = presyncTip
+	sm.presyncHashes = presyncHashes
+	sm.searchAssumeValid = false
+	if assumeValidWorkNeeded != nil && assumeValidWorkNeeded.Sign() <= 0 {
+		sm.assumeValidHeight = assumeValidHeight
+		sm.assumeValidWorkNeeded = assumeValidWorkNeeded
+	}
+
+	log.Infof("Verified the header chain of peer %s up to height %d -- "+
+		"downloading blocks", sm.syncPeer.Addr(), presyncTip.height)
+	locator, err := sm.chain.LatestBlockLocator()
 	if err != nil {
-		log.Warnf("Failed to send getblocks message to peer %s: %v",
-			peer.Addr(), err)
+		log.Errorf("Failed to get block locator for the latest block: "+
+			"%v", err)
+		return
+	}
+	err = sm.syncPeer.PushGetHeadersMsg(locator, &zeroHash)
+	if err != nil {
+		log.Warnf("Failed to send getheaders message to peer %s: %v",
+			sm.syncPeer.Addr(), err)
 		return
 	}
+	sm.headersRequested = true
 }
 
-// fetchHeaderBlocks creates and sends a request to the syncPeer for the next
-// list of blocks to be downloaded based on the current list of headers.
-func (sm *SyncManager) fetchHeaderBlocks() {
-	// Nothing to do if there is no start header.
-	if sm.startHeader == nil {
-		log.Warnf("fetchHeaderBlocks called with no start header")
+// downloadWindowEnd returns the height of the final block that may be
+// requested in headers-first mode.
+func (sm *SyncManager) downloadWindowEnd() int32 {
+	frontNode := sm.headerList.Front().Value.(*headerNode)
+	windowEnd := frontNode.height + blockDownloadWindow
+
+	// Headers downloaded again after presync mode are only trusted once
+	// they have been verified.
+	if sm.presyncHashes != nil && sm.verifiedHeight < windowEnd {
+		windowEnd = sm.verifiedHeight
+	}
+	return windowEnd
+}
+
+// fetchHeaders requests the next batch of headers from the sync peer when
+// there is no outstanding request and the number of headers whose blocks still
+// need to be processed is low enough.
+func (sm *SyncManager) fetchHeaders() {
+	if sm.syncPeer == nil || sm.headersRequested || sm.headersSynced ||
+		sm.headerList.Len() > maxPendingHeaders {
+
 		return
 	}
 
-	// Build up a getdata request for the list of blocks the headers
-	// describe.  The size hint will be limited to wire.MaxInvPerMsg by
-	// the function, so no need to double check it here.
-	gdmsg := wire.NewMsgGetDataSizeHint(uint(sm.headerList.Len()))
-	numRequested := 0
-	for e := sm.startHeader; e != nil; e = e.Next() {
-		node, ok := e.Value.(*headerNode)
-		if !ok {
-			log.Warn("Header list node type is not a headerNode")
-			continue
+	// Include the locator of the best chain so a peer that does not know
+	// the final header, such as a new sync peer on a different chain than
+	// the previous one, responds with the headers after the latest block
+	// they have in common.
+	finalNode := sm.headerTip()
+	locator := blockchain.BlockLocator([]*chainhash.Hash{finalNode.hash})
+	chainLocator, err := sm.chain.LatestBlockLocator()
+	if err != nil {
+		log.Errorf("Failed to get block locator for the latest block: "+
+			"%v", err)
+		return
+	}
+	locator = append(locator, chainLocator...)
+	err = sm.syncPeer.PushGetHeadersMsg(locator, &zeroHash)
+	if err != nil {
+		log.Warnf("Failed to send getheaders message to peer %s: %v",
+			sm.syncPeer.Addr(), err)
+		return
+	}
+	sm.headersRequested = true
+}
+
+// fetchHeaderBlocks requests the blocks described by the header list within
+// the download window from all sync candidates which have spare capacity and
+// claim to have them.  The blocks are requested in order so the ones which are
+// needed next are always downloaded first.
+func (sm *SyncManager) fetchHeaderBlocks() {
+	// Deal with the peers that stall the block download first, so the
+	// blocks released by them are requested from other peers right away.
+	sm.disconnectStalledPeers()
+
+	// Determine the number of blocks in flight from each peer along with
+	// the blocks within the download window that still need to be
+	// requested.
+	windowEnd := sm.downloadWindowEnd()
+	inFlight := make(map[*peerpkg.Peer]int)
+	var needed []*headerNode
+	for e := sm.headerList.Front().Next(); e != nil; e = e.Next() {
+		node := e.Value.(*headerNode)
+		if node.height > windowEnd {
+			break
 		}
 
-		iv := wire.NewInvVect(wire.InvTypeBlock, node.hash)
-		haveInv, err := sm.haveInventory(iv)
-		if err != nil {
-			log.Warnf("Unexpected failure when checking for "+
-				"existing inventory during header block "+
-				"fetch: %v", err)
+		switch {
+		case node.block != nil:
+		case node.peer != nil:
+			inFlight[node.peer]++
+		default:
+			// Blocks already known as orphans will be connected
+			// along with their parent, so there is no need to
+			// request them again.
+			if sm.chain.IsKnownOrphan(node.hash) {
+				continue
+			}
+			needed = append(needed, node)
 		}
-		if !haveInv {
-			syncPeerState := sm.peerStates[sm.syncPeer]
+	}
+
+	// Assign the needed blocks to the peers that claim to have them until
+	// their capacity is exhausted.
+	now := time.Now()
+	for peer, state := range sm.peerStates {
+		if len(needed) == 0 {
+			break
+		}
+		if !state.syncCandidate || state.blocksStalled ||
+			!peer.Connected() {
+
+			continue
+		}
+
+		gdmsg := wire.NewMsgGetDataSizeHint(maxBlocksInFlightPerPeer)
+		peerHeight := peer.LastBlock()
+		remaining := needed[:0]
+		for _, node := range needed {
+			if inFlight[peer] >= maxBlocksInFlightPerPeer ||
+				node.height > peerHeight {
+
+				remaining = append(remaining, node)
+				continue
+			}
 
 			sm.requestedBlocks[*node.hash] = struct{}{}
-			syncPeerState.requestedBlocks[*node.hash] = struct{}{}
+			state.requestedBlocks[*node.hash] = struct{}{}
+			node.peer = peer
+			node.requestTime = now
+			inFlight[peer]++
 
 			// If we're fetching from a witness enabled peer
 			// post-fork, then ensure that we receive all the
 			// witness data in the blocks.
-			if sm.syncPeer.IsWitnessEnabled() {
+			iv := wire.NewInvVect(wire.InvTypeBlock, node.hash)
+			if peer.IsWitnessEnabled() {
 				iv.Type = wire.InvTypeWitnessBlock
 			}
-
 			gdmsg.AddInvVect(iv)
-			numRequested++
 		}
-		sm.startHeader = e.Next()
-		if numRequested >= wire.MaxInvPerMsg {
+		needed = remaining
+
+		if len(gdmsg.InvList) > 0 {
+			peer.QueueMessage(gdmsg, nil)
+		}
+	}
+}
+
+// releaseHeaderBlocks marks the blocks of the header list that are in flight
+// from the passed peer as no longer requested so they are requested from other
+// peers instead.
+func (sm *SyncManager) releaseHeaderBlocks(peer *peerpkg.Peer) {
+	for e := sm.headerList.Front().Next(); e != nil; e = e.Next() {
+		node := e.Value.(*headerNode)
+		if node.peer == peer && node.block == nil {
+			node.peer = nil
+		}
+	}
+}
+
+// findHeader returns the element of the header list with the passed hash, or
+// nil if there is none.
+func (sm *SyncManager) findHeader(hash *chainhash.Hash) *list.Element {
+	if el, ok := sm.headerIndex[*hash]; ok {
+		return el
+	}
+	if front := sm.headerList.Front(); front != nil &&
+		front.Value.(*headerNode).hash.IsEqual(hash) {
+
+		return front
+	}
+	return nil
+}
+
+// truncateHeaders removes the headers after the passed element from the header
+// list.  This is used when the sync peer is on a different chain than the
+// headers downloaded before, which happens when the sync peer changes.  Blocks
+// of the removed headers which are still in flight are processed like any
+// other block once they arrive.
+func (sm *SyncManager) truncateHeaders(el *list.Element) {
+	for e := sm.headerList.Back(); e != el; e = sm.headerList.Back() {
+		delete(sm.headerIndex, *e.Value.(*headerNode).hash)
+		sm.headerList.Remove(e)
+	}
+
+	// Only the remaining headers are known to lead to a verified
+	// checkpoint or the assumed valid block now.
+	node := el.Value.(*headerNode)
+	if sm.fastAddHeight > node.height {
+		sm.fastAddHeight = node.height
+		sm.nextCheckpoint = sm.findNextHeaderCheckpoint(node.height)
+	}
+	if sm.assumeValidHeight > node.height {
+		sm.assumeValidHeight = node.height
+	}
+}
+
+// handleStalledPeer deals with the passed peer stalling the block download in
+// headers-first mode.  The sync peer is kept to download the remaining headers
+// as long as there are other peers to download the blocks from, so it is no
+// longer asked for blocks instead.  Any other peer is disconnected.
+func (sm *SyncManager) handleStalledPeer(peer *peerpkg.Peer) {
+	state, exists := sm.peerStates[peer]
+	if exists && peer == sm.syncPeer && sm.haveOtherBlockPeers(peer) {
+		log.Infof("Requesting blocks from peers other than sync peer %s",
+			peer)
+		state.blocksStalled = true
+		sm.releaseHeaderBlocks(peer)
+		return
+	}
+
+	log.Infof("Disconnecting peer %s for stalling the block download",
+		peer)
+	peer.Disconnect()
+}
+
+// haveOtherBlockPeers returns whether or not there are peers other than the
+// passed one that blocks can be requested from in headers-first mode.
+func (sm *SyncManager) haveOtherBlockPeers(peer *peerpkg.Peer) bool {
+	for p, state := range sm.peerStates {
+		if p != peer && state.syncCandidate && !state.blocksStalled &&
+			p.Connected() {
+
+			return true
+		}
+	}
+	return false
+}
+
+// disconnectStalledPeers deals with the peers which stall the block download
+// in headers-first mode.  That is any peer that has not delivered a requested
+// block within blockRequestTimeout, as well as the peer that is holding back
+// the download window for longer than blockStallTimeout when every block
+// within the window has been requested already.  See handleStalledPeer for
+// details.
+func (sm *SyncManager) disconnectStalledPeers() {
+	windowEnd := sm.downloadWindowEnd()
+	windowFull := true
+	now := time.Now()
+	for e := sm.headerList.Front().Next(); e != nil; e = e.Next() {
+		node := e.Value.(*headerNode)
+		if node.height > windowEnd {
 			break
 		}
+		if node.block != nil {
+			continue
+		}
+		if node.peer == nil {
+			windowFull = false
+			continue
+		}
+
+		if node.peer.Connected() &&
+			now.Sub(node.requestTime) > blockRequestTimeout {
+
+			log.Infof("Peer %s did not deliver block %v in time",
+				node.peer, node.hash)
+			sm.handleStalledPeer(node.peer)
+		}
 	}
-	if len(gdmsg.InvList) > 0 {
-		sm.syncPeer.QueueMessage(gdmsg, nil)
+
+	// The block following the front of the list is the one that holds back
+	// the download window.
+	nextEl := sm.headerList.Front().Next()
+	if !windowFull || nextEl == nil ||
+		sm.headerList.Back().Value.(*headerNode).height <= windowEnd {
+
+		return
+	}
+	node := nextEl.Value.(*headerNode)
+	if node.peer != nil && node.block == nil && node.peer.Connected() &&
+		now.Sub(node.requestTime) > blockStallTimeout {
+
+		log.Infof("Peer %s is stalling the block download at block %v",
+			node.peer, node.hash)
+		sm.handleStalledPeer(node.peer)
 	}
 }
 
 // handleHeadersMsg handles block header messages from all peers.  Headers are
-// requested when performing a headers-first sync.
+// requested from the sync peer when performing a headers-first sync.
 func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 	peer := hmsg.peer
 	_, exists := sm.peerStates[peer]
@@ -906,54 +1699,103 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		return
 	}
 
-	// Nothing to do for an empty headers message.
-	if numHeaders == 0 {
+	// Headers are only requested from the sync peer.
+	if peer != sm.syncPeer {
+		log.Debugf("Ignoring %d headers from non-sync peer %s",
+			numHeaders, peer)
 		return
 	}
+	sm.headersRequested = false
+	sm.lastProgressTime = time.Now()
 
 	// Process all of the received headers ensuring each one connects to the
 	// previous and that checkpoints match.
-	receivedCheckpoint := false
-	var finalHash *chainhash.Hash
-	for _, blockHeader := range msg.Headers {
+	for i, blockHeader := range msg.Headers {
 		blockHash := blockHeader.BlockHash()
-		finalHash = &blockHash
-
-		// Ensure there is a previous header to compare against.
-		prevNodeEl := sm.headerList.Back()
-		if prevNodeEl == nil {
-			log.Warnf("Header list does not contain a previous" +
-				"element as expected -- disconnecting peer")
-			peer.Disconnect()
-			return
-		}
 
 		// Ensure the header properly connects to the previous one and
 		// add it to the list of headers.
 		node := headerNode{hash: &blockHash}
-		prevNode := prevNodeEl.Value.(*headerNode)
-		if prevNode.hash.IsEqual(&blockHeader.PrevBlock) {
-			node.height = prevNode.height + 1
-			e := sm.headerList.PushBack(&node)
-			if sm.startHeader == nil {
-				sm.startHeader = e
+		prevNode := sm.headerTip()
+		if !prevNode.hash.IsEqual(&blockHeader.PrevBlock) {
+			// A new sync peer might be on a different chain than the
+			// headers downloaded from the previous one, in which case
+			// it responds with the headers after the latest block
+			// they have in common.  Continue from there when that is
+			// one of the downloaded headers.  Otherwise, the peer is
+			// on a chain that forks from the current best chain, so
+			// fall back to normal mode which is able to handle side
+			// chains by means of the orphan pool.  Neither applies to
+			// a header chain that is checked in presync mode or
+			// verified against it, unless it forks from the best
+			// chain right away.
+			frontNode := sm.headerList.Front().Value.(*headerNode)
+			canFork := i == 0 && !sm.headersPresync &&
+				sm.presyncHashes == nil
+			forkEl := sm.findHeader(&blockHeader.PrevBlock)
+			switch {
+			case canFork && forkEl != nil:
+				log.Infof("Headers from peer %s fork from the "+
+					"downloaded headers at height %d",
+					peer.Addr(), forkEl.Value.(*headerNode).height)
+				sm.truncateHeaders(forkEl)
+				prevNode = forkEl.Value.(*headerNode)
+
+			case i == 0 && (canFork || prevNode.height == frontNode.height):
+				log.Infof("Headers from peer %s fork from the "+
+					"best chain -- switching to normal mode",
+					peer.Addr())
+				best := sm.chain.BestSnapshot()
+				sm.resetHeaderState(&best.Hash, best.Height)
+				locator, err := sm.chain.LatestBlockLocator()
+				if err != nil {
+					log.Errorf("Failed to get block locator "+
+						"for the latest block: %v", err)
+					return
+				}
+				peer.PushGetBlocksMsg(locator, &zeroHash)
+				return
+
+			default:
+				log.Warnf("Received block header that does not "+
+					"properly connect to the chain from peer %s "+
+					"-- disconnecting", peer.Addr())
+				peer.Disconnect()
+				return
 			}
-		} else {
-			log.Warnf("Received block header that does not "+
-				"properly connect to the chain from peer %s "+
-				"-- disconnecting", peer.Addr())
+		}
+		node.height = prevNode.height + 1
+
+		// Ensure the header has the proof of work it claims using the
+		// algorithm in effect at its height.
+		powAlgo := sm.chainParams.PowAlgorithm(node.height)
+		err := blockchain.CheckHeaderProofOfWork(blockHeader,
+			sm.chainParams.PowLimit, powAlgo)
+		if err != nil {
+			log.Warnf("Received block header %v with invalid "+
+				"proof of work from peer %s -- disconnecting: %v",
+				blockHash, peer.Addr(), err)
 			peer.Disconnect()
 			return
 		}
 
-		// Verify the header at the next checkpoint height matches.
-		if node.height == sm.nextCheckpoint.Height {
-			if node.hash.IsEqual(sm.nextCheckpoint.Hash) {
-				receivedCheckpoint = true
-				log.Infof("Verified downloaded block "+
-					"header against checkpoint at height "+
-					"%d/hash %s", node.height, node.hash)
-			} else {
+		// Headers of blocks which are known to be invalid can't become
+		// part of the chain.
+		if sm.chain.IsKnownInvalid(&blockHash) {
+			log.Warnf("Received block header %v of a known invalid "+
+				"block from peer %s -- disconnecting", blockHash,
+				peer.Addr())
+			peer.Disconnect()
+			return
+		}
+
+		// Verify the header at the next checkpoint height matches.  All
+		// blocks up to a verified checkpoint are eligible for less
+		// validation.
+		if sm.nextCheckpoint != nil &&
+			node.height == sm.nextCheckpoint.Height {
+
+			if !node.hash.IsEqual(sm.nextCheckpoint.Hash) {
 				log.Warnf("Block header at height %d/hash "+
 					"%s from peer %s does NOT match "+
 					"expected checkpoint hash of %s -- "+
@@ -963,35 +1805,116 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 				peer.Disconnect()
 				return
 			}
-			break
+
+			log.Infof("Verified downloaded block header against "+
+				"checkpoint at height %d/hash %s", node.height,
+				node.hash)
+			sm.fastAddHeight = node.height
+			sm.nextCheckpoint = sm.findNextHeaderCheckpoint(node.height)
 		}
-	}
 
-	// When this header is a checkpoint, switch to fetching the blocks for
-	// all of the headers since the last checkpoint.
-	if receivedCheckpoint {
-		// Since the first entry of the list is always the final block
-		// that is already in the database and is only used to ensure
-		// the next header links properly, it must be removed before
-		// fetching the blocks.
-		sm.headerList.Remove(sm.headerList.Front())
-		log.Infof("Received %v block headers: Fetching blocks",
-			sm.headerList.Len())
-		sm.progressLogger.SetLastLogTime(time.Now())
-		sm.fetchHeaderBlocks()
+		// Only keep track of the tip and the work of the header chain in
+		// presync mode until it has the minimum chain work and the
+		// assumed valid block is buried deep enough in it.
+		sm.headerWork.Add(sm.headerWork, blockchain.CalcWork(blockHeader.Bits))
+		if sm.headersPresync {
+			sm.presyncTip = &node
+			sm.trackAssumeValid(&node, blockHeader)
+			if sm.presyncDone() {
+				sm.presyncHashes[node.height] = blockHash
+				sm.finishHeadersPresync()
+				return
+			}
+			continue
+		}
+
+		// Verify the headers downloaded again after presync mode match
+		// the ones seen before.  Blocks can be requested up to the
+		// final verified header.
+		if sm.presyncHashes != nil {
+			presyncHash, ok := sm.presyncHashes[node.height]
+			if ok && presyncHash != blockHash {
+				log.Warnf("Block header at height %d/hash %s "+
+					"from peer %s does NOT match the header "+
+					"%s it sent before -- disconnecting",
+					node.height, node.hash, peer.Addr(),
+					presyncHash)
+				peer.Disconnect()
+				return
+			}
+			if ok {
+				sm.verifiedHeight = node.height
+			}
+			if node.height == sm.presyncTip.height {
+				sm.presyncTip = nil
+				sm.presyncHashes = nil
+			}
+		}
+
+		sm.headerIndex[blockHash] = sm.headerList.PushBack(&node)
+	}
+
+	// Remember the hash of the final header of the batch in presync mode.
+	// When the peer has no more headers at this point, its blocks are
+	// downloaded without assuming any scripts to be valid if the chain
+	// has the minimum chain work.  Otherwise, the peer's chain is useless,
+	// so sync from another peer instead.
+	if sm.headersPresync {
+		sm.presyncHashes[sm.presyncTip.height] = *sm.presyncTip.hash
+		if numHeaders < wire.MaxBlockHeadersPerMsg && sm.hasMinChainWork() {
+			frontNode := sm.headerList.Front().Value.(*headerNode)
+			if sm.presyncTip.height == frontNode.height {
+				// There are no blocks to download, so switch
+				// to normal mode right away.
+				sm.headersPresync = false
+				sm.presyncTip = nil
+				sm.presyncHashes = nil
+				sm.headersSynced = true
+				sm.processHeaderBlocks()
+				return
+			}
+			sm.finishHeadersPresync()
+			return
+		}
+		if numHeaders < wire.MaxBlockHeadersPerMsg {
+			log.Infof("Header chain of peer %s ends at height %d "+
+				"with less than the minimum chain work -- not "+
+				"syncing from it", peer.Addr(),
+				sm.presyncTip.height)
+			sm.peerStates[peer].syncCandidate = false
+			sm.restartSync()
+			return
+		}
+		sm.fetchHeaders()
 		return
 	}
 
-	// This header is not a checkpoint, so request the next batch of
-	// headers starting from the latest known header and ending with the
-	// next checkpoint.
-	locator := blockchain.BlockLocator([]*chainhash.Hash{finalHash})
-	err := peer.PushGetHeadersMsg(locator, sm.nextCheckpoint.Hash)
-	if err != nil {
-		log.Warnf("Failed to send getheaders message to "+
-			"peer %s: %v", peer.Addr(), err)
+	// The peer evidently has the blocks of the headers it sent, so make
+	// sure they are requested from it.
+	finalHeight := sm.headerList.Back().Value.(*headerNode).height
+	if finalHeight > peer.LastBlock() {
+		peer.UpdateLastBlockHeight(finalHeight)
+	}
+
+	// The peer has no more headers when it sent less than the maximum
+	// allowed per message.  It must at least send all of the headers it
+	// sent in presync mode again.
+	if numHeaders < wire.MaxBlockHeadersPerMsg && sm.presyncHashes != nil {
+		log.Warnf("Peer %s stopped sending headers at height %d before "+
+			"the header at height %d it sent before -- disconnecting",
+			peer.Addr(), finalHeight, sm.presyncTip.height)
+		peer.Disconnect()
 		return
 	}
+	if numHeaders < wire.MaxBlockHeadersPerMsg {
+		sm.headersSynced = true
+		log.Infof("Received block headers up to height %d from "+
+			"peer %s", finalHeight, peer.Addr())
+	}
+
+	// Request the blocks of the new headers and, if needed, the next batch
+	// of headers.
+	sm.processHeaderBlocks()
 }
 
 // haveInventory returns whether or not the inventory represented by the passed
@@ -1210,6 +2133,14 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 					iv.Type = wire.InvTypeWitnessBlock
 				}
 
+				// Request new blocks as compact blocks when
+				// the chain is current and the peer supports
+				// them since the mempool likely already has
+				// most of their transactions.
+				if sm.current() && peer.CmpctBlockVersion() != 0 {
+					iv.Type = wire.InvTypeCmpctBlock
+				}
+
 				gdmsg.AddInvVect(iv)
 				numRequested++
 			}
@@ -1289,6 +2220,14 @@ out:
 				sm.handleBlockMsg(msg)
 				msg.reply <- struct{}{}
 
+			case *cmpctBlockMsg:
+				sm.handleCmpctBlockMsg(msg)
+				msg.reply <- struct{}{}
+
+			case *blockTxnMsg:
+				sm.handleBlockTxnMsg(msg)
+				msg.reply <- struct{}{}
+
 			case *invMsg:
 				sm.handleInvMsg(msg)
 
@@ -1364,9 +2303,11 @@ func (sm *SyncManager) handleBlockchainNotification(notification *blockchain.Not
 			break
 		}
 
-		// Generate the inventory vector and relay it.
+		// Generate the inventory vector and relay it along with the
+		// block so it can be announced via headers or cmpctblock
+		// messages to the peers that prefer them.
 		iv := wire.NewInvVect(wire.InvTypeBlock, block.Hash())
-		sm.peerNotifier.RelayInventory(iv, block.MsgBlock().Header)
+		sm.peerNotifier.RelayInventory(iv, block)
 
 	// A block has been connected to the main block chain.
 	case blockchain.NTBlockConnected:
@@ -1469,6 +2410,31 @@ func (sm *SyncManager) QueueBlock(block *acmutil.Block, peer *peerpkg.Peer, done
 	sm.msgChan <- &blockMsg{block: block, peer: peer, reply: done}
 }
 
+// QueueCmpctBlock adds the passed cmpctblock message and peer to the block
+// handling queue.  Responds to the done channel argument after the message is
+// processed.
+func (sm *SyncManager) QueueCmpctBlock(cmpctBlock *wire.MsgCmpctBlock, peer *peerpkg.Peer, done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+
+	sm.msgChan <- &cmpctBlockMsg{cmpctBlock: cmpctBlock, peer: peer, reply: done}
+}
+
+// QueueBlockTxn adds the passed blocktxn message and peer to the block handling
+// queue.  Responds to the done channel argument after the message is processed.
+func (sm *SyncManager) QueueBlockTxn(blockTxn *wire.MsgBlockTxn, peer *peerpkg.Peer, done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+
+	sm.msgChan <- &blockTxnMsg{blockTxn: blockTxn, peer: peer, reply: done}
+}
+
 // QueueInv adds the passed inv message and peer to the block handling queue.
 func (sm *SyncManager) QueueInv(inv *wire.MsgInv, peer *peerpkg.Peer) {
 	// No channel handling here because peers do not need to block on inv
@@ -1571,6 +2537,8 @@ func New(config *Config) (*SyncManager, error) {
 		chain:           config.Chain,
 		txMemPool:       config.TxMemPool,
 		chainParams:     config.ChainParams,
+		assumeValid:     config.AssumeValid,
+		minChainWork:    config.MinimumChainWork,
 		rejectedTxns:    make(map[chainhash.Hash]struct{}),
 		requestedTxns:   make(map[chainhash.Hash]struct{}),
 		requestedBlocks: make(map[chainhash.Hash]struct{}),
@@ -1582,14 +2550,11 @@ func New(config *Config) (*SyncManager, error) {
 		feeEstimator:    config.FeeEstimator,
 	}
 
+	// Initialize the header state, including the next checkpoint, based on
+	// the current best block.
 	best := sm.chain.BestSnapshot()
-	if !config.DisableCheckpoints {
-		// Initialize the next checkpoint based on the current height.
-		sm.nextCheckpoint = sm.findNextHeaderCheckpoint(best.Height)
-		if sm.nextCheckpoint != nil {
-			sm.resetHeaderState(&best.Hash, best.Height)
-		}
-	} else {
+	sm.resetHeaderState(&best.Hash, best.Height)
+	if config.DisableCheckpoints {
 		log.Info("Checkpoints are disabled")
 	}
 
@@ -0,0 +1,301 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package netsync
+
+import (
+	"container/list"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Actinium-project/acmd/blockchain"
+	"github.com/Actinium-project/acmd/chaincfg"
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	peerpkg "github.com/Actinium-project/acmd/peer"
+	"github.com/Actinium-project/acmd/wire"
+	"github.com/Actinium-project/acmutil"
+)
+
+// newTestPeer returns a peer that is not connected for use in the tests.
+func newTestPeer(t *testing.T) *peerpkg.Peer {
+	t.Helper()
+	peer, err := peerpkg.NewOutboundPeer(&peerpkg.Config{}, "127.0.0.1:9333")
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected error: %v", err)
+	}
+	return peer
+}
+
+// newTestHeaderList returns a sync manager with a header list that starts at
+// the passed height and contains the given number of headers after it.  Like
+// resetHeaderState, the front of the list is not part of the header index.
+func newTestHeaderList(front int32, numHeaders int) *SyncManager {
+	sm := &SyncManager{
+		headerList:  list.New(),
+		headerIndex: make(map[chainhash.Hash]*list.Element),
+	}
+	for i := 0; i <= numHeaders; i++ {
+		height := front + int32(i)
+		node := &headerNode{
+			height: height,
+			hash:   &chainhash.Hash{byte(height), byte(height >> 8)},
+		}
+		el := sm.headerList.PushBack(node)
+		if i > 0 {
+			sm.headerIndex[*node.hash] = el
+		}
+	}
+	return sm
+}
+
+// TestFindHeader ensures the headers of the header list are found by hash,
+// including the front of the list.
+func TestFindHeader(t *testing.T) {
+	sm := newTestHeaderList(100, 5)
+	for e := sm.headerList.Front(); e != nil; e = e.Next() {
+		node := e.Value.(*headerNode)
+		if got := sm.findHeader(node.hash); got != e {
+			t.Errorf("findHeader(%v): did not find header at height %d",
+				node.hash, node.height)
+		}
+	}
+
+	if got := sm.findHeader(&chainhash.Hash{0xff, 0xff}); got != nil {
+		t.Errorf("findHeader: found unknown header at height %d",
+			got.Value.(*headerNode).height)
+	}
+}
+
+// TestTruncateHeaders ensures the headers after a fork point are removed from
+// the header list and index while the state related to the remaining headers
+// is adjusted accordingly.
+func TestTruncateHeaders(t *testing.T) {
+	tests := []struct {
+		name              string
+		forkHeight        int32
+		assumeValidHeight int32
+		wantLen           int
+		wantAssumeValid   int32
+	}{{
+		name:              "fork at the front",
+		forkHeight:        100,
+		assumeValidHeight: 0,
+		wantLen:           1,
+		wantAssumeValid:   0,
+	}, {
+		name:              "fork below the assumed valid block",
+		forkHeight:        103,
+		assumeValidHeight: 107,
+		wantLen:           4,
+		wantAssumeValid:   103,
+	}, {
+		name:              "fork above the assumed valid block",
+		forkHeight:        108,
+		assumeValidHeight: 105,
+		wantLen:           9,
+		wantAssumeValid:   105,
+	}, {
+		name:              "fork at the tip",
+		forkHeight:        110,
+		assumeValidHeight: 0,
+		wantLen:           11,
+		wantAssumeValid:   0,
+	}}
+
+	for _, test := range tests {
+		sm := newTestHeaderList(100, 10)
+		sm.fastAddHeight = 100
+		sm.assumeValidHeight = test.assumeValidHeight
+
+		forkHash := chainhash.Hash{byte(test.forkHeight),
+			byte(test.forkHeight >> 8)}
+		forkEl := sm.findHeader(&forkHash)
+		sm.truncateHeaders(forkEl)
+
+		if sm.headerList.Len() != test.wantLen {
+			t.Errorf("%s: unexpected header list length -- got %d, "+
+				"want %d", test.name, sm.headerList.Len(),
+				test.wantLen)
+			continue
+		}
+		if sm.headerList.Back() != forkEl {
+			t.Errorf("%s: fork point is not the tip of the header "+
+				"list", test.name)
+		}
+		if len(sm.headerIndex) != test.wantLen-1 {
+			t.Errorf("%s: unexpected header index size -- got %d, "+
+				"want %d", test.name, len(sm.headerIndex),
+				test.wantLen-1)
+		}
+		for hash, el := range sm.headerIndex {
+			if el.Value.(*headerNode).height > test.forkHeight {
+				t.Errorf("%s: removed header %v still indexed",
+					test.name, hash)
+			}
+		}
+		if sm.fastAddHeight != 100 {
+			t.Errorf("%s: unexpected fast add height -- got %d, "+
+				"want %d", test.name, sm.fastAddHeight, 100)
+		}
+		if sm.assumeValidHeight != test.wantAssumeValid {
+			t.Errorf("%s: unexpected assumed valid height -- got "+
+				"%d, want %d", test.name, sm.assumeValidHeight,
+				test.wantAssumeValid)
+		}
+	}
+}
+
+// TestReleaseHeaderBlocks ensures only the blocks in flight from the passed
+// peer are released while downloaded blocks and the blocks of other peers are
+// left alone.
+func TestReleaseHeaderBlocks(t *testing.T) {
+	peer1 := newTestPeer(t)
+	peer2 := newTestPeer(t)
+
+	sm := newTestHeaderList(100, 4)
+	nodes := make([]*headerNode, 0, 4)
+	for e := sm.headerList.Front().Next(); e != nil; e = e.Next() {
+		nodes = append(nodes, e.Value.(*headerNode))
+	}
+	nodes[0].peer = peer1
+	nodes[0].block = acmutil.NewBlock(&wire.MsgBlock{})
+	nodes[1].peer = peer1
+	nodes[2].peer = peer2
+	nodes[3].peer = peer1
+
+	sm.releaseHeaderBlocks(peer1)
+
+	wantPeers := []*peerpkg.Peer{peer1, nil, peer2, nil}
+	for i, node := range nodes {
+		if node.peer != wantPeers[i] {
+			t.Errorf("header at height %d: unexpected peer -- got "+
+				"%v, want %v", node.height, node.peer,
+				wantPeers[i])
+		}
+	}
+}
+
+// TestHandleStalledPeer ensures a stalling sync peer is only kept to download
+// headers when there are other peers to download the blocks from.
+func TestHandleStalledPeer(t *testing.T) {
+	syncPeer := newTestPeer(t)
+	otherPeer := newTestPeer(t)
+
+	sm := newTestHeaderList(100, 2)
+	sm.syncPeer = syncPeer
+	sm.peerStates = map[*peerpkg.Peer]*peerSyncState{
+		syncPeer:  {syncCandidate: true},
+		otherPeer: {syncCandidate: true},
+	}
+	node := sm.headerList.Back().Value.(*headerNode)
+	node.peer = syncPeer
+
+	// The other peer is not connected, so the blocks can't be downloaded
+	// from it and the sync peer is disconnected instead.
+	if sm.haveOtherBlockPeers(syncPeer) {
+		t.Fatal("haveOtherBlockPeers: unexpected peer to download " +
+			"blocks from")
+	}
+	sm.handleStalledPeer(syncPeer)
+	if sm.peerStates[syncPeer].blocksStalled {
+		t.Error("handleStalledPeer: sync peer kept without other " +
+			"peers to download blocks from")
+	}
+	if node.peer != syncPeer {
+		t.Error("handleStalledPeer: released blocks of disconnected " +
+			"sync peer")
+	}
+	select {
+	case <-waitForDisconnect(syncPeer):
+	case <-time.After(time.Second):
+		t.Error("handleStalledPeer: sync peer not disconnected")
+	}
+}
+
+// waitForDisconnect returns a channel that is closed once the passed peer has
+// been disconnected.
+func waitForDisconnect(peer *peerpkg.Peer) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		peer.WaitForDisconnect()
+		close(done)
+	}()
+	return done
+}
+
+// TestPresyncAssumeValid ensures the assumed valid block is found in the header
+// chain checked in presync mode and that presync mode only finishes once the
+// chain has the minimum chain work and the block is buried deep enough.
+func TestPresyncAssumeValid(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	bits := params.PowLimitBits
+	workPerBlock := blockchain.CalcWork(bits)
+	numBuried := int64(assumeValidMinBuriedTime / params.TargetTimePerBlock)
+
+	assumeValid := chainhash.Hash{0x01}
+	sm := &SyncManager{
+		chainParams:       params,
+		assumeValid:       &assumeValid,
+		minChainWork:      new(big.Int).Mul(workPerBlock, big.NewInt(10)),
+		headerWork:        new(big.Int),
+		searchAssumeValid: true,
+	}
+
+	header := &wire.BlockHeader{Bits: bits}
+	addHeader := func(height int32, hash chainhash.Hash) {
+		sm.headerWork.Add(sm.headerWork, workPerBlock)
+		sm.trackAssumeValid(&headerNode{height: height, hash: &hash},
+			header)
+	}
+
+	// Presync mode is not done while the chain is below the minimum chain
+	// work or the assumed valid block has not been found.
+	for height := int32(1); height <= 20; height++ {
+		addHeader(height, chainhash.Hash{0x02, byte(height)})
+		if sm.presyncDone() {
+			t.Fatalf("presyncDone: done at height %d without the "+
+				"assumed valid block", height)
+		}
+	}
+	if sm.assumeValidWorkNeeded != nil || sm.assumeValidHeight != 0 {
+		t.Fatal("trackAssumeValid: found assumed valid block in a " +
+			"chain that does not have it")
+	}
+
+	// Presync mode is done once the assumed valid block is buried by the
+	// required number of blocks.
+	addHeader(21, assumeValid)
+	if sm.assumeValidHeight != 21 {
+		t.Fatalf("trackAssumeValid: unexpected assumed valid height -- "+
+			"got %d, want %d", sm.assumeValidHeight, 21)
+	}
+	for i := int64(1); i <= numBuried; i++ {
+		if sm.assumeValidBuried() || sm.presyncDone() {
+			t.Fatalf("presyncDone: done after burying the assumed "+
+				"valid block by %d of %d blocks", i-1, numBuried)
+		}
+		addHeader(21+int32(i), chainhash.Hash{0x03, byte(i), byte(i >> 8)})
+	}
+	if !sm.assumeValidBuried() || !sm.presyncDone() {
+		t.Fatal("presyncDone: not done after burying the assumed valid " +
+			"block")
+	}
+
+	// Presync mode only depends on the minimum chain work when the assumed
+	// valid block is not searched for.
+	sm = &SyncManager{
+		chainParams:  params,
+		minChainWork: new(big.Int).Mul(workPerBlock, big.NewInt(2)),
+		headerWork:   new(big.Int),
+	}
+	addHeader(1, chainhash.Hash{0x04})
+	if sm.presyncDone() {
+		t.Fatal("presyncDone: done below the minimum chain work")
+	}
+	addHeader(2, chainhash.Hash{0x05})
+	if !sm.presyncDone() {
+		t.Fatal("presyncDone: not done with the minimum chain work")
+	}
+}
@@ -5,10 +5,13 @@
 package main
 
 import (
+	"net"
 	"sync/atomic"
+	"time"
 
 	"github.com/Actinium-project/acmd/blockchain"
 	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/connmgr"
 	"github.com/Actinium-project/acmd/mempool"
 	"github.com/Actinium-project/acmd/netsync"
 	"github.com/Actinium-project/acmd/peer"
@@ -223,6 +226,47 @@ func (cm *rpcConnManager) RelayTransactions(txns []*mempool.TxDesc) {
 	cm.server.relayTransactions(txns)
 }
 
+// BanSubnet bans the provided subnet until the given time and disconnects all
+// of the peers which are part of it.  Attempting to ban a subnet that is
+// already banned will return an error.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) BanSubnet(subnet *net.IPNet, until time.Time) error {
+	replyChan := make(chan error)
+	cm.server.query <- banSubnetMsg{
+		subnet: subnet,
+		until:  until,
+		reply:  replyChan,
+	}
+	return <-replyChan
+}
+
+// UnbanSubnet removes the ban of the provided subnet.  Attempting to unban a
+// subnet that is not banned will return an error.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) UnbanSubnet(subnet *net.IPNet) error {
+	return cm.server.banList.Unban(subnet)
+}
+
+// BannedSubnets returns all of the subnets which are currently banned.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) BannedSubnets() []connmgr.BanEntry {
+	return cm.server.banList.Entries()
+}
+
+// ClearBanned removes the bans of all subnets.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) ClearBanned() error {
+	return cm.server.banList.Clear()
+}
+
 // rpcSyncMgr provides a block manager for use with the RPC server and
 // implements the rpcserverSyncManager interface.
 type rpcSyncMgr struct {
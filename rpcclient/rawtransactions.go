@@ -336,6 +336,56 @@ func (c *Client) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainh
 	return c.SendRawTransactionAsync(tx, allowHighFees).Receive()
 }
 
+// FutureTestMempoolAcceptResult is a future promise to deliver the result
+// of a TestMempoolAcceptAsync RPC invocation (or an applicable error).
+type FutureTestMempoolAcceptResult chan *response
+
+// Receive waits for the response promised by the future and returns whether or
+// not each of the transactions would be accepted into the memory pool.
+func (r FutureTestMempoolAcceptResult) Receive() ([]acmjson.TestMempoolAcceptResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of testmempoolaccept result objects.
+	var results []acmjson.TestMempoolAcceptResult
+	err = json.Unmarshal(res, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// TestMempoolAcceptAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See TestMempoolAccept for the blocking version and more details.
+func (c *Client) TestMempoolAcceptAsync(txns []*wire.MsgTx) FutureTestMempoolAcceptResult {
+	// Serialize the transactions and convert them to hex strings.
+	txHexes := make([]string, 0, len(txns))
+	for _, tx := range txns {
+		buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+		if err := tx.Serialize(buf); err != nil {
+			return newFutureError(err)
+		}
+		txHexes = append(txHexes, hex.EncodeToString(buf.Bytes()))
+	}
+
+	cmd := acmjson.NewTestMempoolAcceptCmd(txHexes)
+	return c.sendCmd(cmd)
+}
+
+// TestMempoolAccept returns whether or not the passed transactions would be
+// accepted into the memory pool of the server without actually submitting
+// them.  The transactions are validated in order as a package, so each of them
+// may spend the outputs of the transactions that precede it.
+func (c *Client) TestMempoolAccept(txns []*wire.MsgTx) ([]acmjson.TestMempoolAcceptResult, error) {
+	return c.TestMempoolAcceptAsync(txns).Receive()
+}
+
 // FutureSignRawTransactionResult is a future promise to deliver the result
 // of one of the SignRawTransactionAsync family of RPC invocations (or an
 // applicable error).
@@ -655,6 +705,218 @@ func (c *Client) SearchRawTransactionsVerbose(address acmutil.Address, skip,
 		includePrevOut, reverse, &filterAddrs).Receive()
 }
 
+// newAddressIndexQuery returns an address index query for the passed addresses
+// which includes unconfirmed transactions according to the passed flag.
+func newAddressIndexQuery(addresses []acmutil.Address,
+	includeMempool bool) acmjson.AddressIndexQuery {
+
+	addrStrs := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		addrStrs = append(addrStrs, addr.EncodeAddress())
+	}
+	return acmjson.AddressIndexQuery{
+		Addresses:      addrStrs,
+		IncludeMempool: &includeMempool,
+	}
+}
+
+// FutureGetAddressBalanceResult is a future promise to deliver the result of a
+// GetAddressBalanceAsync RPC invocation (or an applicable error).
+type FutureGetAddressBalanceResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// balance of the requested addresses.
+func (r FutureGetAddressBalanceResult) Receive() (*acmjson.GetAddressBalanceResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getaddressbalance result object.
+	var balance acmjson.GetAddressBalanceResult
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// GetAddressBalanceAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressBalance for the blocking version and more details.
+func (c *Client) GetAddressBalanceAsync(addresses []acmutil.Address,
+	includeMempool bool) FutureGetAddressBalanceResult {
+
+	query := newAddressIndexQuery(addresses, includeMempool)
+	cmd := acmjson.NewGetAddressBalanceCmd(query)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressBalance returns the combined balance of the passed addresses along
+// with the total amount they ever received.
+//
+// NOTE: This requires the address index to be enabled on the server.
+func (c *Client) GetAddressBalance(addresses []acmutil.Address,
+	includeMempool bool) (*acmjson.GetAddressBalanceResult, error) {
+
+	return c.GetAddressBalanceAsync(addresses, includeMempool).Receive()
+}
+
+// FutureGetAddressDeltasResult is a future promise to deliver the result of a
+// GetAddressDeltasAsync RPC invocation (or an applicable error).
+type FutureGetAddressDeltasResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// changes to the balances of the requested addresses.
+func (r FutureGetAddressDeltasResult) Receive() ([]acmjson.GetAddressDeltasResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of getaddressdeltas result objects.
+	var deltas []acmjson.GetAddressDeltasResult
+	err = json.Unmarshal(res, &deltas)
+	if err != nil {
+		return nil, err
+	}
+
+	return deltas, nil
+}
+
+// GetAddressDeltasAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressDeltas for the blocking version and more details.
+func (c *Client) GetAddressDeltasAsync(addresses []acmutil.Address,
+	startHeight, endHeight int32, includeMempool bool) FutureGetAddressDeltasResult {
+
+	query := newAddressIndexQuery(addresses, includeMempool)
+	query.Start = &startHeight
+	query.End = &endHeight
+	cmd := acmjson.NewGetAddressDeltasCmd(query)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressDeltas returns all changes to the balances of the passed addresses
+// made by transactions in blocks between the passed start and end heights,
+// inclusive, in the order they appear in the blockchain.
+//
+// NOTE: This requires the address index to be enabled on the server.
+func (c *Client) GetAddressDeltas(addresses []acmutil.Address, startHeight,
+	endHeight int32, includeMempool bool) ([]acmjson.GetAddressDeltasResult, error) {
+
+	return c.GetAddressDeltasAsync(addresses, startHeight, endHeight,
+		includeMempool).Receive()
+}
+
+// FutureGetAddressTxIDsResult is a future promise to deliver the result of a
+// GetAddressTxIDsAsync RPC invocation (or an applicable error).
+type FutureGetAddressTxIDsResult chan *response
+
+// Receive waits for the response promised by the future and returns the hashes
+// of the transactions involving the requested addresses.
+func (r FutureGetAddressTxIDsResult) Receive() ([]*chainhash.Hash, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of strings.
+	var txIDs []string
+	err = json.Unmarshal(res, &txIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a slice of transaction hashes from the strings.
+	txHashes := make([]*chainhash.Hash, 0, len(txIDs))
+	for _, txID := range txIDs {
+		txHash, err := chainhash.NewHashFromStr(txID)
+		if err != nil {
+			return nil, err
+		}
+		txHashes = append(txHashes, txHash)
+	}
+
+	return txHashes, nil
+}
+
+// GetAddressTxIDsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressTxIDs for the blocking version and more details.
+func (c *Client) GetAddressTxIDsAsync(addresses []acmutil.Address,
+	startHeight, endHeight int32, includeMempool bool) FutureGetAddressTxIDsResult {
+
+	query := newAddressIndexQuery(addresses, includeMempool)
+	query.Start = &startHeight
+	query.End = &endHeight
+	cmd := acmjson.NewGetAddressTxIDsCmd(query)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressTxIDs returns the hashes of all transactions involving the passed
+// addresses in blocks between the passed start and end heights, inclusive, in
+// the order they appear in the blockchain.
+//
+// NOTE: This requires the address index to be enabled on the server.
+func (c *Client) GetAddressTxIDs(addresses []acmutil.Address, startHeight,
+	endHeight int32, includeMempool bool) ([]*chainhash.Hash, error) {
+
+	return c.GetAddressTxIDsAsync(addresses, startHeight, endHeight,
+		includeMempool).Receive()
+}
+
+// FutureGetAddressUtxosResult is a future promise to deliver the result of a
+// GetAddressUtxosAsync RPC invocation (or an applicable error).
+type FutureGetAddressUtxosResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// unspent outputs paying to the requested addresses.
+func (r FutureGetAddressUtxosResult) Receive() ([]acmjson.GetAddressUtxosResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of getaddressutxos result objects.
+	var utxos []acmjson.GetAddressUtxosResult
+	err = json.Unmarshal(res, &utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	return utxos, nil
+}
+
+// GetAddressUtxosAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressUtxos for the blocking version and more details.
+func (c *Client) GetAddressUtxosAsync(addresses []acmutil.Address,
+	includeMempool bool) FutureGetAddressUtxosResult {
+
+	query := newAddressIndexQuery(addresses, includeMempool)
+	cmd := acmjson.NewGetAddressUtxosCmd(query)
+	return c.sendCmd(cmd)
+}
+
+// GetAddressUtxos returns all unspent outputs paying to the passed addresses.
+//
+// NOTE: This requires the address index to be enabled on the server.
+func (c *Client) GetAddressUtxos(addresses []acmutil.Address,
+	includeMempool bool) ([]acmjson.GetAddressUtxosResult, error) {
+
+	return c.GetAddressUtxosAsync(addresses, includeMempool).Receive()
+}
+
 // FutureDecodeScriptResult is a future promise to deliver the result
 // of a DecodeScriptAsync RPC invocation (or an applicable error).
 type FutureDecodeScriptResult chan *response
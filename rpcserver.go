@@ -16,11 +16,13 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"math/rand"
 	"net"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,11 +30,13 @@ import (
 	"time"
 
 	"github.com/Actinium-project/acmd/acmjson"
+	"github.com/Actinium-project/acmd/addrmgr"
 	"github.com/Actinium-project/acmd/blockchain"
 	"github.com/Actinium-project/acmd/blockchain/indexers"
 	"github.com/Actinium-project/acmd/btcec"
 	"github.com/Actinium-project/acmd/chaincfg"
 	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/connmgr"
 	"github.com/Actinium-project/acmd/database"
 	"github.com/Actinium-project/acmd/mempool"
 	"github.com/Actinium-project/acmd/mining"
@@ -75,6 +79,10 @@ const (
 
 	// maxProtocolVersion is the max protocol version the server supports.
 	maxProtocolVersion = 70002
+
+	// maxTestMempoolAcceptTxns is the maximum number of transactions that
+	// can be validated as a package by a single testmempoolaccept request.
+	maxTestMempoolAcceptTxns = 25
 )
 
 var (
@@ -128,23 +136,33 @@ type commandHandler func(*rpcServer, interface{}, <-chan struct{}) (interface{},
 var rpcHandlers map[string]commandHandler
 var rpcHandlersBeforeInit = map[string]commandHandler{
 	"addnode":               handleAddNode,
+	"clearbanned":           handleClearBanned,
 	"createrawtransaction":  handleCreateRawTransaction,
 	"debuglevel":            handleDebugLevel,
 	"decoderawtransaction":  handleDecodeRawTransaction,
 	"decodescript":          handleDecodeScript,
+	"disconnectnode":        handleDisconnectNode,
 	"estimatefee":           handleEstimateFee,
+	"estimatesmartfee":      handleEstimateSmartFee,
 	"generate":              handleGenerate,
 	"getaddednodeinfo":      handleGetAddedNodeInfo,
+	"getaddressbalance":     handleGetAddressBalance,
+	"getaddressdeltas":      handleGetAddressDeltas,
+	"getaddresstxids":       handleGetAddressTxIDs,
+	"getaddressutxos":       handleGetAddressUtxos,
 	"getbestblock":          handleGetBestBlock,
 	"getbestblockhash":      handleGetBestBlockHash,
 	"getblock":              handleGetBlock,
 	"getblockchaininfo":     handleGetBlockChainInfo,
 	"getblockcount":         handleGetBlockCount,
+	"getblockfilter":        handleGetBlockFilter,
 	"getblockhash":          handleGetBlockHash,
 	"getblockheader":        handleGetBlockHeader,
+	"getblockstats":         handleGetBlockStats,
 	"getblocktemplate":      handleGetBlockTemplate,
 	"getcfilter":            handleGetCFilter,
 	"getcfilterheader":      handleGetCFilterHeader,
+	"getchaintips":          handleGetChainTips,
 	"getconnectioncount":    handleGetConnectionCount,
 	"getcurrentnet":         handleGetCurrentNet,
 	"getdifficulty":         handleGetDifficulty,
@@ -152,26 +170,42 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"gethashespersec":       handleGetHashesPerSec,
 	"getheaders":            handleGetHeaders,
 	"getinfo":               handleGetInfo,
+	"getmempoolancestors":   handleGetMempoolAncestors,
+	"getmempooldescendants": handleGetMempoolDescendants,
+	"getmempoolentry":       handleGetMempoolEntry,
 	"getmempoolinfo":        handleGetMempoolInfo,
 	"getmininginfo":         handleGetMiningInfo,
 	"getnettotals":          handleGetNetTotals,
 	"getnetworkhashps":      handleGetNetworkHashPS,
+	"getnetworkinfo":        handleGetNetworkInfo,
+	"getnodeaddresses":      handleGetNodeAddresses,
 	"getpeerinfo":           handleGetPeerInfo,
 	"getrawmempool":         handleGetRawMempool,
 	"getrawtransaction":     handleGetRawTransaction,
 	"gettxout":              handleGetTxOut,
+	"gettxoutproof":         handleGetTxOutProof,
+	"gettxoutsetinfo":       handleGetTxOutSetInfo,
 	"help":                  handleHelp,
+	"invalidateblock":       handleInvalidateBlock,
+	"listbanned":            handleListBanned,
 	"node":                  handleNode,
 	"ping":                  handlePing,
+	"pruneblockchain":       handlePruneBlockchain,
+	"reconsiderblock":       handleReconsiderBlock,
+	"savemempool":           handleSaveMempool,
+	"scantxoutset":          handleScanTxOutSet,
 	"searchrawtransactions": handleSearchRawTransactions,
 	"sendrawtransaction":    handleSendRawTransaction,
+	"setban":                handleSetBan,
 	"setgenerate":           handleSetGenerate,
 	"stop":                  handleStop,
 	"submitblock":           handleSubmitBlock,
+	"testmempoolaccept":     handleTestMempoolAccept,
 	"uptime":                handleUptime,
 	"validateaddress":       handleValidateAddress,
 	"verifychain":           handleVerifyChain,
 	"verifymessage":         handleVerifyMessage,
+	"verifytxoutproof":      handleVerifyTxOutProof,
 	"version":               handleVersion,
 }
 
@@ -195,7 +229,6 @@ var rpcAskWallet = map[string]struct{}{
 	"getreceivedbyaccount":   {},
 	"getreceivedbyaddress":   {},
 	"gettransaction":         {},
-	"gettxoutsetinfo":        {},
 	"getunconfirmedbalance":  {},
 	"getwalletinfo":          {},
 	"importprivkey":          {},
@@ -226,13 +259,8 @@ var rpcAskWallet = map[string]struct{}{
 // Commands that are currently unimplemented, but should ultimately be.
 var rpcUnimplemented = map[string]struct{}{
 	"estimatepriority": {},
-	"getchaintips":     {},
-	"getmempoolentry":  {},
-	"getnetworkinfo":   {},
 	"getwork":          {},
-	"invalidateblock":  {},
 	"preciousblock":    {},
-	"reconsiderblock":  {},
 }
 
 // Commands that are available to a limited user
@@ -255,29 +283,43 @@ var rpcLimited = map[string]struct{}{
 	"decoderawtransaction":  {},
 	"decodescript":          {},
 	"estimatefee":           {},
+	"estimatesmartfee":      {},
+	"getaddressbalance":     {},
+	"getaddressdeltas":      {},
+	"getaddresstxids":       {},
+	"getaddressutxos":       {},
 	"getbestblock":          {},
 	"getbestblockhash":      {},
 	"getblock":              {},
 	"getblockcount":         {},
+	"getblockfilter":        {},
 	"getblockhash":          {},
 	"getblockheader":        {},
+	"getblockstats":         {},
 	"getcfilter":            {},
 	"getcfilterheader":      {},
 	"getcurrentnet":         {},
 	"getdifficulty":         {},
 	"getheaders":            {},
 	"getinfo":               {},
+	"getmempoolancestors":   {},
+	"getmempooldescendants": {},
+	"getmempoolentry":       {},
 	"getnettotals":          {},
 	"getnetworkhashps":      {},
+	"getnetworkinfo":        {},
 	"getrawmempool":         {},
 	"getrawtransaction":     {},
 	"gettxout":              {},
+	"gettxoutproof":         {},
 	"searchrawtransactions": {},
 	"sendrawtransaction":    {},
 	"submitblock":           {},
+	"testmempoolaccept":     {},
 	"uptime":                {},
 	"validateaddress":       {},
 	"verifymessage":         {},
+	"verifytxoutproof":      {},
 	"version":               {},
 }
 
@@ -335,14 +377,16 @@ type gbtWorkState struct {
 	template      *mining.BlockTemplate
 	notifyMap     map[chainhash.Hash]map[int64]chan struct{}
 	timeSource    blockchain.MedianTimeSource
+	chainParams   *chaincfg.Params
 }
 
 // newGbtWorkState returns a new instance of a gbtWorkState with all internal
 // fields initialized and ready to use.
-func newGbtWorkState(timeSource blockchain.MedianTimeSource) *gbtWorkState {
+func newGbtWorkState(timeSource blockchain.MedianTimeSource, chainParams *chaincfg.Params) *gbtWorkState {
 	return &gbtWorkState{
-		notifyMap:  make(map[chainhash.Hash]map[int64]chan struct{}),
-		timeSource: timeSource,
+		notifyMap:   make(map[chainhash.Hash]map[int64]chan struct{}),
+		timeSource:  timeSource,
+		chainParams: chainParams,
 	}
 }
 
@@ -508,6 +552,17 @@ func messageToHex(msg wire.Message) (string, error) {
 	return hex.EncodeToString(buf.Bytes()), nil
 }
 
+// handleClearBanned handles clearbanned commands.
+func handleClearBanned(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	err := s.cfg.ConnMgr.ClearBanned()
+	if err != nil {
+		context := "Failed to clear banned subnets"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return nil, nil
+}
+
 // handleCreateRawTransaction handles createrawtransaction commands.
 func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*acmjson.CreateRawTransactionCmd)
@@ -855,6 +910,36 @@ func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return reply, nil
 }
 
+// handleDisconnectNode handles disconnectnode commands.
+func handleDisconnectNode(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.DisconnectNodeCmd)
+
+	hasAddr := c.Address != nil && *c.Address != ""
+	hasID := c.NodeID != nil
+	if hasAddr == hasID {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "Exactly one of address and nodeid must be provided",
+		}
+	}
+
+	var err error
+	if hasAddr {
+		addr := normalizeAddress(*c.Address, s.cfg.ChainParams.DefaultPort)
+		err = s.cfg.ConnMgr.DisconnectByAddr(addr)
+	} else {
+		err = s.cfg.ConnMgr.DisconnectByID(*c.NodeID)
+	}
+	if err != nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCClientNodeNotConnected,
+			Message: "Node not found in connected nodes",
+		}
+	}
+
+	return nil, nil
+}
+
 // handleEstimateFee handles estimatefee commands.
 func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*acmjson.EstimateFeeCmd)
@@ -877,6 +962,55 @@ func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return float64(feeRate), nil
 }
 
+// handleEstimateSmartFee handles estimatesmartfee commands.
+func handleEstimateSmartFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.EstimateSmartFeeCmd)
+
+	if s.cfg.FeeEstimator == nil {
+		return nil, errors.New("Fee estimation disabled")
+	}
+
+	if c.ConfTarget < 1 || c.ConfTarget > mempool.MaxSmartFeeTarget {
+		return nil, &acmjson.RPCError{
+			Code: acmjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Invalid conf_target, must be "+
+				"between 1 and %d", mempool.MaxSmartFeeTarget),
+		}
+	}
+
+	conservative := true
+	if c.EstimateMode != nil {
+		switch *c.EstimateMode {
+		case acmjson.EstimateModeUnset, acmjson.EstimateModeConservative:
+		case acmjson.EstimateModeEconomical:
+			conservative = false
+		default:
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCInvalidParameter,
+				Message: "Invalid estimate_mode parameter",
+			}
+		}
+	}
+
+	feeRate, blocks, err := s.cfg.FeeEstimator.EstimateSmartFee(
+		uint32(c.ConfTarget), conservative)
+	result := &acmjson.EstimateSmartFeeResult{
+		Blocks: int64(blocks),
+	}
+	if err != nil {
+		result.Errors = []string{err.Error()}
+		return result, nil
+	}
+
+	// Never suggest a fee rate the mempool would not accept.
+	rate := float64(feeRate)
+	if minFee := s.cfg.TxMemPool.MinFee().ToBTC(); rate < minFee {
+		rate = minFee
+	}
+	result.FeeRate = &rate
+	return result, nil
+}
+
 // handleGenerate handles generate commands.
 func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Respond with an error if there are no addresses to pay the
@@ -1021,6 +1155,254 @@ func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return results, nil
 }
 
+// addrIndexQuery houses the decoded parameters of the address index commands.
+type addrIndexQuery struct {
+	addrs       []acmutil.Address
+	addrStrs    []string
+	startHeight int32
+	endHeight   int32
+	mempool     bool
+}
+
+// parseAddrIndexQuery decodes the addresses, block height range and mempool
+// flag of the passed address index query.  An error is returned when the
+// address index is not enabled.
+func parseAddrIndexQuery(s *rpcServer, q *acmjson.AddressIndexQuery) (*addrIndexQuery, error) {
+	// Respond with an error if the address index is not enabled.
+	if s.cfg.AddrIndex == nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCMisc,
+			Message: "Address index must be enabled (--addrindex)",
+		}
+	}
+
+	query := addrIndexQuery{
+		addrs:     make([]acmutil.Address, 0, len(q.Addresses)),
+		addrStrs:  q.Addresses,
+		endHeight: math.MaxInt32,
+	}
+	for _, addrStr := range q.Addresses {
+		addr, err := acmutil.DecodeAddress(addrStr, s.cfg.ChainParams)
+		if err != nil {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCInvalidAddressOrKey,
+				Message: "Invalid address or key: " + err.Error(),
+			}
+		}
+		query.addrs = append(query.addrs, addr)
+	}
+
+	if q.Start != nil {
+		query.startHeight = *q.Start
+	}
+	if q.End != nil {
+		query.endHeight = *q.End
+	}
+	if query.startHeight < 0 || query.endHeight < query.startHeight {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "Start must be positive and not greater than end",
+		}
+	}
+	if q.IncludeMempool != nil {
+		query.mempool = *q.IncludeMempool
+	}
+	return &query, nil
+}
+
+// addrDeltaLess returns whether the first passed address delta appears before
+// the second one in the blockchain.  Unconfirmed deltas are ordered last.
+func addrDeltaLess(a, b *indexers.AddrDelta) bool {
+	aHeight, bHeight := a.Height, b.Height
+	if aHeight < 0 {
+		aHeight = math.MaxInt32
+	}
+	if bHeight < 0 {
+		bHeight = math.MaxInt32
+	}
+	if aHeight != bHeight {
+		return aHeight < bHeight
+	}
+	return a.BlockIndex < b.BlockIndex
+}
+
+// fetchAddrDeltas returns the deltas of all of the addresses in the passed
+// query within its block height range along with the unconfirmed deltas when
+// requested.  The address each delta belongs to is returned in a parallel
+// slice.
+func fetchAddrDeltas(s *rpcServer, query *addrIndexQuery) ([]indexers.AddrDelta, []string, error) {
+	var deltas []indexers.AddrDelta
+	var addrStrs []string
+	for i, addr := range query.addrs {
+		addrDeltas, err := s.cfg.AddrIndex.DeltasForAddress(addr,
+			query.startHeight, query.endHeight)
+		if err != nil {
+			context := "Failed to load address deltas"
+			return nil, nil, internalRPCError(err.Error(), context)
+		}
+		if query.mempool {
+			addrDeltas = append(addrDeltas,
+				s.cfg.AddrIndex.UnconfirmedDeltasForAddress(addr)...)
+		}
+
+		deltas = append(deltas, addrDeltas...)
+		for range addrDeltas {
+			addrStrs = append(addrStrs, query.addrStrs[i])
+		}
+	}
+
+	// Order the deltas of all addresses by their appearance in the
+	// blockchain.
+	sort.Stable(addrDeltasSorter{deltas, addrStrs})
+	return deltas, addrStrs, nil
+}
+
+// addrDeltasSorter implements sort.Interface to allow a slice of address deltas
+// along with the parallel slice of their addresses to be sorted.
+type addrDeltasSorter struct {
+	deltas   []indexers.AddrDelta
+	addrStrs []string
+}
+
+// Len returns the number of deltas in the slice.  It is part of the
+// sort.Interface implementation.
+func (s addrDeltasSorter) Len() int {
+	return len(s.deltas)
+}
+
+// Swap swaps the deltas at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s addrDeltasSorter) Swap(i, j int) {
+	s.deltas[i], s.deltas[j] = s.deltas[j], s.deltas[i]
+	s.addrStrs[i], s.addrStrs[j] = s.addrStrs[j], s.addrStrs[i]
+}
+
+// Less returns whether the delta with index i should sort before the delta with
+// index j.  It is part of the sort.Interface implementation.
+func (s addrDeltasSorter) Less(i, j int) bool {
+	return addrDeltaLess(&s.deltas[i], &s.deltas[j])
+}
+
+// handleGetAddressBalance implements the getaddressbalance command.
+func handleGetAddressBalance(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetAddressBalanceCmd)
+	query, err := parseAddrIndexQuery(s, &c.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	// The balance always covers the entire chain.
+	query.startHeight = 0
+	query.endHeight = math.MaxInt32
+	deltas, _, err := fetchAddrDeltas(s, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result acmjson.GetAddressBalanceResult
+	for i := range deltas {
+		result.Balance += deltas[i].Amount
+		if deltas[i].Amount > 0 {
+			result.Received += deltas[i].Amount
+		}
+	}
+	return &result, nil
+}
+
+// handleGetAddressDeltas implements the getaddressdeltas command.
+func handleGetAddressDeltas(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetAddressDeltasCmd)
+	query, err := parseAddrIndexQuery(s, &c.Query)
+	if err != nil {
+		return nil, err
+	}
+	deltas, addrStrs, err := fetchAddrDeltas(s, query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]acmjson.GetAddressDeltasResult, 0, len(deltas))
+	for i := range deltas {
+		delta := &deltas[i]
+		result := acmjson.GetAddressDeltasResult{
+			Address:    addrStrs[i],
+			TxID:       delta.TxHash.String(),
+			Index:      delta.Index,
+			Satoshis:   delta.Amount,
+			Height:     delta.Height,
+			BlockIndex: delta.BlockIndex,
+		}
+		if delta.PrevOut != nil {
+			result.PrevTxID = delta.PrevOut.Hash.String()
+			result.PrevOut = &delta.PrevOut.Index
+		}
+		if delta.SpentBy != nil {
+			result.SpentTxID = delta.SpentBy.TxHash.String()
+			result.SpentIndex = &delta.SpentBy.Index
+			result.SpentHeight = &delta.SpentBy.Height
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// handleGetAddressTxIDs implements the getaddresstxids command.
+func handleGetAddressTxIDs(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetAddressTxIDsCmd)
+	query, err := parseAddrIndexQuery(s, &c.Query)
+	if err != nil {
+		return nil, err
+	}
+	deltas, _, err := fetchAddrDeltas(s, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transactions typically involve an address more than once, so only
+	// include the first occurrence of each.
+	seen := make(map[chainhash.Hash]struct{}, len(deltas))
+	txIDs := make([]string, 0, len(deltas))
+	for i := range deltas {
+		txHash := deltas[i].TxHash
+		if _, ok := seen[txHash]; ok {
+			continue
+		}
+		seen[txHash] = struct{}{}
+		txIDs = append(txIDs, txHash.String())
+	}
+	return txIDs, nil
+}
+
+// handleGetAddressUtxos implements the getaddressutxos command.
+func handleGetAddressUtxos(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetAddressUtxosCmd)
+	query, err := parseAddrIndexQuery(s, &c.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]acmjson.GetAddressUtxosResult, 0)
+	for i, addr := range query.addrs {
+		utxos, err := s.cfg.AddrIndex.UtxosForAddress(addr, query.mempool)
+		if err != nil {
+			context := "Failed to load address unspent outputs"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+		for _, utxo := range utxos {
+			results = append(results, acmjson.GetAddressUtxosResult{
+				Address:     query.addrStrs[i],
+				TxID:        utxo.OutPoint.Hash.String(),
+				OutputIndex: utxo.OutPoint.Index,
+				Script:      hex.EncodeToString(utxo.PkScript),
+				Satoshis:    utxo.Amount,
+				Height:      utxo.Height,
+			})
+		}
+	}
+	return results, nil
+}
+
 // handleGetBestBlock implements the getbestblock command.
 func handleGetBestBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// All other "get block" commands give either the height, the
@@ -1076,6 +1458,15 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		return err
 	})
 	if err != nil {
+		// Known blocks whose data has been pruned can't be served.
+		if s.cfg.Chain.IsPruneMode() {
+			if _, err := s.cfg.Chain.HeaderByHash(hash); err == nil {
+				return nil, &acmjson.RPCError{
+					Code:    acmjson.ErrRPCMisc,
+					Message: "Block not available (pruned data)",
+				}
+			}
+		}
 		return nil, &acmjson.RPCError{
 			Code:    acmjson.ErrRPCBlockNotFound,
 			Message: "Block not found",
@@ -1197,11 +1588,14 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 		BestBlockHash: chainSnapshot.Hash.String(),
 		Difficulty:    getDifficultyRatio(chainSnapshot.Bits, params),
 		MedianTime:    chainSnapshot.MedianTime.Unix(),
-		Pruned:        false,
+		Pruned:        chain.IsPruneMode(),
 		SoftForks: &acmjson.SoftForks{
 			Bip9SoftForks: make(map[string]*acmjson.Bip9SoftForkDescription),
 		},
 	}
+	if chainInfo.Pruned {
+		chainInfo.PruneHeight = chain.PrunedHeight() + 1
+	}
 
 	// Next, populate the response with information describing the current
 	// status of soft-forks deployed via the super-majority block
@@ -1300,6 +1694,76 @@ func handleGetBlockCount(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return int64(best.Height), nil
 }
 
+// handleGetBlockFilter implements the getblockfilter command.
+func handleGetBlockFilter(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.cfg.CfIndex == nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCNoCFIndex,
+			Message: "The CF index must be enabled for this command",
+		}
+	}
+
+	c := cmd.(*acmjson.GetBlockFilterCmd)
+	var filterType wire.FilterType
+	switch *c.FilterType {
+	case acmjson.FilterTypeBasic:
+		filterType = wire.GCSFilterRegular
+	default:
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "Unknown filtertype",
+		}
+	}
+
+	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.BlockHash)
+	}
+	height, err := s.cfg.Chain.BlockHeightByHash(hash)
+	if err != nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	filterBytes, err := s.cfg.CfIndex.FilterByBlockHash(hash, filterType)
+	if err != nil {
+		context := "Failed to load filter"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	headerBytes, err := s.cfg.CfIndex.FilterHeaderByBlockHash(hash, filterType)
+	if err != nil {
+		context := "Failed to load filter header"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	// The filter index is built in the background, so a block in the main
+	// chain may not have had its filter created yet.  Report how far along
+	// the index is in that case.
+	if len(filterBytes) == 0 || len(headerBytes) == 0 {
+		_, indexHeight, err := s.cfg.CfIndex.Tip()
+		if err != nil {
+			context := "Failed to load filter index tip"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		return nil, &acmjson.RPCError{
+			Code: acmjson.ErrRPCMisc,
+			Message: fmt.Sprintf("Filter not found. Block filters are "+
+				"still in the process of being indexed (height "+
+				"%d of %d, block is at height %d)", indexHeight,
+				s.cfg.Chain.BestSnapshot().Height, height),
+		}
+	}
+
+	var header chainhash.Hash
+	copy(header[:], headerBytes)
+	return &acmjson.GetBlockFilterResult{
+		Filter: hex.EncodeToString(filterBytes),
+		Header: header.String(),
+	}, nil
+}
+
 // handleGetBlockHash implements the getblockhash command.
 func handleGetBlockHash(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*acmjson.GetBlockHashCmd)
@@ -1382,6 +1846,306 @@ func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct
 	return blockHeaderReply, nil
 }
 
+// blockStatsUTXOOverhead is the number of bytes added to the serialized size of
+// an output to approximate the size of its entry in the utxo set, accounting
+// for the outpoint, height and coinbase flag.
+const blockStatsUTXOOverhead = 41
+
+// blockStatsFeeStats are the statistics returned by getblockstats which require
+// the values of the outputs spent by the block from the spend journal.
+var blockStatsFeeStats = map[string]struct{}{
+	"avgfee":              {},
+	"avgfeerate":          {},
+	"feerate_percentiles": {},
+	"maxfee":              {},
+	"maxfeerate":          {},
+	"medianfee":           {},
+	"minfee":              {},
+	"minfeerate":          {},
+	"totalfee":            {},
+	"utxo_size_inc":       {},
+}
+
+// truncatedMedian returns the median of the passed values, rounded down to the
+// nearest integer.  Zero is returned when there are no values.  The passed
+// slice is sorted in place.
+func truncatedMedian(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// feeRatePercentiles returns the 10th, 25th, 50th, 75th and 90th percentiles of
+// the passed fee rates, weighted by the passed transaction weights which add up
+// to totalWeight.  The passed slices are sorted in place.
+func feeRatePercentiles(feeRates, weights []int64, totalWeight int64) []int64 {
+	percentiles := make([]int64, 5)
+	if len(feeRates) == 0 {
+		return percentiles
+	}
+
+	indexes := make([]int, len(feeRates))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return feeRates[indexes[i]] < feeRates[indexes[j]]
+	})
+
+	total := float64(totalWeight)
+	thresholds := []float64{total / 10, total / 4, total / 2,
+		total * 3 / 4, total * 9 / 10}
+	var next int
+	var cumulativeWeight int64
+	for _, i := range indexes {
+		cumulativeWeight += weights[i]
+		for next < len(thresholds) &&
+			float64(cumulativeWeight) >= thresholds[next] {
+
+			percentiles[next] = feeRates[i]
+			next++
+		}
+	}
+
+	// Fill any remaining percentiles with the highest fee rate.
+	for ; next < len(percentiles); next++ {
+		percentiles[next] = feeRates[indexes[len(indexes)-1]]
+	}
+	return percentiles
+}
+
+// handleGetBlockStats implements the getblockstats command.
+func handleGetBlockStats(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetBlockStatsCmd)
+
+	// The block is either identified by its height in the main chain or
+	// by its hash.
+	var hash *chainhash.Hash
+	hashOrHeight := string(c.HashOrHeight)
+	if height, err := strconv.ParseInt(hashOrHeight, 10, 32); err == nil {
+		best := s.cfg.Chain.BestSnapshot()
+		if height < 0 || height > int64(best.Height) {
+			return nil, &acmjson.RPCError{
+				Code: acmjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Target block height %d "+
+					"out of range", height),
+			}
+		}
+		hash, err = s.cfg.Chain.BlockHashByHeight(int32(height))
+		if err != nil {
+			context := "Failed to obtain block hash"
+			return nil, internalRPCError(err.Error(), context)
+		}
+	} else {
+		hash, err = chainhash.NewHashFromStr(hashOrHeight)
+		if err != nil {
+			return nil, rpcDecodeHexError(hashOrHeight)
+		}
+	}
+
+	// Determine whether any of the selected statistics require the
+	// values of the spent outputs.
+	needSpent := c.Stats == nil
+	if c.Stats != nil {
+		for _, stat := range *c.Stats {
+			if _, ok := blockStatsFeeStats[stat]; ok {
+				needSpent = true
+				break
+			}
+		}
+	}
+
+	if !s.cfg.Chain.MainChainHasBlock(hash) {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCBlockNotFound,
+			Message: "Block not found in the main chain",
+		}
+	}
+	block, err := s.cfg.Chain.BlockByHash(hash)
+	if err != nil {
+		if s.cfg.Chain.IsPruneMode() {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCMisc,
+				Message: "Block not available (pruned data)",
+			}
+		}
+		context := "Failed to load block"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	var stxos []blockchain.SpentTxOut
+	if needSpent {
+		stxos, err = s.cfg.Chain.FetchSpendJournal(block)
+		if err != nil {
+			context := "Failed to load spent outputs"
+			return nil, internalRPCError(err.Error(), context)
+		}
+	}
+	medianTime, err := s.cfg.Chain.MedianTimeByHash(hash)
+	if err != nil {
+		context := "Failed to obtain median time"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	var ins, outs, totalOut, totalSize, totalWeight, totalFee int64
+	var swTxs, swTotalSize, swTotalWeight, utxoSizeInc int64
+	var maxFee, maxFeeRate, maxTxSize int64
+	minFee, minFeeRate, minTxSize := int64(-1), int64(-1), int64(-1)
+	var fees, feeRates, weights, txSizes []int64
+	var stxoIdx int
+	for _, tx := range block.Transactions() {
+		msgTx := tx.MsgTx()
+		outs += int64(len(msgTx.TxOut))
+		var txOut int64
+		for _, out := range msgTx.TxOut {
+			txOut += out.Value
+			utxoSizeInc += int64(out.SerializeSize()) +
+				blockStatsUTXOOverhead
+		}
+		if blockchain.IsCoinBase(tx) {
+			continue
+		}
+
+		ins += int64(len(msgTx.TxIn))
+		totalOut += txOut
+
+		txSize := int64(msgTx.SerializeSize())
+		txSizes = append(txSizes, txSize)
+		totalSize += txSize
+		if txSize > maxTxSize {
+			maxTxSize = txSize
+		}
+		if minTxSize == -1 || txSize < minTxSize {
+			minTxSize = txSize
+		}
+
+		weight := blockchain.GetTransactionWeight(tx)
+		totalWeight += weight
+		if msgTx.HasWitness() {
+			swTxs++
+			swTotalSize += txSize
+			swTotalWeight += weight
+		}
+
+		if !needSpent {
+			continue
+		}
+		var txIn int64
+		for range msgTx.TxIn {
+			stxo := &stxos[stxoIdx]
+			stxoIdx++
+			txIn += stxo.Amount
+			spent := wire.NewTxOut(stxo.Amount, stxo.PkScript)
+			utxoSizeInc -= int64(spent.SerializeSize()) +
+				blockStatsUTXOOverhead
+		}
+
+		fee := txIn - txOut
+		fees = append(fees, fee)
+		totalFee += fee
+		if fee > maxFee {
+			maxFee = fee
+		}
+		if minFee == -1 || fee < minFee {
+			minFee = fee
+		}
+
+		// Fee rates are in satoshis per virtual byte.
+		var feeRate int64
+		if weight > 0 {
+			feeRate = fee * blockchain.WitnessScaleFactor / weight
+		}
+		feeRates = append(feeRates, feeRate)
+		weights = append(weights, weight)
+		if feeRate > maxFeeRate {
+			maxFeeRate = feeRate
+		}
+		if minFeeRate == -1 || feeRate < minFeeRate {
+			minFeeRate = feeRate
+		}
+	}
+
+	numTxns := int64(len(block.Transactions()))
+	header := &block.MsgBlock().Header
+	stats := acmjson.GetBlockStatsResult{
+		Hash:               hash.String(),
+		FeeRatePercentiles: feeRatePercentiles(feeRates, weights, totalWeight),
+		Height:             int64(block.Height()),
+		Ins:                ins,
+		MaxFee:             maxFee,
+		MaxFeeRate:         maxFeeRate,
+		MaxTxSize:          maxTxSize,
+		MedianFee:          truncatedMedian(fees),
+		MedianTime:         medianTime.Unix(),
+		MedianTxSize:       truncatedMedian(txSizes),
+		Outs:               outs,
+		Subsidy:            blockchain.CalcBlockSubsidy(block.Height(), s.cfg.ChainParams),
+		SegWitTotalSize:    swTotalSize,
+		SegWitTotalWeight:  swTotalWeight,
+		SegWitTxs:          swTxs,
+		Time:               header.Timestamp.Unix(),
+		TotalOut:           totalOut,
+		TotalSize:          totalSize,
+		TotalWeight:        totalWeight,
+		TotalFee:           totalFee,
+		Txs:                numTxns,
+		UTXOIncrease:       outs - ins,
+		UTXOSizeIncrease:   utxoSizeInc,
+	}
+	if numTxns > 1 {
+		stats.AverageFee = totalFee / (numTxns - 1)
+		stats.AverageTxSize = totalSize / (numTxns - 1)
+	}
+	if totalWeight > 0 {
+		stats.AverageFeeRate = totalFee * blockchain.WitnessScaleFactor /
+			totalWeight
+	}
+	if minFee != -1 {
+		stats.MinFee = minFee
+	}
+	if minFeeRate != -1 {
+		stats.MinFeeRate = minFeeRate
+	}
+	if minTxSize != -1 {
+		stats.MinTxSize = minTxSize
+	}
+
+	if c.Stats == nil {
+		return stats, nil
+	}
+
+	// Only return the selected statistics.
+	marshalled, err := json.Marshal(stats)
+	if err != nil {
+		context := "Failed to marshal block stats"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	var allStats map[string]json.RawMessage
+	if err := json.Unmarshal(marshalled, &allStats); err != nil {
+		context := "Failed to unmarshal block stats"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	selected := make(map[string]json.RawMessage, len(*c.Stats))
+	for _, stat := range *c.Stats {
+		value, ok := allStats[stat]
+		if !ok {
+			return nil, &acmjson.RPCError{
+				Code: acmjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Invalid selected "+
+					"statistic %s", stat),
+			}
+		}
+		selected[stat] = value
+	}
+	return selected, nil
+}
+
 // encodeTemplateID encodes the passed details into an ID that can be used to
 // uniquely identify a block template.
 func encodeTemplateID(prevHash *chainhash.Hash, lastGenerated time.Time) string {
@@ -1757,6 +2521,7 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 		Mutable:      gbtMutableFields,
 		NonceRange:   gbtNonceRange,
 		Capabilities: gbtCapabilities,
+		PowAlgorithm: state.chainParams.PowAlgorithm(template.Height).String(),
 	}
 	// If the generated block template includes transactions with witness
 	// data, then include the witness commitment in the GBT result.
@@ -2234,6 +2999,21 @@ func handleGetCFilterHeader(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hash.String(), nil
 }
 
+// handleGetChainTips implements the getchaintips command.
+func handleGetChainTips(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	chainTips := s.cfg.Chain.ChainTips()
+	results := make([]acmjson.GetChainTipsResult, 0, len(chainTips))
+	for _, tip := range chainTips {
+		results = append(results, acmjson.GetChainTipsResult{
+			Height:    tip.Height,
+			Hash:      tip.Hash.String(),
+			BranchLen: tip.BranchLen,
+			Status:    tip.Status.String(),
+		})
+	}
+	return results, nil
+}
+
 // handleGetConnectionCount implements the getconnectioncount command.
 func handleGetConnectionCount(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return s.cfg.ConnMgr.ConnectedCount(), nil
@@ -2320,6 +3100,81 @@ func handleGetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 	return ret, nil
 }
 
+// handleGetMempoolAncestors implements the getmempoolancestors command.
+func handleGetMempoolAncestors(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetMempoolAncestorsCmd)
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	entries, err := s.cfg.TxMemPool.MempoolAncestors(txHash)
+	if err != nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCNoTxInfo,
+			Message: "Transaction not in mempool",
+		}
+	}
+
+	return mempoolEntriesResult(entries, c.Verbose != nil && *c.Verbose), nil
+}
+
+// handleGetMempoolDescendants implements the getmempooldescendants command.
+func handleGetMempoolDescendants(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetMempoolDescendantsCmd)
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	entries, err := s.cfg.TxMemPool.MempoolDescendants(txHash)
+	if err != nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCNoTxInfo,
+			Message: "Transaction not in mempool",
+		}
+	}
+
+	return mempoolEntriesResult(entries, c.Verbose != nil && *c.Verbose), nil
+}
+
+// mempoolEntriesResult returns the result of the getmempoolancestors and
+// getmempooldescendants commands for the passed mempool entries.  The entries
+// themselves are returned when verbose is set, otherwise the response is
+// simply a sorted array of the transaction hashes.
+func mempoolEntriesResult(entries map[string]*acmjson.GetMempoolEntryResult, verbose bool) interface{} {
+	if verbose {
+		return entries
+	}
+
+	hashStrings := make([]string, 0, len(entries))
+	for hash := range entries {
+		hashStrings = append(hashStrings, hash)
+	}
+	sort.Strings(hashStrings)
+
+	return hashStrings
+}
+
+// handleGetMempoolEntry implements the getmempoolentry command.
+func handleGetMempoolEntry(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetMempoolEntryCmd)
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	entry, err := s.cfg.TxMemPool.MempoolEntry(txHash)
+	if err != nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCNoTxInfo,
+			Message: "Transaction not in mempool",
+		}
+	}
+
+	return entry, nil
+}
+
 // handleGetMempoolInfo implements the getmempoolinfo command.
 func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	mempoolTxns := s.cfg.TxMemPool.TxDescs()
@@ -2330,8 +3185,11 @@ func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct
 	}
 
 	ret := &acmjson.GetMempoolInfoResult{
-		Size:  int64(len(mempoolTxns)),
-		Bytes: numBytes,
+		Size:          int64(len(mempoolTxns)),
+		Bytes:         numBytes,
+		MaxMempool:    int64(cfg.MaxMempool) * 1000000,
+		MempoolMinFee: s.cfg.TxMemPool.MinFee().ToBTC(),
+		MinRelayTxFee: cfg.minRelayTxFee.ToBTC(),
 	}
 
 	return ret, nil
@@ -2478,6 +3336,122 @@ func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hashesPerSec.Int64(), nil
 }
 
+// handleGetNetworkInfo implements the getnetworkinfo command.
+func handleGetNetworkInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Build the user agent the same way it is advertised to peers.
+	userAgent := wire.MsgVersion{UserAgent: wire.DefaultUserAgent}
+	err := userAgent.AddUserAgent(userAgentName, userAgentVersion,
+		cfg.UserAgentComments...)
+	if err != nil {
+		context := "Failed to build user agent"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	// There is no way to restrict the networks connections are made to, so
+	// IPv4 and IPv6 are always reachable.  Onion addresses are only
+	// reachable through a proxy, which is the general proxy unless a
+	// dedicated onion proxy is configured.
+	onionProxy := cfg.OnionProxy
+	if onionProxy == "" {
+		onionProxy = cfg.Proxy
+	}
+	if cfg.NoOnion {
+		onionProxy = ""
+	}
+	networks := []acmjson.NetworksResult{
+		{
+			Name:                      "ipv4",
+			Reachable:                 true,
+			Proxy:                     cfg.Proxy,
+			ProxyRandomizeCredentials: cfg.Proxy != "" && cfg.TorIsolation,
+		},
+		{
+			Name:                      "ipv6",
+			Reachable:                 true,
+			Proxy:                     cfg.Proxy,
+			ProxyRandomizeCredentials: cfg.Proxy != "" && cfg.TorIsolation,
+		},
+		{
+			Name:                      "onion",
+			Limited:                   cfg.NoOnion,
+			Reachable:                 onionProxy != "",
+			Proxy:                     onionProxy,
+			ProxyRandomizeCredentials: onionProxy != "" && cfg.TorIsolation,
+		},
+	}
+
+	localAddrs := s.cfg.AddrManager.LocalAddresses()
+	localAddresses := make([]acmjson.LocalAddressesResult, 0, len(localAddrs))
+	for _, localAddr := range localAddrs {
+		// The address key is used since it properly encodes onion
+		// addresses.
+		host, _, err := net.SplitHostPort(addrmgr.NetAddressKey(
+			localAddr.NetAddress))
+		if err != nil {
+			context := "Failed to parse local address"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		localAddresses = append(localAddresses, acmjson.LocalAddressesResult{
+			Address: host,
+			Port:    localAddr.NetAddress.Port,
+			Score:   int32(localAddr.Score),
+		})
+	}
+
+	// The incremental relay fee, which is the minimum fee rate increase
+	// for replacing and evicting transactions, is the minimum relay fee.
+	ret := &acmjson.GetNetworkInfoResult{
+		Version:         int32(1000000*appMajor + 10000*appMinor + 100*appPatch),
+		SubVersion:      userAgent.UserAgent,
+		ProtocolVersion: int32(maxProtocolVersion),
+		LocalServices:   fmt.Sprintf("%016x", uint64(s.cfg.Services)),
+		LocalRelay:      !cfg.BlocksOnly,
+		TimeOffset:      int64(s.cfg.TimeSource.Offset().Seconds()),
+		Connections:     s.cfg.ConnMgr.ConnectedCount(),
+		NetworkActive:   true,
+		Networks:        networks,
+		RelayFee:        cfg.minRelayTxFee.ToBTC(),
+		IncrementalFee:  cfg.minRelayTxFee.ToBTC(),
+		LocalAddresses:  localAddresses,
+	}
+
+	return ret, nil
+}
+
+// handleGetNodeAddresses implements the getnodeaddresses command.
+func handleGetNodeAddresses(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetNodeAddressesCmd)
+
+	count := int32(1)
+	if c.Count != nil {
+		count = *c.Count
+	}
+	if count < 0 {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "Address count out of range",
+		}
+	}
+
+	addrs := s.cfg.AddrManager.RandomAddresses(int(count))
+	results := make([]acmjson.GetNodeAddressesResult, 0, len(addrs))
+	for _, na := range addrs {
+		host, _, err := net.SplitHostPort(addrmgr.NetAddressKey(na))
+		if err != nil {
+			context := "Failed to parse node address"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		results = append(results, acmjson.GetNodeAddressesResult{
+			Time:     na.Timestamp.Unix(),
+			Services: uint64(na.Services),
+			Address:  host,
+			Port:     na.Port,
+		})
+	}
+
+	return results, nil
+}
+
 // handleGetPeerInfo implements the getpeerinfo command.
 func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	peers := s.cfg.ConnMgr.ConnectedPeers()
@@ -2746,20 +3720,154 @@ func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		addresses[i] = addr.EncodeAddress()
 	}
 
-	txOutReply := &acmjson.GetTxOutResult{
-		BestBlock:     bestBlockHash,
-		Confirmations: int64(confirmations),
-		Value:         acmutil.Amount(value).ToBTC(),
-		ScriptPubKey: acmjson.ScriptPubKeyResult{
-			Asm:       disbuf,
-			Hex:       hex.EncodeToString(pkScript),
-			ReqSigs:   int32(reqSigs),
-			Type:      scriptClass.String(),
-			Addresses: addresses,
-		},
-		Coinbase: isCoinbase,
-	}
-	return txOutReply, nil
+	txOutReply := &acmjson.GetTxOutResult{
+		BestBlock:     bestBlockHash,
+		Confirmations: int64(confirmations),
+		Value:         acmutil.Amount(value).ToBTC(),
+		ScriptPubKey: acmjson.ScriptPubKeyResult{
+			Asm:       disbuf,
+			Hex:       hex.EncodeToString(pkScript),
+			ReqSigs:   int32(reqSigs),
+			Type:      scriptClass.String(),
+			Addresses: addresses,
+		},
+		Coinbase: isCoinbase,
+	}
+	return txOutReply, nil
+}
+
+// handleGetTxOutProof implements the gettxoutproof command.
+func handleGetTxOutProof(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.GetTxOutProofCmd)
+
+	if len(c.TxIDs) == 0 {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "Parameter 'txids' cannot be empty",
+		}
+	}
+	txHashes := make(map[chainhash.Hash]struct{}, len(c.TxIDs))
+	var firstTxHash *chainhash.Hash
+	for _, txID := range c.TxIDs {
+		txHash, err := chainhash.NewHashFromStr(txID)
+		if err != nil {
+			return nil, rpcDecodeHexError(txID)
+		}
+		if _, ok := txHashes[*txHash]; ok {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCInvalidParameter,
+				Message: "Invalid parameter, duplicated txid: " + txID,
+			}
+		}
+		txHashes[*txHash] = struct{}{}
+		if firstTxHash == nil {
+			firstTxHash = txHash
+		}
+	}
+
+	// Determine the block containing the transactions, either from the
+	// passed block hash or by looking up the first transaction in the
+	// transaction index.
+	var blockHash *chainhash.Hash
+	if c.BlockHash != nil {
+		var err error
+		blockHash, err = chainhash.NewHashFromStr(*c.BlockHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.BlockHash)
+		}
+	} else {
+		if s.cfg.TxIndex == nil {
+			return nil, &acmjson.RPCError{
+				Code: acmjson.ErrRPCNoTxInfo,
+				Message: "The transaction index must be " +
+					"enabled to find the block containing " +
+					"the transactions (specify --txindex) " +
+					"unless the block hash is provided",
+			}
+		}
+
+		blockRegion, err := s.cfg.TxIndex.TxBlockRegion(firstTxHash)
+		if err != nil {
+			context := "Failed to retrieve transaction location"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if blockRegion == nil {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCNoTxInfo,
+				Message: "Transaction not yet in block",
+			}
+		}
+		blockHash = blockRegion.Hash
+	}
+
+	if !s.cfg.Chain.MainChainHasBlock(blockHash) {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCBlockNotFound,
+			Message: "Block not found in the main chain",
+		}
+	}
+	block, err := s.cfg.Chain.BlockByHash(blockHash)
+	if err != nil {
+		if s.cfg.Chain.IsPruneMode() {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCMisc,
+				Message: "Block not available (pruned data)",
+			}
+		}
+		context := "Failed to load block"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	// Ensure all of the transactions are actually part of the block.
+	var numFound int
+	for _, tx := range block.Transactions() {
+		if _, ok := txHashes[*tx.Hash()]; ok {
+			numFound++
+		}
+	}
+	if numFound != len(txHashes) {
+		return nil, &acmjson.RPCError{
+			Code: acmjson.ErrRPCInvalidAddressOrKey,
+			Message: "Not all transactions found in specified or " +
+				"retrieved block",
+		}
+	}
+
+	proof := blockchain.BuildMerkleProof(block, txHashes)
+	var buf bytes.Buffer
+	err = proof.BtcEncode(&buf, maxProtocolVersion, wire.BaseEncoding)
+	if err != nil {
+		context := "Failed to serialize merkle proof"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// handleGetTxOutSetInfo implements the gettxoutsetinfo command.
+func handleGetTxOutSetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Iterating the utxo set takes a while, so stop early when the client
+	// goes away.
+	stats, err := s.cfg.Chain.FetchUtxoStats(closeChan)
+	if err != nil {
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit
+		default:
+		}
+		context := "Failed to calculate utxo set statistics"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return &acmjson.GetTxOutSetInfoResult{
+		Height:          int64(stats.BestHeight),
+		BestBlock:       stats.BestHash.String(),
+		Transactions:    stats.Transactions,
+		TxOuts:          stats.TxOuts,
+		BytesSerialized: stats.SerializedSize,
+		HashSerialized:  stats.Hash.String(),
+		TotalAmount:     acmutil.Amount(stats.TotalAmount).ToBTC(),
+	}, nil
 }
 
 // handleHelp implements the help command.
@@ -2801,6 +3909,49 @@ func handleHelp(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return help, nil
 }
 
+// handleInvalidateBlock implements the invalidateblock command.
+func handleInvalidateBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.InvalidateBlockCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.BlockHash)
+	}
+	if _, err := s.cfg.Chain.HeaderByHash(hash); err != nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	err = s.cfg.Chain.InvalidateBlock(hash)
+	if err != nil {
+		context := "Failed to invalidate block"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return nil, nil
+}
+
+// handleListBanned implements the listbanned command.
+func handleListBanned(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	entries := s.cfg.ConnMgr.BannedSubnets()
+	now := time.Now().Unix()
+	results := make([]acmjson.ListBannedResult, 0, len(entries))
+	for _, entry := range entries {
+		created, until := entry.Created.Unix(), entry.Until.Unix()
+		results = append(results, acmjson.ListBannedResult{
+			Address:       entry.Subnet.String(),
+			BanCreated:    created,
+			BannedUntil:   until,
+			BanDuration:   until - created,
+			TimeRemaining: until - now,
+		})
+	}
+
+	return results, nil
+}
+
 // handlePing implements the ping command.
 func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Ask server to ping \o_
@@ -2814,6 +3965,87 @@ func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return nil, nil
 }
 
+// handlePruneBlockchain implements the pruneblockchain command.
+func handlePruneBlockchain(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.PruneBlockchainCmd)
+
+	if !s.cfg.Chain.IsPruneMode() {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCMisc,
+			Message: "Cannot prune blocks because node is not in prune mode",
+		}
+	}
+	if c.Height < 0 {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "Negative block height",
+		}
+	}
+
+	best := s.cfg.Chain.BestSnapshot()
+	if c.Height > int64(best.Height) {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "Blockchain is shorter than the attempted prune height",
+		}
+	}
+
+	prunedHeight, err := s.cfg.Chain.PruneBlockchain(int32(c.Height))
+	if err != nil {
+		context := "Failed to prune blockchain"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return int64(prunedHeight), nil
+}
+
+// handleReconsiderBlock implements the reconsiderblock command.
+func handleReconsiderBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.ReconsiderBlockCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.BlockHash)
+	}
+	if _, err := s.cfg.Chain.HeaderByHash(hash); err != nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	err = s.cfg.Chain.ReconsiderBlock(hash)
+	if err != nil {
+		context := "Failed to reconsider block"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return nil, nil
+}
+
+// handleSaveMempool implements the savemempool command.
+func handleSaveMempool(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	select {
+	case <-s.cfg.MempoolLoaded:
+	default:
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCMisc,
+			Message: "The mempool was not loaded yet",
+		}
+	}
+
+	numTxns, err := s.cfg.TxMemPool.SaveFile(s.cfg.MempoolFile)
+	if err != nil {
+		context := "Failed to save mempool"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	rpcsLog.Infof("Saved %d mempool %s to %s", numTxns,
+		pickNoun(uint64(numTxns), "transaction", "transactions"),
+		s.cfg.MempoolFile)
+
+	return nil, nil
+}
+
 // retrievedTx represents a transaction that was either loaded from the
 // transaction memory pool or from the database.  When a transaction is loaded
 // from the database, it is loaded with the raw serialized bytes while the
@@ -3035,6 +4267,214 @@ func fetchMempoolTxnsForAddress(s *rpcServer, addr acmutil.Address, numToSkip, n
 	return mpTxns[numToSkip:rangeEnd], numToSkip
 }
 
+// utxoScanProgressInterval is the number of unspent outputs scanned by the
+// scantxoutset command between updates of the scan progress.
+const utxoScanProgressInterval = 1000
+
+// utxoScanState houses the state of the utxo set scan started by the
+// scantxoutset command.  Only a single scan may be in progress at a time.
+type utxoScanState struct {
+	sync.Mutex
+	inProgress bool
+	progress   float64
+	abort      chan struct{}
+}
+
+// abortScan aborts the scan in progress, if any, and returns whether or not a
+// scan was aborted.
+func (u *utxoScanState) abortScan() bool {
+	u.Lock()
+	defer u.Unlock()
+
+	if !u.inProgress {
+		return false
+	}
+	select {
+	case <-u.abort:
+		return false
+	default:
+		close(u.abort)
+		return true
+	}
+}
+
+// parseScanObject returns the public key script described by the passed scan
+// object of the scantxoutset command.  Only the addr(<address>) and
+// raw(<hex script>) descriptors are supported.  An optional descriptor checksum
+// is accepted, but not verified.
+func parseScanObject(desc string, params *chaincfg.Params) ([]byte, error) {
+	invalidErr := &acmjson.RPCError{
+		Code: acmjson.ErrRPCInvalidParameter,
+		Message: fmt.Sprintf("Invalid scan object %q, only "+
+			"addr(<address>) and raw(<hex script>) descriptors "+
+			"are supported", desc),
+	}
+
+	if idx := strings.IndexByte(desc, '#'); idx != -1 {
+		desc = desc[:idx]
+	}
+	if !strings.HasSuffix(desc, ")") {
+		return nil, invalidErr
+	}
+	switch {
+	case strings.HasPrefix(desc, "addr("):
+		addrStr := desc[len("addr(") : len(desc)-1]
+		addr, err := acmutil.DecodeAddress(addrStr, params)
+		if err != nil || !addr.IsForNet(params) {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCInvalidAddressOrKey,
+				Message: "Invalid address or key: " + addrStr,
+			}
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			context := "Failed to generate pay-to-address script"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		return pkScript, nil
+
+	case strings.HasPrefix(desc, "raw("):
+		scriptHex := desc[len("raw(") : len(desc)-1]
+		pkScript, err := hex.DecodeString(scriptHex)
+		if err != nil {
+			return nil, rpcDecodeHexError(scriptHex)
+		}
+		return pkScript, nil
+	}
+
+	return nil, invalidErr
+}
+
+// handleScanTxOutSet implements the scantxoutset command.
+func handleScanTxOutSet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.ScanTxOutSetCmd)
+
+	switch c.Action {
+	case acmjson.STOStatus:
+		s.utxoScan.Lock()
+		defer s.utxoScan.Unlock()
+		if !s.utxoScan.inProgress {
+			return nil, nil
+		}
+		return &acmjson.ScanTxOutSetStatusResult{
+			Progress: s.utxoScan.progress,
+		}, nil
+
+	case acmjson.STOAbort:
+		return s.utxoScan.abortScan(), nil
+
+	case acmjson.STOStart:
+
+	default:
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "Invalid action: " + string(c.Action),
+		}
+	}
+
+	if c.ScanObjects == nil || len(*c.ScanObjects) == 0 {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "Scan objects are required to start a scan",
+		}
+	}
+	descs := make(map[string]string, len(*c.ScanObjects))
+	for _, desc := range *c.ScanObjects {
+		pkScript, err := parseScanObject(desc, s.cfg.ChainParams)
+		if err != nil {
+			return nil, err
+		}
+		descs[string(pkScript)] = desc
+	}
+
+	s.utxoScan.Lock()
+	if s.utxoScan.inProgress {
+		s.utxoScan.Unlock()
+		return nil, &acmjson.RPCError{
+			Code: acmjson.ErrRPCInvalidParameter,
+			Message: "Scan already in progress, use action " +
+				"\"abort\" or \"status\"",
+		}
+	}
+	abort := make(chan struct{})
+	s.utxoScan.inProgress = true
+	s.utxoScan.progress = 0
+	s.utxoScan.abort = abort
+	s.utxoScan.Unlock()
+	defer func() {
+		s.utxoScan.Lock()
+		s.utxoScan.inProgress = false
+		s.utxoScan.Unlock()
+	}()
+
+	// Scanning the utxo set takes a while, so stop early when the client
+	// goes away.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-closeChan:
+			s.utxoScan.abortScan()
+		case <-done:
+		}
+	}()
+
+	var numScanned, totalAmount int64
+	unspents := make([]acmjson.ScanTxOutSetUnspent, 0)
+	bestHash, bestHeight, err := s.cfg.Chain.ScanUtxoSet(abort,
+		func(outpoint wire.OutPoint, entry *blockchain.UtxoEntry) error {
+			numScanned++
+			if numScanned%utxoScanProgressInterval == 0 {
+				// The outputs are ordered by their outpoint, so
+				// the first two bytes of the hash provide a good
+				// estimate of the progress.
+				pos := uint16(outpoint.Hash[0])<<8 |
+					uint16(outpoint.Hash[1])
+				s.utxoScan.Lock()
+				s.utxoScan.progress = float64(pos) * 100 / 65536
+				s.utxoScan.Unlock()
+			}
+
+			desc, ok := descs[string(entry.PkScript())]
+			if !ok {
+				return nil
+			}
+			totalAmount += entry.Amount()
+			unspents = append(unspents, acmjson.ScanTxOutSetUnspent{
+				TxID:         outpoint.Hash.String(),
+				Vout:         outpoint.Index,
+				ScriptPubKey: hex.EncodeToString(entry.PkScript()),
+				Desc:         desc,
+				Amount:       acmutil.Amount(entry.Amount()).ToBTC(),
+				Height:       int64(entry.BlockHeight()),
+			})
+			return nil
+		})
+
+	select {
+	case <-closeChan:
+		return nil, ErrClientQuit
+	default:
+	}
+
+	// The best block is only returned along with an error when the scan was
+	// aborted, in which case it is reported as unsuccessful along with the
+	// unspent outputs found so far.
+	if err != nil && bestHash == nil {
+		context := "Failed to scan utxo set"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return &acmjson.ScanTxOutSetResult{
+		Success:     err == nil,
+		TxOuts:      numScanned,
+		Height:      int64(bestHeight),
+		BestBlock:   bestHash.String(),
+		Unspents:    unspents,
+		TotalAmount: acmutil.Amount(totalAmount).ToBTC(),
+	}, nil
+}
+
 // handleSearchRawTransactions implements the searchrawtransactions command.
 func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Respond with an error if the address index is not enabled.
@@ -3401,6 +4841,75 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	return tx.Hash().String(), nil
 }
 
+// handleSetBan implements the setban command.
+func handleSetBan(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.SetBanCmd)
+
+	subnet, err := connmgr.ParseSubnet(c.Subnet)
+	if err != nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCClientInvalidIPOrSubnet,
+			Message: "Invalid IP/Subnet",
+		}
+	}
+
+	switch c.SubCmd {
+	case acmjson.SBAdd:
+		// Ban for the configured ban duration unless a ban time is
+		// given, which is either a number of seconds from now or a
+		// unix timestamp when the absolute flag is set.
+		now := time.Now()
+		until := now.Add(cfg.BanDuration)
+		if c.BanTime != nil && *c.BanTime > 0 {
+			if c.Absolute != nil && *c.Absolute {
+				until = time.Unix(*c.BanTime, 0)
+			} else {
+				banTime := time.Duration(*c.BanTime) * time.Second
+				until = now.Add(banTime)
+			}
+		}
+		if !until.After(now) {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCInvalidParameter,
+				Message: "Ban time must be in the future",
+			}
+		}
+
+		err := s.cfg.ConnMgr.BanSubnet(subnet, until)
+		if err == connmgr.ErrSubnetBanned {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCClientNodeAlreadyAdded,
+				Message: "IP/Subnet already banned",
+			}
+		}
+		if err != nil {
+			context := "Failed to ban subnet"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+	case acmjson.SBRemove:
+		err := s.cfg.ConnMgr.UnbanSubnet(subnet)
+		if err == connmgr.ErrSubnetNotBanned {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCClientInvalidIPOrSubnet,
+				Message: "Unban failed. Requested address/subnet was not previously banned",
+			}
+		}
+		if err != nil {
+			context := "Failed to unban subnet"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+	default:
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidParameter,
+			Message: "invalid subcommand for setban",
+		}
+	}
+
+	return nil, nil
+}
+
 // handleSetGenerate implements the setgenerate command.
 func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*acmjson.SetGenerateCmd)
@@ -3479,6 +4988,47 @@ func handleSubmitBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// handleTestMempoolAccept implements the testmempoolaccept command.
+func handleTestMempoolAccept(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.TestMempoolAcceptCmd)
+	if len(c.RawTxns) == 0 || len(c.RawTxns) > maxTestMempoolAcceptTxns {
+		return nil, &acmjson.RPCError{
+			Code: acmjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Array must contain between 1 and "+
+				"%d transactions", maxTestMempoolAcceptTxns),
+		}
+	}
+
+	// Deserialize all of the transactions before validating any of them.
+	txns := make([]*acmutil.Tx, 0, len(c.RawTxns))
+	for _, hexStr := range c.RawTxns {
+		if len(hexStr)%2 != 0 {
+			hexStr = "0" + hexStr
+		}
+		serializedTx, err := hex.DecodeString(hexStr)
+		if err != nil {
+			return nil, rpcDecodeHexError(hexStr)
+		}
+		var msgTx wire.MsgTx
+		err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+		if err != nil {
+			return nil, &acmjson.RPCError{
+				Code:    acmjson.ErrRPCDeserialization,
+				Message: "TX decode failed: " + err.Error(),
+			}
+		}
+		txns = append(txns, acmutil.NewTx(&msgTx))
+	}
+
+	results, err := s.cfg.TxMemPool.TestMempoolAccept(txns)
+	if err != nil {
+		context := "Failed to validate transactions"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return results, nil
+}
+
 // handleUptime implements the uptime command.
 func handleUptime(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return time.Now().Unix() - s.cfg.StartupTime, nil
@@ -3522,13 +5072,29 @@ func verifyChain(s *rpcServer, level, depth int32) error {
 		// Level 1 does basic chain sanity checks.
 		if level > 0 {
 			err := blockchain.CheckBlockSanity(block,
-				s.cfg.ChainParams.PowLimit, s.cfg.TimeSource)
+				s.cfg.ChainParams.PowLimit,
+				s.cfg.ChainParams.PowAlgorithms(),
+				s.cfg.TimeSource)
 			if err != nil {
 				rpcsLog.Errorf("Verify is unable to validate "+
 					"block at hash %v height %d: %v",
 					block.Hash(), height, err)
 				return err
 			}
+
+			// The context free sanity checks accept the proof of
+			// work of any algorithm, so ensure the one in effect at
+			// the height of the block was used.
+			powAlgo := s.cfg.ChainParams.PowAlgorithm(height)
+			err = blockchain.CheckProofOfWork(block,
+				s.cfg.ChainParams.PowLimit, powAlgo)
+			if err != nil {
+				rpcsLog.Errorf("Verify is unable to validate "+
+					"proof of work of block at hash %v "+
+					"height %d: %v", block.Hash(), height,
+					err)
+				return err
+			}
 		}
 	}
 	rpcsLog.Infof("Chain verify completed successfully")
@@ -3615,6 +5181,48 @@ func handleVerifyMessage(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return address.EncodeAddress() == c.Address, nil
 }
 
+// handleVerifyTxOutProof implements the verifytxoutproof command.
+func handleVerifyTxOutProof(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*acmjson.VerifyTxOutProofCmd)
+
+	serializedProof, err := hex.DecodeString(c.Proof)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Proof)
+	}
+	var proof wire.MsgMerkleBlock
+	err = proof.BtcDecode(bytes.NewReader(serializedProof),
+		maxProtocolVersion, wire.BaseEncoding)
+	if err != nil {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCDeserialization,
+			Message: "Proof decode failed: " + err.Error(),
+		}
+	}
+
+	// A proof which does not commit to the merkle root of its block does
+	// not prove the inclusion of any transactions.
+	matches, err := blockchain.ExtractMerkleProofMatches(&proof)
+	if err != nil {
+		rpcsLog.Debugf("Rejecting merkle proof for block %v: %v",
+			proof.Header.BlockHash(), err)
+		return []string{}, nil
+	}
+
+	blockHash := proof.Header.BlockHash()
+	if !s.cfg.Chain.MainChainHasBlock(&blockHash) {
+		return nil, &acmjson.RPCError{
+			Code:    acmjson.ErrRPCInvalidAddressOrKey,
+			Message: "Block not found in the main chain",
+		}
+	}
+
+	txIDs := make([]string, 0, len(matches))
+	for _, txHash := range matches {
+		txIDs = append(txIDs, txHash.String())
+	}
+	return txIDs, nil
+}
+
 // handleVersion implements the version command.
 //
 // NOTE: This is a btcsuite extension ported from github.com/decred/dcrd.
@@ -3644,6 +5252,7 @@ type rpcServer struct {
 	wg                     sync.WaitGroup
 	gbtWorkState           *gbtWorkState
 	helpCacher             *helpCacher
+	utxoScan               utxoScanState
 	requestProcessShutdown chan struct{}
 	quit                   chan int
 }
@@ -4222,6 +5831,21 @@ type rpcserverConnManager interface {
 	// RelayTransactions generates and relays inventory vectors for all of
 	// the passed transactions to all connected peers.
 	RelayTransactions(txns []*mempool.TxDesc)
+
+	// BanSubnet bans the provided subnet until the given time and
+	// disconnects all of the peers which are part of it.  Attempting to ban
+	// a subnet that is already banned will return an error.
+	BanSubnet(subnet *net.IPNet, until time.Time) error
+
+	// UnbanSubnet removes the ban of the provided subnet.  Attempting to
+	// unban a subnet that is not banned will return an error.
+	UnbanSubnet(subnet *net.IPNet) error
+
+	// BannedSubnets returns all of the subnets which are currently banned.
+	BannedSubnets() []connmgr.BanEntry
+
+	// ClearBanned removes the bans of all subnets.
+	ClearBanned() error
 }
 
 // rpcserverSyncManager represents a sync manager for use with the RPC server.
@@ -4272,6 +5896,13 @@ type rpcserverConfig struct {
 	// SyncMgr defines the sync manager for the RPC server to use.
 	SyncMgr rpcserverSyncManager
 
+	// AddrManager defines the address manager the RPC server uses to query
+	// the local addresses and the addresses of known peers.
+	AddrManager *addrmgr.AddrManager
+
+	// Services defines the services advertised to peers.
+	Services wire.ServiceFlag
+
 	// These fields allow the RPC server to interface with the local block
 	// chain data and state.
 	TimeSource  blockchain.MedianTimeSource
@@ -4299,6 +5930,15 @@ type rpcserverConfig struct {
 	// The fee estimator keeps track of how long transactions are left in
 	// the mempool before they are mined into blocks.
 	FeeEstimator *mempool.FeeEstimator
+
+	// MempoolFile is the path of the file the transaction memory pool is
+	// saved to.
+	MempoolFile string
+
+	// MempoolLoaded is closed once the transactions previously saved to
+	// MempoolFile have been restored.  The memory pool can't be saved
+	// before then.
+	MempoolLoaded <-chan struct{}
 }
 
 // newRPCServer returns a new instance of the rpcServer struct.
@@ -4306,7 +5946,7 @@ func newRPCServer(config *rpcserverConfig) (*rpcServer, error) {
 	rpc := rpcServer{
 		cfg:                    *config,
 		statusLines:            make(map[int]string),
-		gbtWorkState:           newGbtWorkState(config.TimeSource),
+		gbtWorkState:           newGbtWorkState(config.TimeSource, config.ChainParams),
 		helpCacher:             newHelpCacher(),
 		requestProcessShutdown: make(chan struct{}),
 		quit:                   make(chan int),
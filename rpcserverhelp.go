@@ -44,6 +44,9 @@ var helpDescsEnUS = map[string]string{
 	"transactioninput-txid": "The hash of the input transaction",
 	"transactioninput-vout": "The specific output of the input transaction to redeem",
 
+	// ClearBannedCmd help.
+	"clearbanned--synopsis": "Removes all banned IP addresses and subnets.",
+
 	// CreateRawTransactionCmd help.
 	"createrawtransaction--synopsis": "Returns a new transaction spending the provided inputs and sending to the provided addresses.\n" +
 		"The transaction inputs are not signed in the created transaction.\n" +
@@ -115,6 +118,12 @@ var helpDescsEnUS = map[string]string{
 	"decodescript--synopsis": "Returns a JSON object with information about the provided hex-encoded script.",
 	"decodescript-hexscript": "Hex-encoded script",
 
+	// DisconnectNodeCmd help.
+	"disconnectnode--synopsis": "Immediately disconnects from the specified peer.\n" +
+		"Exactly one of address and nodeid must be provided.",
+	"disconnectnode-address": "The IP address and port of the peer to disconnect",
+	"disconnectnode-nodeid":  "The peer ID of the peer to disconnect as shown by getpeerinfo",
+
 	// EstimateFeeCmd help.
 	"estimatefee--synopsis": "Estimate the fee per kilobyte in satoshis " +
 		"required for a transaction to be mined before a certain number of " +
@@ -124,6 +133,18 @@ var helpDescsEnUS = map[string]string{
 	"estimatefee--result0": "Estimated fee per kilobyte in satoshis for a block to " +
 		"be mined in the next NumBlocks blocks.",
 
+	// EstimateSmartFeeCmd help.
+	"estimatesmartfee--synopsis": "Estimate the fee per kilobyte in ACM required for a transaction to " +
+		"be confirmed within a certain number of blocks, based on how quickly transactions paying " +
+		"different fee rates were confirmed in recent blocks.",
+	"estimatesmartfee-conftarget":   "The number of blocks within which the transaction should be confirmed (1 to 1008)",
+	"estimatesmartfee-estimatemode": "The fee estimation mode, either ECONOMICAL or CONSERVATIVE, where conservative estimates are less likely to be too low",
+
+	// EstimateSmartFeeResult help.
+	"estimatesmartfeeresult-feerate": "Estimated fee per kilobyte in ACM (only present if an estimate is available)",
+	"estimatesmartfeeresult-errors":  "Errors encountered while estimating the fee (only present if no estimate is available)",
+	"estimatesmartfeeresult-blocks":  "The number of blocks the estimate is actually for, which can be lower than requested when not enough blocks have been observed",
+
 	// GenerateCmd help
 	"generate--synopsis": "Generates a set number of blocks (simnet or regtest only) and returns a JSON\n" +
 		" array of their hashes.",
@@ -147,6 +168,58 @@ var helpDescsEnUS = map[string]string{
 	"getaddednodeinfo--condition1": "dns=true",
 	"getaddednodeinfo--result0":    "List of added peers",
 
+	// AddressIndexQuery help.
+	"addressindexquery-addresses":      "The addresses to query",
+	"addressindexquery-start":          "The height of the first block to include (ignored by getaddressbalance and getaddressutxos)",
+	"addressindexquery-end":            "The height of the last block to include (ignored by getaddressbalance and getaddressutxos)",
+	"addressindexquery-includemempool": "Whether or not to include unconfirmed transactions in the memory pool",
+
+	// GetAddressBalanceResult help.
+	"getaddressbalanceresult-balance":  "The current balance of the addresses in satoshis",
+	"getaddressbalanceresult-received": "The total amount ever received by the addresses in satoshis",
+
+	// GetAddressBalanceCmd help.
+	"getaddressbalance--synopsis": "Returns the balance of the passed addresses.\n" +
+		"The address index must be enabled (--addrindex).",
+	"getaddressbalance-query": "The addresses to query and whether or not to include unconfirmed transactions",
+
+	// GetAddressDeltasResult help.
+	"getaddressdeltasresult-address":     "The address the delta applies to",
+	"getaddressdeltasresult-txid":        "The hash of the transaction which caused the delta",
+	"getaddressdeltasresult-index":       "The index of the output for credits or of the input for debits",
+	"getaddressdeltasresult-satoshis":    "The change to the balance in satoshis which is negative for debits",
+	"getaddressdeltasresult-height":      "The height of the block containing the transaction or -1 when it is unconfirmed",
+	"getaddressdeltasresult-blockindex":  "The index of the transaction within its block",
+	"getaddressdeltasresult-prevtxid":    "The hash of the transaction containing the output spent by debits",
+	"getaddressdeltasresult-prevout":     "The index of the output spent by debits",
+	"getaddressdeltasresult-spenttxid":   "The hash of the transaction spending credits in the main chain",
+	"getaddressdeltasresult-spentindex":  "The index of the input spending credits in the main chain",
+	"getaddressdeltasresult-spentheight": "The height of the block containing the transaction spending credits",
+
+	// GetAddressDeltasCmd help.
+	"getaddressdeltas--synopsis": "Returns all changes to the balance of the passed addresses in the order they appear in the blockchain.\n" +
+		"The address index must be enabled (--addrindex).",
+	"getaddressdeltas-query": "The addresses to query along with the block height range and whether or not to include unconfirmed transactions",
+
+	// GetAddressTxIDsCmd help.
+	"getaddresstxids--synopsis": "Returns the hashes of all transactions involving the passed addresses in the order they appear in the blockchain.\n" +
+		"The address index must be enabled (--addrindex).",
+	"getaddresstxids-query":    "The addresses to query along with the block height range and whether or not to include unconfirmed transactions",
+	"getaddresstxids--result0": "The hashes of the transactions",
+
+	// GetAddressUtxosResult help.
+	"getaddressutxosresult-address":     "The address the output pays to",
+	"getaddressutxosresult-txid":        "The hash of the transaction containing the output",
+	"getaddressutxosresult-outputIndex": "The index of the output",
+	"getaddressutxosresult-script":      "The hex-encoded public key script of the output",
+	"getaddressutxosresult-satoshis":    "The amount of the output in satoshis",
+	"getaddressutxosresult-height":      "The height of the block containing the output or -1 when it is unconfirmed",
+
+	// GetAddressUtxosCmd help.
+	"getaddressutxos--synopsis": "Returns all unspent outputs paying to the passed addresses.\n" +
+		"The address index must be enabled (--addrindex).",
+	"getaddressutxos-query": "The addresses to query and whether or not to include unconfirmed transactions",
+
 	// GetBestBlockResult help.
 	"getbestblockresult-hash":   "Hex-encoded bytes of the best block hash",
 	"getbestblockresult-height": "Height of the best block",
@@ -259,6 +332,15 @@ var helpDescsEnUS = map[string]string{
 	"getblockcount--synopsis": "Returns the number of blocks in the longest block chain.",
 	"getblockcount--result0":  "The current block count",
 
+	// GetBlockFilterCmd help.
+	"getblockfilter--synopsis":  "Returns the BIP0157 committed filter and filter header of a block in the main chain.",
+	"getblockfilter-blockhash":  "The hash of the block",
+	"getblockfilter-filtertype": "The type of filter to return (basic)",
+
+	// GetBlockFilterResult help.
+	"getblockfilterresult-filter": "The hex-encoded filter data",
+	"getblockfilterresult-header": "The hex-encoded filter header",
+
 	// GetBlockHashCmd help.
 	"getblockhash--synopsis": "Returns hash of the block in best block chain at the given height.",
 	"getblockhash-index":     "The block height",
@@ -286,6 +368,42 @@ var helpDescsEnUS = map[string]string{
 	"getblockheaderverboseresult-previousblockhash": "The hash of the previous block",
 	"getblockheaderverboseresult-nextblockhash":     "The hash of the next block (only if there is one)",
 
+	// GetBlockStatsCmd help.
+	"getblockstats--synopsis":    "Returns statistics about the transactions and fees of a block in the main chain.",
+	"getblockstats-hashorheight": "The hash or the height of the block",
+	"getblockstats-stats":        "The names of the statistics to return (default: all statistics)",
+
+	// GetBlockStatsResult help.
+	"getblockstatsresult-avgfee":              "The average fee of the transactions in satoshis",
+	"getblockstatsresult-avgfeerate":          "The average fee rate of the transactions in satoshis per virtual byte",
+	"getblockstatsresult-avgtxsize":           "The average size of the transactions in bytes",
+	"getblockstatsresult-blockhash":           "The hash of the block",
+	"getblockstatsresult-feerate_percentiles": "The 10th, 25th, 50th, 75th and 90th percentiles of the fee rates in satoshis per virtual byte, weighted by transaction weight",
+	"getblockstatsresult-height":              "The height of the block",
+	"getblockstatsresult-ins":                 "The number of inputs",
+	"getblockstatsresult-maxfee":              "The highest fee of the transactions in satoshis",
+	"getblockstatsresult-maxfeerate":          "The highest fee rate of the transactions in satoshis per virtual byte",
+	"getblockstatsresult-maxtxsize":           "The size of the largest transaction in bytes",
+	"getblockstatsresult-medianfee":           "The median fee of the transactions in satoshis",
+	"getblockstatsresult-mediantime":          "The median time of the past 11 blocks in seconds since 1 Jan 1970 GMT",
+	"getblockstatsresult-mediantxsize":        "The median size of the transactions in bytes",
+	"getblockstatsresult-minfee":              "The lowest fee of the transactions in satoshis",
+	"getblockstatsresult-minfeerate":          "The lowest fee rate of the transactions in satoshis per virtual byte",
+	"getblockstatsresult-mintxsize":           "The size of the smallest transaction in bytes",
+	"getblockstatsresult-outs":                "The number of outputs, including those of the coinbase",
+	"getblockstatsresult-subsidy":             "The block subsidy in satoshis",
+	"getblockstatsresult-swtotal_size":        "The total size of the segwit transactions in bytes",
+	"getblockstatsresult-swtotal_weight":      "The total weight of the segwit transactions",
+	"getblockstatsresult-swtxs":               "The number of segwit transactions",
+	"getblockstatsresult-time":                "The block time in seconds since 1 Jan 1970 GMT",
+	"getblockstatsresult-total_out":           "The total amount of the outputs of the transactions in satoshis",
+	"getblockstatsresult-total_size":          "The total size of the transactions in bytes",
+	"getblockstatsresult-total_weight":        "The total weight of the transactions",
+	"getblockstatsresult-totalfee":            "The total fees of the transactions in satoshis",
+	"getblockstatsresult-txs":                 "The number of transactions, including the coinbase",
+	"getblockstatsresult-utxo_increase":       "The change in the number of unspent transaction outputs",
+	"getblockstatsresult-utxo_size_inc":       "The change in the approximate size of the unspent transaction output set in bytes",
+
 	// TemplateRequest help.
 	"templaterequest-mode":         "This is 'template', 'proposal', or omitted",
 	"templaterequest-capabilities": "List of capabilities",
@@ -334,6 +452,7 @@ var helpDescsEnUS = map[string]string{
 	"getblocktemplateresult-reject-reason":              "Reason the proposal was invalid as-is (only applies to proposal responses)",
 	"getblocktemplateresult-default_witness_commitment": "The witness commitment itself. Will be populated if the block has witness data",
 	"getblocktemplateresult-weightlimit":                "The current limit on the max allowed weight of a block",
+	"getblocktemplateresult-powalgorithm":               "The proof-of-work algorithm the block must be hashed with (scrypt or lyra2z)",
 
 	// GetBlockTemplateCmd help.
 	"getblocktemplate--synopsis": "Returns a JSON object with information necessary to construct a block to mine or accepts a proposal to validate.\n" +
@@ -356,6 +475,15 @@ var helpDescsEnUS = map[string]string{
 	"getcfilterheader-hash":       "The hash of the block",
 	"getcfilterheader--result0":   "The block's gcs filter header",
 
+	// GetChainTipsCmd help.
+	"getchaintips--synopsis": "Returns information about all known tips in the block tree, including the main chain as well as orphaned branches.",
+
+	// GetChainTipsResult help.
+	"getchaintipsresult-height":    "The height of the chain tip",
+	"getchaintipsresult-hash":      "The block hash of the chain tip",
+	"getchaintipsresult-branchlen": "The length of the branch connecting the tip to the main chain (zero for the main chain)",
+	"getchaintipsresult-status":    "The status of the chain (active, valid-fork, valid-headers, headers-only or invalid)",
+
 	// GetConnectionCountCmd help.
 	"getconnectioncount--synopsis": "Returns the number of active connections to other peers.",
 	"getconnectioncount--result0":  "The number of connections",
@@ -415,12 +543,51 @@ var helpDescsEnUS = map[string]string{
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
 
+	// GetMempoolAncestorsCmd help.
+	"getmempoolancestors--synopsis":   "Returns all of the unconfirmed ancestors of a transaction in the memory pool.",
+	"getmempoolancestors-txid":        "The hash of the transaction",
+	"getmempoolancestors-verbose":     "Returns JSON object when true or an array of transaction hashes when false",
+	"getmempoolancestors--condition0": "verbose=false",
+	"getmempoolancestors--condition1": "verbose=true",
+	"getmempoolancestors--result0":    "Array of transaction hashes",
+
+	// GetMempoolDescendantsCmd help.
+	"getmempooldescendants--synopsis":   "Returns all of the unconfirmed descendants of a transaction in the memory pool.",
+	"getmempooldescendants-txid":        "The hash of the transaction",
+	"getmempooldescendants-verbose":     "Returns JSON object when true or an array of transaction hashes when false",
+	"getmempooldescendants--condition0": "verbose=false",
+	"getmempooldescendants--condition1": "verbose=true",
+	"getmempooldescendants--result0":    "Array of transaction hashes",
+
+	// GetMempoolEntryCmd help.
+	"getmempoolentry--synopsis": "Returns information about a transaction in the memory pool.",
+	"getmempoolentry-txid":      "The hash of the transaction",
+
+	// GetMempoolEntryResult help.
+	"getmempoolentryresult-size":             "The virtual size of the transaction",
+	"getmempoolentryresult-fee":              "Transaction fee in actiniums",
+	"getmempoolentryresult-modifiedfee":      "Transaction fee in actiniums used for mining priority",
+	"getmempoolentryresult-time":             "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
+	"getmempoolentryresult-height":           "Block height when transaction entered the pool",
+	"getmempoolentryresult-startingpriority": "Priority when transaction entered the pool",
+	"getmempoolentryresult-currentpriority":  "Current priority",
+	"getmempoolentryresult-descendantcount":  "Number of in-pool descendant transactions including this one",
+	"getmempoolentryresult-descendantsize":   "Virtual size of in-pool descendants including this one",
+	"getmempoolentryresult-descendantfees":   "Fees of in-pool descendants including this one in actiniums",
+	"getmempoolentryresult-ancestorcount":    "Number of in-pool ancestor transactions including this one",
+	"getmempoolentryresult-ancestorsize":     "Virtual size of in-pool ancestors including this one",
+	"getmempoolentryresult-ancestorfees":     "Fees of in-pool ancestors including this one in actiniums",
+	"getmempoolentryresult-depends":          "Unconfirmed transactions used as inputs for this transaction",
+
 	// GetMempoolInfoCmd help.
 	"getmempoolinfo--synopsis": "Returns memory pool information",
 
 	// GetMempoolInfoResult help.
-	"getmempoolinforesult-bytes": "Size in bytes of the mempool",
-	"getmempoolinforesult-size":  "Number of transactions in the mempool",
+	"getmempoolinforesult-bytes":         "Size in bytes of the mempool",
+	"getmempoolinforesult-size":          "Number of transactions in the mempool",
+	"getmempoolinforesult-maxmempool":    "Maximum size in bytes of the mempool",
+	"getmempoolinforesult-mempoolminfee": "Minimum fee rate in ACM/kB for a transaction to be accepted into the mempool",
+	"getmempoolinforesult-minrelaytxfee": "Minimum fee rate in ACM/kB for a transaction to be relayed",
 
 	// GetMiningInfoResult help.
 	"getmininginforesult-blocks":             "Height of the latest best block",
@@ -453,6 +620,46 @@ var helpDescsEnUS = map[string]string{
 	"getnettotalsresult-totalbytessent": "Total bytes sent",
 	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
 
+	// GetNetworkInfoCmd help.
+	"getnetworkinfo--synopsis": "Returns a JSON object containing network-related information.",
+
+	// NetworksResult help.
+	"networksresult-name":                        "The name of the network (ipv4, ipv6 or onion)",
+	"networksresult-limited":                     "Whether or not connections to the network have been disabled",
+	"networksresult-reachable":                   "Whether or not the network is reachable",
+	"networksresult-proxy":                       "The proxy used for the network, if any",
+	"networksresult-proxy_randomize_credentials": "Whether or not random proxy credentials are used for each connection (Tor stream isolation)",
+
+	// LocalAddressesResult help.
+	"localaddressesresult-address": "The local address being advertised",
+	"localaddressesresult-port":    "The port of the local address",
+	"localaddressesresult-score":   "The relative priority of the local address",
+
+	// GetNetworkInfoResult help.
+	"getnetworkinforesult-version":         "The version of the server",
+	"getnetworkinforesult-subversion":      "The user agent advertised to peers",
+	"getnetworkinforesult-protocolversion": "The latest supported protocol version",
+	"getnetworkinforesult-localservices":   "Hex-encoded services bitmask advertised to peers",
+	"getnetworkinforesult-localrelay":      "Whether or not transactions are requested from peers",
+	"getnetworkinforesult-timeoffset":      "The time offset",
+	"getnetworkinforesult-connections":     "The number of connected peers",
+	"getnetworkinforesult-networkactive":   "Whether or not networking is enabled",
+	"getnetworkinforesult-networks":        "Information about each network",
+	"getnetworkinforesult-relayfee":        "The minimum relay fee for non-free transactions in ACM/KB",
+	"getnetworkinforesult-incrementalfee":  "The minimum fee rate increase for mempool limiting or replacement in ACM/KB",
+	"getnetworkinforesult-localaddresses":  "The local addresses advertised to peers",
+	"getnetworkinforesult-warnings":        "Any network or blockchain warnings",
+
+	// GetNodeAddressesCmd help.
+	"getnodeaddresses--synopsis": "Returns randomly selected addresses of known peers which can be used to find new nodes in the network.",
+	"getnodeaddresses-count":     "The maximum number of addresses to return, or 0 to return all known addresses",
+
+	// GetNodeAddressesResult help.
+	"getnodeaddressesresult-time":     "The last time the node was seen in seconds since 1 Jan 1970 GMT",
+	"getnodeaddressesresult-services": "The services bitmask advertised by the node",
+	"getnodeaddressesresult-address":  "The IP address or onion address of the node",
+	"getnodeaddressesresult-port":     "The port of the node",
+
 	// GetPeerInfoResult help.
 	"getpeerinforesult-id":             "A unique node ID",
 	"getpeerinforesult-addr":           "The ip address and port of the peer",
@@ -519,6 +726,26 @@ var helpDescsEnUS = map[string]string{
 	"gettxout-vout":           "The index of the output",
 	"gettxout-includemempool": "Include the mempool when true",
 
+	// GetTxOutProofCmd help.
+	"gettxoutproof--synopsis": "Returns a hex-encoded proof that the transactions are included in a block.\n" +
+		"Unless the block hash is provided, the transaction index must be enabled to find the block.",
+	"gettxoutproof-txids":     "The hashes of the transactions to prove the inclusion of",
+	"gettxoutproof-blockhash": "The hash of the block containing the transactions",
+	"gettxoutproof--result0":  "The serialized merkle block proving the inclusion of the transactions",
+
+	// GetTxOutSetInfoCmd help.
+	"gettxoutsetinfo--synopsis": "Returns statistics about the unspent transaction output set.\n" +
+		"This iterates the entire set and may take some time.",
+
+	// GetTxOutSetInfoResult help.
+	"gettxoutsetinforesult-height":           "The height of the best block the statistics were calculated at",
+	"gettxoutsetinforesult-bestblock":        "The hash of the best block the statistics were calculated at",
+	"gettxoutsetinforesult-transactions":     "The number of transactions with unspent outputs",
+	"gettxoutsetinforesult-txouts":           "The number of unspent transaction outputs",
+	"gettxoutsetinforesult-bytes_serialized": "The size of the serialized unspent transaction outputs in the database",
+	"gettxoutsetinforesult-hash_serialized":  "The hash of the serialized unspent transaction outputs",
+	"gettxoutsetinforesult-total_amount":     "The total amount of all unspent transaction outputs in ACM",
+
 	// HelpCmd help.
 	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
 	"help-command":     "The command to retrieve help for",
@@ -527,10 +754,69 @@ var helpDescsEnUS = map[string]string{
 	"help--result0":    "List of commands",
 	"help--result1":    "Help for specified command",
 
+	// InvalidateBlockCmd help.
+	"invalidateblock--synopsis": "Permanently marks a block as invalid, as if it violated a consensus rule.\n" +
+		"The block and all of its descendants are disconnected from the main chain when necessary.",
+	"invalidateblock-blockhash": "The hash of the block to mark as invalid",
+
+	// ListBannedCmd help.
+	"listbanned--synopsis": "Returns a list of all banned IP addresses and subnets.",
+
+	// ListBannedResult help.
+	"listbannedresult-address":        "The banned IP address or subnet in CIDR notation",
+	"listbannedresult-ban_created":    "The time the ban was created in seconds since 1 Jan 1970 GMT",
+	"listbannedresult-banned_until":   "The time the ban expires in seconds since 1 Jan 1970 GMT",
+	"listbannedresult-ban_duration":   "The total duration of the ban in seconds",
+	"listbannedresult-time_remaining": "The number of seconds until the ban expires",
+
 	// PingCmd help.
 	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
 		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
 
+	// PruneBlockchainCmd help.
+	"pruneblockchain--synopsis": "Deletes the data of old blocks in the main chain up to the specified height.\n" +
+		"Requires the node to be running in prune mode.  The most recent 288 blocks are always retained.",
+	"pruneblockchain-height":   "The height of the most recent block to prune",
+	"pruneblockchain--result0": "The height of the most recent block whose data has been pruned",
+
+	// ReconsiderBlockCmd help.
+	"reconsiderblock--synopsis": "Removes the invalid status of a block and its ancestors and descendants, reconsidering them for activation.\n" +
+		"This can be used to undo the effects of invalidateblock.",
+	"reconsiderblock-blockhash": "The hash of the block to reconsider",
+
+	// SaveMempoolCmd help.
+	"savemempool--synopsis": "Saves the transaction memory pool to the mempool.dat file in the data directory.\n" +
+		"The saved transactions are restored on the next start unless the nopersistmempool option is set.",
+
+	// ScanTxOutSetCmd help.
+	"scantxoutset--synopsis": "Scans the unspent transaction output set for outputs paying to the provided scripts or addresses.\n" +
+		"Only a single scan may be in progress at a time.  This does not require any optional indexes.",
+	"scantxoutset-action":      "'start' to scan for the scan objects, 'abort' to abort the scan in progress, or 'status' to query the progress of the scan in progress",
+	"scantxoutset-scanobjects": "The descriptors to scan for when starting a scan: addr(<address>) or raw(<hex script>)",
+	"scantxoutset--condition0": "action=start",
+	"scantxoutset--condition1": "action=status (null when no scan is in progress)",
+	"scantxoutset--condition2": "action=abort",
+	"scantxoutset--result2":    "Whether or not a scan was aborted",
+
+	// ScanTxOutSetUnspent help.
+	"scantxoutsetunspent-txid":         "The hash of the transaction containing the output",
+	"scantxoutsetunspent-vout":         "The index of the output",
+	"scantxoutsetunspent-scriptPubKey": "The hex-encoded public key script of the output",
+	"scantxoutsetunspent-desc":         "The scan object the output matched",
+	"scantxoutsetunspent-amount":       "The amount of the output in ACM",
+	"scantxoutsetunspent-height":       "The height of the block containing the output",
+
+	// ScanTxOutSetResult help.
+	"scantxoutsetresult-success":      "Whether or not the scan completed without being aborted",
+	"scantxoutsetresult-txouts":       "The number of unspent transaction outputs scanned",
+	"scantxoutsetresult-height":       "The height of the best block the scan was performed at",
+	"scantxoutsetresult-bestblock":    "The hash of the best block the scan was performed at",
+	"scantxoutsetresult-unspents":     "The unspent outputs matching the scan objects",
+	"scantxoutsetresult-total_amount": "The total amount of the matching outputs in ACM",
+
+	// ScanTxOutSetStatusResult help.
+	"scantxoutsetstatusresult-progress": "The approximate progress of the scan in percent",
+
 	// SearchRawTransactionsCmd help.
 	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
 		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
@@ -555,6 +841,14 @@ var helpDescsEnUS = map[string]string{
 	"sendrawtransaction-maxfeerate":    "Used by Actiniumd on or after v0.19.0",
 	"sendrawtransaction--result0":      "The hash of the transaction",
 
+	// SetBanCmd help.
+	"setban--synopsis": "Attempts to add or remove an IP address or subnet from the list of banned peers.\n" +
+		"Connected peers which match an added ban are disconnected.",
+	"setban-subnet":   "The IP address or subnet in CIDR notation (an IP address without a netmask bans only that address)",
+	"setban-subcmd":   "'add' to ban the IP address or subnet, 'remove' to lift an existing ban",
+	"setban-bantime":  "The ban duration in seconds, or the time the ban expires in seconds since 1 Jan 1970 GMT when absolute is true (0 uses the --banduration option)",
+	"setban-absolute": "Whether bantime is an absolute timestamp instead of a duration",
+
 	// SetGenerateCmd help.
 	"setgenerate--synopsis":    "Set the server to generate coins (mine) or not.",
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
@@ -600,6 +894,11 @@ var helpDescsEnUS = map[string]string{
 	"verifymessage-message":   "The signed message",
 	"verifymessage--result0":  "Whether or not the signature verified",
 
+	// VerifyTxOutProofCmd help.
+	"verifytxoutproof--synopsis": "Verifies that a proof created by gettxoutproof points to transactions in a block of the main chain.",
+	"verifytxoutproof-proof":     "The hex-encoded proof returned by gettxoutproof",
+	"verifytxoutproof--result0":  "The hashes of the transactions the proof commits to, or an empty array if the proof is invalid",
+
 	// -------- Websocket-specific help --------
 
 	// Session help.
@@ -665,6 +964,22 @@ var helpDescsEnUS = map[string]string{
 	"rescannedblock-hash":         "Hash of the matching block.",
 	"rescannedblock-transactions": "List of matching transactions, serialized and hex-encoded.",
 
+	// TestMempoolAcceptCmd help.
+	"testmempoolaccept--synopsis": "Returns whether or not serialized, hex-encoded transactions would be accepted into the memory pool without adding them to it.\n" +
+		"The transactions are validated in order as a package, so each of them may spend the outputs of the transactions that precede it.",
+	"testmempoolaccept-rawtxns": "Serialized, hex-encoded transactions",
+
+	// TestMempoolAcceptResult help.
+	"testmempoolacceptresult-txid":          "The hash of the transaction",
+	"testmempoolacceptresult-wtxid":         "The witness hash of the transaction",
+	"testmempoolacceptresult-allowed":       "Whether or not the transaction would be accepted into the memory pool",
+	"testmempoolacceptresult-vsize":         "The virtual size of the transaction (only when allowed)",
+	"testmempoolacceptresult-fees":          "The fees of the transaction (only when allowed)",
+	"testmempoolacceptresult-reject-reason": "The reason the transaction would be rejected (only when not allowed)",
+
+	// TestMempoolAcceptFees help.
+	"testmempoolacceptfees-base": "The fee the transaction pays in actiniums",
+
 	// Uptime help.
 	"uptime--synopsis": "Returns the total uptime of the server.",
 	"uptime--result0":  "The number of seconds that the server has been running",
@@ -689,23 +1004,33 @@ var helpDescsEnUS = map[string]string{
 // pointer to the type (or nil to indicate no return value).
 var rpcResultTypes = map[string][]interface{}{
 	"addnode":               nil,
+	"clearbanned":           nil,
 	"createrawtransaction":  {(*string)(nil)},
 	"debuglevel":            {(*string)(nil), (*string)(nil)},
 	"decoderawtransaction":  {(*acmjson.TxRawDecodeResult)(nil)},
 	"decodescript":          {(*acmjson.DecodeScriptResult)(nil)},
+	"disconnectnode":        nil,
 	"estimatefee":           {(*float64)(nil)},
+	"estimatesmartfee":      {(*acmjson.EstimateSmartFeeResult)(nil)},
 	"generate":              {(*[]string)(nil)},
 	"getaddednodeinfo":      {(*[]string)(nil), (*[]acmjson.GetAddedNodeInfoResult)(nil)},
+	"getaddressbalance":     {(*acmjson.GetAddressBalanceResult)(nil)},
+	"getaddressdeltas":      {(*[]acmjson.GetAddressDeltasResult)(nil)},
+	"getaddresstxids":       {(*[]string)(nil)},
+	"getaddressutxos":       {(*[]acmjson.GetAddressUtxosResult)(nil)},
 	"getbestblock":          {(*acmjson.GetBestBlockResult)(nil)},
 	"getbestblockhash":      {(*string)(nil)},
 	"getblock":              {(*string)(nil), (*acmjson.GetBlockVerboseResult)(nil)},
 	"getblockcount":         {(*int64)(nil)},
+	"getblockfilter":        {(*acmjson.GetBlockFilterResult)(nil)},
 	"getblockhash":          {(*string)(nil)},
 	"getblockheader":        {(*string)(nil), (*acmjson.GetBlockHeaderVerboseResult)(nil)},
+	"getblockstats":         {(*acmjson.GetBlockStatsResult)(nil)},
 	"getblocktemplate":      {(*acmjson.GetBlockTemplateResult)(nil), (*string)(nil), nil},
 	"getblockchaininfo":     {(*acmjson.GetBlockChainInfoResult)(nil)},
 	"getcfilter":            {(*string)(nil)},
 	"getcfilterheader":      {(*string)(nil)},
+	"getchaintips":          {(*[]acmjson.GetChainTipsResult)(nil)},
 	"getconnectioncount":    {(*int32)(nil)},
 	"getcurrentnet":         {(*uint32)(nil)},
 	"getdifficulty":         {(*float64)(nil)},
@@ -713,26 +1038,42 @@ var rpcResultTypes = map[string][]interface{}{
 	"gethashespersec":       {(*float64)(nil)},
 	"getheaders":            {(*[]string)(nil)},
 	"getinfo":               {(*acmjson.InfoChainResult)(nil)},
+	"getmempoolancestors":   {(*[]string)(nil), (*acmjson.GetMempoolEntryResult)(nil)},
+	"getmempooldescendants": {(*[]string)(nil), (*acmjson.GetMempoolEntryResult)(nil)},
+	"getmempoolentry":       {(*acmjson.GetMempoolEntryResult)(nil)},
 	"getmempoolinfo":        {(*acmjson.GetMempoolInfoResult)(nil)},
 	"getmininginfo":         {(*acmjson.GetMiningInfoResult)(nil)},
 	"getnettotals":          {(*acmjson.GetNetTotalsResult)(nil)},
 	"getnetworkhashps":      {(*int64)(nil)},
+	"getnetworkinfo":        {(*acmjson.GetNetworkInfoResult)(nil)},
+	"getnodeaddresses":      {(*[]acmjson.GetNodeAddressesResult)(nil)},
 	"getpeerinfo":           {(*[]acmjson.GetPeerInfoResult)(nil)},
 	"getrawmempool":         {(*[]string)(nil), (*acmjson.GetRawMempoolVerboseResult)(nil)},
 	"getrawtransaction":     {(*string)(nil), (*acmjson.TxRawResult)(nil)},
 	"gettxout":              {(*acmjson.GetTxOutResult)(nil)},
+	"gettxoutproof":         {(*string)(nil)},
+	"gettxoutsetinfo":       {(*acmjson.GetTxOutSetInfoResult)(nil)},
 	"node":                  nil,
 	"help":                  {(*string)(nil), (*string)(nil)},
+	"invalidateblock":       nil,
+	"listbanned":            {(*[]acmjson.ListBannedResult)(nil)},
 	"ping":                  nil,
+	"pruneblockchain":       {(*int64)(nil)},
+	"reconsiderblock":       nil,
+	"savemempool":           nil,
+	"scantxoutset":          {(*acmjson.ScanTxOutSetResult)(nil), (*acmjson.ScanTxOutSetStatusResult)(nil), (*bool)(nil)},
 	"searchrawtransactions": {(*string)(nil), (*[]acmjson.SearchRawTransactionsResult)(nil)},
 	"sendrawtransaction":    {(*string)(nil)},
+	"setban":                nil,
 	"setgenerate":           nil,
 	"stop":                  {(*string)(nil)},
 	"submitblock":           {nil, (*string)(nil)},
+	"testmempoolaccept":     {(*[]acmjson.TestMempoolAcceptResult)(nil)},
 	"uptime":                {(*int64)(nil)},
 	"validateaddress":       {(*acmjson.ValidateAddressChainResult)(nil)},
 	"verifychain":           {(*bool)(nil)},
 	"verifymessage":         {(*bool)(nil)},
+	"verifytxoutproof":      {(*[]string)(nil)},
 	"version":               {(*map[string]acmjson.VersionResult)(nil)},
 
 	// Websocket commands.
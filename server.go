@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -50,6 +51,19 @@ const (
 	// required to be supported by outbound peers.
 	defaultRequiredServices = wire.SFNodeNetwork
 
+	// mempoolFilename is the name of the file in the data directory the
+	// transaction memory pool is saved to.
+	mempoolFilename = "mempool.dat"
+
+	// banListFilename is the name of the file in the data directory the
+	// banned subnets are saved to.
+	banListFilename = "banlist.json"
+
+	// anchorsFilename is the name of the file in the data directory the
+	// addresses of the block-relay-only peers are saved to on shutdown, so
+	// they can be connected to first on the next start.
+	anchorsFilename = "anchors.json"
+
 	// defaultTargetOutbound is the default number of outbound peers to target.
 	defaultTargetOutbound = 8
 
@@ -57,6 +71,24 @@ const (
 	// retries when connecting to persistent peers.  It is adjusted by the
 	// number of retries such that there is a retry backoff.
 	connectionRetryInterval = time.Second * 5
+
+	// feeFilterCheckInterval is the amount of time in between checks of
+	// whether or not the minimum fee rate of the memory pool needs to be
+	// announced to a peer via a feefilter message.
+	feeFilterCheckInterval = time.Minute
+
+	// maxFeeFilterInterval is the maximum amount of time in seconds in
+	// between regular feefilter announcements to a peer.  The actual
+	// interval is randomized up to this value for privacy reasons while
+	// significant changes are announced on the next check.
+	maxFeeFilterInterval = 20 * 60
+
+	// maxCmpctBlockDepth is the maximum depth from the tip of the best
+	// chain of the blocks that are served as compact blocks or have their
+	// transactions served through blocktxn messages.  Requests for deeper
+	// blocks are answered with the full block since the peer is unlikely
+	// to have their transactions in its mempool.
+	maxCmpctBlockDepth = 10
 )
 
 var (
@@ -151,13 +183,17 @@ type updatePeerHeightsMsg struct {
 }
 
 // peerState maintains state of inbound, persistent, outbound peers as well
-// as banned peers and outbound groups.
+// as outbound groups and peers banned by host.
 type peerState struct {
 	inboundPeers    map[int32]*serverPeer
 	outboundPeers   map[int32]*serverPeer
 	persistentPeers map[int32]*serverPeer
-	banned          map[string]time.Time
 	outboundGroups  map[string]int
+
+	// bannedHosts houses the ban expiration times of peers whose host is
+	// not an IP address, such as onion peers, and therefore can't be part
+	// of the ban list.  These bans are not persisted.
+	bannedHosts map[string]time.Time
 }
 
 // Count returns the count of all known peers.
@@ -208,6 +244,7 @@ type server struct {
 	chainParams          *chaincfg.Params
 	addrManager          *addrmgr.AddrManager
 	connManager          *connmgr.ConnManager
+	banList              *connmgr.BanList
 	sigCache             *txscript.SigCache
 	hashCache            *txscript.HashCache
 	rpcServer            *rpcServer
@@ -238,6 +275,17 @@ type server struct {
 	addrIndex *indexers.AddrIndex
 	cfIndex   *indexers.CfIndex
 
+	// indexManager manages the optional indexes.  It is nil when none of
+	// them are enabled.
+	indexManager *indexers.Manager
+
+	// mempoolLoaded is closed once the transactions of the memory pool
+	// saved on the last shutdown have been restored, or right away when
+	// the memory pool is not persisted.  The memory pool is not saved
+	// before then, since it would replace the saved transactions that
+	// were not restored yet.
+	mempoolLoaded chan struct{}
+
 	// The fee estimator keeps track of how long transactions are left in
 	// the mempool before they are mined into blocks.
 	feeEstimator *mempool.FeeEstimator
@@ -267,6 +315,7 @@ type serverPeer struct {
 	connReq        *connmgr.ConnReq
 	server         *server
 	persistent     bool
+	blockRelayOnly bool
 	continueHash   *chainhash.Hash
 	relayMtx       sync.Mutex
 	disableRelayTx bool
@@ -305,7 +354,7 @@ func (sp *serverPeer) newestBlock() (*chainhash.Hash, int32, error) {
 
 // addKnownAddresses adds the given addresses to the set of known addresses to
 // the peer to prevent sending duplicate addresses.
-func (sp *serverPeer) addKnownAddresses(addresses []*wire.NetAddress) {
+func (sp *serverPeer) addKnownAddresses(addresses []*wire.NetAddressV2) {
 	sp.addressesMtx.Lock()
 	for _, na := range addresses {
 		sp.knownAddresses[addrmgr.NetAddressKey(na)] = struct{}{}
@@ -314,7 +363,7 @@ func (sp *serverPeer) addKnownAddresses(addresses []*wire.NetAddress) {
 }
 
 // addressKnown true if the given address is already known to the peer.
-func (sp *serverPeer) addressKnown(na *wire.NetAddress) bool {
+func (sp *serverPeer) addressKnown(na *wire.NetAddressV2) bool {
 	sp.addressesMtx.RLock()
 	_, exists := sp.knownAddresses[addrmgr.NetAddressKey(na)]
 	sp.addressesMtx.RUnlock()
@@ -340,17 +389,37 @@ func (sp *serverPeer) relayTxDisabled() bool {
 	return isDisabled
 }
 
-// pushAddrMsg sends an addr message to the connected peer using the provided
+// pushAddrMsg sends an addr or addrv2 message to the connected peer using the
+// provided addresses.  Peers which did not signal support for addrv2 messages
+// (BIP0155) only receive the addresses which can be expressed as legacy
 // addresses.
-func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddress) {
+func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddressV2) {
 	// Filter addresses already known to the peer.
-	addrs := make([]*wire.NetAddress, 0, len(addresses))
+	addrs := make([]*wire.NetAddressV2, 0, len(addresses))
 	for _, addr := range addresses {
 		if !sp.addressKnown(addr) {
 			addrs = append(addrs, addr)
 		}
 	}
-	known, err := sp.PushAddrMsg(addrs)
+
+	var known []*wire.NetAddressV2
+	var err error
+	if sp.WantsAddrV2() {
+		known, err = sp.PushAddrV2Msg(addrs)
+	} else {
+		legacyAddrs := make([]*wire.NetAddress, 0, len(addrs))
+		for _, addr := range addrs {
+			if legacyAddr := addr.ToLegacy(); legacyAddr != nil {
+				legacyAddrs = append(legacyAddrs, legacyAddr)
+			}
+		}
+
+		var sent []*wire.NetAddress
+		sent, err = sp.PushAddrMsg(legacyAddrs)
+		for _, na := range sent {
+			known = append(known, wire.NewNetAddressV2FromLegacy(na))
+		}
+	}
 	if err != nil {
 		peerLog.Errorf("Can't push address message to %s: %v", sp.Peer, err)
 		sp.Disconnect()
@@ -468,8 +537,9 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) *wire.MsgRej
 	sp.server.timeSource.AddTimeSample(sp.Addr(), msg.Timestamp)
 
 	// Choose whether or not to relay transactions before a filter command
-	// is received.
-	sp.setDisableRelayTx(msg.DisableRelayTx)
+	// is received.  Transactions are never relayed to block-relay-only
+	// peers.
+	sp.setDisableRelayTx(msg.DisableRelayTx || sp.blockRelayOnly)
 
 	return nil
 }
@@ -478,6 +548,49 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) *wire.MsgRej
 // to kick start communication with them.
 func (sp *serverPeer) OnVerAck(_ *peer.Peer, _ *wire.MsgVerAck) {
 	sp.server.AddPeer(sp)
+
+	// Let the peer know about the minimum fee rate of transactions we are
+	// willing to accept when it supports fee filtering.  There is no need
+	// to do so in blocks only mode or for block-relay-only peers since
+	// transactions are not relayed.
+	if sp.ProtocolVersion() >= wire.FeeFilterVersion && !cfg.BlocksOnly &&
+		!sp.blockRelayOnly {
+
+		go sp.feeFilterHandler()
+	}
+}
+
+// feeFilterHandler periodically announces the minimum fee rate in atoms/kB a
+// transaction must pay to be accepted into the memory pool to the peer via
+// feefilter messages, so it doesn't waste bandwidth announcing transactions
+// which would be rejected anyways.  Changes of the fee rate are announced at
+// random intervals, unless they are significant, in which case they are
+// announced right away.  It must be run as a goroutine.
+func (sp *serverPeer) feeFilterHandler() {
+	ticker := time.NewTicker(feeFilterCheckInterval)
+	defer ticker.Stop()
+
+	lastSent := int64(-1)
+	var nextSend time.Time
+	for {
+		select {
+		case <-ticker.C:
+		case <-sp.quit:
+			return
+		}
+
+		minFee := int64(sp.server.txMemPool.MinFee())
+		now := time.Now()
+		significant := minFee*4 < lastSent*3 || minFee*3 > lastSent*4
+		if minFee == lastSent || (now.Before(nextSend) && !significant) {
+			continue
+		}
+
+		sp.QueueMessage(wire.NewMsgFeeFilter(minFee), nil)
+		lastSent = minFee
+		nextSend = now.Add(time.Second *
+			time.Duration(randomUint16Number(maxFeeFilterInterval)))
+	}
 }
 
 // OnMemPool is invoked when a peer receives a mempool bitcoin message.
@@ -538,6 +651,11 @@ func (sp *serverPeer) OnTx(_ *peer.Peer, msg *wire.MsgTx) {
 			msg.TxHash(), sp)
 		return
 	}
+	if sp.blockRelayOnly {
+		peerLog.Tracef("Ignoring tx %v from block-relay-only peer %v",
+			msg.TxHash(), sp)
+		return
+	}
 
 	// Add the transaction to the known inventory for the peer.
 	// Convert the raw MsgTx to a acmutil.Tx which provides some convenience
@@ -581,12 +699,73 @@ func (sp *serverPeer) OnBlock(_ *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 	<-sp.blockProcessed
 }
 
+// OnCmpctBlock is invoked when a peer receives a cmpctblock bitcoin message.
+// It blocks until the compact block has been processed by the sync manager,
+// which either reconstructs the block or requests its missing transactions.
+func (sp *serverPeer) OnCmpctBlock(_ *peer.Peer, msg *wire.MsgCmpctBlock) {
+	sp.server.syncManager.QueueCmpctBlock(msg, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
+// OnBlockTxn is invoked when a peer receives a blocktxn bitcoin message.  It
+// blocks until the transactions have been used to complete the pending
+// compact block and the resulting block has been fully processed.
+func (sp *serverPeer) OnBlockTxn(_ *peer.Peer, msg *wire.MsgBlockTxn) {
+	sp.server.syncManager.QueueBlockTxn(msg, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
+// OnGetBlockTxn is invoked when a peer receives a getblocktxn bitcoin message
+// and is used to deliver the requested transactions of a recent block the peer
+// is reconstructing from a compact block.  The full block is sent instead for
+// blocks deeper than maxCmpctBlockDepth.
+func (sp *serverPeer) OnGetBlockTxn(_ *peer.Peer, msg *wire.MsgGetBlockTxn) {
+	encoding := wire.BaseEncoding
+	if sp.CmpctBlockVersion() == 2 {
+		encoding = wire.WitnessEncoding
+	}
+
+	chain := sp.server.chain
+	height, err := chain.BlockHeightByHash(&msg.BlockHash)
+	if err != nil {
+		peerLog.Debugf("Unable to find block %v requested by "+
+			"getblocktxn from %v: %v", msg.BlockHash, sp, err)
+		return
+	}
+	if chain.BestSnapshot().Height-height >= maxCmpctBlockDepth {
+		sp.server.pushBlockMsg(sp, &msg.BlockHash, nil, nil, encoding)
+		return
+	}
+
+	block, err := chain.BlockByHash(&msg.BlockHash)
+	if err != nil {
+		peerLog.Debugf("Unable to fetch block %v requested by "+
+			"getblocktxn from %v: %v", msg.BlockHash, sp, err)
+		return
+	}
+
+	txns := block.MsgBlock().Transactions
+	blockTxn := wire.NewMsgBlockTxn(&msg.BlockHash)
+	for _, index := range msg.Indexes {
+		if int(index) >= len(txns) {
+			peerLog.Debugf("%s sent a getblocktxn request for "+
+				"block %v with out of range index %d -- "+
+				"disconnecting", sp, msg.BlockHash, index)
+			sp.addBanScore(100, 0, "getblocktxn")
+			sp.Disconnect()
+			return
+		}
+		blockTxn.AddTransaction(txns[index])
+	}
+	sp.QueueMessageWithEncoding(blockTxn, nil, encoding)
+}
+
 // OnInv is invoked when a peer receives an inv bitcoin message and is
 // used to examine the inventory being advertised by the remote peer and react
 // accordingly.  We pass the message down to blockmanager which will call
 // QueueMessage with any appropriate responses.
 func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
-	if !cfg.BlocksOnly {
+	if !cfg.BlocksOnly && !sp.blockRelayOnly {
 		if len(msg.InvList) > 0 {
 			sp.server.syncManager.QueueInv(msg, sp.Peer)
 		}
@@ -597,7 +776,7 @@ func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
 	for _, invVect := range msg.InvList {
 		if invVect.Type == wire.InvTypeTx {
 			peerLog.Tracef("Ignoring tx %v in inv from %v -- "+
-				"blocksonly enabled", invVect.Hash, sp)
+				"transaction relay disabled", invVect.Hash, sp)
 			if sp.ProtocolVersion() >= wire.BIP0037Version {
 				peerLog.Infof("Peer %v is announcing "+
 					"transactions -- disconnecting", sp)
@@ -666,6 +845,8 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 			err = sp.server.pushBlockMsg(sp, &iv.Hash, c, waitChan, wire.WitnessEncoding)
 		case wire.InvTypeBlock:
 			err = sp.server.pushBlockMsg(sp, &iv.Hash, c, waitChan, wire.BaseEncoding)
+		case wire.InvTypeCmpctBlock:
+			err = sp.server.pushCmpctBlockMsg(sp, &iv.Hash, c, waitChan)
 		case wire.InvTypeFilteredWitnessBlock:
 			err = sp.server.pushMerkleBlockMsg(sp, &iv.Hash, c, waitChan, wire.WitnessEncoding)
 		case wire.InvTypeFilteredBlock:
@@ -779,6 +960,11 @@ func (sp *serverPeer) OnGetCFilters(_ *peer.Peer, msg *wire.MsgGetCFilters) {
 		return
 	}
 
+	// Ignore the request when filters are not served.
+	if !sp.server.servesCFilters() {
+		return
+	}
+
 	// We'll also ensure that the remote party is requesting a set of
 	// filters that we actually currently maintain.
 	switch msg.FilterType {
@@ -835,6 +1021,11 @@ func (sp *serverPeer) OnGetCFHeaders(_ *peer.Peer, msg *wire.MsgGetCFHeaders) {
 		return
 	}
 
+	// Ignore the request when filters are not served.
+	if !sp.server.servesCFilters() {
+		return
+	}
+
 	// We'll also ensure that the remote party is requesting a set of
 	// headers for filters that we actually currently maintain.
 	switch msg.FilterType {
@@ -952,6 +1143,11 @@ func (sp *serverPeer) OnGetCFCheckpt(_ *peer.Peer, msg *wire.MsgGetCFCheckpt) {
 		return
 	}
 
+	// Ignore the request when filters are not served.
+	if !sp.server.servesCFilters() {
+		return
+	}
+
 	// We'll also ensure that the remote party is requesting a set of
 	// checkpoints for filters that we actually currently maintain.
 	switch msg.FilterType {
@@ -1201,7 +1397,10 @@ func (sp *serverPeer) OnFilterLoad(_ *peer.Peer, msg *wire.MsgFilterLoad) {
 		return
 	}
 
-	sp.setDisableRelayTx(false)
+	// Transactions are never relayed to block-relay-only peers.
+	if !sp.blockRelayOnly {
+		sp.setDisableRelayTx(false)
+	}
 
 	sp.filter.Reload(msg)
 }
@@ -1245,6 +1444,28 @@ func (sp *serverPeer) OnGetAddr(_ *peer.Peer, msg *wire.MsgGetAddr) {
 // OnAddr is invoked when a peer receives an addr bitcoin message and is
 // used to notify the server about advertised addresses.
 func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
+	// Ignore old style addresses which don't include a timestamp.
+	if sp.ProtocolVersion() < wire.NetAddressTimeVersion {
+		return
+	}
+
+	addrs := make([]*wire.NetAddressV2, 0, len(msg.AddrList))
+	for _, na := range msg.AddrList {
+		addrs = append(addrs, wire.NewNetAddressV2FromLegacy(na))
+	}
+	sp.handleAddrs(msg.Command(), addrs)
+}
+
+// OnAddrV2 is invoked when a peer receives an addrv2 bitcoin message and is
+// used to notify the server about advertised addresses, including those of
+// networks which can't be relayed via addr messages (BIP0155).
+func (sp *serverPeer) OnAddrV2(_ *peer.Peer, msg *wire.MsgAddrV2) {
+	sp.handleAddrs(msg.Command(), msg.AddrList)
+}
+
+// handleAddrs adds the addresses advertised by the peer through the provided
+// command to the server address manager.
+func (sp *serverPeer) handleAddrs(command string, addrs []*wire.NetAddressV2) {
 	// Ignore addresses when running on the simulation test network.  This
 	// helps prevent the network from becoming another public test network
 	// since it will not be able to learn about other peers that have not
@@ -1253,20 +1474,23 @@ func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
 		return
 	}
 
-	// Ignore old style addresses which don't include a timestamp.
-	if sp.ProtocolVersion() < wire.NetAddressTimeVersion {
+	// Ignore addresses from block-relay-only peers since addresses are not
+	// relayed over their connections.
+	if sp.blockRelayOnly {
+		peerLog.Debugf("Ignoring %s from block-relay-only peer %v",
+			command, sp)
 		return
 	}
 
 	// A message that has no addresses is invalid.
-	if len(msg.AddrList) == 0 {
+	if len(addrs) == 0 {
 		peerLog.Errorf("Command [%s] from %s does not contain any addresses",
-			msg.Command(), sp.Peer)
+			command, sp.Peer)
 		sp.Disconnect()
 		return
 	}
 
-	for _, na := range msg.AddrList {
+	for _, na := range addrs {
 		// Don't add more address if we're disconnecting.
 		if !sp.Connected() {
 			return
@@ -1281,7 +1505,7 @@ func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
 		}
 
 		// Add address to known addresses for this peer.
-		sp.addKnownAddresses([]*wire.NetAddress{na})
+		sp.addKnownAddresses([]*wire.NetAddressV2{na})
 	}
 
 	// Add addresses to server address manager.  The address manager handles
@@ -1289,7 +1513,7 @@ func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
 	// addresses, and last seen updates.
 	// XXX bitcoind gives a 2 hour time penalty here, do we want to do the
 	// same?
-	sp.server.addrManager.AddAddresses(msg.AddrList, sp.NA())
+	sp.server.addrManager.AddAddresses(addrs, sp.NA())
 }
 
 // OnRead is invoked when a peer receives a message and it is used to update
@@ -1410,11 +1634,45 @@ func (s *server) pushTxMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<-
 	return nil
 }
 
+// checkBlockServable returns an error when the node is pruning block data and
+// the block with the provided hash is not among the most recent blocks of the
+// main chain, which are the only ones a pruned node serves (BIP0159).
+func (s *server) checkBlockServable(hash *chainhash.Hash) error {
+	if !s.chain.IsPruneMode() {
+		return nil
+	}
+
+	// Blocks that are not part of the main chain are served as long as
+	// their data is still available.
+	height, err := s.chain.BlockHeightByHash(hash)
+	if err != nil {
+		return nil
+	}
+	best := s.chain.BestSnapshot()
+	if best.Height-height >= blockchain.MinBlocksToKeep {
+		return fmt.Errorf("block %v at height %d is below the most "+
+			"recent %d blocks served in prune mode", hash, height,
+			blockchain.MinBlocksToKeep)
+	}
+	return nil
+}
+
 // pushBlockMsg sends a block message for the provided block hash to the
-// connected peer.  An error is returned if the block hash is not known.
+// connected peer.  An error is returned if the block hash is not known or the
+// block data is not served because the node is pruning.
 func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<- struct{},
 	waitChan <-chan struct{}, encoding wire.MessageEncoding) error {
 
+	if err := s.checkBlockServable(hash); err != nil {
+		peerLog.Debugf("Refusing to serve block %v to %v: %v", hash,
+			sp, err)
+
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+
 	// Fetch the raw block bytes from the database.
 	var blockBytes []byte
 	err := sp.server.db.View(func(dbTx database.Tx) error {
@@ -1476,6 +1734,56 @@ func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan cha
 	return nil
 }
 
+// pushCmpctBlockMsg sends a cmpctblock message for the provided block hash to
+// the connected peer.  Blocks deeper than maxCmpctBlockDepth, as well as
+// blocks requested by peers that have not negotiated compact blocks, are sent
+// in full instead.  An error is returned if the block hash is not known.
+func (s *server) pushCmpctBlockMsg(sp *serverPeer, hash *chainhash.Hash,
+	doneChan chan<- struct{}, waitChan <-chan struct{}) error {
+
+	version := sp.CmpctBlockVersion()
+	encoding := wire.BaseEncoding
+	if version == 2 || (version == 0 && sp.IsWitnessEnabled()) {
+		encoding = wire.WitnessEncoding
+	}
+
+	height, err := s.chain.BlockHeightByHash(hash)
+	if err != nil || version == 0 ||
+		s.chain.BestSnapshot().Height-height >= maxCmpctBlockDepth {
+
+		return s.pushBlockMsg(sp, hash, doneChan, waitChan, encoding)
+	}
+
+	block, err := s.chain.BlockByHash(hash)
+	if err != nil {
+		peerLog.Tracef("Unable to fetch requested block hash %v: %v",
+			hash, err)
+
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+
+	nonce, err := wire.RandomUint64()
+	if err != nil {
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+	cmpctBlock := wire.NewMsgCmpctBlockFromBlock(block.MsgBlock(), nonce,
+		version == 2)
+
+	// Once we have fetched data wait for any previous operation to finish.
+	if waitChan != nil {
+		<-waitChan
+	}
+
+	sp.QueueMessageWithEncoding(cmpctBlock, doneChan, encoding)
+	return nil
+}
+
 // pushMerkleBlockMsg sends a merkleblock message for the provided block hash to
 // the connected peer.  Since a merkle block requires the peer to have a filter
 // loaded, this call will simply be ignored if there is no filter loaded.  An
@@ -1491,6 +1799,16 @@ func (s *server) pushMerkleBlockMsg(sp *serverPeer, hash *chainhash.Hash,
 		return nil
 	}
 
+	if err := s.checkBlockServable(hash); err != nil {
+		peerLog.Debugf("Refusing to serve merkle block %v to %v: %v",
+			hash, sp, err)
+
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+
 	// Fetch the raw block bytes from the database.
 	blk, err := sp.server.chain.BlockByHash(hash)
 	if err != nil {
@@ -1592,7 +1910,12 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		sp.Disconnect()
 		return false
 	}
-	if banEnd, ok := state.banned[host]; ok {
+	if ip := net.ParseIP(host); ip != nil && s.banList.IsBanned(ip) {
+		srvrLog.Debugf("Peer %s is banned - disconnecting", host)
+		sp.Disconnect()
+		return false
+	}
+	if banEnd, ok := state.bannedHosts[host]; ok {
 		if time.Now().Before(banEnd) {
 			srvrLog.Debugf("Peer %s is banned for another %v - disconnecting",
 				host, time.Until(banEnd))
@@ -1601,7 +1924,7 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		}
 
 		srvrLog.Infof("Peer %s is no longer banned", host)
-		delete(state.banned, host)
+		delete(state.bannedHosts, host)
 	}
 
 	// TODO: Check for max peers from a single IP.
@@ -1646,13 +1969,23 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 	if !cfg.SimNet && !sp.Inbound() {
 		// Advertise the local address when the server accepts incoming
 		// connections and it believes itself to be close to the best
-		// known tip.
-		if !cfg.DisableListen && s.syncManager.IsCurrent() {
+		// known tip.  Addresses are neither advertised to nor requested
+		// from block-relay-only peers so the connection can't be
+		// inferred from the relay of addresses.
+		if !cfg.DisableListen && !sp.blockRelayOnly &&
+			s.syncManager.IsCurrent() {
+
 			// Get address that best matches.
 			lna := s.addrManager.GetBestLocalAddress(sp.NA())
 			if addrmgr.IsRoutable(lna) {
+				// Advertise the services currently offered
+				// rather than the ones the address was added
+				// with.
+				na := *lna
+				na.Services = s.advertisedServices()
+
 				// Filter addresses the peer already knows about.
-				addresses := []*wire.NetAddress{lna}
+				addresses := []*wire.NetAddressV2{&na}
 				sp.pushAddrMsg(addresses)
 			}
 		}
@@ -1661,7 +1994,9 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		// more and the peer has a protocol version new enough to
 		// include a timestamp with addresses.
 		hasTimestamp := sp.ProtocolVersion() >= wire.NetAddressTimeVersion
-		if s.addrManager.NeedMoreAddresses() && hasTimestamp {
+		if s.addrManager.NeedMoreAddresses() && hasTimestamp &&
+			!sp.blockRelayOnly {
+
 			sp.QueueMessage(wire.NewMsgGetAddr(), nil)
 		}
 
@@ -1692,7 +2027,11 @@ func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
 			s.connManager.Disconnect(sp.connReq.ID())
 		} else {
 			s.connManager.Remove(sp.connReq.ID())
-			go s.connManager.NewConnReq()
+			if sp.blockRelayOnly {
+				go s.connManager.NewBlockRelayOnlyConnReq()
+			} else {
+				go s.connManager.NewConnReq()
+			}
 		}
 	}
 
@@ -1715,9 +2054,23 @@ func (s *server) handleBanPeerMsg(state *peerState, sp *serverPeer) {
 		return
 	}
 	direction := directionString(sp.Inbound())
+
+	// Hosts which are not IP addresses, such as onion addresses, can't be
+	// added to the ban list, so they are banned by host instead.
+	subnet, err := connmgr.ParseSubnet(host)
+	if err != nil {
+		srvrLog.Infof("Banned peer %s (%s) by host for %v", host,
+			direction, cfg.BanDuration)
+		state.bannedHosts[host] = time.Now().Add(cfg.BanDuration)
+		return
+	}
+	err = s.banList.Ban(subnet, time.Now().Add(cfg.BanDuration))
+	if err != nil && err != connmgr.ErrSubnetBanned {
+		srvrLog.Errorf("Unable to ban peer %s: %v", host, err)
+		return
+	}
 	srvrLog.Infof("Banned peer %s (%s) for %v", host, direction,
 		cfg.BanDuration)
-	state.banned[host] = time.Now().Add(cfg.BanDuration)
 }
 
 // handleRelayInvMsg deals with relaying inventory to peers that are not already
@@ -1728,16 +2081,45 @@ func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
 			return
 		}
 
+		// If the inventory is a block and the peer asked for compact
+		// blocks in high-bandwidth mode, send the block directly as a
+		// cmpctblock message instead of announcing it.
+		if msg.invVect.Type == wire.InvTypeBlock && sp.WantsCmpctBlocks() {
+			block, ok := msg.data.(*acmutil.Block)
+			if !ok {
+				peerLog.Warnf("Underlying data for compact block " +
+					"is not a block")
+				return
+			}
+			nonce, err := wire.RandomUint64()
+			if err != nil {
+				peerLog.Errorf("Failed to generate compact block "+
+					"nonce: %v", err)
+				return
+			}
+			encoding := wire.BaseEncoding
+			useWitnessHash := sp.CmpctBlockVersion() == 2
+			if useWitnessHash {
+				encoding = wire.WitnessEncoding
+			}
+			cmpctBlock := wire.NewMsgCmpctBlockFromBlock(
+				block.MsgBlock(), nonce, useWitnessHash)
+			sp.AddKnownInventory(msg.invVect)
+			sp.QueueMessageWithEncoding(cmpctBlock, nil, encoding)
+			return
+		}
+
 		// If the inventory is a block and the peer prefers headers,
 		// generate and send a headers message instead of an inventory
 		// message.
 		if msg.invVect.Type == wire.InvTypeBlock && sp.WantsHeaders() {
-			blockHeader, ok := msg.data.(wire.BlockHeader)
+			block, ok := msg.data.(*acmutil.Block)
 			if !ok {
 				peerLog.Warnf("Underlying data for headers" +
-					" is not a block header")
+					" is not a block")
 				return
 			}
+			blockHeader := block.MsgBlock().Header
 			msgHeaders := wire.NewMsgHeaders()
 			if err := msgHeaders.AddBlockHeader(&blockHeader); err != nil {
 				peerLog.Errorf("Failed to add block"+
@@ -1837,6 +2219,12 @@ type removeNodeMsg struct {
 	reply chan error
 }
 
+type banSubnetMsg struct {
+	subnet *net.IPNet
+	until  time.Time
+	reply  chan error
+}
+
 // handleQuery is the central handler for all queries and commands from other
 // goroutines related to peer state.
 func (s *server) handleQuery(state *peerState, querymsg interface{}) {
@@ -1946,6 +2334,27 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		}
 
 		msg.reply <- errors.New("peer not found")
+
+	case banSubnetMsg:
+		err := s.banList.Ban(msg.subnet, msg.until)
+		if err != nil {
+			msg.reply <- err
+			return
+		}
+
+		// Disconnect all of the peers which are part of the newly
+		// banned subnet.
+		state.forAllPeers(func(sp *serverPeer) {
+			host, _, err := net.SplitHostPort(sp.Addr())
+			if err != nil {
+				return
+			}
+			if ip := net.ParseIP(host); ip != nil && msg.subnet.Contains(ip) {
+				srvrLog.Infof("Disconnecting banned peer %s", sp)
+				sp.Disconnect()
+			}
+		})
+		msg.reply <- nil
 	}
 }
 
@@ -1984,6 +2393,9 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			OnBlock:        sp.OnBlock,
 			OnInv:          sp.OnInv,
 			OnHeaders:      sp.OnHeaders,
+			OnCmpctBlock:   sp.OnCmpctBlock,
+			OnGetBlockTxn:  sp.OnGetBlockTxn,
+			OnBlockTxn:     sp.OnBlockTxn,
 			OnGetData:      sp.OnGetData,
 			OnGetBlocks:    sp.OnGetBlocks,
 			OnGetHeaders:   sp.OnGetHeaders,
@@ -1996,6 +2408,7 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			OnFilterLoad:   sp.OnFilterLoad,
 			OnGetAddr:      sp.OnGetAddr,
 			OnAddr:         sp.OnAddr,
+			OnAddrV2:       sp.OnAddrV2,
 			OnRead:         sp.OnRead,
 			OnWrite:        sp.OnWrite,
 
@@ -2012,8 +2425,8 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 		UserAgentVersion:  userAgentVersion,
 		UserAgentComments: cfg.UserAgentComments,
 		ChainParams:       sp.server.chainParams,
-		Services:          sp.server.services,
-		DisableRelayTx:    cfg.BlocksOnly,
+		Services:          sp.server.advertisedServices(),
+		DisableRelayTx:    cfg.BlocksOnly || sp.blockRelayOnly,
 		ProtocolVersion:   peer.MaxProtocolVersion,
 		TrickleInterval:   cfg.TrickleInterval,
 	}
@@ -2038,11 +2451,15 @@ func (s *server) inboundPeerConnected(conn net.Conn) {
 // manager of the attempt.
 func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 	sp := newServerPeer(s, c.Permanent)
+	sp.blockRelayOnly = c.BlockRelayOnly
 	p, err := peer.NewOutboundPeer(newPeerConfig(sp), c.Addr.String())
 	if err != nil {
 		srvrLog.Debugf("Cannot create outbound peer %s: %v", c.Addr, err)
 		if c.Permanent {
 			s.connManager.Disconnect(c.ID())
+		} else if c.BlockRelayOnly {
+			s.connManager.Remove(c.ID())
+			go s.connManager.NewBlockRelayOnlyConnReq()
 		} else {
 			s.connManager.Remove(c.ID())
 			go s.connManager.NewConnReq()
@@ -2095,20 +2512,26 @@ func (s *server) peerHandler() {
 		inboundPeers:    make(map[int32]*serverPeer),
 		persistentPeers: make(map[int32]*serverPeer),
 		outboundPeers:   make(map[int32]*serverPeer),
-		banned:          make(map[string]time.Time),
 		outboundGroups:  make(map[string]int),
+		bannedHosts:     make(map[string]time.Time),
 	}
 
 	if !cfg.DisableDNSSeed {
 		// Add peers discovered through DNS to the address manager.
 		connmgr.SeedFromDNS(activeNetParams.Params, defaultRequiredServices,
 			acmdLookup, func(addrs []*wire.NetAddress) {
+				addrsV2 := make([]*wire.NetAddressV2, 0, len(addrs))
+				for _, na := range addrs {
+					addrsV2 = append(addrsV2,
+						wire.NewNetAddressV2FromLegacy(na))
+				}
+
 				// Bitcoind uses a lookup of the dns seeder here. This
 				// is rather strange since the values looked up by the
 				// DNS seed lookups will vary quite a lot.
 				// to replicate this behaviour we put all addresses as
 				// having come from the first one.
-				s.addrManager.AddAddresses(addrs, addrs[0])
+				s.addrManager.AddAddresses(addrsV2, addrsV2[0])
 			})
 	}
 	go s.connManager.Start()
@@ -2145,6 +2568,10 @@ out:
 			s.handleQuery(state, qmsg)
 
 		case <-s.quit:
+			// Save the block-relay-only peers as anchors for the
+			// next start before disconnecting them.
+			s.saveAnchors(state)
+
 			// Disconnect all peers on server shutdown.
 			state.forAllPeers(func(sp *serverPeer) {
 				srvrLog.Tracef("Shutdown peer %s", sp)
@@ -2177,6 +2604,27 @@ cleanup:
 	srvrLog.Tracef("Peer handler done")
 }
 
+// servesCFilters returns whether or not committed filters are served to peers.
+// They are only served once the cf index has caught up to the main chain since
+// the filters of the blocks which haven't been indexed yet are missing.
+//
+// This function is safe for concurrent access.
+func (s *server) servesCFilters() bool {
+	return s.cfIndex != nil && s.cfIndex.IsCaughtUp()
+}
+
+// advertisedServices returns the services advertised to peers.  This is the
+// configured set of services without committed filters until they are served.
+//
+// This function is safe for concurrent access.
+func (s *server) advertisedServices() wire.ServiceFlag {
+	services := s.services
+	if !s.servesCFilters() {
+		services &^= wire.SFNodeCF
+	}
+	return services
+}
+
 // AddPeer adds a new peer that has already been connected to the server.
 func (s *server) AddPeer(sp *serverPeer) {
 	s.newPeers <- sp
@@ -2320,11 +2768,29 @@ func (s *server) Start() {
 	// Server startup time. Used for the uptime command for uptime calculation.
 	s.startupTime = time.Now().Unix()
 
+	// Restore the transactions of the memory pool saved on the last
+	// shutdown in the background, since validating them can take a while
+	// with a large pool.  This means peers are already connected while it
+	// happens and the transactions they relay are processed alongside, so
+	// some of the saved transactions might be replaced or conflict with
+	// them.  Shutting down waits for it to finish.
+	if cfg.NoPersistMempool {
+		close(s.mempoolLoaded)
+	} else {
+		s.wg.Add(1)
+		go s.loadMempool()
+	}
+
 	// Start the peer handler which in turn starts the address and block
 	// managers.
 	s.wg.Add(1)
 	go s.peerHandler()
 
+	// Start catching up the indexes which are indexed in the background.
+	if s.indexManager != nil {
+		s.indexManager.Start()
+	}
+
 	if s.nat != nil {
 		s.wg.Add(1)
 		go s.upnpUpdateThread()
@@ -2365,6 +2831,23 @@ func (s *server) Stop() error {
 		s.rpcServer.Stop()
 	}
 
+	// Stop catching up indexes in the background.
+	if s.indexManager != nil {
+		s.indexManager.Stop()
+	}
+
+	// Save the memory pool so it can be restored on the next start, unless
+	// the previously saved transactions are still being restored.
+	if !cfg.NoPersistMempool {
+		select {
+		case <-s.mempoolLoaded:
+			s.saveMempool()
+		default:
+			srvrLog.Infof("Not saving mempool since it is still being " +
+				"restored")
+		}
+	}
+
 	// Save fee estimator state in the database.
 	s.db.Update(func(tx database.Tx) error {
 		metadata := tx.Metadata()
@@ -2378,6 +2861,82 @@ func (s *server) Stop() error {
 	return nil
 }
 
+// loadMempool restores the transactions of the memory pool from the file it was
+// saved to on the last shutdown and signals when it is done.  Failing to do so
+// is not fatal, so any errors are only logged.
+//
+// It must be run as a goroutine.
+func (s *server) loadMempool() {
+	path := filepath.Join(cfg.DataDir, mempoolFilename)
+	if _, err := s.txMemPool.LoadFile(path); err != nil {
+		srvrLog.Errorf("Unable to restore mempool from %s: %v", path, err)
+	}
+	close(s.mempoolLoaded)
+	s.wg.Done()
+}
+
+// saveMempool saves the transactions of the memory pool to a file so they can
+// be restored on the next start.  Any errors are only logged.
+func (s *server) saveMempool() {
+	path := filepath.Join(cfg.DataDir, mempoolFilename)
+	numTxns, err := s.txMemPool.SaveFile(path)
+	if err != nil {
+		srvrLog.Errorf("Unable to save mempool to %s: %v", path, err)
+		return
+	}
+	srvrLog.Infof("Saved %d mempool %s to %s", numTxns,
+		pickNoun(uint64(numTxns), "transaction", "transactions"), path)
+}
+
+// loadAnchors returns the addresses of the block-relay-only peers saved on the
+// last shutdown, up to the passed maximum.  Failing to load them is not fatal,
+// so any errors are only logged.
+func loadAnchors(max int) []net.Addr {
+	path := filepath.Join(cfg.DataDir, anchorsFilename)
+	anchors, err := connmgr.LoadAnchors(path)
+	if err != nil {
+		srvrLog.Errorf("Unable to load anchors from %s: %v", path, err)
+		return nil
+	}
+
+	addrs := make([]net.Addr, 0, len(anchors))
+	for _, anchor := range anchors {
+		if len(addrs) == max {
+			break
+		}
+		addr, err := addrStringToNetAddr(anchor)
+		if err != nil {
+			srvrLog.Warnf("Ignoring anchor %s: %v", anchor, err)
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// saveAnchors saves the addresses of the connected block-relay-only peers to a
+// file so they can be connected to first on the next start.  Any errors are
+// only logged.  It is invoked from the peerHandler goroutine.
+func (s *server) saveAnchors(state *peerState) {
+	var anchors []string
+	for _, sp := range state.outboundPeers {
+		if sp.blockRelayOnly && sp.Connected() {
+			anchors = append(anchors, sp.Addr())
+		}
+	}
+	if len(anchors) == 0 {
+		return
+	}
+
+	path := filepath.Join(cfg.DataDir, anchorsFilename)
+	if err := connmgr.SaveAnchors(path, anchors); err != nil {
+		srvrLog.Errorf("Unable to save anchors to %s: %v", path, err)
+		return
+	}
+	srvrLog.Infof("Saved %d %s to %s", len(anchors),
+		pickNoun(uint64(len(anchors)), "anchor", "anchors"), path)
+}
+
 // WaitForShutdown blocks until the main listener and peer handlers are stopped.
 func (s *server) WaitForShutdown() {
 	s.wg.Wait()
@@ -2495,8 +3054,8 @@ out:
 					srvrLog.Warnf("UPnP can't get external address: %v", err)
 					continue out
 				}
-				na := wire.NewNetAddressIPPort(externalip, uint16(listenPort),
-					s.services)
+				na := wire.NewNetAddressV2IPPort(externalip,
+					uint16(listenPort), s.services)
 				err = s.addrManager.AddLocalAddress(na, addrmgr.UpnpPrio)
 				if err != nil {
 					// XXX DeletePortMapping?
@@ -2584,9 +3143,21 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	if cfg.NoCFilters {
 		services &^= wire.SFNodeCF
 	}
+	if cfg.Prune != 0 {
+		// Pruned nodes only serve the most recent blocks (BIP0159).
+		services &^= wire.SFNodeNetwork
+		services |= wire.SFNodeNetworkLimited
+	}
 
 	amgr := addrmgr.New(cfg.DataDir, acmdLookup)
 
+	// Load the banned subnets.  A ban list which can't be read is not fatal
+	// since the bans only protect against misbehaving peers.
+	banList := connmgr.NewBanList(filepath.Join(cfg.DataDir, banListFilename))
+	if err := banList.Load(); err != nil {
+		srvrLog.Errorf("Unable to load ban list: %v", err)
+	}
+
 	var listeners []net.Listener
 	var nat NAT
 	if !cfg.DisableListen {
@@ -2610,6 +3181,7 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	s := server{
 		chainParams:          chainParams,
 		addrManager:          amgr,
+		banList:              banList,
 		newPeers:             make(chan *serverPeer, cfg.MaxPeers),
 		donePeers:            make(chan *serverPeer, cfg.MaxPeers),
 		banPeers:             make(chan *serverPeer, cfg.MaxPeers),
@@ -2617,6 +3189,7 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		relayInv:             make(chan relayMsg, cfg.MaxPeers),
 		broadcast:            make(chan broadcastMsg, cfg.MaxPeers),
 		quit:                 make(chan struct{}),
+		mempoolLoaded:        make(chan struct{}),
 		modifyRebroadcastInv: make(chan interface{}),
 		peerHeightsUpdate:    make(chan updatePeerHeightsMsg),
 		nat:                  nat,
@@ -2665,7 +3238,8 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	// Create an index manager if any of the optional indexes are enabled.
 	var indexManager blockchain.IndexManager
 	if len(indexes) > 0 {
-		indexManager = indexers.NewManager(db, indexes)
+		s.indexManager = indexers.NewManager(db, indexes)
+		indexManager = s.indexManager
 	}
 
 	// Merge given checkpoints with the default ones unless they are disabled.
@@ -2675,16 +3249,25 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	}
 
 	// Create a new block chain instance with the appropriate configuration.
+	// A prune option of 1 only enables pruning on request, so there is no
+	// target size in that case.
+	var pruneTarget uint64
+	if cfg.Prune > 1 {
+		pruneTarget = cfg.Prune * 1024 * 1024
+	}
 	var err error
 	s.chain, err = blockchain.New(&blockchain.Config{
-		DB:           s.db,
-		Interrupt:    interrupt,
-		ChainParams:  s.chainParams,
-		Checkpoints:  checkpoints,
-		TimeSource:   s.timeSource,
-		SigCache:     s.sigCache,
-		IndexManager: indexManager,
-		HashCache:    s.hashCache,
+		DB:               s.db,
+		Interrupt:        interrupt,
+		ChainParams:      s.chainParams,
+		Checkpoints:      checkpoints,
+		TimeSource:       s.timeSource,
+		SigCache:         s.sigCache,
+		IndexManager:     indexManager,
+		HashCache:        s.hashCache,
+		Prune:            cfg.Prune != 0,
+		PruneTarget:      pruneTarget,
+		MinimumChainWork: cfg.minimumChainWork,
 	})
 	if err != nil {
 		return nil, err
@@ -2731,6 +3314,8 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			MinRelayTxFee:        cfg.minRelayTxFee,
 			MaxTxVersion:         2,
 			RejectReplacement:    cfg.RejectReplacement,
+			MaxMempoolSize:       int64(cfg.MaxMempool) * 1000000,
+			MempoolExpiry:        cfg.MempoolExpiry,
 		},
 		ChainParams:    chainParams,
 		FetchUtxoView:  s.chain.FetchUtxoView,
@@ -2754,6 +3339,8 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		ChainParams:        s.chainParams,
 		DisableCheckpoints: cfg.DisableCheckpoints,
 		MaxPeers:           cfg.MaxPeers,
+		AssumeValid:        cfg.assumeValid,
+		MinimumChainWork:   cfg.minimumChainWork,
 		FeeEstimator:       s.feeEstimator,
 	})
 	if err != nil {
@@ -2811,6 +3398,21 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 					continue
 				}
 
+				// Don't connect to networks which can't be dialed.
+				// Onion addresses require tor to not be disabled,
+				// while I2P and CJDNS are not supported at all.
+				na := addr.NetAddress()
+				if addrmgr.IsI2P(na) || addrmgr.IsCJDNS(na) ||
+					(cfg.NoOnion && addrmgr.IsOnion(na)) {
+
+					continue
+				}
+
+				// Don't connect to banned addresses.
+				if s.banList.IsBanned(na.IP()) {
+					continue
+				}
+
 				// only allow recent nodes (10mins) after we failed 30
 				// times
 				if tries < 30 && time.Since(addr.LastAttempt()) < 10*time.Minute {
@@ -2834,19 +3436,33 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		}
 	}
 
-	// Create a connection manager.
+	// Create a connection manager.  The block-relay-only peers are only
+	// made when new addresses can be connected to, starting with the
+	// anchors saved on the last shutdown.
 	targetOutbound := defaultTargetOutbound
 	if cfg.MaxPeers < targetOutbound {
 		targetOutbound = cfg.MaxPeers
 	}
+	var targetBlockRelayOnly int
+	var anchors []net.Addr
+	if newAddressFunc != nil {
+		targetBlockRelayOnly = cfg.BlockRelayOnlyPeers
+		if cfg.MaxPeers-targetOutbound < targetBlockRelayOnly {
+			targetBlockRelayOnly = cfg.MaxPeers - targetOutbound
+		}
+		anchors = loadAnchors(targetBlockRelayOnly)
+	}
 	cmgr, err := connmgr.New(&connmgr.Config{
-		Listeners:      listeners,
-		OnAccept:       s.inboundPeerConnected,
-		RetryDuration:  connectionRetryInterval,
-		TargetOutbound: uint32(targetOutbound),
-		Dial:           acmdDial,
-		OnConnection:   s.outboundPeerConnected,
-		GetNewAddress:  newAddressFunc,
+		Listeners:            listeners,
+		OnAccept:             s.inboundPeerConnected,
+		RetryDuration:        connectionRetryInterval,
+		TargetOutbound:       uint32(targetOutbound),
+		TargetBlockRelayOnly: uint32(targetBlockRelayOnly),
+		Anchors:              anchors,
+		Dial:                 acmdDial,
+		OnConnection:         s.outboundPeerConnected,
+		GetNewAddress:        newAddressFunc,
+		BanList:              s.banList,
 	})
 	if err != nil {
 		return nil, err
@@ -2882,21 +3498,25 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		}
 
 		s.rpcServer, err = newRPCServer(&rpcserverConfig{
-			Listeners:    rpcListeners,
-			StartupTime:  s.startupTime,
-			ConnMgr:      &rpcConnManager{&s},
-			SyncMgr:      &rpcSyncMgr{&s, s.syncManager},
-			TimeSource:   s.timeSource,
-			Chain:        s.chain,
-			ChainParams:  chainParams,
-			DB:           db,
-			TxMemPool:    s.txMemPool,
-			Generator:    blockTemplateGenerator,
-			CPUMiner:     s.cpuMiner,
-			TxIndex:      s.txIndex,
-			AddrIndex:    s.addrIndex,
-			CfIndex:      s.cfIndex,
-			FeeEstimator: s.feeEstimator,
+			Listeners:     rpcListeners,
+			StartupTime:   s.startupTime,
+			ConnMgr:       &rpcConnManager{&s},
+			SyncMgr:       &rpcSyncMgr{&s, s.syncManager},
+			AddrManager:   s.addrManager,
+			Services:      s.services,
+			TimeSource:    s.timeSource,
+			Chain:         s.chain,
+			ChainParams:   chainParams,
+			DB:            db,
+			TxMemPool:     s.txMemPool,
+			Generator:     blockTemplateGenerator,
+			CPUMiner:      s.cpuMiner,
+			TxIndex:       s.txIndex,
+			AddrIndex:     s.addrIndex,
+			CfIndex:       s.cfIndex,
+			FeeEstimator:  s.feeEstimator,
+			MempoolFile:   filepath.Join(cfg.DataDir, mempoolFilename),
+			MempoolLoaded: s.mempoolLoaded,
 		})
 		if err != nil {
 			return nil, err
@@ -3019,6 +3639,9 @@ func addrStringToNetAddr(addr string) (net.Addr, error) {
 		if cfg.NoOnion {
 			return nil, errors.New("tor has been disabled")
 		}
+		if !connmgr.IsOnionHost(host) {
+			return nil, fmt.Errorf("invalid onion address %s", host)
+		}
 
 		return &onionAddr{addr: addr}, nil
 	}
@@ -3069,7 +3692,7 @@ func addLocalAddress(addrMgr *addrmgr.AddrManager, addr string, services wire.Se
 				continue
 			}
 
-			netAddr := wire.NewNetAddressIPPort(ifaceIP, uint16(port), services)
+			netAddr := wire.NewNetAddressV2IPPort(ifaceIP, uint16(port), services)
 			addrMgr.AddLocalAddress(netAddr, addrmgr.BoundPrio)
 		}
 	} else {
@@ -6,11 +6,10 @@ package wire
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"time"
 
-	"golang.org/x/crypto/scrypt"
-
 	"github.com/Actinium-project/acmd/chaincfg/chainhash"
 )
 
@@ -58,20 +57,24 @@ func (h *BlockHeader) BlockHash() chainhash.Hash {
 	return chainhash.DoubleHashH(buf.Bytes())
 }
 
-// PowHash returns the litecoin scrypt hash of this block header. This value is
-// used to check the PoW on blocks advertised on the network.
-func (h *BlockHeader) PowHash() (*chainhash.Hash, error) {
-	var powHash chainhash.Hash
+// PowHash returns the proof-of-work hash of this block header calculated with
+// the provided algorithm.  This value is used to check the PoW on blocks
+// advertised on the network.
+func (h *BlockHeader) PowHash(algo PowAlgorithm) (*chainhash.Hash, error) {
+	powHashFunc, ok := powHashFuncs[algo]
+	if !ok {
+		str := fmt.Sprintf("unsupported proof-of-work algorithm %v",
+			algo)
+		return nil, messageError("BlockHeader.PowHash", str)
+	}
 
 	buf := bytes.NewBuffer(make([]byte, 0, MaxBlockHeaderPayload))
 	_ = writeBlockHeader(buf, 0, h)
 
-	scryptHash, err := scrypt.Key(buf.Bytes(), buf.Bytes(), 1024, 1, 1, 32)
+	powHash, err := powHashFunc(buf.Bytes())
 	if err != nil {
 		return nil, err
 	}
-	copy(powHash[:], scryptHash)
-
 	return &powHash, nil
 }
 
@@ -259,3 +259,45 @@ func TestBlockHeaderSerialize(t *testing.T) {
 		}
 	}
 }
+
+// TestBlockHeaderPowHash ensures the proof-of-work hash of a block header is
+// calculated with the requested algorithm.
+func TestBlockHeaderPowHash(t *testing.T) {
+	tests := []struct {
+		name string
+		algo PowAlgorithm
+		want string // expected hash in byte-reversed hex
+	}{
+		{
+			name: "scrypt",
+			algo: PowScrypt,
+			want: "e1b9f825bc9f973f17439bdfbabaab1a724fb0bfa1ab77ecd60663f946626828",
+		},
+		{
+			name: "lyra2z",
+			algo: PowLyra2Z,
+			want: "be370833c83b7dccb585a1c82ab6c4e15a43110349c7e664027ca950c862ad9b",
+		},
+	}
+
+	header := blockOne.Header
+	for _, test := range tests {
+		hash, err := header.PowHash(test.algo)
+		if err != nil {
+			t.Errorf("PowHash (%s): unexpected error: %v", test.name,
+				err)
+			continue
+		}
+		if hash.String() != test.want {
+			t.Errorf("PowHash (%s): wrong hash - got %v, want %v",
+				test.name, hash, test.want)
+		}
+	}
+
+	// Ensure unknown algorithms are rejected.
+	_, err := header.PowHash(PowAlgorithm(0xff))
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("PowHash: expected MessageError for unknown "+
+			"algorithm, got %v", err)
+	}
+}
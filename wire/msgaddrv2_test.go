@@ -0,0 +1,149 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestAddrV2 tests the MsgAddrV2 API.
+func TestAddrV2(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "addrv2"
+	msg := NewMsgAddrV2()
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgAddrV2: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	// Num addresses (varInt) + max allowed addresses.
+	wantPayload := uint32(531009)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure addresses are added properly.
+	na := NewNetAddressV2IPPort(net.ParseIP("127.0.0.1"), 9333,
+		SFNodeNetwork)
+	if err := msg.AddAddress(na); err != nil {
+		t.Errorf("AddAddress: %v", err)
+	}
+	if msg.AddrList[0] != na {
+		t.Errorf("AddAddress: wrong address added - got %v, want %v",
+			spew.Sprint(msg.AddrList[0]), spew.Sprint(na))
+	}
+
+	// Ensure the address list is cleared properly.
+	msg.ClearAddresses()
+	if len(msg.AddrList) != 0 {
+		t.Errorf("ClearAddresses: address list is not empty - "+
+			"got %v, want 0", len(msg.AddrList))
+	}
+
+	// Ensure adding more than the max allowed addresses per message returns
+	// error.
+	var err error
+	for i := 0; i < MaxAddrPerV2Msg+1; i++ {
+		err = msg.AddAddress(na)
+	}
+	if err == nil {
+		t.Errorf("AddAddress: expected error on too many addresses " +
+			"not received")
+	}
+	err = msg.AddAddresses(na)
+	if err == nil {
+		t.Errorf("AddAddresses: expected error on too many addresses " +
+			"not received")
+	}
+}
+
+// TestAddrV2Wire tests the MsgAddrV2 wire encode and decode.
+func TestAddrV2Wire(t *testing.T) {
+	pver := ProtocolVersion
+	enc := BaseEncoding
+
+	msg := NewMsgAddrV2()
+	msg.AddAddresses(&NetAddressV2{
+		Timestamp: time.Unix(0x495fab29, 0), // 2009-01-03 12:15:05 -0600 CST
+		Services:  SFNodeNetwork,
+		Network:   NetworkIPv4,
+		Addr:      []byte{127, 0, 0, 1},
+		Port:      9333,
+	}, &NetAddressV2{
+		Timestamp: time.Unix(0x495fab29, 0), // 2009-01-03 12:15:05 -0600 CST
+		Services:  SFNodeNetwork,
+		Network:   NetworkI2P,
+		Addr:      bytes.Repeat([]byte{0x01}, 32),
+		Port:      0,
+	})
+	msgEncoded := append([]byte{
+		0x02,                   // Varint for number of addresses
+		0x29, 0xab, 0x5f, 0x49, // Timestamp
+		0x01,                   // Services (varInt)
+		0x01,                   // Network id
+		0x04,                   // Address length (varInt)
+		0x7f, 0x00, 0x00, 0x01, // IPv4 127.0.0.1
+		0x24, 0x75, // Port 9333 in big-endian
+		0x29, 0xab, 0x5f, 0x49, // Timestamp
+		0x01, // Services (varInt)
+		0x05, // Network id
+		0x20, // Address length (varInt)
+	}, append(bytes.Repeat([]byte{0x01}, 32),
+		0x00, 0x00, // Port 0
+	)...)
+
+	var buf bytes.Buffer
+	if err := msg.BtcEncode(&buf, pver, enc); err != nil {
+		t.Fatalf("BtcEncode: unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), msgEncoded) {
+		t.Fatalf("BtcEncode: got %s want %s", spew.Sdump(buf.Bytes()),
+			spew.Sdump(msgEncoded))
+	}
+
+	var readmsg MsgAddrV2
+	err := readmsg.BtcDecode(bytes.NewReader(msgEncoded), pver, enc)
+	if err != nil {
+		t.Fatalf("BtcDecode: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(&readmsg, msg) {
+		t.Fatalf("BtcDecode: got %s want %s", spew.Sdump(&readmsg),
+			spew.Sdump(msg))
+	}
+
+	// Older protocol versions should fail encode and decode since message
+	// didn't exist yet.
+	oldPver := AddrV2Version - 1
+	if err := msg.BtcEncode(&buf, oldPver, enc); err == nil {
+		t.Errorf("BtcEncode: passed for old protocol version %v",
+			oldPver)
+	}
+	err = readmsg.BtcDecode(bytes.NewReader(msgEncoded), oldPver, enc)
+	if err == nil {
+		t.Errorf("BtcDecode: passed for old protocol version %v",
+			oldPver)
+	}
+
+	// Messages claiming more than the max allowed addresses must be
+	// rejected.
+	tooMany := []byte{0xfd, 0xe9, 0x03} // Varint for 1001 addresses
+	err = readmsg.BtcDecode(bytes.NewReader(tooMany), pver, enc)
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("BtcDecode: unexpected error for too many "+
+			"addresses: %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+// PrefilledTx defines a transaction that is sent in full as part of a
+// cmpctblock message along with its index in the block.
+type PrefilledTx struct {
+	// Index is the absolute index of the transaction in the block.  It is
+	// differentially encoded on the wire.
+	Index uint32
+	Tx    *MsgTx
+}
+
+// MsgCmpctBlock implements the Message interface and represents a bitcoin
+// cmpctblock message.  It is used to relay a block as its header along with
+// short transaction ids for the transactions the receiver is expected to
+// already have and the transactions which it likely does not (BIP0152).
+//
+// The short ids and prefilled transactions together make up all of the
+// transactions in the block, where the short ids fill the positions not taken
+// by the prefilled transactions in order.
+//
+// This message was not added until protocol versions starting with
+// ShortIDsBlocksVersion.
+type MsgCmpctBlock struct {
+	Header       BlockHeader
+	Nonce        uint64
+	ShortIDs     []uint64
+	PrefilledTxs []PrefilledTx
+}
+
+// AddShortID adds a short transaction id to the message.
+func (msg *MsgCmpctBlock) AddShortID(shortID uint64) {
+	msg.ShortIDs = append(msg.ShortIDs, shortID&shortIDMask)
+}
+
+// AddPrefilledTx adds a transaction that is sent in full to the message.  The
+// transactions must be added in order of increasing index.
+func (msg *MsgCmpctBlock) AddPrefilledTx(index uint32, tx *MsgTx) {
+	msg.PrefilledTxs = append(msg.PrefilledTxs, PrefilledTx{
+		Index: index,
+		Tx:    tx,
+	})
+}
+
+// TotalTxns returns the total number of transactions in the block described by
+// the message.
+func (msg *MsgCmpctBlock) TotalTxns() int {
+	return len(msg.ShortIDs) + len(msg.PrefilledTxs)
+}
+
+// ShortIDKeys returns the SipHash keys used to calculate the short transaction
+// ids of the message.
+func (msg *MsgCmpctBlock) ShortIDKeys() (uint64, uint64) {
+	return ShortIDKeys(&msg.Header, msg.Nonce)
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < ShortIDsBlocksVersion {
+		str := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+
+	err := readBlockHeader(r, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+	err = readElement(r, &msg.Nonce)
+	if err != nil {
+		return err
+	}
+
+	// Prevent more short ids than could possibly fit into a block.
+	shortIDCount, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if shortIDCount > maxTxPerBlock {
+		str := fmt.Sprintf("too many short ids to fit into a block "+
+			"[count %d, max %d]", shortIDCount, maxTxPerBlock)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+
+	var buf [8]byte
+	msg.ShortIDs = make([]uint64, 0, shortIDCount)
+	for i := uint64(0); i < shortIDCount; i++ {
+		if _, err := io.ReadFull(r, buf[:ShortIDSize]); err != nil {
+			return err
+		}
+		msg.ShortIDs = append(msg.ShortIDs, littleEndian.Uint64(buf[:]))
+	}
+
+	prefilledCount, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	totalCount := shortIDCount + prefilledCount
+	if prefilledCount > maxTxPerBlock || totalCount > maxTxPerBlock {
+		str := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", totalCount, maxTxPerBlock)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+
+	// The indexes are differentially encoded, so each one is the offset
+	// from the index following the previous one.
+	msg.PrefilledTxs = make([]PrefilledTx, 0, prefilledCount)
+	nextIndex := uint64(0)
+	for i := uint64(0); i < prefilledCount; i++ {
+		offset, err := ReadVarInt(r, pver)
+		if err != nil {
+			return err
+		}
+		index := nextIndex + offset
+		if offset >= totalCount || index >= totalCount {
+			str := fmt.Sprintf("prefilled transaction index %d is "+
+				"out of range [count %d]", index, totalCount)
+			return messageError("MsgCmpctBlock.BtcDecode", str)
+		}
+
+		tx := MsgTx{}
+		err = tx.BtcDecode(r, pver, enc)
+		if err != nil {
+			return err
+		}
+		msg.AddPrefilledTx(uint32(index), &tx)
+		nextIndex = index + 1
+	}
+
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < ShortIDsBlocksVersion {
+		str := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctBlock.BtcEncode", str)
+	}
+
+	totalCount := msg.TotalTxns()
+	if totalCount > maxTxPerBlock {
+		str := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", totalCount, maxTxPerBlock)
+		return messageError("MsgCmpctBlock.BtcEncode", str)
+	}
+
+	err := writeBlockHeader(w, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+	err = writeElement(w, msg.Nonce)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.ShortIDs)))
+	if err != nil {
+		return err
+	}
+	var buf [8]byte
+	for _, shortID := range msg.ShortIDs {
+		littleEndian.PutUint64(buf[:], shortID)
+		if _, err := w.Write(buf[:ShortIDSize]); err != nil {
+			return err
+		}
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.PrefilledTxs)))
+	if err != nil {
+		return err
+	}
+	nextIndex := uint32(0)
+	for _, ptx := range msg.PrefilledTxs {
+		if ptx.Index < nextIndex || int(ptx.Index) >= totalCount {
+			str := fmt.Sprintf("prefilled transaction index %d is "+
+				"out of order or range [count %d]", ptx.Index,
+				totalCount)
+			return messageError("MsgCmpctBlock.BtcEncode", str)
+		}
+		err = WriteVarInt(w, pver, uint64(ptx.Index-nextIndex))
+		if err != nil {
+			return err
+		}
+		err = ptx.Tx.BtcEncode(w, pver, enc)
+		if err != nil {
+			return err
+		}
+		nextIndex = ptx.Index + 1
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgCmpctBlock) Command() string {
+	return CmdCmpctBlock
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) MaxPayloadLength(pver uint32) uint32 {
+	// A compact block can never be larger than the full block it
+	// describes.
+	return MaxBlockPayload
+}
+
+// NewMsgCmpctBlock returns a new bitcoin cmpctblock message that conforms to
+// the Message interface using the provided header and nonce.  See
+// MsgCmpctBlock for details.
+func NewMsgCmpctBlock(header *BlockHeader, nonce uint64) *MsgCmpctBlock {
+	return &MsgCmpctBlock{
+		Header:       *header,
+		Nonce:        nonce,
+		ShortIDs:     make([]uint64, 0, defaultTransactionAlloc),
+		PrefilledTxs: make([]PrefilledTx, 0, 1),
+	}
+}
+
+// NewMsgCmpctBlockFromBlock returns a new bitcoin cmpctblock message for the
+// provided block with the coinbase transaction prefilled and short ids for all
+// other transactions.  The short ids are calculated from the witness hashes of
+// the transactions when useWitnessHash is set (version 2) and from their
+// transaction hashes otherwise (version 1).
+func NewMsgCmpctBlockFromBlock(block *MsgBlock, nonce uint64, useWitnessHash bool) *MsgCmpctBlock {
+	msg := NewMsgCmpctBlock(&block.Header, nonce)
+	if len(block.Transactions) == 0 {
+		return msg
+	}
+
+	msg.AddPrefilledTx(0, block.Transactions[0])
+	k0, k1 := msg.ShortIDKeys()
+	for _, tx := range block.Transactions[1:] {
+		hash := tx.TxHash()
+		if useWitnessHash {
+			hash = tx.WitnessHash()
+		}
+		msg.AddShortID(ShortTxID(k0, k1, &hash))
+	}
+	return msg
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// testCmpctBlock returns a compact block for blockOne with two short ids and
+// the coinbase prefilled at index 1 along with its expected wire encoding.
+func testCmpctBlock() (*MsgCmpctBlock, []byte) {
+	msg := NewMsgCmpctBlock(&blockOne.Header, 0x0102030405060708)
+	msg.AddShortID(0x0a0b0c0d0e0f)
+	msg.AddShortID(0x010203040506)
+	msg.AddPrefilledTx(1, blockOne.Transactions[0])
+
+	var encoded []byte
+	encoded = append(encoded, blockOneBytes[:MaxBlockHeaderPayload]...)
+	encoded = append(encoded,
+		0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01, // Nonce
+		0x02,                               // Varint for number of short ids
+		0x0f, 0x0e, 0x0d, 0x0c, 0x0b, 0x0a, // Short id 0
+		0x06, 0x05, 0x04, 0x03, 0x02, 0x01, // Short id 1
+		0x01, // Varint for number of prefilled transactions
+		0x01, // Differential index of prefilled transaction
+	)
+	encoded = append(encoded, blockOneBytes[MaxBlockHeaderPayload+1:]...)
+	return msg, encoded
+}
+
+// TestCmpctBlockLatest tests the MsgCmpctBlock API against the latest protocol
+// version.
+func TestCmpctBlockLatest(t *testing.T) {
+	pver := ProtocolVersion
+
+	msg, _ := testCmpctBlock()
+	if msg.TotalTxns() != 3 {
+		t.Errorf("TotalTxns: wrong number of transactions - got %v, "+
+			"want %v", msg.TotalTxns(), 3)
+	}
+
+	// Ensure short ids are truncated to six bytes.
+	msg.AddShortID(0xffffffffffffffff)
+	if got := msg.ShortIDs[2]; got != 0xffffffffffff {
+		t.Errorf("AddShortID: short id not truncated - got %x", got)
+	}
+
+	// Ensure the command is expected value.
+	wantCmd := "cmpctblock"
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgCmpctBlock: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value for latest protocol version.
+	wantPayload := uint32(MaxBlockPayload)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Ensure the short id keys match the header and nonce.
+	k0, k1 := msg.ShortIDKeys()
+	wantK0, wantK1 := ShortIDKeys(&msg.Header, msg.Nonce)
+	if k0 != wantK0 || k1 != wantK1 {
+		t.Errorf("ShortIDKeys: unexpected keys - got (%x, %x), want "+
+			"(%x, %x)", k0, k1, wantK0, wantK1)
+	}
+}
+
+// TestCmpctBlockFromBlock ensures compact blocks created from full blocks
+// prefill the coinbase and use the correct hashes for the short ids.
+func TestCmpctBlockFromBlock(t *testing.T) {
+	block := NewMsgBlock(&blockOne.Header)
+	block.AddTransaction(blockOne.Transactions[0])
+	block.AddTransaction(multiTx)
+
+	for _, useWitnessHash := range []bool{false, true} {
+		msg := NewMsgCmpctBlockFromBlock(block, 123, useWitnessHash)
+		if len(msg.PrefilledTxs) != 1 || msg.PrefilledTxs[0].Index != 0 ||
+			msg.PrefilledTxs[0].Tx != blockOne.Transactions[0] {
+
+			t.Fatalf("NewMsgCmpctBlockFromBlock: coinbase not "+
+				"prefilled - got %v", spew.Sdump(msg.PrefilledTxs))
+		}
+
+		k0, k1 := ShortIDKeys(&block.Header, 123)
+		hash := multiTx.TxHash()
+		if useWitnessHash {
+			hash = multiTx.WitnessHash()
+		}
+		want := []uint64{ShortTxID(k0, k1, &hash)}
+		if !reflect.DeepEqual(msg.ShortIDs, want) {
+			t.Fatalf("NewMsgCmpctBlockFromBlock: wrong short ids "+
+				"(witness %v) - got %x, want %x", useWitnessHash,
+				msg.ShortIDs, want)
+		}
+	}
+}
+
+// TestCmpctBlockWire tests the MsgCmpctBlock wire encode and decode for various
+// protocol versions.
+func TestCmpctBlockWire(t *testing.T) {
+	msg, encoded := testCmpctBlock()
+
+	emptyMsg := NewMsgCmpctBlock(&blockOne.Header, 0)
+	emptyEncoded := append([]byte{}, blockOneBytes[:MaxBlockHeaderPayload]...)
+	emptyEncoded = append(emptyEncoded,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+		0x00, // Varint for number of short ids
+		0x00, // Varint for number of prefilled transactions
+	)
+
+	tests := []struct {
+		in   *MsgCmpctBlock // Message to encode
+		out  *MsgCmpctBlock // Expected decoded message
+		buf  []byte         // Wire encoding
+		pver uint32         // Protocol version for wire encoding
+	}{
+		// Latest protocol version.
+		{msg, msg, encoded, ProtocolVersion},
+
+		// Latest protocol version with no transactions.
+		{emptyMsg, emptyMsg, emptyEncoded, ProtocolVersion},
+
+		// Protocol version ShortIDsBlocksVersion.
+		{msg, msg, encoded, ShortIDsBlocksVersion},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgCmpctBlock
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.BtcDecode(rbuf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestCmpctBlockWireErrors performs negative tests against wire encode and
+// decode of MsgCmpctBlock to confirm error paths work correctly.
+func TestCmpctBlockWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	pverNoCmpctBlock := ShortIDsBlocksVersion - 1
+	wireErr := &MessageError{}
+
+	baseCmpctBlock, baseCmpctBlockEncoded := testCmpctBlock()
+
+	// Message with a prefilled transaction index that is out of range for
+	// the number of transactions.
+	badIndex := NewMsgCmpctBlock(&blockOne.Header, 0)
+	badIndex.AddPrefilledTx(1, blockOne.Transactions[0])
+	badIndexEncoded := append([]byte{}, blockOneBytes[:MaxBlockHeaderPayload]...)
+	badIndexEncoded = append(badIndexEncoded,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+		0x00, // Varint for number of short ids
+		0x01, // Varint for number of prefilled transactions
+		0x01, // Differential index of prefilled transaction
+	)
+
+	// Message that forces an error by having more short ids than could
+	// possibly fit into a block.
+	maxShortIDs := NewMsgCmpctBlock(&blockOne.Header, 0)
+	maxShortIDs.ShortIDs = make([]uint64, maxTxPerBlock+1)
+	maxShortIDsEncoded := append([]byte{}, blockOneBytes[:MaxBlockHeaderPayload]...)
+	maxShortIDsEncoded = append(maxShortIDsEncoded,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Nonce
+		0xfe, 0x82, 0x1a, 0x06, 0x00, // Varint for number of short ids
+	)
+
+	tests := []struct {
+		in       *MsgCmpctBlock // Value to encode
+		buf      []byte         // Wire encoding
+		pver     uint32         // Protocol version for wire encoding
+		max      int            // Max size of fixed buffer to induce errors
+		writeErr error          // Expected write error
+		readErr  error          // Expected read error
+	}{
+		// Force error in header.
+		{baseCmpctBlock, baseCmpctBlockEncoded, pver, 0, io.ErrShortWrite, io.EOF},
+		// Force error in nonce.
+		{baseCmpctBlock, baseCmpctBlockEncoded, pver, 80, io.ErrShortWrite, io.EOF},
+		// Force error in short id count.
+		{baseCmpctBlock, baseCmpctBlockEncoded, pver, 88, io.ErrShortWrite, io.EOF},
+		// Force error in short ids.
+		{baseCmpctBlock, baseCmpctBlockEncoded, pver, 89, io.ErrShortWrite, io.EOF},
+		// Force error in prefilled transaction count.
+		{baseCmpctBlock, baseCmpctBlockEncoded, pver, 101, io.ErrShortWrite, io.EOF},
+		// Force error in prefilled transaction index.
+		{baseCmpctBlock, baseCmpctBlockEncoded, pver, 102, io.ErrShortWrite, io.EOF},
+		// Force error in prefilled transaction.
+		{baseCmpctBlock, baseCmpctBlockEncoded, pver, 103, io.ErrShortWrite, io.EOF},
+		// Force error due to unsupported protocol version.
+		{baseCmpctBlock, baseCmpctBlockEncoded, pverNoCmpctBlock, 0, wireErr, wireErr},
+		// Force error with out of range prefilled transaction index.
+		{badIndex, badIndexEncoded, pver, len(badIndexEncoded), wireErr, wireErr},
+		// Force error with greater than max short ids.
+		{maxShortIDs, maxShortIDsEncoded, pver, len(maxShortIDsEncoded), wireErr, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.BtcEncode(w, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("BtcEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+
+		// For errors which are not of type MessageError, check them for
+		// equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.writeErr {
+				t.Errorf("BtcEncode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.writeErr)
+				continue
+			}
+		}
+
+		// Decode from wire format.
+		var msg MsgCmpctBlock
+		r := newFixedReader(test.max, test.buf)
+		err = msg.BtcDecode(r, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("BtcDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+
+		// For errors which are not of type MessageError, check them for
+		// equality.
+		if _, ok := err.(*MessageError); !ok {
+			if err != test.readErr {
+				t.Errorf("BtcDecode #%d wrong error got: %v, "+
+					"want: %v", i, err, test.readErr)
+				continue
+			}
+		}
+	}
+}
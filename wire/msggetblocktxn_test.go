@@ -0,0 +1,171 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestGetBlockTxnWire tests the MsgGetBlockTxn API and wire encode and decode
+// for various protocol versions.
+func TestGetBlockTxnWire(t *testing.T) {
+	hash := blockOne.BlockHash()
+
+	// Ensure the command and max payload are the expected values.
+	msg := NewMsgGetBlockTxn(&hash)
+	if cmd := msg.Command(); cmd != "getblocktxn" {
+		t.Errorf("NewMsgGetBlockTxn: wrong command - got %v want %v",
+			cmd, "getblocktxn")
+	}
+	wantPayload := uint32(3600050)
+	maxPayload := msg.MaxPayloadLength(ProtocolVersion)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length - got "+
+			"%v, want %v", maxPayload, wantPayload)
+	}
+
+	msg.AddIndex(0)
+	msg.AddIndex(1)
+	msg.AddIndex(300)
+	msg.AddIndex(302)
+	encoded := append([]byte{}, hash[:]...)
+	encoded = append(encoded,
+		0x04,             // Varint for number of indexes
+		0x00,             // Index 0
+		0x00,             // Index 1
+		0xfd, 0x2a, 0x01, // Index 300
+		0x01, // Index 302
+	)
+
+	noIndexes := NewMsgGetBlockTxn(&hash)
+	noIndexesEncoded := append(append([]byte{}, hash[:]...), 0x00)
+
+	tests := []struct {
+		in   *MsgGetBlockTxn // Message to encode
+		out  *MsgGetBlockTxn // Expected decoded message
+		buf  []byte          // Wire encoding
+		pver uint32          // Protocol version for wire encoding
+	}{
+		// Latest protocol version.
+		{msg, msg, encoded, ProtocolVersion},
+
+		// Latest protocol version with no indexes.
+		{noIndexes, noIndexes, noIndexesEncoded, ProtocolVersion},
+
+		// Protocol version ShortIDsBlocksVersion.
+		{msg, msg, encoded, ShortIDsBlocksVersion},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgGetBlockTxn
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.BtcDecode(rbuf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(&msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
+
+// TestGetBlockTxnWireErrors performs negative tests against wire encode and
+// decode of MsgGetBlockTxn to confirm error paths work correctly.
+func TestGetBlockTxnWireErrors(t *testing.T) {
+	pver := ProtocolVersion
+	pverNoGetBlockTxn := ShortIDsBlocksVersion - 1
+	wireErr := &MessageError{}
+
+	hash := chainhash.Hash{0x01}
+	baseGetBlockTxn := NewMsgGetBlockTxn(&hash)
+	baseGetBlockTxn.AddIndex(1)
+	baseGetBlockTxnEncoded := append(append([]byte{}, hash[:]...), 0x01, 0x01)
+
+	// Message with indexes that are not in increasing order.
+	unordered := NewMsgGetBlockTxn(&hash)
+	unordered.AddIndex(2)
+	unordered.AddIndex(1)
+
+	// Message with an index that is out of range.
+	badIndexEncoded := append(append([]byte{}, hash[:]...), 0x01, 0xfe,
+		0xff, 0xff, 0xff, 0xff)
+
+	// Message that forces an error by having more indexes than could
+	// possibly fit into a block.
+	maxIndexes := NewMsgGetBlockTxn(&hash)
+	for i := uint32(0); i < maxTxPerBlock+1; i++ {
+		maxIndexes.AddIndex(i)
+	}
+	maxIndexesEncoded := append(append([]byte{}, hash[:]...), 0xfe, 0x82,
+		0x1a, 0x06, 0x00)
+
+	tests := []struct {
+		in       *MsgGetBlockTxn // Value to encode
+		buf      []byte          // Wire encoding
+		pver     uint32          // Protocol version for wire encoding
+		max      int             // Max size of fixed buffer to induce errors
+		writeErr error           // Expected write error
+		readErr  error           // Expected read error
+	}{
+		// Force error in block hash.
+		{baseGetBlockTxn, baseGetBlockTxnEncoded, pver, 0, io.ErrShortWrite, io.EOF},
+		// Force error in index count.
+		{baseGetBlockTxn, baseGetBlockTxnEncoded, pver, 32, io.ErrShortWrite, io.EOF},
+		// Force error in indexes.
+		{baseGetBlockTxn, baseGetBlockTxnEncoded, pver, 33, io.ErrShortWrite, io.EOF},
+		// Force error due to unsupported protocol version.
+		{baseGetBlockTxn, baseGetBlockTxnEncoded, pverNoGetBlockTxn, 0, wireErr, wireErr},
+		// Force error with unordered and out of range indexes.
+		{unordered, badIndexEncoded, pver, len(badIndexEncoded), wireErr, wireErr},
+		// Force error with greater than max indexes.
+		{maxIndexes, maxIndexesEncoded, pver, len(maxIndexesEncoded), wireErr, wireErr},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode to wire format.
+		w := newFixedWriter(test.max)
+		err := test.in.BtcEncode(w, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.writeErr) {
+			t.Errorf("BtcEncode #%d wrong error got: %v, want: %v",
+				i, err, test.writeErr)
+			continue
+		}
+
+		// Decode from wire format.
+		var msg MsgGetBlockTxn
+		r := newFixedReader(test.max, test.buf)
+		err = msg.BtcDecode(r, test.pver, BaseEncoding)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.readErr) {
+			t.Errorf("BtcDecode #%d wrong error got: %v, want: %v",
+				i, err, test.readErr)
+			continue
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// NetworkID identifies the network a variable-length network address belongs
+// to as defined by BIP0155.
+type NetworkID uint8
+
+const (
+	// NetworkIPv4 identifies a 4 byte IPv4 address.
+	NetworkIPv4 NetworkID = 1
+
+	// NetworkIPv6 identifies a 16 byte IPv6 address.
+	NetworkIPv6 NetworkID = 2
+
+	// NetworkTorV2 identifies a 10 byte Tor v2 onion service address.
+	NetworkTorV2 NetworkID = 3
+
+	// NetworkTorV3 identifies a 32 byte Tor v3 onion service address,
+	// which is the ed25519 public key of the service.
+	NetworkTorV3 NetworkID = 4
+
+	// NetworkI2P identifies a 32 byte I2P address, which is the SHA256
+	// hash of the destination.
+	NetworkI2P NetworkID = 5
+
+	// NetworkCJDNS identifies a 16 byte CJDNS address, which is an IPv6
+	// address in the fc00::/8 range.
+	NetworkCJDNS NetworkID = 6
+)
+
+// MaxNetAddressV2Size is the maximum number of address bytes permitted for a
+// single network address in an addrv2 message.  Addresses of unknown networks
+// larger than this cause the whole message to be rejected.
+const MaxNetAddressV2Size = 512
+
+// networkAddrSizes houses the exact address size of every network known to
+// this package.
+var networkAddrSizes = map[NetworkID]int{
+	NetworkIPv4:  4,
+	NetworkIPv6:  16,
+	NetworkTorV2: 10,
+	NetworkTorV3: 32,
+	NetworkI2P:   32,
+	NetworkCJDNS: 16,
+}
+
+// Map of network IDs back to their constant names for pretty printing.
+var networkIDStrings = map[NetworkID]string{
+	NetworkIPv4:  "IPv4",
+	NetworkIPv6:  "IPv6",
+	NetworkTorV2: "TorV2",
+	NetworkTorV3: "TorV3",
+	NetworkI2P:   "I2P",
+	NetworkCJDNS: "CJDNS",
+}
+
+// String returns the NetworkID in human-readable form.
+func (n NetworkID) String() string {
+	if s, ok := networkIDStrings[n]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown NetworkID (%d)", uint8(n))
+}
+
+// IsKnown returns whether the network is one understood by this package.
+func (n NetworkID) IsKnown() bool {
+	_, ok := networkAddrSizes[n]
+	return ok
+}
+
+// onionCatPrefix is the IPv6 prefix used by OnionCat to embed Tor v2 onion
+// addresses into the fixed 16 byte address field of the legacy NetAddress.
+var onionCatPrefix = []byte{0xfd, 0x87, 0xd8, 0x7e, 0xeb, 0x43}
+
+// maxNetAddressV2Payload returns the max payload size for a single variable
+// length network address as encoded in an addrv2 message.
+func maxNetAddressV2Payload() uint32 {
+	// Timestamp 4 bytes + services (varInt) + network id 1 byte +
+	// address length (varInt) + address + port 2 bytes.
+	return 4 + MaxVarIntPayload + 1 + uint32(VarIntSerializeSize(
+		MaxNetAddressV2Size)) + MaxNetAddressV2Size + 2
+}
+
+// NetAddressV2 defines information about a peer on the network including the
+// time it was last seen, the services it supports, its variable-length address
+// and port as defined by BIP0155.  Unlike NetAddress, it is able to represent
+// addresses of networks which do not fit into 16 bytes such as Tor v3 and I2P.
+type NetAddressV2 struct {
+	// Last time the address was seen.  This is encoded as a uint32 on the
+	// wire and therefore is limited to 2106.
+	Timestamp time.Time
+
+	// Bitfield which identifies the services supported by the address.
+	// This is encoded as a variable length integer on the wire.
+	Services ServiceFlag
+
+	// Network identifies how Addr should be interpreted.
+	Network NetworkID
+
+	// Addr is the raw address in the format of Network.
+	Addr []byte
+
+	// Port the peer is using.  This is encoded in big endian on the wire
+	// which differs from most everything else.
+	Port uint16
+}
+
+// HasService returns whether the specified service is supported by the address.
+func (na *NetAddressV2) HasService(service ServiceFlag) bool {
+	return na.Services&service == service
+}
+
+// AddService adds service as a supported service by the peer generating the
+// message.
+func (na *NetAddressV2) AddService(service ServiceFlag) {
+	na.Services |= service
+}
+
+// IP returns the address as a net.IP for the networks which can be expressed
+// as one, that is IPv4, IPv6, CJDNS and Tor v2 through its OnionCat encoding.
+// nil is returned for every other network.
+func (na *NetAddressV2) IP() net.IP {
+	switch na.Network {
+	case NetworkIPv4:
+		if len(na.Addr) == 4 {
+			return net.IPv4(na.Addr[0], na.Addr[1], na.Addr[2],
+				na.Addr[3])
+		}
+
+	case NetworkIPv6, NetworkCJDNS:
+		if len(na.Addr) == 16 {
+			ip := make(net.IP, 16)
+			copy(ip, na.Addr)
+			return ip
+		}
+
+	case NetworkTorV2:
+		if len(na.Addr) == 10 {
+			ip := make(net.IP, 0, 16)
+			ip = append(ip, onionCatPrefix...)
+			return append(ip, na.Addr...)
+		}
+	}
+	return nil
+}
+
+// ToLegacy returns the address as a legacy NetAddress suitable for the addr
+// and version messages.  nil is returned when the address belongs to a network
+// which cannot be represented by a legacy address.  CJDNS addresses are
+// deliberately excluded since legacy peers would mistake them for private
+// IPv6 addresses.
+func (na *NetAddressV2) ToLegacy() *NetAddress {
+	if na.Network == NetworkCJDNS {
+		return nil
+	}
+	ip := na.IP()
+	if ip == nil {
+		return nil
+	}
+	return &NetAddress{
+		Timestamp: na.Timestamp,
+		Services:  na.Services,
+		IP:        ip,
+		Port:      na.Port,
+	}
+}
+
+// NewNetAddressV2 returns a new NetAddressV2 using the provided network,
+// address, port, and supported services with the timestamp set to the current
+// time.
+func NewNetAddressV2(network NetworkID, addr []byte, port uint16,
+	services ServiceFlag) *NetAddressV2 {
+
+	return &NetAddressV2{
+		Timestamp: time.Unix(time.Now().Unix(), 0),
+		Services:  services,
+		Network:   network,
+		Addr:      addr,
+		Port:      port,
+	}
+}
+
+// NewNetAddressV2IPPort returns a new NetAddressV2 using the provided IP, port,
+// and supported services with defaults for the remaining fields.  IPv4 and
+// OnionCat encoded Tor v2 addresses are mapped to their respective networks
+// while every other address is treated as IPv6.
+func NewNetAddressV2IPPort(ip net.IP, port uint16,
+	services ServiceFlag) *NetAddressV2 {
+
+	return NewNetAddressV2Timestamp(time.Now(), services, ip, port)
+}
+
+// NewNetAddressV2Timestamp returns a new NetAddressV2 using the provided
+// timestamp, IP, port, and supported services.  The timestamp is rounded to
+// single second precision.
+func NewNetAddressV2Timestamp(timestamp time.Time, services ServiceFlag,
+	ip net.IP, port uint16) *NetAddressV2 {
+
+	var network NetworkID
+	var addr []byte
+	switch {
+	case ip.To4() != nil:
+		network = NetworkIPv4
+		addr = append([]byte(nil), ip.To4()...)
+
+	case len(ip) == net.IPv6len && bytes.HasPrefix(ip, onionCatPrefix):
+		network = NetworkTorV2
+		addr = append([]byte(nil), ip[len(onionCatPrefix):]...)
+
+	default:
+		network = NetworkIPv6
+		addr = make([]byte, net.IPv6len)
+		copy(addr, ip.To16())
+	}
+
+	return &NetAddressV2{
+		Timestamp: time.Unix(timestamp.Unix(), 0),
+		Services:  services,
+		Network:   network,
+		Addr:      addr,
+		Port:      port,
+	}
+}
+
+// NewNetAddressV2FromLegacy returns a new NetAddressV2 describing the same
+// peer as the provided legacy NetAddress.
+func NewNetAddressV2FromLegacy(na *NetAddress) *NetAddressV2 {
+	return NewNetAddressV2Timestamp(na.Timestamp, na.Services, na.IP,
+		na.Port)
+}
+
+// readNetAddressV2 reads an encoded NetAddressV2 from r as defined by BIP0155.
+// Addresses of known networks must have the exact size of the network while
+// addresses of unknown networks are read but left for the caller to ignore.
+func readNetAddressV2(r io.Reader, pver uint32, na *NetAddressV2) error {
+	err := readElement(r, (*uint32Time)(&na.Timestamp))
+	if err != nil {
+		return err
+	}
+
+	services, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+
+	var network [1]byte
+	if _, err := io.ReadFull(r, network[:]); err != nil {
+		return err
+	}
+
+	addr, err := ReadVarBytes(r, pver, MaxNetAddressV2Size, "addr")
+	if err != nil {
+		return err
+	}
+	if size, ok := networkAddrSizes[NetworkID(network[0])]; ok &&
+		len(addr) != size {
+
+		str := fmt.Sprintf("invalid address size for network %v "+
+			"[size %d, want %d]", NetworkID(network[0]), len(addr),
+			size)
+		return messageError("readNetAddressV2", str)
+	}
+
+	// Sigh.  Bitcoin protocol mixes little and big endian.
+	port, err := binarySerializer.Uint16(r, bigEndian)
+	if err != nil {
+		return err
+	}
+
+	*na = NetAddressV2{
+		Timestamp: na.Timestamp,
+		Services:  ServiceFlag(services),
+		Network:   NetworkID(network[0]),
+		Addr:      addr,
+		Port:      port,
+	}
+	return nil
+}
+
+// writeNetAddressV2 serializes a NetAddressV2 to w as defined by BIP0155.
+func writeNetAddressV2(w io.Writer, pver uint32, na *NetAddressV2) error {
+	if len(na.Addr) > MaxNetAddressV2Size {
+		str := fmt.Sprintf("address is too large [size %d, max %d]",
+			len(na.Addr), MaxNetAddressV2Size)
+		return messageError("writeNetAddressV2", str)
+	}
+	if size, ok := networkAddrSizes[na.Network]; ok && len(na.Addr) != size {
+		str := fmt.Sprintf("invalid address size for network %v "+
+			"[size %d, want %d]", na.Network, len(na.Addr), size)
+		return messageError("writeNetAddressV2", str)
+	}
+
+	err := writeElement(w, uint32(na.Timestamp.Unix()))
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(na.Services))
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write([]byte{byte(na.Network)}); err != nil {
+		return err
+	}
+
+	err = WriteVarBytes(w, pver, na.Addr)
+	if err != nil {
+		return err
+	}
+
+	// Sigh.  Bitcoin protocol mixes little and big endian.
+	return binary.Write(w, bigEndian, na.Port)
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestNetAddressV2 tests the NetAddressV2 API.
+func TestNetAddressV2(t *testing.T) {
+	tests := []struct {
+		name    string
+		ip      net.IP
+		network NetworkID
+		addr    []byte
+		legacy  bool
+	}{
+		{
+			name:    "ipv4",
+			ip:      net.ParseIP("127.0.0.1"),
+			network: NetworkIPv4,
+			addr:    []byte{127, 0, 0, 1},
+			legacy:  true,
+		},
+		{
+			name:    "ipv6",
+			ip:      net.ParseIP("2001:db8::1"),
+			network: NetworkIPv6,
+			addr: []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0x01},
+			legacy: true,
+		},
+		{
+			name:    "onioncat",
+			ip:      net.ParseIP("fd87:d87e:eb43:102:304:506:708:90a"),
+			network: NetworkTorV2,
+			addr:    []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			legacy:  true,
+		},
+	}
+
+	for _, test := range tests {
+		na := NewNetAddressV2IPPort(test.ip, 9333, SFNodeNetwork)
+		if na.Network != test.network {
+			t.Errorf("%s: wrong network - got %v, want %v",
+				test.name, na.Network, test.network)
+		}
+		if !bytes.Equal(na.Addr, test.addr) {
+			t.Errorf("%s: wrong addr - got %x, want %x", test.name,
+				na.Addr, test.addr)
+		}
+		if !na.IP().Equal(test.ip) {
+			t.Errorf("%s: wrong ip - got %v, want %v", test.name,
+				na.IP(), test.ip)
+		}
+		if !na.HasService(SFNodeNetwork) {
+			t.Errorf("%s: SFNodeNetwork service not set", test.name)
+		}
+
+		legacy := na.ToLegacy()
+		if (legacy != nil) != test.legacy {
+			t.Errorf("%s: unexpected legacy address %v", test.name,
+				legacy)
+			continue
+		}
+		if legacy == nil {
+			continue
+		}
+		back := NewNetAddressV2FromLegacy(legacy)
+		if !reflect.DeepEqual(back, na) {
+			t.Errorf("%s: legacy round trip mismatch - got %v, "+
+				"want %v", test.name, spew.Sdump(back),
+				spew.Sdump(na))
+		}
+	}
+
+	// Addresses which only exist in addrv2 have no legacy form.
+	for _, network := range []NetworkID{NetworkTorV3, NetworkI2P,
+		NetworkCJDNS} {
+
+		addr := make([]byte, networkAddrSizes[network])
+		na := NewNetAddressV2(network, addr, 9333, 0)
+		if na.ToLegacy() != nil {
+			t.Errorf("ToLegacy: unexpected legacy address for %v",
+				network)
+		}
+	}
+}
+
+// TestNetAddressV2Wire tests the NetAddressV2 wire encode and decode.
+func TestNetAddressV2Wire(t *testing.T) {
+	pver := ProtocolVersion
+	torV3 := &NetAddressV2{
+		Timestamp: time.Unix(0x495fab29, 0), // 2009-01-03 12:15:05 -0600 CST
+		Services:  SFNodeNetwork | SFNodeWitness,
+		Network:   NetworkTorV3,
+		Addr:      bytes.Repeat([]byte{0xab}, 32),
+		Port:      9333,
+	}
+	torV3Encoded := append([]byte{
+		0x29, 0xab, 0x5f, 0x49, // Timestamp
+		0x09, // Services (varInt)
+		0x04, // Network id
+		0x20, // Address length (varInt)
+	}, append(bytes.Repeat([]byte{0xab}, 32),
+		0x24, 0x75, // Port 9333 in big-endian
+	)...)
+
+	var buf bytes.Buffer
+	if err := writeNetAddressV2(&buf, pver, torV3); err != nil {
+		t.Fatalf("writeNetAddressV2: unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), torV3Encoded) {
+		t.Fatalf("writeNetAddressV2: got %s want %s",
+			spew.Sdump(buf.Bytes()), spew.Sdump(torV3Encoded))
+	}
+
+	var na NetAddressV2
+	err := readNetAddressV2(bytes.NewReader(torV3Encoded), pver, &na)
+	if err != nil {
+		t.Fatalf("readNetAddressV2: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(&na, torV3) {
+		t.Fatalf("readNetAddressV2: got %s want %s", spew.Sdump(&na),
+			spew.Sdump(torV3))
+	}
+
+	// Addresses of known networks with the wrong size must be rejected.
+	bad := append([]byte(nil), torV3Encoded...)
+	bad[5] = byte(NetworkIPv4)
+	err = readNetAddressV2(bytes.NewReader(bad), pver, &na)
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("readNetAddressV2: unexpected error for wrong address "+
+			"size: %v", err)
+	}
+	badNA := *torV3
+	badNA.Network = NetworkIPv4
+	err = writeNetAddressV2(&bytes.Buffer{}, pver, &badNA)
+	if _, ok := err.(*MessageError); !ok {
+		t.Errorf("writeNetAddressV2: unexpected error for wrong "+
+			"address size: %v", err)
+	}
+
+	// Addresses of unknown networks are read so they can be skipped.
+	unknown := append([]byte(nil), torV3Encoded...)
+	unknown[5] = 0xff
+	err = readNetAddressV2(bytes.NewReader(unknown), pver, &na)
+	if err != nil {
+		t.Errorf("readNetAddressV2: unexpected error for unknown "+
+			"network: %v", err)
+	}
+	if na.Network.IsKnown() {
+		t.Errorf("readNetAddressV2: network %v unexpectedly known",
+			na.Network)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+	"github.com/Actinium-project/acmd/lyra2z"
+)
+
+// PowAlgorithm identifies the hash function used to calculate the
+// proof-of-work hash of a block header.  The algorithm a block must be hashed
+// with depends on its height and is defined by the network parameters.
+type PowAlgorithm uint8
+
+const (
+	// PowScrypt is the scrypt(N=1024, r=1, p=1) hash inherited from
+	// Litecoin.
+	PowScrypt PowAlgorithm = iota
+
+	// PowLyra2Z is the Lyra2Z hash Actinium switched to in order to
+	// support GPU mining.
+	PowLyra2Z
+)
+
+// Map of proof-of-work algorithms back to their constant names for pretty
+// printing.
+var powAlgorithmStrings = map[PowAlgorithm]string{
+	PowScrypt: "scrypt",
+	PowLyra2Z: "lyra2z",
+}
+
+// String returns the PowAlgorithm in human-readable form.
+func (a PowAlgorithm) String() string {
+	if s, ok := powAlgorithmStrings[a]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown PowAlgorithm (%d)", uint8(a))
+}
+
+// PowHashFunc calculates the proof-of-work hash of a serialized block header.
+type PowHashFunc func(header []byte) (chainhash.Hash, error)
+
+// powHashFuncs maps each proof-of-work algorithm to the function that
+// implements it.
+var powHashFuncs = map[PowAlgorithm]PowHashFunc{
+	PowScrypt: scryptPowHash,
+	PowLyra2Z: lyra2zPowHash,
+}
+
+// RegisterPowHashFunc registers the function used to calculate proof-of-work
+// hashes for the provided algorithm, replacing any existing one.  This allows
+// callers to provide an optimized implementation or to add new algorithms.
+//
+// NOTE: This function is not safe for concurrent access and should only be
+// called during initialization, such as from an init function.
+func RegisterPowHashFunc(algo PowAlgorithm, fn PowHashFunc) {
+	powHashFuncs[algo] = fn
+}
+
+// scryptPowHash returns the Litecoin style scrypt hash of the serialized block
+// header which uses the header as both the password and the salt.
+func scryptPowHash(header []byte) (chainhash.Hash, error) {
+	var powHash chainhash.Hash
+	scryptHash, err := scrypt.Key(header, header, 1024, 1, 1, 32)
+	if err != nil {
+		return powHash, err
+	}
+	copy(powHash[:], scryptHash)
+	return powHash, nil
+}
+
+// lyra2zPowHash returns the Lyra2Z hash of the serialized block header.
+func lyra2zPowHash(header []byte) (chainhash.Hash, error) {
+	return chainhash.Hash(lyra2z.Sum(header)), nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+)
+
+// ShortIDSize is the number of bytes a short transaction id occupies in a
+// cmpctblock message.
+const ShortIDSize = 6
+
+// shortIDMask is used to truncate a SipHash-2-4 result to a short transaction
+// id.
+const shortIDMask = 1<<(ShortIDSize*8) - 1
+
+// sipRound performs a single SipHash round on the provided state.
+func sipRound(v0, v1, v2, v3 uint64) (uint64, uint64, uint64, uint64) {
+	v0 += v1
+	v1 = bits.RotateLeft64(v1, 13)
+	v1 ^= v0
+	v0 = bits.RotateLeft64(v0, 32)
+	v2 += v3
+	v3 = bits.RotateLeft64(v3, 16)
+	v3 ^= v2
+	v0 += v3
+	v3 = bits.RotateLeft64(v3, 21)
+	v3 ^= v0
+	v2 += v1
+	v1 = bits.RotateLeft64(v1, 17)
+	v1 ^= v2
+	v2 = bits.RotateLeft64(v2, 32)
+	return v0, v1, v2, v3
+}
+
+// SipHash returns the SipHash-2-4 of the provided data using the 128-bit key
+// formed by k0 and k1.
+func SipHash(k0, k1 uint64, data []byte) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	// Compress all full 8-byte blocks.
+	length := len(data)
+	for len(data) >= 8 {
+		m := binary.LittleEndian.Uint64(data)
+		v3 ^= m
+		v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+		v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+		v0 ^= m
+		data = data[8:]
+	}
+
+	// The final block consists of the remaining bytes with the length of
+	// the data in the most significant byte.
+	m := uint64(length) << 56
+	for i, b := range data {
+		m |= uint64(b) << (8 * uint(i))
+	}
+	v3 ^= m
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0 ^= m
+
+	// Finalization.
+	v2 ^= 0xff
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+// ShortIDKeys returns the SipHash keys used to calculate the short transaction
+// ids for a compact block with the provided header and nonce as defined by
+// BIP0152.  The keys are the first two little-endian 64-bit integers of the
+// single SHA256 of the serialized header followed by the little-endian nonce.
+func ShortIDKeys(header *BlockHeader, nonce uint64) (uint64, uint64) {
+	var buf bytes.Buffer
+	buf.Grow(MaxBlockHeaderPayload + 8)
+	_ = writeBlockHeader(&buf, 0, header)
+	_ = binarySerializer.PutUint64(&buf, littleEndian, nonce)
+
+	hash := chainhash.HashB(buf.Bytes())
+	k0 := binary.LittleEndian.Uint64(hash[0:8])
+	k1 := binary.LittleEndian.Uint64(hash[8:16])
+	return k0, k1
+}
+
+// ShortTxID returns the short transaction id of the transaction with the
+// provided hash using the passed SipHash keys.  Version 1 compact blocks use
+// transaction hashes while version 2 compact blocks use witness hashes.
+func ShortTxID(k0, k1 uint64, hash *chainhash.Hash) uint64 {
+	return SipHash(k0, k1, hash[:]) & shortIDMask
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2020 The Actinium developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"testing"
+
+	"github.com/Actinium-project/acmd/chaincfg/chainhash"
+)
+
+// TestSipHash ensures SipHash-2-4 produces the expected results for the
+// reference test vectors.
+func TestSipHash(t *testing.T) {
+	// The reference vectors use the key 00 01 02 ... 0f and messages that
+	// consist of the bytes 00 01 02 ... up to the message length.
+	const k0, k1 = 0x0706050403020100, 0x0f0e0d0c0b0a0908
+	tests := []struct {
+		length int
+		want   uint64
+	}{
+		{0, 0x726fdb47dd0e0e31},
+		{8, 0x93f5f5799a932462},
+		{15, 0xa129ca6149be45e5},
+		{32, 0x7127512f72f27cce},
+	}
+
+	for _, test := range tests {
+		data := make([]byte, test.length)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		if got := SipHash(k0, k1, data); got != test.want {
+			t.Errorf("SipHash: unexpected result for length %d -- "+
+				"got %x, want %x", test.length, got, test.want)
+		}
+	}
+}
+
+// TestShortTxID ensures short transaction ids are derived from the SipHash
+// keys of the block header and nonce and are truncated to six bytes.
+func TestShortTxID(t *testing.T) {
+	k0, k1 := ShortIDKeys(&blockOne.Header, 0x0102030405060708)
+
+	// The keys must be the first 16 bytes of the single SHA256 of the
+	// header followed by the nonce.
+	var data []byte
+	data = append(data, blockOneBytes[:MaxBlockHeaderPayload]...)
+	data = append(data, 0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01)
+	hash := chainhash.HashB(data)
+	wantK0 := littleEndian.Uint64(hash[0:8])
+	wantK1 := littleEndian.Uint64(hash[8:16])
+	if k0 != wantK0 || k1 != wantK1 {
+		t.Fatalf("ShortIDKeys: unexpected keys -- got (%x, %x), want "+
+			"(%x, %x)", k0, k1, wantK0, wantK1)
+	}
+
+	txHash := blockOne.Transactions[0].TxHash()
+	shortID := ShortTxID(k0, k1, &txHash)
+	if shortID>>(ShortIDSize*8) != 0 {
+		t.Fatalf("ShortTxID: short id %x is larger than %d bytes",
+			shortID, ShortIDSize)
+	}
+	want := SipHash(k0, k1, txHash[:]) & 0xffffffffffff
+	if shortID != want {
+		t.Fatalf("ShortTxID: unexpected short id -- got %x, want %x",
+			shortID, want)
+	}
+}